@@ -19,6 +19,7 @@ package btp
 import (
 	"github.com/icon-project/goloop/common/atomic"
 	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/module"
 )
 
@@ -126,3 +127,57 @@ func NewBTPBlockHeader(
 	}
 	return bb, nil
 }
+
+// NewBTPBlockHeaderFromBytes returns a BTPBlockHeader decoded from bytes
+// returned by HeaderBytes.
+func NewBTPBlockHeaderFromBytes(bs []byte) (module.BTPBlockHeader, error) {
+	bh := &btpBlockHeader{}
+	if _, err := codec.UnmarshalFromBytes(bs, &bh.format); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "invalid BTP block header bytes")
+	}
+	return bh, nil
+}
+
+// NetworkSectionHashOf returns the hash of the network section described
+// by the header.
+func NetworkSectionHashOf(mod module.NetworkTypeModule, bh module.BTPBlockHeader) []byte {
+	nsFormat := networkSectionFormat{
+		NetworkID:    bh.NetworkID(),
+		UpdateNumber: bh.UpdateNumber(),
+		PrevHash:     bh.PrevNetworkSectionHash(),
+		MessageCount: bh.MessageCount(),
+		MessagesRoot: bh.MessagesRoot(),
+	}
+	return mod.Hash(codec.MustMarshalToBytes(&nsFormat))
+}
+
+// NetworkTypeSectionHashOf returns the hash of the network type section
+// which includes the network section described by the header. The network
+// sections root is calculated from NetworkSectionToRoot of the header.
+func NetworkTypeSectionHashOf(mod module.NetworkTypeModule, bh module.BTPBlockHeader) []byte {
+	root := NetworkSectionHashOf(mod, bh)
+	for _, n := range bh.NetworkSectionToRoot() {
+		if n.Value == nil {
+			continue
+		}
+		if n.Dir == module.DirLeft {
+			root = mod.Hash(append(append([]byte{}, n.Value...), root...))
+		} else {
+			root = mod.Hash(append(append([]byte{}, root...), n.Value...))
+		}
+	}
+	ntsFormat := networkTypeSectionFormat{
+		NextProofContextHash: bh.NextProofContextHash(),
+		NetworkSectionsRoot:  root,
+	}
+	return mod.Hash(codec.MustMarshalToBytes(&ntsFormat))
+}
+
+// MessagesRootOf returns the messages root of the messages.
+func MessagesRootOf(mod module.NetworkTypeModule, msgs [][]byte) []byte {
+	hc := makeHashesCat(len(msgs) * hashLen)
+	for _, msg := range msgs {
+		hc.Append(mod.Hash(msg))
+	}
+	return mod.MerkleRoot(&hc)
+}
@@ -53,3 +53,26 @@ func TestBTPBlockHeader_Basics(t *testing.T) {
 	codec.MustUnmarshalFromBytes(bs, &bb2.format)
 	assert.EqualValues(bb.(*btpBlockHeader).format, bb2.format)
 }
+
+func TestBTPBlockHeader_FromBytes(t *testing.T) {
+	assert := assert.New(t)
+	s := newComplexTestBuilderSetup(t)
+	nts, err := s.bs.NetworkTypeSectionFor(2)
+	assert.NoError(err)
+	ns, err := nts.NetworkSectionFor(3)
+	assert.NoError(err)
+	bb, err := NewBTPBlockHeader(10, 1, nts, 3, 0)
+	assert.NoError(err)
+
+	bb2, err := NewBTPBlockHeaderFromBytes(bb.HeaderBytes())
+	assert.NoError(err)
+	assert.EqualValues(bb.HeaderBytes(), bb2.HeaderBytes())
+	assert.EqualValues(ns.Hash(), NetworkSectionHashOf(s.mod, bb2))
+	assert.EqualValues(nts.Hash(), NetworkTypeSectionHashOf(s.mod, bb2))
+	msgs := [][]byte{[]byte("b"), []byte("c"), []byte("d")}
+	assert.EqualValues(ns.MessagesRoot(), MessagesRootOf(s.mod, msgs))
+	assert.Nil(MessagesRootOf(s.mod, nil))
+
+	_, err = NewBTPBlockHeaderFromBytes([]byte("invalid"))
+	assert.Error(err)
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"math/big"
+
+	bls "github.com/kilic/bls12-381"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+// BLS signature over BLS12-381 with public keys in G1 and signatures in G2
+// (minimal-pubkey-size variant). Public key is registered with its proof
+// of possession to prevent rogue key attack on aggregated signatures.
+const (
+	blsDSA          = "bls/bls12-381"
+	blsPublicKeyLen = 48
+	blsSignatureLen = 96
+)
+
+var (
+	blsSignatureDST = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+	blsPoPDST       = []byte("BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+)
+
+func parseBLSPublicKey(pubKey []byte) (*bls.PointG1, error) {
+	if len(pubKey) != blsPublicKeyLen {
+		return nil, errors.IllegalArgumentError.Errorf("invalid bls public key length=%d", len(pubKey))
+	}
+	g1 := bls.NewG1()
+	p, err := g1.FromCompressed(pubKey)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "invalid bls public key")
+	}
+	if g1.IsZero(p) {
+		return nil, errors.IllegalArgumentError.New("bls public key is infinity")
+	}
+	return p, nil
+}
+
+func parseBLSSignature(sig []byte) (*bls.PointG2, error) {
+	if len(sig) != blsSignatureLen {
+		return nil, errors.IllegalArgumentError.Errorf("invalid bls signature length=%d", len(sig))
+	}
+	g2 := bls.NewG2()
+	p, err := g2.FromCompressed(sig)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "invalid bls signature")
+	}
+	if g2.IsZero(p) {
+		return nil, errors.IllegalArgumentError.New("bls signature is infinity")
+	}
+	return p, nil
+}
+
+// blsVerify checks e(pk, H(msg)) == e(g1, sig)
+func blsVerify(pk *bls.PointG1, msg, dst []byte, sig *bls.PointG2) bool {
+	h, err := bls.NewG2().HashToCurve(msg, dst)
+	if err != nil {
+		return false
+	}
+	e := bls.NewEngine()
+	return e.AddPair(pk, h).AddPairInv(e.G1.One(), sig).Check()
+}
+
+type blsDSAModule struct {
+}
+
+func (s blsDSAModule) Name() string {
+	return blsDSA
+}
+
+// Verify accepts the public key or the public key followed by its proof of
+// possession.
+func (s blsDSAModule) Verify(pubKey []byte) error {
+	if len(pubKey) == blsPublicKeyLen+blsSignatureLen {
+		_, err := s.Canonicalize(pubKey)
+		return err
+	}
+	_, err := parseBLSPublicKey(pubKey)
+	return err
+}
+
+// Canonicalize requires the public key followed by its proof of possession
+// and returns the public key.
+func (s blsDSAModule) Canonicalize(pubKey []byte) ([]byte, error) {
+	if len(pubKey) != blsPublicKeyLen+blsSignatureLen {
+		return nil, errors.IllegalArgumentError.Errorf("public key with proof of possession is required length=%d", len(pubKey))
+	}
+	key := pubKey[:blsPublicKeyLen]
+	pk, err := parseBLSPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	pop, err := parseBLSSignature(pubKey[blsPublicKeyLen:])
+	if err != nil {
+		return nil, err
+	}
+	if !blsVerify(pk, key, blsPoPDST, pop) {
+		return nil, errors.IllegalArgumentError.New("invalid proof of possession")
+	}
+	return append([]byte{}, key...), nil
+}
+
+func (s blsDSAModule) newWallet(secret []byte) (module.BaseWallet, error) {
+	g1 := bls.NewG1()
+	sk := new(big.Int).SetBytes(secret)
+	sk.Mod(sk, g1.Q())
+	if sk.Sign() == 0 {
+		return nil, errors.IllegalArgumentError.New("invalid secret")
+	}
+	pk := g1.MulScalarBig(g1.New(), g1.One(), sk)
+	return &blsWallet{sk: sk, pk: g1.ToCompressed(pk)}, nil
+}
+
+type blsWallet struct {
+	sk *big.Int
+	pk []byte
+}
+
+func (w *blsWallet) sign(data, dst []byte) ([]byte, error) {
+	g2 := bls.NewG2()
+	h, err := g2.HashToCurve(data, dst)
+	if err != nil {
+		return nil, err
+	}
+	return g2.ToCompressed(g2.MulScalarBig(h, h, w.sk)), nil
+}
+
+func (w *blsWallet) Sign(data []byte) ([]byte, error) {
+	return w.sign(data, blsSignatureDST)
+}
+
+func (w *blsWallet) PublicKey() []byte {
+	return w.pk
+}
+
+func (w *blsWallet) proofOfPossession() ([]byte, error) {
+	return w.sign(w.pk, blsPoPDST)
+}
+
+var blsDSAModuleInstance blsDSAModule
+
+func init() {
+	registerDSAModule(blsDSAModuleInstance)
+}
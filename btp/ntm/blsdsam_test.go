@@ -0,0 +1,62 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/wallet"
+)
+
+func TestBLSDSAModule_Canonicalize(t *testing.T) {
+	assert := assert.New(t)
+
+	w := NewDSAWallet(blsDSA, wallet.New())
+	assert.NotNil(w)
+	dsam := DSAModuleForName(blsDSA)
+	assert.NoError(dsam.Verify(w.PublicKey()))
+
+	pkBytes, err := PublicKeyForRegistration(w)
+	assert.NoError(err)
+	assert.Len(pkBytes, blsPublicKeyLen+blsSignatureLen)
+	assert.NoError(dsam.Verify(pkBytes))
+
+	key, err := dsam.Canonicalize(pkBytes)
+	assert.NoError(err)
+	assert.EqualValues(w.PublicKey(), key)
+
+	// proof of possession is required
+	_, err = dsam.Canonicalize(w.PublicKey())
+	assert.Error(err)
+
+	// proof of possession of other key
+	w2 := NewDSAWallet(blsDSA, wallet.New())
+	pk2, err := PublicKeyForRegistration(w2)
+	assert.NoError(err)
+	bad := append(append([]byte{}, w.PublicKey()...), pk2[blsPublicKeyLen:]...)
+	_, err = dsam.Canonicalize(bad)
+	assert.Error(err)
+
+	// signature is not a proof of possession
+	sig, err := w.Sign(w.PublicKey())
+	assert.NoError(err)
+	bad = append(append([]byte{}, w.PublicKey()...), sig...)
+	_, err = dsam.Canonicalize(bad)
+	assert.Error(err)
+}
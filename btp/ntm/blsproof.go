@@ -0,0 +1,350 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"sync"
+
+	bls "github.com/kilic/bls12-381"
+
+	"github.com/icon-project/goloop/common/cache"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+func signersLen(n int) int {
+	return (n + 7) / 8
+}
+
+func hasSigner(signers []byte, i int) bool {
+	return signers[i/8]&(1<<(i%8)) != 0
+}
+
+func setSigner(signers []byte, i int) {
+	signers[i/8] |= 1 << (i % 8)
+}
+
+// blsProofPart is the signature of a validator or the aggregated signature
+// of the validators in Signers. Signers is nil for the signature of the
+// validator.
+type blsProofPart struct {
+	Index     int
+	Signers   []byte
+	Signature []byte
+}
+
+func (pp *blsProofPart) Bytes() []byte {
+	return codec.MustMarshalToBytes(pp)
+}
+
+// blsProof has an aggregated signature and the bitmap of the validators
+// who signed.
+type blsProof struct {
+	Count     int
+	Signers   []byte
+	Signature []byte
+	sig       *bls.PointG2
+	bytes     []byte
+}
+
+func newBLSProofFromBytes(bs []byte) (*blsProof, error) {
+	var p blsProof
+	_, err := codec.UnmarshalFromBytes(bs, &p)
+	if err != nil {
+		return nil, err
+	}
+	if p.Count < 0 || len(p.Signers) != signersLen(p.Count) {
+		return nil, errors.Errorf("invalid proof count=%d len(signers)=%d", p.Count, len(p.Signers))
+	}
+	return &p, err
+}
+
+func (p *blsProof) Bytes() []byte {
+	if p.bytes == nil {
+		p.bytes = codec.MustMarshalToBytes(p)
+	}
+	return p.bytes
+}
+
+// Add aggregates the signature of the proof part. The part overlapped with
+// existing signers is ignored.
+func (p *blsProof) Add(pp module.BTPProofPart) {
+	bpp := pp.(*blsProofPart)
+	signers := bpp.Signers
+	if signers == nil {
+		if bpp.Index < 0 || bpp.Index >= p.Count {
+			return
+		}
+		signers = make([]byte, len(p.Signers))
+		setSigner(signers, bpp.Index)
+	}
+	if len(signers) != len(p.Signers) {
+		return
+	}
+	for i := range signers {
+		if signers[i]&p.Signers[i] != 0 {
+			return
+		}
+	}
+	sig, err := parseBLSSignature(bpp.Signature)
+	if err != nil {
+		return
+	}
+	g2 := bls.NewG2()
+	if p.sig == nil && p.Signature != nil {
+		if p.sig, err = parseBLSSignature(p.Signature); err != nil {
+			return
+		}
+	}
+	if p.sig != nil {
+		g2.Add(sig, sig, p.sig)
+	}
+	for i := range signers {
+		p.Signers[i] |= signers[i]
+	}
+	p.sig = sig
+	p.Signature = g2.ToCompressed(sig)
+	p.bytes = nil
+}
+
+func (p *blsProof) ValidatorCount() int {
+	return p.Count
+}
+
+// ProofPartAt returns the aggregated signature as the part of the validator
+// if the validator is one of the signers.
+func (p *blsProof) ProofPartAt(i int) module.BTPProofPart {
+	if i < 0 || i >= p.Count || !hasSigner(p.Signers, i) {
+		return nil
+	}
+	return &blsProofPart{i, p.Signers, p.Signature}
+}
+
+type blsProofContext struct {
+	Validators [][]byte
+	mod        *networkTypeModule
+	bytes      cache.ByteSlice
+	mtx        sync.Mutex
+	keyToIndex map[string]int
+	pubKeys    []*bls.PointG1
+}
+
+func newBLSProofContext(
+	mod *networkTypeModule,
+	keys [][]byte,
+) (*blsProofContext, error) {
+	pc := &blsProofContext{
+		Validators: make([][]byte, 0, len(keys)),
+		mod:        mod,
+	}
+	for i, key := range keys {
+		if key != nil {
+			if _, err := parseBLSPublicKey(key); err != nil {
+				return nil, errors.Wrapf(err, "invalid key index=%d key=%x", i, key)
+			}
+		}
+		pc.Validators = append(pc.Validators, key)
+	}
+	return pc, nil
+}
+
+func newBLSProofContextFromBytes(
+	mod *networkTypeModule,
+	bytes []byte,
+) (*blsProofContext, error) {
+	pc := &blsProofContext{
+		mod: mod,
+	}
+	if bytes != nil {
+		_, err := codec.UnmarshalFromBytes(bytes, pc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pc, nil
+}
+
+func (pc *blsProofContext) indexOf(key []byte) (int, bool) {
+	pc.mtx.Lock()
+	defer pc.mtx.Unlock()
+
+	if pc.keyToIndex == nil {
+		pc.keyToIndex = make(map[string]int, len(pc.Validators))
+		for i, k := range pc.Validators {
+			if k != nil {
+				pc.keyToIndex[string(k)] = i
+			}
+		}
+	}
+	idx, ok := pc.keyToIndex[string(key)]
+	return idx, ok
+}
+
+func (pc *blsProofContext) pubKeyAt(i int) (*bls.PointG1, error) {
+	pc.mtx.Lock()
+	defer pc.mtx.Unlock()
+
+	if pc.pubKeys == nil {
+		pc.pubKeys = make([]*bls.PointG1, len(pc.Validators))
+	}
+	if pc.pubKeys[i] == nil {
+		if pc.Validators[i] == nil {
+			return nil, errors.Errorf("no key for validator index=%d", i)
+		}
+		pk, err := parseBLSPublicKey(pc.Validators[i])
+		if err != nil {
+			return nil, err
+		}
+		pc.pubKeys[i] = pk
+	}
+	return pc.pubKeys[i], nil
+}
+
+// aggregatePubKey returns aggregated public key of the signers and the
+// number of the signers.
+func (pc *blsProofContext) aggregatePubKey(signers []byte) (*bls.PointG1, int, error) {
+	n := len(pc.Validators)
+	if len(signers) != signersLen(n) {
+		return nil, 0, errors.Errorf("invalid signers len=%d numValidators=%d", len(signers), n)
+	}
+	if n%8 != 0 && signers[len(signers)-1]>>(n%8) != 0 {
+		return nil, 0, errors.Errorf("invalid signers %x numValidators=%d", signers, n)
+	}
+	g1 := bls.NewG1()
+	agg := g1.Zero()
+	count := 0
+	for i := 0; i < n; i++ {
+		if !hasSigner(signers, i) {
+			continue
+		}
+		pk, err := pc.pubKeyAt(i)
+		if err != nil {
+			return nil, 0, err
+		}
+		g1.Add(agg, agg, pk)
+		count++
+	}
+	return agg, count, nil
+}
+
+func (pc *blsProofContext) NetworkTypeModule() module.NetworkTypeModule {
+	return pc.mod
+}
+
+func (pc *blsProofContext) Bytes() []byte {
+	return pc.bytes.Get(func() []byte {
+		if pc.Validators == nil {
+			return nil
+		}
+		return codec.MustMarshalToBytes(pc)
+	})
+}
+
+// VerifyPart returns validator index and error
+func (pc *blsProofContext) VerifyPart(dHash []byte, pp module.BTPProofPart) (int, error) {
+	bpp := pp.(*blsProofPart)
+	if bpp.Index < 0 || bpp.Index >= len(pc.Validators) {
+		return -1, errors.Errorf("invalid proof part index=%d numValidators=%d", bpp.Index, len(pc.Validators))
+	}
+	sig, err := parseBLSSignature(bpp.Signature)
+	if err != nil {
+		return -1, err
+	}
+	var pk *bls.PointG1
+	if bpp.Signers == nil {
+		if pk, err = pc.pubKeyAt(bpp.Index); err != nil {
+			return -1, err
+		}
+	} else {
+		if pk, _, err = pc.aggregatePubKey(bpp.Signers); err != nil {
+			return -1, err
+		}
+		if !hasSigner(bpp.Signers, bpp.Index) {
+			return -1, errors.Errorf("invalid proof part. not a signer index=%d signers=%x", bpp.Index, bpp.Signers)
+		}
+	}
+	if !blsVerify(pk, dHash, blsSignatureDST, sig) {
+		return -1, errors.Errorf("invalid proof part. bad signature index=%d signers=%x", bpp.Index, bpp.Signers)
+	}
+	return bpp.Index, nil
+}
+
+func (pc *blsProofContext) NewProofPartFromBytes(ppBytes []byte) (module.BTPProofPart, error) {
+	var pp blsProofPart
+	_, err := codec.UnmarshalFromBytes(ppBytes, &pp)
+	if err != nil {
+		return nil, err
+	}
+	return &pp, err
+}
+
+func (pc *blsProofContext) Verify(dHash []byte, p module.BTPProof) error {
+	bp := p.(*blsProof)
+	if bp.Count != len(pc.Validators) {
+		return errors.Errorf("invalid proof numValidators=%d count=%d", len(pc.Validators), bp.Count)
+	}
+	pk, count, err := pc.aggregatePubKey(bp.Signers)
+	if err != nil {
+		return err
+	}
+	if count <= 2*len(pc.Validators)/3 {
+		return errors.Errorf("not enough signers numValidator=%d numSigners=%d", len(pc.Validators), count)
+	}
+	sig, err := parseBLSSignature(bp.Signature)
+	if err != nil {
+		return err
+	}
+	if !blsVerify(pk, dHash, blsSignatureDST, sig) {
+		return errors.Errorf("invalid aggregated signature signers=%x", bp.Signers)
+	}
+	return nil
+}
+
+func (pc *blsProofContext) NewProofFromBytes(proofBytes []byte) (module.BTPProof, error) {
+	return newBLSProofFromBytes(proofBytes)
+}
+
+func (pc *blsProofContext) NewProofPart(
+	dHash []byte,
+	wp module.WalletProvider,
+) (module.BTPProofPart, error) {
+	w := wp.WalletFor(blsDSA)
+	if w == nil {
+		return nil, errors.Errorf("no wallet for uid=%s dsa=%s", pc.mod.UID(), blsDSA)
+	}
+	idx, ok := pc.indexOf(w.PublicKey())
+	if !ok {
+		return nil, errors.Errorf("not validator key=%x", w.PublicKey())
+	}
+	sig, err := w.Sign(dHash)
+	if err != nil {
+		return nil, err
+	}
+	return &blsProofPart{Index: idx, Signature: sig}, nil
+}
+
+func (pc *blsProofContext) DSA() string {
+	return blsDSA
+}
+
+func (pc *blsProofContext) NewProof() module.BTPProof {
+	return &blsProof{
+		Count:   len(pc.Validators),
+		Signers: make([]byte, signersLen(len(pc.Validators))),
+	}
+}
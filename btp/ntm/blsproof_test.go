@@ -0,0 +1,153 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+)
+
+type testWalletProvider struct {
+	w module.BaseWallet
+}
+
+func (wp testWalletProvider) WalletFor(dsa string) module.BaseWallet {
+	return wp.w
+}
+
+func newTestProofContext(t *testing.T, uid string, count int) (module.BTPProofContext, []module.WalletProvider) {
+	mod := ForUID(uid)
+	keys := make([][]byte, 0, count)
+	wps := make([]module.WalletProvider, 0, count)
+	for i := 0; i < count; i++ {
+		w := NewDSAWallet(mod.DSA(), wallet.New())
+		keys = append(keys, w.PublicKey())
+		wps = append(wps, testWalletProvider{w})
+	}
+	pc, err := mod.NewProofContext(keys)
+	assert.NoError(t, err)
+	return pc, wps
+}
+
+func testProofContext(t *testing.T, uid string) {
+	assert := assert.New(t)
+	const count = 4
+	pc, wps := newTestProofContext(t, uid, count)
+	pc2, err := ForUID(uid).NewProofContextFromBytes(pc.Bytes())
+	assert.NoError(err)
+	assert.EqualValues(pc.Hash(), pc2.Hash())
+
+	dHash := pc.NewDecision([]byte("src"), 1, 10, 0, []byte("nts")).Hash()
+	p := pc.NewProof()
+	for i, wp := range wps {
+		pp, err := pc.NewProofPart(dHash, wp)
+		assert.NoError(err)
+		pp, err = pc2.NewProofPartFromBytes(pp.Bytes())
+		assert.NoError(err)
+		idx, err := pc2.VerifyPart(dHash, pp)
+		assert.NoError(err)
+		assert.Equal(i, idx)
+		_, err = pc2.VerifyPart([]byte("other"), pp)
+		assert.Error(err)
+
+		if i <= 2*count/3 {
+			assert.Error(pc2.Verify(dHash, p))
+		}
+		p.Add(pp)
+	}
+	assert.NoError(pc2.Verify(dHash, p))
+	assert.Error(pc2.Verify([]byte("other"), p))
+
+	p2, err := pc2.NewProofFromBytes(p.Bytes())
+	assert.NoError(err)
+	assert.Equal(count, p2.ValidatorCount())
+	assert.NoError(pc2.Verify(dHash, p2))
+
+	// parts from the proof are verified and build the same proof
+	p3 := pc2.NewProof()
+	for i := 0; i < p2.ValidatorCount(); i++ {
+		pp := p2.ProofPartAt(i)
+		assert.NotNil(pp)
+		pp, err = pc2.NewProofPartFromBytes(pp.Bytes())
+		assert.NoError(err)
+		idx, err := pc2.VerifyPart(dHash, pp)
+		assert.NoError(err)
+		assert.Equal(i, idx)
+		p3.Add(pp)
+	}
+	assert.EqualValues(p2.Bytes(), p3.Bytes())
+}
+
+func TestEd25519ProofContext(t *testing.T) {
+	testProofContext(t, ed25519UID)
+}
+
+func TestBLSProofContext(t *testing.T) {
+	testProofContext(t, blsUID)
+}
+
+func TestBLSProof_Aggregate(t *testing.T) {
+	assert := assert.New(t)
+	const count = 10
+	pc, wps := newTestProofContext(t, blsUID, count)
+	dHash := pc.NewDecision([]byte("src"), 1, 10, 0, []byte("nts")).Hash()
+
+	parts := make([]module.BTPProofPart, count)
+	for i, wp := range wps {
+		var err error
+		parts[i], err = pc.NewProofPart(dHash, wp)
+		assert.NoError(err)
+	}
+
+	// aggregated proof has single signature regardless of signers
+	p := pc.NewProof()
+	for _, pp := range parts[:7] {
+		p.Add(pp)
+	}
+	assert.NoError(pc.Verify(dHash, p))
+	assert.Len(p.(*blsProof).Signature, blsSignatureLen)
+	assert.Nil(p.ProofPartAt(8))
+
+	// duplicated parts are ignored
+	p.Add(parts[0])
+	p.Add(p.ProofPartAt(1))
+	assert.NoError(pc.Verify(dHash, p))
+
+	// disjoint aggregated parts are merged
+	p2 := pc.NewProof()
+	for _, pp := range parts[7:] {
+		p2.Add(pp)
+	}
+	p.Add(p2.ProofPartAt(count - 1))
+	for i := 0; i < count; i++ {
+		assert.NotNil(p.ProofPartAt(i))
+	}
+	assert.NoError(pc.Verify(dHash, p))
+
+	// signer bitmap shall match the signature
+	bp, err := newBLSProofFromBytes(p.Bytes())
+	assert.NoError(err)
+	bp.Signers[0] &^= 1
+	assert.Error(pc.Verify(dHash, bp))
+	bpp := &blsProofPart{Index: 1, Signers: bp.Signers, Signature: bp.Signature}
+	_, err = pc.VerifyPart(dHash, bpp)
+	assert.Error(err)
+}
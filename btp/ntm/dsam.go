@@ -1,5 +1,5 @@
 /*
- * Copyright 2022 ICON Foundation
+ * Copyright 2023 ICON Foundation
  *
  * Licensed under the Apache License, Version 2.0 (the "License");
  * you may not use this file except in compliance with the License.
@@ -16,7 +16,22 @@
 
 package ntm
 
-import "github.com/icon-project/goloop/module"
+import (
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	dsaWalletSecretLabel = "goloop/btp/dsa/"
+	dsaWalletSecretSize  = 32
+)
+
+type walletFactory interface {
+	newWallet(secret []byte) (module.BaseWallet, error)
+}
+
+type possessionProver interface {
+	proofOfPossession() ([]byte, error)
+}
 
 var dsaModules = make(map[string]module.DSAModule)
 
@@ -27,3 +42,54 @@ func DSAModuleForName(name string) module.DSAModule {
 func registerDSAModule(dsa module.DSAModule) {
 	dsaModules[dsa.Name()] = dsa
 }
+
+// secretDeriver derives secrets from the private key of the wallet.
+type secretDeriver interface {
+	DeriveSecret(label string, size int) ([]byte, error)
+}
+
+// NewDSAWallet returns the wallet for the DSA whose key is derived from the
+// private key of the base wallet with HKDF. The name of the DSA is used in
+// the label of the derivation, so keys of DSAs are independent of each other
+// and of the base key. It returns nil if the DSA is not supported or the base
+// wallet can't derive secrets.
+func NewDSAWallet(name string, w module.BaseWallet) module.BaseWallet {
+	if w == nil {
+		return nil
+	}
+	if name == secp256k1DSA {
+		return w
+	}
+	f, ok := dsaModules[name].(walletFactory)
+	if !ok {
+		return nil
+	}
+	d, ok := w.(secretDeriver)
+	if !ok {
+		return nil
+	}
+	secret, err := d.DeriveSecret(dsaWalletSecretLabel+name, dsaWalletSecretSize)
+	if err != nil {
+		return nil
+	}
+	dw, err := f.newWallet(secret)
+	if err != nil {
+		return nil
+	}
+	return dw
+}
+
+// PublicKeyForRegistration returns the public key of the wallet to be
+// registered as the public key of the node. For the aggregate signature
+// DSA, it has the proof of possession of the key after the key.
+func PublicKeyForRegistration(w module.BaseWallet) ([]byte, error) {
+	pk := w.PublicKey()
+	if p, ok := w.(possessionProver); ok {
+		pop, err := p.proofOfPossession()
+		if err != nil {
+			return nil, err
+		}
+		return append(append([]byte{}, pk...), pop...), nil
+	}
+	return pk, nil
+}
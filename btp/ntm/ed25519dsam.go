@@ -0,0 +1,74 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"crypto/ed25519"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	ed25519DSA = "eddsa/ed25519"
+)
+
+type ed25519DSAModule struct {
+}
+
+func (s ed25519DSAModule) Name() string {
+	return ed25519DSA
+}
+
+func (s ed25519DSAModule) Verify(pubKey []byte) error {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return errors.IllegalArgumentError.Errorf("invalid ed25519 public key length=%d", len(pubKey))
+	}
+	return nil
+}
+
+func (s ed25519DSAModule) Canonicalize(pubKey []byte) ([]byte, error) {
+	if err := s.Verify(pubKey); err != nil {
+		return nil, err
+	}
+	return append([]byte{}, pubKey...), nil
+}
+
+func (s ed25519DSAModule) newWallet(secret []byte) (module.BaseWallet, error) {
+	if len(secret) != ed25519.SeedSize {
+		return nil, errors.IllegalArgumentError.Errorf("invalid secret length=%d", len(secret))
+	}
+	return &ed25519Wallet{ed25519.NewKeyFromSeed(secret)}, nil
+}
+
+type ed25519Wallet struct {
+	key ed25519.PrivateKey
+}
+
+func (w *ed25519Wallet) Sign(data []byte) ([]byte, error) {
+	return ed25519.Sign(w.key, data), nil
+}
+
+func (w *ed25519Wallet) PublicKey() []byte {
+	return w.key.Public().(ed25519.PublicKey)
+}
+
+var ed25519DSAModuleInstance ed25519DSAModule
+
+func init() {
+	registerDSAModule(ed25519DSAModuleInstance)
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ntm
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+)
+
+func TestEd25519DSAModule_Verify(t *testing.T) {
+	assert := assert.New(t)
+
+	w := NewDSAWallet(ed25519DSA, wallet.New())
+	assert.NotNil(w)
+	dsam := DSAModuleForName(ed25519DSA)
+	assert.NoError(dsam.Verify(w.PublicKey()))
+
+	pkBytes := w.PublicKey()
+	assert.Error(dsam.Verify(pkBytes[:len(pkBytes)-1]))
+
+	cKey, err := dsam.Canonicalize(pkBytes)
+	assert.NoError(err)
+	assert.EqualValues(pkBytes, cKey)
+}
+
+func TestNewDSAWallet(t *testing.T) {
+	assert := assert.New(t)
+
+	base := wallet.New()
+	assert.Equal(base, NewDSAWallet(secp256k1DSA, base))
+	assert.Nil(NewDSAWallet("unknown", base))
+
+	w1 := NewDSAWallet(ed25519DSA, base)
+	w2 := NewDSAWallet(ed25519DSA, base)
+	assert.EqualValues(w1.PublicKey(), w2.PublicKey())
+	assert.NotEqualValues(w1.PublicKey(), NewDSAWallet(ed25519DSA, wallet.New()).PublicKey())
+
+	msg := []byte("message")
+	sig, err := w1.Sign(msg)
+	assert.NoError(err)
+	assert.True(ed25519.Verify(w2.PublicKey(), msg, sig))
+
+	pk, err := PublicKeyForRegistration(w1)
+	assert.NoError(err)
+	assert.EqualValues(w1.PublicKey(), pk)
+
+	// keys are derived from the private key of the base wallet
+	sk, _ := crypto.GenerateKeyPair()
+	b1, err := wallet.NewFromPrivateKey(sk)
+	assert.NoError(err)
+	b2, err := wallet.NewFromPrivateKey(sk)
+	assert.NoError(err)
+	assert.EqualValues(NewDSAWallet(ed25519DSA, b1).PublicKey(), NewDSAWallet(ed25519DSA, b2).PublicKey())
+
+	// wallets which can't derive secrets are not supported
+	assert.Nil(NewDSAWallet(ed25519DSA, signOnlyWallet{base}))
+}
+
+type signOnlyWallet struct {
+	w module.BaseWallet
+}
+
+func (w signOnlyWallet) Sign(data []byte) ([]byte, error) {
+	return w.w.Sign(data)
+}
+
+func (w signOnlyWallet) PublicKey() []byte {
+	return w.w.PublicKey()
+}
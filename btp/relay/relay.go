@@ -0,0 +1,273 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"encoding/base64"
+	"sync"
+
+	"github.com/icon-project/goloop/btp"
+	"github.com/icon-project/goloop/btp/ntm"
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/server"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	v3 "github.com/icon-project/goloop/server/v3"
+)
+
+// Client is the subset of client.ClientV3 used by Relay.
+type Client interface {
+	GetBTPSourceInformation() (*client.BTPSourceInformation, error)
+	GetBTPNetworkInfo(param *v3.BTPQueryParam) (*client.BTPNetworkInfo, error)
+	GetBTPNetworkTypeInfo(param *v3.BTPQueryParam) (*client.BTPNetworkTypeInfo, error)
+	GetBTPMessages(param *v3.BTPMessagesParam) ([]string, error)
+	MonitorBtp(param *server.BTPRequest, cb func(v *server.BTPNotification), cancelCh <-chan bool) error
+	Cleanup()
+}
+
+// Sink receives verified batches in order of height.
+type Sink interface {
+	Send(b *Batch) error
+}
+
+// SinkFunc is an adapter to use a function as a Sink.
+type SinkFunc func(b *Batch) error
+
+func (f SinkFunc) Send(b *Batch) error {
+	return f(b)
+}
+
+// Relay follows a BTP network of the source chain, verifies its headers
+// and sends verified batches to the sink.
+type Relay struct {
+	client Client
+	nid    int64
+	sink   Sink
+	log    log.Logger
+
+	trust    []byte
+	trustPC  []byte
+	insecure bool
+
+	lock     sync.Mutex
+	verifier *Verifier
+}
+
+func New(c Client, nid int64, sink Sink, logger log.Logger) *Relay {
+	if logger == nil {
+		logger = log.GlobalLogger()
+	}
+	return &Relay{
+		client: c,
+		nid:    nid,
+		sink:   sink,
+		log:    logger,
+	}
+}
+
+// SetTrust sets the hash of the proof context trusted by the caller. The
+// proof context for the first relayed header shall match it.
+func (r *Relay) SetTrust(pcHash []byte) {
+	r.trust = pcHash
+}
+
+// SetTrustedProofContext sets the proof context trusted by the caller. The
+// proof context for the first relayed header shall match it.
+func (r *Relay) SetTrustedProofContext(pc []byte) {
+	r.trustPC = pc
+}
+
+// SetInsecure allows the relay to start without a trusted proof context.
+// Then, the proof context for the first header is taken from the node
+// without verification.
+func (r *Relay) SetInsecure(insecure bool) {
+	r.insecure = insecure
+}
+
+// Height returns the main height of the last relayed header.
+func (r *Relay) Height() int64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.verifier == nil {
+		return 0
+	}
+	return r.verifier.Height()
+}
+
+func (r *Relay) prepare(height int64) (*Verifier, int64, error) {
+	if r.trust == nil && r.trustPC == nil && !r.insecure {
+		return nil, 0, errors.IllegalArgumentError.New(
+			"no trusted proof context without insecure option")
+	}
+	si, err := r.client.GetBTPSourceInformation()
+	if err != nil {
+		return nil, 0, err
+	}
+	ni, err := r.client.GetBTPNetworkInfo(&v3.BTPQueryParam{
+		Id: jsonrpc.HexInt(intconv.FormatInt(r.nid)),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	ntid, err := ni.NetworkTypeID.Int64()
+	if err != nil {
+		return nil, 0, err
+	}
+	startHeight, err := ni.StartHeight.Int64()
+	if err != nil {
+		return nil, 0, err
+	}
+	nti, err := r.client.GetBTPNetworkTypeInfo(&v3.BTPQueryParam{
+		Id: jsonrpc.HexInt(intconv.FormatInt(ntid)),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	var pcBytes []byte
+	if height <= startHeight+1 {
+		height = startHeight + 1
+	} else {
+		nti, err = r.client.GetBTPNetworkTypeInfo(&v3.BTPQueryParam{
+			Height: jsonrpc.HexInt(intconv.FormatInt(height - 1)),
+			Id:     jsonrpc.HexInt(intconv.FormatInt(ntid)),
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		pcBytes = nti.NextProofContext.Bytes()
+	}
+	trust := r.trust
+	if r.trustPC != nil {
+		mod := ntm.ForUID(nti.NetworkTypeName)
+		if mod == nil {
+			return nil, 0, errors.IllegalArgumentError.Errorf(
+				"unknown network type module uid=%s", nti.NetworkTypeName)
+		}
+		trust = mod.Hash(r.trustPC)
+	}
+	if trust == nil {
+		r.log.Warnf("relay without trusted proof context nid=%d", r.nid)
+	}
+	v, err := NewVerifier([]byte(si.SrcNetworkUID), ntid, r.nid, nti.NetworkTypeName, pcBytes, trust)
+	if err != nil {
+		return nil, 0, err
+	}
+	return v, height, nil
+}
+
+func (r *Relay) messagesAt(height int64) ([][]byte, error) {
+	msgs, err := r.client.GetBTPMessages(&v3.BTPMessagesParam{
+		Height:    jsonrpc.HexInt(intconv.FormatInt(height)),
+		NetworkId: jsonrpc.HexInt(intconv.FormatInt(r.nid)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := make([][]byte, len(msgs))
+	for i, msg := range msgs {
+		if res[i], err = base64.StdEncoding.DecodeString(msg); err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err, "invalid message idx=%d", i)
+		}
+	}
+	return res, nil
+}
+
+func (r *Relay) handle(n *server.BTPNotification) error {
+	if n.Header == "" {
+		return nil
+	}
+	header, err := base64.StdEncoding.DecodeString(n.Header)
+	if err != nil {
+		return errors.IllegalArgumentError.Wrap(err, "invalid header")
+	}
+	var proof []byte
+	if n.Proof != "" {
+		if proof, err = base64.StdEncoding.DecodeString(n.Proof); err != nil {
+			return errors.IllegalArgumentError.Wrap(err, "invalid proof")
+		}
+	}
+	bh, err := btp.NewBTPBlockHeaderFromBytes(header)
+	if err != nil {
+		return err
+	}
+	var msgs [][]byte
+	if bh.MessageCount() > 0 {
+		if msgs, err = r.messagesAt(bh.MainHeight()); err != nil {
+			return err
+		}
+	}
+	b, err := r.verifier.Verify(header, proof, msgs)
+	if err != nil {
+		return err
+	}
+	if b.NextProofContext != nil {
+		r.log.Infof("proof context changed nid=%d height=%d", r.nid, b.Height)
+	}
+	return r.sink.Send(b)
+}
+
+// Run relays verified batches from the height until an error occurs or
+// cancelCh is closed. If the height is lower than the first section of the
+// network, it starts from the first section.
+func (r *Relay) Run(height int64, cancelCh <-chan bool) error {
+	v, height, err := r.prepare(height)
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	r.verifier = v
+	r.lock.Unlock()
+	r.log.Infof("start relay nid=%d height=%d", r.nid, height)
+
+	errCh := make(chan error, 1)
+	go func() {
+		var herr error
+		err := r.client.MonitorBtp(&server.BTPRequest{
+			Height:    common.HexInt64{Value: height},
+			NetworkId: common.HexInt64{Value: r.nid},
+			ProofFlag: common.HexBool{Value: true},
+		}, func(n *server.BTPNotification) {
+			if herr != nil {
+				return
+			}
+			r.lock.Lock()
+			herr = r.handle(n)
+			r.lock.Unlock()
+			if herr != nil {
+				r.client.Cleanup()
+			}
+		}, nil)
+		if herr != nil {
+			err = herr
+		} else if err == nil {
+			err = errors.InvalidStateError.New("monitor closed")
+		}
+		errCh <- err
+	}()
+
+	select {
+	case err = <-errCh:
+		return err
+	case <-cancelCh:
+		r.client.Cleanup()
+		<-errCh
+		return nil
+	}
+}
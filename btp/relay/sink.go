@@ -0,0 +1,120 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+type batchJSON struct {
+	NetworkID        common.HexInt64   `json:"networkID"`
+	Height           common.HexInt64   `json:"height"`
+	FirstSN          common.HexInt64   `json:"firstSN"`
+	Messages         []common.HexBytes `json:"messages"`
+	Header           common.HexBytes   `json:"header"`
+	Proof            common.HexBytes   `json:"proof,omitempty"`
+	NextProofContext common.HexBytes   `json:"nextProofContext,omitempty"`
+}
+
+func (b *Batch) MarshalJSON() ([]byte, error) {
+	msgs := make([]common.HexBytes, len(b.Messages))
+	for i, msg := range b.Messages {
+		msgs[i] = msg
+	}
+	return json.Marshal(&batchJSON{
+		NetworkID:        common.HexInt64{Value: b.NetworkID},
+		Height:           common.HexInt64{Value: b.Height},
+		FirstSN:          common.HexInt64{Value: b.FirstSN},
+		Messages:         msgs,
+		Header:           b.Header,
+		Proof:            b.Proof,
+		NextProofContext: b.NextProofContext,
+	})
+}
+
+func (b *Batch) UnmarshalJSON(bs []byte) error {
+	var bj batchJSON
+	if err := json.Unmarshal(bs, &bj); err != nil {
+		return err
+	}
+	b.NetworkID = bj.NetworkID.Value
+	b.Height = bj.Height.Value
+	b.FirstSN = bj.FirstSN.Value
+	b.Messages = make([][]byte, len(bj.Messages))
+	for i, msg := range bj.Messages {
+		b.Messages[i] = msg
+	}
+	b.Header = bj.Header
+	b.Proof = bj.Proof
+	b.NextProofContext = bj.NextProofContext
+	return nil
+}
+
+type jsonSink struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (s *jsonSink) Send(b *Batch) error {
+	bs, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, err = s.w.Write(append(bs, '\n'))
+	return err
+}
+
+// NewJSONSink returns a sink writing each batch as a line of JSON.
+func NewJSONSink(w io.Writer) Sink {
+	return &jsonSink{w: w}
+}
+
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpSink) Send(b *Batch) error {
+	bs, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(bs))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return errors.InvalidStateError.Errorf(
+			"fail to send batch height=%d status=%s", b.Height, resp.Status)
+	}
+	return nil
+}
+
+// NewHTTPSink returns a sink posting each batch as JSON to the url.
+func NewHTTPSink(url string) Sink {
+	return &httpSink{url: url, client: http.DefaultClient}
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/btp"
+	"github.com/icon-project/goloop/btp/ntm"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+// Batch is a verified set of messages of a network section.
+type Batch struct {
+	NetworkID int64
+	Height    int64
+	FirstSN   int64
+	Messages  [][]byte
+
+	Header []byte
+	Proof  []byte
+
+	// NextProofContext is the new proof context if the validator set is
+	// changed by the section. Otherwise, it's nil.
+	NextProofContext []byte
+}
+
+// Verifier verifies consecutive BTP block headers of a network and tracks
+// the proof context of the network type.
+type Verifier struct {
+	srcUID []byte
+	ntid   int64
+	nid    int64
+	mod    module.NetworkTypeModule
+	pc     module.BTPProofContext
+	trust  []byte
+
+	height     int64
+	lastNSHash []byte
+	nextSN     int64
+}
+
+// NewVerifier returns a verifier for the network. pcBytes is the proof
+// context used for the votes of the first header. If it's nil, the first
+// header shall be the first section of the network, which has no proof.
+// trust is the hash of the proof context trusted by the caller. If it's not
+// nil, pcBytes or the next proof context of the first section shall match
+// it.
+func NewVerifier(
+	srcUID []byte, ntid, nid int64, uid string, pcBytes, trust []byte,
+) (*Verifier, error) {
+	mod := ntm.ForUID(uid)
+	if mod == nil {
+		return nil, errors.IllegalArgumentError.Errorf("unknown network type module uid=%s", uid)
+	}
+	v := &Verifier{
+		srcUID: srcUID,
+		ntid:   ntid,
+		nid:    nid,
+		mod:    mod,
+		trust:  trust,
+		nextSN: -1,
+	}
+	if pcBytes != nil {
+		pc, err := mod.NewProofContextFromBytes(pcBytes)
+		if err != nil {
+			return nil, err
+		}
+		if trust != nil && !bytes.Equal(pc.Hash(), trust) {
+			return nil, errors.InvalidStateError.Errorf(
+				"untrusted proof context exp=%x real=%x", trust, pc.Hash())
+		}
+		v.pc = pc
+	}
+	return v, nil
+}
+
+// ProofContext returns the proof context for the next header.
+func (v *Verifier) ProofContext() module.BTPProofContext {
+	return v.pc
+}
+
+// Height returns the main height of the last verified header.
+func (v *Verifier) Height() int64 {
+	return v.height
+}
+
+// Verify verifies the header, the proof and the messages of a network
+// section. On success, it updates the state of the verifier and returns
+// a batch of the messages.
+func (v *Verifier) Verify(header, proof []byte, msgs [][]byte) (*Batch, error) {
+	bh, err := btp.NewBTPBlockHeaderFromBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	if bh.NetworkID() != v.nid {
+		return nil, errors.InvalidStateError.Errorf(
+			"invalid network id exp=%d real=%d", v.nid, bh.NetworkID())
+	}
+	if bh.MainHeight() <= v.height {
+		return nil, errors.InvalidStateError.Errorf(
+			"invalid height last=%d real=%d", v.height, bh.MainHeight())
+	}
+	if v.lastNSHash != nil && !bytes.Equal(v.lastNSHash, bh.PrevNetworkSectionHash()) {
+		return nil, errors.InvalidStateError.Errorf(
+			"invalid prev network section hash exp=%x real=%x",
+			v.lastNSHash, bh.PrevNetworkSectionHash())
+	}
+	if v.nextSN >= 0 && v.nextSN != bh.FirstMessageSN() {
+		return nil, errors.InvalidStateError.Errorf(
+			"invalid first message sn exp=%d real=%d", v.nextSN, bh.FirstMessageSN())
+	}
+	if int64(len(msgs)) != bh.MessageCount() {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"invalid message count exp=%d real=%d", bh.MessageCount(), len(msgs))
+	}
+	if root := btp.MessagesRootOf(v.mod, msgs); !bytes.Equal(root, bh.MessagesRoot()) {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"invalid messages root exp=%x real=%x", bh.MessagesRoot(), root)
+	}
+
+	if len(proof) == 0 {
+		// Only the first section of the network has no proof, and it's
+		// acceptable only for a verifier without any trusted state.
+		if bh.PrevNetworkSectionHash() != nil || v.pc != nil || v.lastNSHash != nil {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"no proof for height=%d", bh.MainHeight())
+		}
+		if v.trust != nil && !bytes.Equal(v.trust, bh.NextProofContextHash()) {
+			return nil, errors.InvalidStateError.Errorf(
+				"untrusted proof context exp=%x real=%x",
+				v.trust, bh.NextProofContextHash())
+		}
+	} else {
+		if v.pc == nil {
+			return nil, errors.InvalidStateError.Errorf(
+				"no proof context for height=%d", bh.MainHeight())
+		}
+		pf, err := v.pc.NewProofFromBytes(proof)
+		if err != nil {
+			return nil, err
+		}
+		decision := v.pc.NewDecision(
+			v.srcUID, v.ntid, bh.MainHeight(), bh.Round(),
+			btp.NetworkTypeSectionHashOf(v.mod, bh),
+		)
+		if err = v.pc.Verify(decision.Hash(), pf); err != nil {
+			return nil, err
+		}
+	}
+
+	pc := v.pc
+	var npcBytes []byte
+	if npc := bh.NextProofContext(); npc != nil {
+		if h := v.mod.Hash(npc); !bytes.Equal(h, bh.NextProofContextHash()) {
+			return nil, errors.IllegalArgumentError.Errorf(
+				"invalid next proof context hash exp=%x real=%x",
+				bh.NextProofContextHash(), h)
+		}
+		if pc == nil || !bytes.Equal(pc.Hash(), bh.NextProofContextHash()) {
+			if pc, err = v.mod.NewProofContextFromBytes(npc); err != nil {
+				return nil, err
+			}
+			npcBytes = npc
+		}
+	} else if pc == nil || !bytes.Equal(pc.Hash(), bh.NextProofContextHash()) {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"no next proof context for changed hash=%x", bh.NextProofContextHash())
+	}
+
+	v.pc = pc
+	v.height = bh.MainHeight()
+	v.lastNSHash = btp.NetworkSectionHashOf(v.mod, bh)
+	v.nextSN = bh.FirstMessageSN() + bh.MessageCount()
+	return &Batch{
+		NetworkID:        bh.NetworkID(),
+		Height:           bh.MainHeight(),
+		FirstSN:          bh.FirstMessageSN(),
+		Messages:         msgs,
+		Header:           header,
+		Proof:            proof,
+		NextProofContext: npcBytes,
+	}, nil
+}
@@ -0,0 +1,314 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package relay
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/btp"
+	"github.com/icon-project/goloop/btp/ntm"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/module"
+)
+
+const testUID = "ed25519"
+
+var testSrcUID = module.SourceNetworkUID(1)
+
+type testNetworkType struct {
+	pc module.BTPProofContext
+}
+
+func (nt *testNetworkType) UID() string                  { return testUID }
+func (nt *testNetworkType) NextProofContextHash() []byte { return nt.pc.Hash() }
+func (nt *testNetworkType) NextProofContext() []byte     { return nt.pc.Bytes() }
+func (nt *testNetworkType) OpenNetworkIDs() []int64      { return []int64{1, 2} }
+
+type testNetwork struct {
+	nextSN     int64
+	npcChanged bool
+	prevHash   []byte
+	lastHash   []byte
+}
+
+func (nw *testNetwork) Name() string                   { return "test" }
+func (nw *testNetwork) Owner() module.Address          { return nil }
+func (nw *testNetwork) NetworkTypeID() int64           { return 1 }
+func (nw *testNetwork) Open() bool                     { return true }
+func (nw *testNetwork) NextMessageSN() int64           { return nw.nextSN }
+func (nw *testNetwork) NextProofContextChanged() bool  { return nw.npcChanged }
+func (nw *testNetwork) PrevNetworkSectionHash() []byte { return nw.prevHash }
+func (nw *testNetwork) LastNetworkSectionHash() []byte { return nw.lastHash }
+
+type testWalletProvider struct {
+	w module.BaseWallet
+}
+
+func (wp testWalletProvider) WalletFor(dsa string) module.BaseWallet {
+	return wp.w
+}
+
+// testSource builds BTP blocks of networks 1 and 2 of network type 1.
+type testSource struct {
+	t        *testing.T
+	height   int64
+	nt       *testNetworkType
+	networks map[int64]*testNetwork
+
+	// validators signing the next block and the validators of nt
+	pc      module.BTPProofContext
+	wps     []module.WalletProvider
+	nextWps []module.WalletProvider
+}
+
+func (s *testSource) GetNetworkTypeIDs() ([]int64, error) {
+	return []int64{1}, nil
+}
+
+func (s *testSource) GetNetworkView(nid int64) (btp.NetworkView, error) {
+	if nw, ok := s.networks[nid]; ok {
+		return nw, nil
+	}
+	return nil, errors.ErrNotFound
+}
+
+func (s *testSource) GetNetworkTypeView(ntid int64) (btp.NetworkTypeView, error) {
+	if ntid == 1 {
+		return s.nt, nil
+	}
+	return nil, errors.ErrNotFound
+}
+
+func newTestValidators(t *testing.T, count int) (module.BTPProofContext, []module.WalletProvider) {
+	mod := ntm.ForUID(testUID)
+	keys := make([][]byte, 0, count)
+	wps := make([]module.WalletProvider, 0, count)
+	for i := 0; i < count; i++ {
+		w := ntm.NewDSAWallet(mod.DSA(), wallet.New())
+		keys = append(keys, w.PublicKey())
+		wps = append(wps, testWalletProvider{w})
+	}
+	pc, err := mod.NewProofContext(keys)
+	assert.NoError(t, err)
+	return pc, wps
+}
+
+func newTestSource(t *testing.T) *testSource {
+	pc, wps := newTestValidators(t, 4)
+	return &testSource{
+		t:      t,
+		height: 10,
+		nt:     &testNetworkType{pc: pc},
+		networks: map[int64]*testNetwork{
+			1: {npcChanged: true},
+			2: {npcChanged: true},
+		},
+		pc:      pc,
+		wps:     wps,
+		nextWps: wps,
+	}
+}
+
+// changeValidators changes the validators from the next block.
+func (s *testSource) changeValidators(count int) {
+	s.nt.pc, s.nextWps = newTestValidators(s.t, count)
+	for _, nw := range s.networks {
+		nw.npcChanged = true
+	}
+}
+
+// next builds the next block with messages for network 1 and returns the
+// header and the proof for network 1.
+func (s *testSource) next(msgs ...[]byte) ([]byte, []byte) {
+	assert := assert.New(s.t)
+	s.height++
+	pc, wps := s.pc, s.wps
+	s.networks[1].nextSN += int64(len(msgs))
+	builder := btp.NewSectionBuilder(s)
+	for nid, nw := range s.networks {
+		if nw.npcChanged {
+			builder.EnsureSection(nid)
+		}
+	}
+	builder.EnsureSection(2)
+	for _, msg := range msgs {
+		builder.SendMessage(1, msg)
+	}
+	bs, err := builder.Build()
+	assert.NoError(err)
+	nts, err := bs.NetworkTypeSectionFor(1)
+	assert.NoError(err)
+	bh, err := btp.NewBTPBlockHeader(s.height, 0, nts, 1, 0)
+	assert.NoError(err)
+
+	var proof []byte
+	if s.networks[1].prevHash != nil || s.networks[1].lastHash != nil {
+		dHash := pc.NewDecision(testSrcUID, 1, s.height, 0, nts.Hash()).Hash()
+		pf := pc.NewProof()
+		for _, wp := range wps {
+			pp, err := pc.NewProofPart(dHash, wp)
+			assert.NoError(err)
+			pf.Add(pp)
+		}
+		proof = pf.Bytes()
+	}
+
+	for _, ntd := range bs.Digest().NetworkTypeDigests() {
+		for _, nd := range ntd.NetworkDigests() {
+			nw := s.networks[nd.NetworkID()]
+			nw.prevHash = nw.lastHash
+			nw.lastHash = nd.NetworkSectionHash()
+			nw.npcChanged = false
+		}
+	}
+	s.pc, s.wps = s.nt.pc, s.nextWps
+	return bh.HeaderBytes(), proof
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	assert := assert.New(t)
+	src := newTestSource(t)
+
+	v, err := NewVerifier(testSrcUID, 1, 1, testUID, nil, nil)
+	assert.NoError(err)
+
+	// first section has no proof but the proof context
+	h, p := src.next([]byte("a"))
+	assert.Nil(p)
+	b, err := v.Verify(h, p, [][]byte{[]byte("a")})
+	assert.NoError(err)
+	assert.EqualValues(11, b.Height)
+	assert.EqualValues(0, b.FirstSN)
+	assert.EqualValues(src.nt.pc.Bytes(), b.NextProofContext)
+	assert.EqualValues(src.nt.pc.Hash(), v.ProofContext().Hash())
+
+	h, p = src.next([]byte("b"), []byte("c"))
+	_, err = v.Verify(h, p, [][]byte{[]byte("b")})
+	assert.Error(err)
+	_, err = v.Verify(h, p, [][]byte{[]byte("b"), []byte("d")})
+	assert.Error(err)
+	_, err = v.Verify(h, nil, [][]byte{[]byte("b"), []byte("c")})
+	assert.Error(err)
+	b, err = v.Verify(h, p, [][]byte{[]byte("b"), []byte("c")})
+	assert.NoError(err)
+	assert.EqualValues(1, b.FirstSN)
+	assert.Nil(b.NextProofContext)
+
+	// replayed header
+	_, err = v.Verify(h, p, [][]byte{[]byte("b"), []byte("c")})
+	assert.Error(err)
+
+	// proof context changes are tracked
+	oldPC := src.nt.pc
+	src.changeValidators(5)
+	h, p = src.next()
+	b, err = v.Verify(h, p, nil)
+	assert.NoError(err)
+	assert.EqualValues(src.nt.pc.Bytes(), b.NextProofContext)
+	assert.False(bytes.Equal(oldPC.Hash(), v.ProofContext().Hash()))
+
+	h, p = src.next([]byte("d"))
+	b, err = v.Verify(h, p, [][]byte{[]byte("d")})
+	assert.NoError(err)
+	assert.EqualValues(3, b.FirstSN)
+
+	// skipped section
+	src.next([]byte("e"))
+	h, p = src.next([]byte("f"))
+	_, err = v.Verify(h, p, [][]byte{[]byte("f")})
+	assert.Error(err)
+
+	// a new verifier may start from the middle with the proof context
+	v2, err := NewVerifier(testSrcUID, 1, 1, testUID, src.nt.pc.Bytes(), nil)
+	assert.NoError(err)
+	b, err = v2.Verify(h, p, [][]byte{[]byte("f")})
+	assert.NoError(err)
+	assert.EqualValues(5, b.FirstSN)
+
+	// invalid proof context
+	v3, err := NewVerifier(testSrcUID, 1, 1, testUID, oldPC.Bytes(), nil)
+	assert.NoError(err)
+	_, err = v3.Verify(h, p, [][]byte{[]byte("f")})
+	assert.Error(err)
+
+	// a forged first section without proof isn't accepted by a verifier
+	// resumed with the proof context
+	v4, err := NewVerifier(testSrcUID, 1, 1, testUID, src.nt.pc.Bytes(), nil)
+	assert.NoError(err)
+	forged := newTestSource(t)
+	forged.height = src.height
+	h, p = forged.next([]byte("x"))
+	assert.Nil(p)
+	_, err = v4.Verify(h, p, [][]byte{[]byte("x")})
+	assert.Error(err)
+	assert.EqualValues(src.nt.pc.Hash(), v4.ProofContext().Hash())
+
+	_, err = NewVerifier(testSrcUID, 1, 1, "unknown", nil, nil)
+	assert.Error(err)
+}
+
+func TestVerifier_Trust(t *testing.T) {
+	assert := assert.New(t)
+	src := newTestSource(t)
+	forged := newTestSource(t)
+
+	// the first section of the network shall match the trusted hash
+	v, err := NewVerifier(testSrcUID, 1, 1, testUID, nil, src.nt.pc.Hash())
+	assert.NoError(err)
+	h, p := forged.next([]byte("x"))
+	_, err = v.Verify(h, p, [][]byte{[]byte("x")})
+	assert.Error(err)
+	assert.Nil(v.ProofContext())
+
+	h, p = src.next([]byte("a"))
+	_, err = v.Verify(h, p, [][]byte{[]byte("a")})
+	assert.NoError(err)
+
+	// the proof context to resume shall match the trusted hash
+	_, err = NewVerifier(testSrcUID, 1, 1, testUID, forged.nt.pc.Bytes(), src.nt.pc.Hash())
+	assert.Error(err)
+	v2, err := NewVerifier(testSrcUID, 1, 1, testUID, src.nt.pc.Bytes(), src.nt.pc.Hash())
+	assert.NoError(err)
+	h, p = src.next([]byte("b"))
+	_, err = v2.Verify(h, p, [][]byte{[]byte("b")})
+	assert.NoError(err)
+}
+
+func TestBatch_JSON(t *testing.T) {
+	assert := assert.New(t)
+	b := &Batch{
+		NetworkID: 1,
+		Height:    10,
+		FirstSN:   3,
+		Messages:  [][]byte{[]byte("a"), []byte("b")},
+		Header:    []byte("header"),
+	}
+	var buf bytes.Buffer
+	assert.NoError(NewJSONSink(&buf).Send(b))
+	var b2 Batch
+	assert.NoError(json.Unmarshal(buf.Bytes(), &b2))
+	assert.EqualValues(b.Messages, b2.Messages)
+	assert.EqualValues(b.Header, b2.Header)
+	assert.EqualValues(b.Height, b2.Height)
+	assert.EqualValues(b.FirstSN, b2.FirstSN)
+	assert.Nil(b2.Proof)
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/btp/ntm"
 	"github.com/icon-project/goloop/chain/base"
 	"github.com/icon-project/goloop/chain/gs"
 	"github.com/icon-project/goloop/common/db"
@@ -81,7 +82,9 @@ type simpleTask interface {
 }
 
 type singleChain struct {
-	wallet module.Wallet
+	wallet     module.Wallet
+	walletMtx  sync.Mutex
+	dsaWallets map[string]module.BaseWallet
 
 	dbLock   sync.RWMutex
 	database db.Database
@@ -206,6 +209,10 @@ func (c *singleChain) ConcurrencyLevel() int {
 	}
 }
 
+func (c *singleChain) OptimisticExecution() bool {
+	return c.cfg.OptimisticExec
+}
+
 func (c *singleChain) NormalTxPoolSize() int {
 	if c.cfg.NormalTxPoolSize > 0 {
 		return c.cfg.NormalTxPoolSize
@@ -265,6 +272,18 @@ func (c *singleChain) NephewsLimit() int {
 	return ConfigDefaultNephewLimit
 }
 
+func (c *singleChain) BandwidthLimit() string {
+	return c.cfg.BandwidthLimit
+}
+
+func (c *singleChain) PrivatePeers() string {
+	return c.cfg.PrivatePeers
+}
+
+func (c *singleChain) DisableDiscovery() bool {
+	return c.cfg.DisableDiscovery
+}
+
 func (c *singleChain) ValidateTxOnSend() bool {
 	return c.cfg.ValidateTxOnSend
 }
@@ -719,7 +738,20 @@ func (c *singleChain) WalletFor(dsa string) module.BaseWallet {
 	case "ecdsa/secp256k1":
 		return c.wallet
 	}
-	return nil
+	c.walletMtx.Lock()
+	defer c.walletMtx.Unlock()
+	if w, ok := c.dsaWallets[dsa]; ok {
+		return w
+	}
+	w := ntm.NewDSAWallet(dsa, c.wallet)
+	if w == nil {
+		return nil
+	}
+	if c.dsaWallets == nil {
+		c.dsaWallets = make(map[string]module.BaseWallet)
+	}
+	c.dsaWallets[dsa] = w
+	return w
 }
 
 func (c *singleChain) DoDBTask(task func(database db.Database)) {
@@ -40,6 +40,7 @@ type Config struct {
 	SeedAddr         string `json:"seed_addr"`
 	Role             uint   `json:"role"`
 	ConcurrencyLevel int    `json:"concurrency_level,omitempty"`
+	OptimisticExec   bool   `json:"optimistic_execution,omitempty"`
 	NormalTxPoolSize int    `json:"normal_tx_pool,omitempty"`
 	PatchTxPoolSize  int    `json:"patch_tx_pool,omitempty"`
 	MaxBlockTxBytes  int    `json:"max_block_tx_bytes,omitempty"`
@@ -48,6 +49,9 @@ type Config struct {
 	ChildrenLimit    *int   `json:"children_limit,omitempty"`
 	NephewsLimit     *int   `json:"nephews_limit,omitempty"`
 	ValidateTxOnSend bool   `json:"validate_tx_on_send,omitempty"`
+	BandwidthLimit   string `json:"bandwidth_limit,omitempty"`
+	PrivatePeers     string `json:"private_peers,omitempty"`
+	DisableDiscovery bool   `json:"disable_discovery,omitempty"`
 
 	// runtime
 	Channel        string `json:"channel"`
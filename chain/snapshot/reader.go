@@ -0,0 +1,101 @@
+package snapshot
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+// ProgressCallback is called after each chunk is imported.
+type ProgressCallback func(chunks, total int) error
+
+func (m *Manifest) readChunk(dir string, ci *ChunkInfo) ([]byte, error) {
+	if path.Base(ci.Name) != ci.Name {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidChunkName(name=%s)", ci.Name)
+	}
+	bs, err := ioutil.ReadFile(path.Join(dir, ci.Name))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(bs)) != ci.Size {
+		return nil, errors.InvalidStateError.Errorf(
+			"InvalidChunkSize(name=%s,exp=%d,real=%d)", ci.Name, ci.Size, len(bs))
+	}
+	if hash := crypto.SHA3Sum256(bs); !bytes.Equal(hash, ci.Hash) {
+		return nil, errors.InvalidStateError.Errorf(
+			"InvalidChunkHash(name=%s,exp=%#x,real=%#x)", ci.Name, []byte(ci.Hash), hash)
+	}
+	return bs, nil
+}
+
+func importChunk(ci *ChunkInfo, bs []byte, dst db.Database) error {
+	buckets := make(map[db.BucketID]db.Bucket)
+	de := codec.BC.NewDecoder(bytes.NewReader(bs))
+	defer de.Close()
+
+	entries := 0
+	for {
+		var id db.BucketID
+		var key, value []byte
+		if err := de.DecodeListOf(&id, &key, &value); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return errors.InvalidStateError.Wrapf(err, "InvalidChunkEntry(name=%s)", ci.Name)
+		}
+		if hasher := id.Hasher(); hasher != nil {
+			if !bytes.Equal(hasher.Hash(value), key) {
+				return errors.InvalidStateError.Errorf(
+					"InvalidEntryHash(name=%s,bucket=%q,key=%#x)", ci.Name, id, key)
+			}
+		}
+		bk, ok := buckets[id]
+		if !ok {
+			var err error
+			if bk, err = dst.GetBucket(id); err != nil {
+				return err
+			}
+			buckets[id] = bk
+		}
+		if err := bk.Set(key, value); err != nil {
+			return err
+		}
+		entries += 1
+	}
+	if entries != ci.Entries {
+		return errors.InvalidStateError.Errorf(
+			"InvalidChunkEntries(name=%s,exp=%d,real=%d)", ci.Name, ci.Entries, entries)
+	}
+	return nil
+}
+
+// Import writes entries of the snapshot in dir to dst. Each chunk is
+// verified with its hash in the manifest before any entry of it is
+// written, and entries of buckets with a hasher are verified with their
+// keys. Whether the data matches roots is not verified here.
+func (m *Manifest) Import(dir string, dst db.Database, cb ProgressCallback) error {
+	if m.Codec != codec.BC.Name() {
+		return errors.UnsupportedError.Errorf("UnsupportedCodec(codec=%s)", m.Codec)
+	}
+	for i, ci := range m.Chunks {
+		bs, err := m.readChunk(dir, ci)
+		if err != nil {
+			return err
+		}
+		if err := importChunk(ci, bs, dst); err != nil {
+			return err
+		}
+		if cb != nil {
+			if err := cb(i+1, len(m.Chunks)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
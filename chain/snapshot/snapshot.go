@@ -0,0 +1,73 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	Version          = 1
+	ManifestFileName = "manifest.json"
+
+	DefaultChunkSize = 16 * 1024 * 1024
+)
+
+// Roots are roots of the data included in the result of the block.
+type Roots struct {
+	StateHash          common.HexBytes `json:"stateHash"`
+	PatchReceiptsHash  common.HexBytes `json:"patchReceiptsHash"`
+	NormalReceiptsHash common.HexBytes `json:"normalReceiptsHash"`
+	NextValidatorsHash common.HexBytes `json:"nextValidatorsHash"`
+	ExtensionData      common.HexBytes `json:"extensionData,omitempty"`
+	BTPData            common.HexBytes `json:"btpData,omitempty"`
+}
+
+type ChunkInfo struct {
+	Name    string          `json:"name"`
+	Hash    common.HexBytes `json:"hash"`
+	Size    int64           `json:"size"`
+	Entries int             `json:"entries"`
+}
+
+// Manifest describes the snapshot of the chain at the height. Block is
+// the ID of the block at the height and Votes is the hash of votes for
+// the block. Each chunk is a sequence of entries (bucket ID, key and value)
+// encoded with the codec.
+type Manifest struct {
+	Version int             `json:"version"`
+	CID     common.HexInt32 `json:"cid"`
+	NID     common.HexInt32 `json:"nid"`
+	Height  int64           `json:"height"`
+	Block   common.HexBytes `json:"block"`
+	Votes   common.HexBytes `json:"votes"`
+	Codec   string          `json:"codec"`
+	Roots   Roots           `json:"roots"`
+	Chunks  []*ChunkInfo    `json:"chunks"`
+}
+
+func ReadManifest(dir string) (*Manifest, error) {
+	bs, err := ioutil.ReadFile(path.Join(dir, ManifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := json.Unmarshal(bs, m); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidManifest")
+	}
+	if m.Version != Version {
+		return nil, errors.UnsupportedError.Errorf("UnsupportedVersion(version=%d)", m.Version)
+	}
+	return m, nil
+}
+
+func writeManifest(dir string, m *Manifest) error {
+	bs, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(dir, ManifestFileName), bs, 0644)
+}
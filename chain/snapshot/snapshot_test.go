@@ -0,0 +1,149 @@
+package snapshot
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+)
+
+type testEntry struct {
+	id         db.BucketID
+	key, value []byte
+}
+
+func testEntries(cnt int) []testEntry {
+	var entries []testEntry
+	for i := 0; i < cnt; i++ {
+		value := []byte(fmt.Sprintf("value-%d", i))
+		key := crypto.SHA3Sum256(value)
+		entries = append(entries,
+			testEntry{db.MerkleTrie, key, value},
+			testEntry{db.BlockHeaderHashByHeight, []byte(fmt.Sprintf("height-%d", i)), key},
+		)
+	}
+	return entries
+}
+
+func setEntries(t *testing.T, dbase db.Database, entries []testEntry) {
+	for _, e := range entries {
+		bk, err := dbase.GetBucket(e.id)
+		assert.NoError(t, err)
+		assert.NoError(t, bk.Set(e.key, e.value))
+	}
+}
+
+func exportEntries(t *testing.T, dir string, entries []testEntry) *Manifest {
+	src := db.NewMapDB()
+	setEntries(t, src, entries)
+
+	w, err := NewWriter(dir, src, 256)
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		for _, e := range entries {
+			bk, err := w.GetBucket(e.id)
+			assert.NoError(t, err)
+			if has, _ := bk.Has(e.key); has {
+				continue
+			}
+			assert.NoError(t, bk.Set(e.key, e.value))
+		}
+	}
+	m := &Manifest{Height: 10, Block: []byte{0x01}}
+	assert.NoError(t, w.Finish(m))
+	return m
+}
+
+func TestSnapshot_ExportImport(t *testing.T) {
+	dir := t.TempDir()
+	entries := testEntries(100)
+	exportEntries(t, dir, entries)
+
+	_, err := NewWriter(dir, db.NewMapDB(), 0)
+	assert.Error(t, err)
+
+	m, err := ReadManifest(dir)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, m.Height)
+	assert.True(t, len(m.Chunks) > 1)
+	total := 0
+	for _, ci := range m.Chunks {
+		total += ci.Entries
+	}
+	assert.Equal(t, len(entries), total)
+
+	dst := db.NewMapDB()
+	var progress int
+	err = m.Import(dir, dst, func(chunks, all int) error {
+		progress = chunks
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, len(m.Chunks), progress)
+	for _, e := range entries {
+		bk, err := dst.GetBucket(e.id)
+		assert.NoError(t, err)
+		value, err := bk.Get(e.key)
+		assert.NoError(t, err)
+		assert.Equal(t, e.value, value)
+	}
+}
+
+func TestSnapshot_ImportInvalid(t *testing.T) {
+	dir := t.TempDir()
+	exportEntries(t, dir, testEntries(10))
+	m, err := ReadManifest(dir)
+	assert.NoError(t, err)
+
+	// tampered chunk
+	fp := path.Join(dir, m.Chunks[0].Name)
+	bs, err := ioutil.ReadFile(fp)
+	assert.NoError(t, err)
+	bs[len(bs)-1] ^= 0xff
+	assert.NoError(t, ioutil.WriteFile(fp, bs, 0644))
+	assert.Error(t, m.Import(dir, db.NewMapDB(), nil))
+
+	// entry not matching its hash
+	dir2 := t.TempDir()
+	entries := []testEntry{
+		{db.MerkleTrie, crypto.SHA3Sum256([]byte("a")), []byte("b")},
+	}
+	m2 := exportEntries(t, dir2, entries)
+	assert.Error(t, m2.Import(dir2, db.NewMapDB(), nil))
+
+	// invalid chunk name
+	m2.Chunks[0].Name = "../" + m2.Chunks[0].Name
+	assert.Error(t, m2.Import(dir2, db.NewMapDB(), nil))
+}
+
+func TestTracker(t *testing.T) {
+	src := db.NewMapDB()
+	entries := testEntries(2)
+	setEntries(t, src, entries)
+
+	tr := NewTracker(src)
+	for _, e := range entries {
+		bk, err := tr.GetBucket(e.id)
+		assert.NoError(t, err)
+		has, err := bk.Has(e.key)
+		assert.NoError(t, err)
+		assert.False(t, has)
+		value, err := bk.Get(e.key)
+		assert.NoError(t, err)
+		assert.Nil(t, value)
+
+		assert.NoError(t, bk.Set(e.key, e.value))
+		value, err = bk.Get(e.key)
+		assert.NoError(t, err)
+		assert.Equal(t, e.value, value)
+		assert.Error(t, bk.Delete(e.key))
+	}
+	assert.NoError(t, tr.Close())
+	bk, _ := tr.GetBucket(db.MerkleTrie)
+	assert.Error(t, bk.Set([]byte{0x01}, []byte{0x02}))
+}
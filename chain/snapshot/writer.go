@@ -0,0 +1,207 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+// tracker is a database remembering written keys. Values of buckets with
+// a hasher are read from the source database on demand.
+type tracker struct {
+	lock    sync.Mutex
+	src     db.Database
+	written map[string][]byte
+	onSet   func(id db.BucketID, key, value []byte) error
+}
+
+type trackerBucket struct {
+	t   *tracker
+	id  db.BucketID
+	src db.Bucket
+}
+
+func writtenKey(id db.BucketID, key []byte) string {
+	return string(id) + ":" + string(key)
+}
+
+func (b *trackerBucket) Get(key []byte) ([]byte, error) {
+	value, ok := b.t.get(b.id, key)
+	if !ok {
+		return nil, nil
+	}
+	if value != nil {
+		return value, nil
+	}
+	return b.src.Get(key)
+}
+
+func (b *trackerBucket) Has(key []byte) (bool, error) {
+	_, ok := b.t.get(b.id, key)
+	return ok, nil
+}
+
+func (b *trackerBucket) Set(key []byte, value []byte) error {
+	return b.t.set(b.id, key, value)
+}
+
+func (b *trackerBucket) Delete(key []byte) error {
+	return errors.UnsupportedError.New("DeleteOnSnapshotTracker")
+}
+
+func (t *tracker) GetBucket(id db.BucketID) (db.Bucket, error) {
+	src, err := t.src.GetBucket(id)
+	if err != nil {
+		return nil, err
+	}
+	return &trackerBucket{t: t, id: id, src: src}, nil
+}
+
+func (t *tracker) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.written = nil
+	return nil
+}
+
+func (t *tracker) get(id db.BucketID, key []byte) ([]byte, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	value, ok := t.written[writtenKey(id, key)]
+	return value, ok
+}
+
+func (t *tracker) set(id db.BucketID, key, value []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.written == nil {
+		return errors.InvalidStateError.New("AlreadyClosed")
+	}
+	if t.onSet != nil {
+		if err := t.onSet(id, key, value); err != nil {
+			return err
+		}
+	}
+	var stored []byte
+	if id.Hasher() == nil {
+		stored = append([]byte{}, value...)
+	}
+	t.written[writtenKey(id, key)] = stored
+	return nil
+}
+
+func (t *tracker) init(src db.Database, onSet func(id db.BucketID, key, value []byte) error) {
+	t.src = src
+	t.written = make(map[string][]byte)
+	t.onSet = onSet
+}
+
+// NewTracker returns a database recording written keys without storing
+// values of buckets with a hasher. Copying data reachable from roots in
+// src to it fails if any of them is missing in src.
+func NewTracker(src db.Database) db.Database {
+	t := new(tracker)
+	t.init(src, nil)
+	return t
+}
+
+// Writer is a database writing entries into chunks of the snapshot.
+// Entries already written are not requested again while copying data
+// from the source database, so shared data is written only once.
+type Writer struct {
+	tracker
+	dir       string
+	chunkSize int
+
+	buffer  bytes.Buffer
+	encoder codec.EncodeAndCloser
+	entries int
+	chunks  []*ChunkInfo
+}
+
+func (w *Writer) writeInLock(id db.BucketID, key, value []byte) error {
+	if w.encoder == nil {
+		w.encoder = codec.BC.NewEncoder(&w.buffer)
+	}
+	if err := w.encoder.EncodeListOf(id, key, value); err != nil {
+		return err
+	}
+	w.entries += 1
+	if w.buffer.Len() >= w.chunkSize {
+		return w.flushChunkInLock()
+	}
+	return nil
+}
+
+func (w *Writer) flushChunkInLock() error {
+	if w.encoder == nil {
+		return nil
+	}
+	if err := w.encoder.Close(); err != nil {
+		return err
+	}
+	w.encoder = nil
+
+	bs := w.buffer.Bytes()
+	name := fmt.Sprintf("chunk%06d.bin", len(w.chunks))
+	if err := ioutil.WriteFile(path.Join(w.dir, name), bs, 0644); err != nil {
+		return err
+	}
+	w.chunks = append(w.chunks, &ChunkInfo{
+		Name:    name,
+		Hash:    crypto.SHA3Sum256(bs),
+		Size:    int64(len(bs)),
+		Entries: w.entries,
+	})
+	w.buffer.Reset()
+	w.entries = 0
+	return nil
+}
+
+// Finish writes remaining entries and the manifest with chunks written.
+func (w *Writer) Finish(m *Manifest) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.written == nil {
+		return errors.InvalidStateError.New("AlreadyClosed")
+	}
+	if err := w.flushChunkInLock(); err != nil {
+		return err
+	}
+	m.Version = Version
+	m.Codec = codec.BC.Name()
+	m.Chunks = w.chunks
+	return writeManifest(w.dir, m)
+}
+
+// NewWriter returns a new writer for the snapshot in dir. It fails if
+// dir already has a manifest.
+func NewWriter(dir string, src db.Database, chunkSize int) (*Writer, error) {
+	if _, err := os.Stat(path.Join(dir, ManifestFileName)); err == nil {
+		return nil, errors.IllegalArgumentError.Errorf("SnapshotExists(dir=%s)", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	w := &Writer{
+		dir:       dir,
+		chunkSize: chunkSize,
+	}
+	w.tracker.init(src, w.writeInLock)
+	return w, nil
+}
@@ -0,0 +1,471 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync/atomic"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	ReplayTask = "replay"
+
+	ReplayDone   = "replay done"
+	ReplayFailed = "replay failed"
+)
+
+// ReplayParams is the parameter of the replay task. It executes normal
+// transactions of blocks from Start to End (inclusive) again, and
+// compares the results with the stored ones. The report is written to
+// the file at Report if it's specified.
+type ReplayParams struct {
+	Start  int64  `json:"start"`
+	End    int64  `json:"end"`
+	Report string `json:"report,omitempty"`
+}
+
+type ReplayReport struct {
+	Start      int64             `json:"start"`
+	End        int64             `json:"end"`
+	Replayed   int64             `json:"replayed"`
+	Divergence *ReplayDivergence `json:"divergence,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// ReplayDivergence describes the first block whose result is different
+// from the stored one.
+type ReplayDivergence struct {
+	Height      int64              `json:"height"`
+	BlockID     common.HexBytes    `json:"blockID"`
+	Expected    *ReplayResult      `json:"expected"`
+	Actual      *ReplayResult      `json:"actual"`
+	Transaction *ReplayTransaction `json:"transaction,omitempty"`
+	Accounts    []*ReplayAccount   `json:"accounts"`
+}
+
+type ReplayResult struct {
+	StateHash          common.HexBytes `json:"stateHash"`
+	PatchReceiptsHash  common.HexBytes `json:"patchReceiptsHash"`
+	NormalReceiptsHash common.HexBytes `json:"normalReceiptsHash"`
+	NextValidatorsHash common.HexBytes `json:"nextValidatorsHash"`
+	ExtensionData      common.HexBytes `json:"extensionData,omitempty"`
+	BTPData            common.HexBytes `json:"btpData,omitempty"`
+}
+
+// ReplayTransaction describes the first transaction whose receipt is
+// different from the stored one.
+type ReplayTransaction struct {
+	Group    string          `json:"group"`
+	Index    int             `json:"index"`
+	TxHash   common.HexBytes `json:"txHash"`
+	Reason   string          `json:"reason"`
+	Expected interface{}     `json:"expected"`
+	Actual   interface{}     `json:"actual"`
+}
+
+// ReplayAccount describes the account whose state is different from the
+// stored one.
+type ReplayAccount struct {
+	Address         *common.Address `json:"address"`
+	Diff            []string        `json:"diff"`
+	ExpectedBalance *common.HexInt  `json:"expectedBalance"`
+	ActualBalance   *common.HexInt  `json:"actualBalance"`
+}
+
+var replayStates = map[State]string{
+	Starting: "replay starting",
+	Stopping: "replay stopping",
+	Failed:   ReplayFailed,
+	Finished: ReplayDone,
+}
+
+type taskReplay struct {
+	chain    *singleChain
+	params   *ReplayParams
+	height   int64
+	result   resultStore
+	cancelCh chan struct{}
+}
+
+func (t *taskReplay) String() string {
+	return fmt.Sprintf("Replay(start=%d,end=%d)", t.params.Start, t.params.End)
+}
+
+func (t *taskReplay) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("replay height=%d/%d",
+			atomic.LoadInt64(&t.height), t.params.End)
+	default:
+		if name, ok := replayStates[s]; ok {
+			return name
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskReplay) Start() error {
+	p := t.params
+	if p.Start < 1 || p.End < p.Start {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidRange(start=%d,end=%d)", p.Start, p.End)
+	}
+	if last := t.chain.lastBlockHeight(); p.End >= last {
+		return errors.IllegalArgumentError.Errorf(
+			"NoResultForEnd(end=%d,last=%d)", p.End, last)
+	}
+	if err := t.chain.prepareManagers(); err != nil {
+		t.chain.releaseManagers()
+		return err
+	}
+	t.chain.sm.Start()
+	go func() {
+		t.result.SetValue(t._replayAll())
+	}()
+	return nil
+}
+
+func (t *taskReplay) _replayAll() error {
+	p := t.params
+	report := &ReplayReport{
+		Start: p.Start,
+		End:   p.End,
+	}
+	err := func() error {
+		for h := p.Start; h <= p.End; h++ {
+			atomic.StoreInt64(&t.height, h)
+			d, err := t._replay(h)
+			if err != nil {
+				return err
+			}
+			report.Replayed += 1
+			if d != nil {
+				report.Divergence = d
+				if d.Transaction != nil {
+					return errors.InvalidStateError.Errorf(
+						"Diverged(height=%d,group=%s,index=%d,tx=%#x)",
+						h, d.Transaction.Group, d.Transaction.Index,
+						d.Transaction.TxHash.Bytes())
+				}
+				return errors.InvalidStateError.Errorf("Diverged(height=%d)", h)
+			}
+		}
+		return nil
+	}()
+	if err != nil && report.Divergence == nil {
+		report.Error = err.Error()
+	}
+	if p.Report != "" {
+		bs, jerr := json.MarshalIndent(report, "", "  ")
+		if jerr == nil {
+			jerr = ioutil.WriteFile(p.Report, bs, 0644)
+		}
+		if jerr != nil {
+			t.chain.logger.Warnf("Fail to write replay report file=%s err=%+v",
+				p.Report, jerr)
+		}
+	}
+	return err
+}
+
+type replayCallback chan error
+
+func (cb replayCallback) OnValidate(tr module.Transition, err error) {
+	if err != nil {
+		cb <- err
+	}
+}
+
+func (cb replayCallback) OnExecute(tr module.Transition, err error) {
+	cb <- err
+}
+
+func (t *taskReplay) _replay(height int64) (*ReplayDivergence, error) {
+	c := t.chain
+	blk, err := c.bm.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	nblk, err := c.bm.GetBlockByHeight(height + 1)
+	if err != nil {
+		return nil, err
+	}
+	csi, err := c.bm.NewConsensusInfo(blk)
+	if err != nil {
+		return nil, err
+	}
+	tr1, err := c.sm.CreateInitialTransition(blk.Result(), blk.NextValidators())
+	if err != nil {
+		return nil, err
+	}
+	tr2, err := c.sm.CreateTransition(tr1, blk.NormalTransactions(), blk, csi, true)
+	if err != nil {
+		return nil, err
+	}
+	tr2 = c.sm.PatchTransition(tr2, nblk.PatchTransactions(), nblk)
+
+	cb := make(replayCallback, 2)
+	canceler, err := tr2.Execute(cb)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case err := <-cb:
+		if err != nil {
+			return nil, err
+		}
+	case <-t.cancelCh:
+		canceler()
+		return nil, errors.ErrInterrupted
+	}
+
+	if bytes.Equal(tr2.Result(), nblk.Result()) &&
+		bytes.Equal(tr2.NextValidators().Hash(), nblk.NextValidatorsHash()) {
+		return nil, nil
+	}
+	return t._divergenceOf(blk, nblk, tr2)
+}
+
+func (t *taskReplay) _resultOf(
+	wss state.WorldSnapshot, prl, nrl module.ReceiptList,
+) *ReplayResult {
+	return &ReplayResult{
+		StateHash:          wss.StateHash(),
+		PatchReceiptsHash:  prl.Hash(),
+		NormalReceiptsHash: nrl.Hash(),
+		NextValidatorsHash: wss.GetValidatorSnapshot().Hash(),
+		ExtensionData:      wss.ExtensionData(),
+		BTPData:            wss.BTPData(),
+	}
+}
+
+func (t *taskReplay) _divergenceOf(
+	blk, nblk module.Block, tr module.Transition,
+) (*ReplayDivergence, error) {
+	c := t.chain
+	expWSS, err := service.NewWorldSnapshot(c.Database(), c.plt, nblk.Result(), nblk.NextValidators())
+	if err != nil {
+		return nil, err
+	}
+	actWSS, err := service.NewWorldSnapshot(c.Database(), c.plt, tr.Result(), tr.NextValidators())
+	if err != nil {
+		return nil, err
+	}
+	expPRL, err := c.sm.ReceiptListFromResult(nblk.Result(), module.TransactionGroupPatch)
+	if err != nil {
+		return nil, err
+	}
+	expNRL, err := c.sm.ReceiptListFromResult(nblk.Result(), module.TransactionGroupNormal)
+	if err != nil {
+		return nil, err
+	}
+	d := &ReplayDivergence{
+		Height:   blk.Height(),
+		BlockID:  blk.ID(),
+		Expected: t._resultOf(expWSS, expPRL, expNRL),
+		Actual:   t._resultOf(actWSS, tr.PatchReceipts(), tr.NormalReceipts()),
+	}
+
+	accounts := newReplayAccounts()
+	accounts.add(state.SystemAddress)
+	if ws, err := state.WorldStateFromSnapshot(expWSS); err == nil {
+		wc := state.NewWorldContext(ws, blk, nil, c.plt)
+		accounts.add(wc.Treasury())
+		accounts.add(wc.Governance())
+	}
+
+	groups := []struct {
+		group    module.TransactionGroup
+		name     string
+		txs      module.TransactionList
+		expected module.ReceiptList
+		actual   module.ReceiptList
+	}{
+		{module.TransactionGroupPatch, "patch", nblk.PatchTransactions(), expPRL, tr.PatchReceipts()},
+		{module.TransactionGroupNormal, "normal", blk.NormalTransactions(), expNRL, tr.NormalReceipts()},
+	}
+	for _, g := range groups {
+		if d.Transaction != nil {
+			break
+		}
+		idx := 0
+		for itr := g.txs.Iterator(); itr.Has(); _, idx = itr.Next(), idx+1 {
+			tx, _, err := itr.Get()
+			if err != nil {
+				return nil, err
+			}
+			expRct, err := g.expected.Get(idx)
+			if err != nil {
+				return nil, err
+			}
+			actRct, err := g.actual.Get(idx)
+			if err != nil {
+				return nil, err
+			}
+			if err := actRct.Check(expRct); err != nil {
+				expJSON, _ := expRct.ToJSON(module.JSONVersionLast)
+				actJSON, _ := actRct.ToJSON(module.JSONVersionLast)
+				d.Transaction = &ReplayTransaction{
+					Group:    g.name,
+					Index:    idx,
+					TxHash:   tx.ID(),
+					Reason:   err.Error(),
+					Expected: expJSON,
+					Actual:   actJSON,
+				}
+				accounts.addTransaction(tx, expRct, actRct)
+				break
+			}
+		}
+	}
+
+	// without any divergent transaction, check all accounts related to
+	// the transactions in the block.
+	if d.Transaction == nil {
+		for _, g := range groups {
+			idx := 0
+			for itr := g.txs.Iterator(); itr.Has(); _, idx = itr.Next(), idx+1 {
+				tx, _, err := itr.Get()
+				if err != nil {
+					return nil, err
+				}
+				expRct, err := g.expected.Get(idx)
+				if err != nil {
+					return nil, err
+				}
+				actRct, err := g.actual.Get(idx)
+				if err != nil {
+					return nil, err
+				}
+				accounts.addTransaction(tx, expRct, actRct)
+			}
+		}
+	}
+	d.Accounts = accounts.diff(expWSS, actWSS)
+	return d, nil
+}
+
+type replayAccounts struct {
+	ids   map[string]bool
+	addrs []module.Address
+}
+
+func newReplayAccounts() *replayAccounts {
+	return &replayAccounts{
+		ids: make(map[string]bool),
+	}
+}
+
+func (a *replayAccounts) add(addr module.Address) {
+	if addr == nil || a.ids[string(addr.ID())] {
+		return
+	}
+	a.ids[string(addr.ID())] = true
+	a.addrs = append(a.addrs, addr)
+}
+
+func (a *replayAccounts) addReceipt(rct module.Receipt) {
+	a.add(rct.To())
+	a.add(rct.SCOREAddress())
+	for itr := rct.EventLogIterator(); itr.Has(); _ = itr.Next() {
+		if ev, err := itr.Get(); err == nil {
+			a.add(ev.Address())
+		}
+	}
+	for itr := rct.FeePaymentIterator(); itr.Has(); _ = itr.Next() {
+		if fp, err := itr.Get(); err == nil {
+			a.add(fp.Payer())
+		}
+	}
+}
+
+func (a *replayAccounts) addTransaction(tx module.Transaction, rcts ...module.Receipt) {
+	a.add(tx.From())
+	if txo, ok := tx.(interface{ To() module.Address }); ok {
+		a.add(txo.To())
+	}
+	for _, rct := range rcts {
+		a.addReceipt(rct)
+	}
+}
+
+func balanceOf(ass state.AccountSnapshot) *common.HexInt {
+	v := new(common.HexInt)
+	if ass != nil {
+		v.Set(ass.GetBalance())
+	}
+	return v
+}
+
+func (a *replayAccounts) diff(expWSS, actWSS state.WorldSnapshot) []*ReplayAccount {
+	accounts := make([]*ReplayAccount, 0)
+	for _, addr := range a.addrs {
+		expASS := expWSS.GetAccountSnapshot(addr.ID())
+		actASS := actWSS.GetAccountSnapshot(addr.ID())
+		if diff := state.DiffAccountSnapshots(expASS, actASS); len(diff) > 0 {
+			accounts = append(accounts, &ReplayAccount{
+				Address:         common.AddressToPtr(addr),
+				Diff:            diff,
+				ExpectedBalance: balanceOf(expASS),
+				ActualBalance:   balanceOf(actASS),
+			})
+		}
+	}
+	return accounts
+}
+
+func (t *taskReplay) Stop() {
+	select {
+	case t.cancelCh <- struct{}{}:
+	default:
+	}
+}
+
+func (t *taskReplay) Wait() error {
+	result := t.result.Wait()
+	t.chain.releaseManagers()
+	return result
+}
+
+func taskReplayFactory(c *singleChain, params json.RawMessage) (chainTask, error) {
+	p := new(ReplayParams)
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidParams")
+	}
+	if p.End == 0 {
+		p.End = p.Start
+	}
+	return &taskReplay{
+		chain:    c,
+		params:   p,
+		cancelCh: make(chan struct{}, 1),
+	}, nil
+}
+
+func init() {
+	registerTaskFactory(ReplayTask, taskReplayFactory)
+}
@@ -0,0 +1,522 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sync/atomic"
+
+	"github.com/icon-project/goloop/chain/gs"
+	"github.com/icon-project/goloop/chain/snapshot"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	SnapshotExportTask = "snapshot_export"
+	SnapshotImportTask = "snapshot_import"
+
+	SnapshotExportDone   = "snapshot export done"
+	SnapshotExportFailed = "snapshot export failed"
+	SnapshotImportDone   = "snapshot import done"
+	SnapshotImportFailed = "snapshot import failed"
+)
+
+// SnapshotExportParams is the parameter of the snapshot export task. It
+// writes the snapshot of the block at Height to Dir. If Height is zero,
+// then the last block with votes is used.
+type SnapshotExportParams struct {
+	Height    int64  `json:"height"`
+	Dir       string `json:"dir"`
+	ChunkSize int    `json:"chunkSize,omitempty"`
+}
+
+// SnapshotImportParams is the parameter of the snapshot import task. It
+// replaces the database of the chain with the snapshot in Dir.
+// BlockHash is the hash of the block of the snapshot, which should be
+// obtained from a trusted source. It's the anchor of the verification, as
+// validators checking votes for the block come from the snapshot itself.
+type SnapshotImportParams struct {
+	Dir       string          `json:"dir"`
+	BlockHash common.HexBytes `json:"blockHash"`
+}
+
+func snapshotRootsOf(c *singleChain, blk module.Block) (*snapshot.Roots, error) {
+	wss, err := service.NewWorldSnapshot(c.Database(), c.plt, blk.Result(), blk.NextValidators())
+	if err != nil {
+		return nil, err
+	}
+	prl, err := c.sm.ReceiptListFromResult(blk.Result(), module.TransactionGroupPatch)
+	if err != nil {
+		return nil, err
+	}
+	nrl, err := c.sm.ReceiptListFromResult(blk.Result(), module.TransactionGroupNormal)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot.Roots{
+		StateHash:          wss.StateHash(),
+		PatchReceiptsHash:  prl.Hash(),
+		NormalReceiptsHash: nrl.Hash(),
+		NextValidatorsHash: wss.GetValidatorSnapshot().Hash(),
+		ExtensionData:      wss.ExtensionData(),
+		BTPData:            wss.BTPData(),
+	}, nil
+}
+
+var snapshotExportStates = map[State]string{
+	Starting: "snapshot export starting",
+	Stopping: "snapshot export stopping",
+	Failed:   SnapshotExportFailed,
+	Finished: SnapshotExportDone,
+}
+
+type taskSnapshotExport struct {
+	chain     *singleChain
+	params    *SnapshotExportParams
+	result    resultStore
+	cancelled int32
+
+	reportResolved   int64
+	reportUnresolved int64
+}
+
+func (t *taskSnapshotExport) String() string {
+	return fmt.Sprintf("SnapshotExport(height=%d,dir=%s)", t.params.Height, t.params.Dir)
+}
+
+func (t *taskSnapshotExport) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("snapshot export height=%d resolved=%d unresolved=%d",
+			t.params.Height,
+			atomic.LoadInt64(&t.reportResolved),
+			atomic.LoadInt64(&t.reportUnresolved))
+	default:
+		if name, ok := snapshotExportStates[s]; ok {
+			return name
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskSnapshotExport) Start() error {
+	p := t.params
+	if len(p.Dir) == 0 {
+		return errors.IllegalArgumentError.New("NoSnapshotDir")
+	}
+	last := t.chain.lastBlockHeight()
+	if p.Height == 0 {
+		p.Height = last - 1
+	}
+	if p.Height <= 1 || p.Height >= last {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidHeight(height=%d,last=%d)", p.Height, last)
+	}
+	if err := t.chain.prepareManagers(); err != nil {
+		t.chain.releaseManagers()
+		return err
+	}
+	go func() {
+		t.result.SetValue(t._export())
+	}()
+	return nil
+}
+
+func (t *taskSnapshotExport) _reportProgress(h int64, resolved, unresolved int) error {
+	if atomic.LoadInt32(&t.cancelled) != 0 {
+		return errors.ErrInterrupted
+	}
+	atomic.StoreInt64(&t.reportResolved, int64(resolved))
+	atomic.StoreInt64(&t.reportUnresolved, int64(unresolved))
+	return nil
+}
+
+func (t *taskSnapshotExport) _export() (ret error) {
+	c := t.chain
+	p := t.params
+
+	blk, err := c.bm.GetBlockByHeight(p.Height)
+	if err != nil {
+		return err
+	}
+	nblk, err := c.bm.GetBlockByHeight(p.Height + 1)
+	if err != nil {
+		return err
+	}
+	votes := nblk.Votes()
+	roots, err := snapshotRootsOf(c, blk)
+	if err != nil {
+		return err
+	}
+
+	w, err := snapshot.NewWriter(p.Dir, c.Database(), p.ChunkSize)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		log.Must(w.Close())
+	}()
+	if err := c.bm.ExportBlocks(p.Height, p.Height, w, t._reportProgress); err != nil {
+		return err
+	}
+	bk, err := w.GetBucket(db.BytesByHash)
+	if err != nil {
+		return err
+	}
+	if err := bk.Set(votes.Hash(), votes.Bytes()); err != nil {
+		return err
+	}
+	return w.Finish(&snapshot.Manifest{
+		CID:    common.HexInt32{Value: int32(c.CID())},
+		NID:    common.HexInt32{Value: int32(c.NID())},
+		Height: blk.Height(),
+		Block:  blk.ID(),
+		Votes:  votes.Hash(),
+		Roots:  *roots,
+	})
+}
+
+func (t *taskSnapshotExport) Stop() {
+	atomic.StoreInt32(&t.cancelled, 1)
+}
+
+func (t *taskSnapshotExport) Wait() error {
+	result := t.result.Wait()
+	t.chain.releaseManagers()
+	return result
+}
+
+func taskSnapshotExportFactory(c *singleChain, params json.RawMessage) (chainTask, error) {
+	p := new(SnapshotExportParams)
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidParams")
+	}
+	return &taskSnapshotExport{
+		chain:  c,
+		params: p,
+	}, nil
+}
+
+var snapshotImportStates = map[State]string{
+	Starting: "snapshot import starting",
+	Stopping: "snapshot import stopping",
+	Failed:   SnapshotImportFailed,
+	Finished: SnapshotImportDone,
+}
+
+type taskSnapshotImport struct {
+	chain     *singleChain
+	params    *SnapshotImportParams
+	result    resultStore
+	cancelled int32
+	gsfile    string
+
+	reportChunks int64
+	reportTotal  int64
+}
+
+func (t *taskSnapshotImport) String() string {
+	return fmt.Sprintf("SnapshotImport(dir=%s)", t.params.Dir)
+}
+
+func (t *taskSnapshotImport) DetailOf(s State) string {
+	switch s {
+	case Started:
+		return fmt.Sprintf("snapshot import chunks=%d/%d",
+			atomic.LoadInt64(&t.reportChunks),
+			atomic.LoadInt64(&t.reportTotal))
+	default:
+		if name, ok := snapshotImportStates[s]; ok {
+			return name
+		} else {
+			return s.String()
+		}
+	}
+}
+
+func (t *taskSnapshotImport) Start() error {
+	p := t.params
+	if len(p.Dir) == 0 {
+		return errors.IllegalArgumentError.New("NoSnapshotDir")
+	}
+	if len(p.BlockHash) != crypto.HashLen {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidBlockHash(hash=%#x)", p.BlockHash.Bytes())
+	}
+	go func() {
+		t.result.SetValue(t._import())
+	}()
+	return nil
+}
+
+func (t *taskSnapshotImport) _reportProgress(chunks, total int) error {
+	if atomic.LoadInt32(&t.cancelled) != 0 {
+		return errors.ErrInterrupted
+	}
+	atomic.StoreInt64(&t.reportChunks, int64(chunks))
+	atomic.StoreInt64(&t.reportTotal, int64(total))
+	return nil
+}
+
+func (t *taskSnapshotImport) _checkManifest(m *snapshot.Manifest) error {
+	c := t.chain
+	if int(m.CID.Value) != c.CID() || int(m.NID.Value) != c.NID() {
+		return errors.InvalidStateError.Errorf(
+			"InvalidNetwork(cid=%#x,nid=%#x)", m.CID.Value, m.NID.Value)
+	}
+	if !bytes.Equal(t.params.BlockHash, m.Block) {
+		return errors.InvalidStateError.Errorf(
+			"InvalidBlock(exp=%#x,real=%#x)", t.params.BlockHash.Bytes(), m.Block.Bytes())
+	}
+	if m.Height <= 1 {
+		return errors.InvalidStateError.Errorf("InvalidHeight(height=%d)", m.Height)
+	}
+	return nil
+}
+
+func (t *taskSnapshotImport) _importDatabase(m *snapshot.Manifest, dbDirNew, dbTypeNew string) (ret error) {
+	_ = os.RemoveAll(dbDirNew)
+	newDB, err := t.chain.openDatabase(dbDirNew, dbTypeNew)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		log.Must(newDB.Close())
+		if ret != nil {
+			log.Must(os.RemoveAll(dbDirNew))
+		}
+	}()
+	return m.Import(t.params.Dir, newDB, t._reportProgress)
+}
+
+// _replaceDatabase replaces the database of the chain with the snapshot.
+// It also removes directories depending on the previous database.
+func (t *taskSnapshotImport) _replaceDatabase(m *snapshot.Manifest) (rrb Revertible, ret error) {
+	var rb Revertible
+	defer func() {
+		if ret != nil {
+			rb.RevertOrCommit(true)
+		} else {
+			rrb = rb
+		}
+	}()
+
+	c := t.chain
+	chainDir := c.cfg.AbsBaseDir()
+	dbDir := path.Join(chainDir, DefaultDBDir)
+	dbDirNew := dbDir + TempSuffix
+	if ret = t._importDatabase(m, dbDirNew, c.cfg.DBType); ret != nil {
+		return
+	}
+	rb.Append(func(revert bool) {
+		if revert {
+			log.Must(os.RemoveAll(dbDirNew))
+		}
+	})
+
+	c.releaseDatabase()
+	rb.Append(func(revert bool) {
+		if revert {
+			c.ensureDatabase()
+		}
+	})
+	if ret = rb.Delete(dbDir); ret != nil {
+		return
+	}
+	if ret = rb.Rename(dbDirNew, dbDir); ret != nil {
+		return
+	}
+	c.ensureDatabase()
+	rb.Append(func(revert bool) {
+		if revert {
+			c.releaseDatabase()
+		}
+	})
+
+	for _, dir := range []string{DefaultContractDir, DefaultWALDir, DefaultCacheDir} {
+		if ret = rb.Delete(path.Join(chainDir, dir)); ret != nil {
+			return
+		}
+	}
+	return
+}
+
+// _verify checks the imported block with votes in the snapshot and
+// the roots in the manifest with the result of the block. Then it visits
+// all data reachable from the roots to make sure that nothing is missing.
+// Validators for the votes come from the snapshot, so it relies on the
+// block hash given by the user, which is checked in _checkManifest.
+func (t *taskSnapshotImport) _verify(m *snapshot.Manifest) (module.Block, module.CommitVoteSet, error) {
+	c := t.chain
+	blk, err := c.bm.GetBlockByHeight(m.Height)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(blk.ID(), m.Block) {
+		return nil, nil, errors.InvalidStateError.Errorf(
+			"InvalidBlockID(exp=%#x,real=%#x)", m.Block.Bytes(), blk.ID())
+	}
+	bk, err := c.Database().GetBucket(db.BytesByHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	votesBytes, err := bk.Get(m.Votes)
+	if err != nil || votesBytes == nil {
+		return nil, nil, errors.NotFoundError.Wrapf(err, "NoVotes(hash=%#x)", m.Votes.Bytes())
+	}
+	votes := c.CommitVoteSetDecoder()(votesBytes)
+	pblk, err := c.bm.GetBlockByHeight(m.Height - 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	vl, err := state.ValidatorSnapshotFromHash(c.Database(), pblk.NextValidatorsHash())
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := votes.VerifyBlock(blk, vl); err != nil {
+		return nil, nil, err
+	}
+
+	roots, err := snapshotRootsOf(c, blk)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(roots.StateHash, m.Roots.StateHash) ||
+		!bytes.Equal(roots.PatchReceiptsHash, m.Roots.PatchReceiptsHash) ||
+		!bytes.Equal(roots.NormalReceiptsHash, m.Roots.NormalReceiptsHash) ||
+		!bytes.Equal(roots.NextValidatorsHash, m.Roots.NextValidatorsHash) ||
+		!bytes.Equal(roots.ExtensionData, m.Roots.ExtensionData) ||
+		!bytes.Equal(roots.BTPData, m.Roots.BTPData) {
+		return nil, nil, errors.InvalidStateError.Errorf(
+			"InvalidRoots(exp=%+v,real=%+v)", m.Roots, *roots)
+	}
+
+	tracker := snapshot.NewTracker(c.Database())
+	defer func() {
+		log.Must(tracker.Close())
+	}()
+	if err := c.bm.ExportBlocks(m.Height, m.Height, tracker, nil); err != nil {
+		return nil, nil, errors.InvalidStateError.Wrap(err, "IncompleteSnapshot")
+	}
+	return blk, votes, nil
+}
+
+func (t *taskSnapshotImport) _exportGenesis(m *snapshot.Manifest) (rerr error) {
+	c := t.chain
+	if err := c.prepareManagers(); err != nil {
+		return err
+	}
+	defer c.releaseManagers()
+
+	blk, votes, err := t._verify(m)
+	if err != nil {
+		return err
+	}
+	fd, err := os.OpenFile(t.gsfile, os.O_CREATE|os.O_WRONLY|os.O_EXCL|os.O_TRUNC, 0700)
+	if err != nil {
+		return err
+	}
+	gsw := gs.NewGenesisStorageWriter(fd)
+	defer func() {
+		_ = gsw.Close()
+		_ = fd.Close()
+		if rerr != nil {
+			_ = os.Remove(t.gsfile)
+		}
+	}()
+	if err := c.bm.ExportGenesis(blk, votes, gsw); err != nil {
+		return errors.Wrap(err, "fail on exporting genesis storage")
+	}
+	return nil
+}
+
+func (t *taskSnapshotImport) _import() (ret error) {
+	m, err := snapshot.ReadManifest(t.params.Dir)
+	if err != nil {
+		return err
+	}
+	if err := t._checkManifest(m); err != nil {
+		return err
+	}
+
+	rb, err := t._replaceDatabase(m)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		rb.RevertOrCommit(ret != nil)
+	}()
+
+	if err := rb.Delete(t.gsfile); err != nil {
+		return err
+	}
+	if err := t._exportGenesis(m); err != nil {
+		return err
+	}
+	rb.Append(func(revert bool) {
+		if revert {
+			_ = os.Remove(t.gsfile)
+		}
+	})
+
+	g, err := loadGenesisStorage(t.gsfile)
+	if err != nil {
+		return err
+	}
+	c := t.chain
+	c.cfg.GenesisStorage = g
+	c.cfg.Genesis = g.Genesis()
+	return c.cfg.Save()
+}
+
+func (t *taskSnapshotImport) Stop() {
+	atomic.StoreInt32(&t.cancelled, 1)
+}
+
+func (t *taskSnapshotImport) Wait() error {
+	return t.result.Wait()
+}
+
+func taskSnapshotImportFactory(c *singleChain, params json.RawMessage) (chainTask, error) {
+	p := new(SnapshotImportParams)
+	if err := json.Unmarshal(params, p); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidParams")
+	}
+	const chainGenesisZipFileName = "genesis.zip"
+	return &taskSnapshotImport{
+		chain:  c,
+		params: p,
+		gsfile: path.Join(c.cfg.AbsBaseDir(), chainGenesisZipFileName),
+	}, nil
+}
+
+func init() {
+	registerTaskFactory(SnapshotExportTask, taskSnapshotExportFactory)
+	registerTaskFactory(SnapshotImportTask, taskSnapshotImportFactory)
+}
@@ -15,10 +15,12 @@ import (
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server"
 	"github.com/icon-project/goloop/server/jsonrpc"
 	v3 "github.com/icon-project/goloop/server/v3"
+	"github.com/icon-project/goloop/service/state"
 	"github.com/icon-project/goloop/service/transaction"
 )
 
@@ -179,6 +181,23 @@ type BTPSourceInformation struct {
 	NetworkTypeIDs []jsonrpc.HexInt `json:"networkTypeIDs"`
 }
 
+//refer icon/iiss/rewarddetail.go RewardDetail.ToJSON
+type IScoreDetail struct {
+	Type   string          `json:"type"`
+	PRep   jsonrpc.Address `json:"prep,omitempty"`
+	IScore jsonrpc.HexInt  `json:"iscore"`
+}
+
+//refer icon/chainscore_iiss.go Ex_getIScoreDetails
+type IScoreDetails struct {
+	Address          jsonrpc.Address `json:"address"`
+	StartBlockHeight jsonrpc.HexInt  `json:"startBlockHeight"`
+	EndBlockHeight   jsonrpc.HexInt  `json:"endBlockHeight"`
+	Sequence         jsonrpc.HexInt  `json:"sequence,omitempty"`
+	IScore           jsonrpc.HexInt  `json:"iscore"`
+	Rewards          []IScoreDetail  `json:"rewards"`
+}
+
 func (c *ClientV3) GetLastBlock() (*Block, error) {
 	blk := &Block{}
 	_, err := c.Do("icx_getLastBlock", nil, blk)
@@ -215,6 +234,31 @@ func (c *ClientV3) Call(param *v3.CallParam) (interface{}, error) {
 	return result, nil
 }
 
+// GetIScoreDetails returns the breakdown of I-Score rewarded to the address
+// in the term starting at startHeight, or in the latest calculated term
+// if startHeight is not positive. It works only on ICON networks.
+func (c *ClientV3) GetIScoreDetails(address module.Address, startHeight int64) (*IScoreDetails, error) {
+	params := map[string]interface{}{
+		"address": address.String(),
+	}
+	if startHeight > 0 {
+		params["startBlockHeight"] = jsonrpc.HexInt(intconv.FormatInt(startHeight))
+	}
+	param := &v3.CallParam{
+		ToAddress: jsonrpc.Address(state.SystemAddress.String()),
+		DataType:  "call",
+		Data: map[string]interface{}{
+			"method": "getIScoreDetails",
+			"params": params,
+		},
+	}
+	var result IScoreDetails
+	if _, err := c.Do("icx_call", param, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 func (c *ClientV3) GetBalance(param *v3.AddressParam) (*jsonrpc.HexInt, error) {
 	var result jsonrpc.HexInt
 	_, err := c.Do("icx_getBalance", param, &result)
@@ -438,6 +482,15 @@ func (c *ClientV3) GetScoreStatus(param *v3.ScoreAddressParam) (interface{}, err
 	return result, nil
 }
 
+func (c *ClientV3) GetFeeSharingUsage(param *v3.FeeSharingUsageParam) (interface{}, error) {
+	var result interface{}
+	_, err := c.Do("icx_getFeeSharingUsage", param, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 func (c *ClientV3) GetNetworkInfo() (*NetworkInfo, error) {
 	var result *NetworkInfo
 	_, err := c.Do("icx_getNetworkInfo", nil, &result)
@@ -457,6 +510,9 @@ func (c *ClientV3) MonitorBlock(param *server.BlockRequest, cb func(v *server.Bl
 }
 
 func (c *ClientV3) MonitorEvent(param *server.EventRequest, cb func(v *server.EventNotification), cancelCh <-chan bool) error {
+	if _, err := param.Compile(); err != nil {
+		return err
+	}
 	resp := &server.EventNotification{}
 	return c.Monitor("/event", param, resp, func(v interface{}) {
 		if en, ok := v.(*server.EventNotification); ok {
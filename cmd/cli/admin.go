@@ -126,6 +126,7 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 			param.DBType, _ = fs.GetString("db_type")
 			param.Platform, _ = fs.GetString("platform")
 			param.ConcurrencyLevel, _ = fs.GetInt("concurrency")
+			param.OptimisticExec, _ = fs.GetBool("optimistic_execution")
 			param.NormalTxPoolSize, _ = fs.GetInt("normal_tx_pool")
 			param.PatchTxPoolSize, _ = fs.GetInt("patch_tx_pool")
 			param.MaxBlockTxBytes, _ = fs.GetInt("max_block_tx_bytes")
@@ -146,6 +147,9 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 				param.NephewsLimit = &nephewsLimit
 			}
 			param.ValidateTxOnSend, _ = fs.GetBool("validate_tx_on_send")
+			param.BandwidthLimit, _ = fs.GetString("bandwidth_limit")
+			param.PrivatePeers, _ = fs.GetString("private_peers")
+			param.DisableDiscovery, _ = fs.GetBool("disable_discovery")
 
 			var buf *bytes.Buffer
 			if len(genesisZip) > 0 {
@@ -187,6 +191,7 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	joinFlags.String("db_type", "goleveldb", "Name of database system("+strings.Join(db.RegisteredBackendTypes(), ", ")+")")
 	joinFlags.String("platform", "", "Name of service platform")
 	joinFlags.Int("concurrency", 1, "Maximum number of executors to be used for concurrency")
+	joinFlags.Bool("optimistic_execution", false, "Execute transactions optimistically with conflict detection (requires concurrency > 1)")
 	joinFlags.Int("normal_tx_pool", 0, "Size of normal transaction pool")
 	joinFlags.Int("patch_tx_pool", 0, "Size of patch transaction pool")
 	joinFlags.Int("max_block_tx_bytes", 0, "Max size of transactions in a block")
@@ -203,6 +208,11 @@ func NewChainCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	joinFlags.Int("children_limit", -1, "Maximum number of child connections (-1: uses system default value)")
 	joinFlags.Int("nephews_limit", -1, "Maximum number of nephew connections (-1: uses system default value)")
 	joinFlags.Bool("validate_tx_on_send", false, "Validate transaction on send")
+	joinFlags.String("bandwidth_limit", "",
+		"Bandwidth limits in bytes/s for peers and protocols (ex: peer=1m/2m,statesync=512k) - Comma separated string")
+	joinFlags.String("private_peers", "",
+		"Private peers which are always connected and never gossiped ([<peer id>@]<ip>:<port>) - Comma separated string")
+	joinFlags.Bool("disable_discovery", false, "Disable peer discovery, connect to private peers only")
 
 	leaveCmd := &cobra.Command{
 		Use:   "leave CID",
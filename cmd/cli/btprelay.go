@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/icon-project/goloop/btp/relay"
+	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+)
+
+// newRelaySink returns a sink for the specification.
+// "-" for stdout, URL starting with "http://" or "https://" for HTTP POST,
+// and otherwise the path of the file to append.
+func newRelaySink(spec string) (relay.Sink, io.Closer, error) {
+	switch {
+	case spec == "" || spec == "-":
+		return relay.NewJSONSink(os.Stdout), nil, nil
+	case strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://"):
+		return relay.NewHTTPSink(spec), nil, nil
+	default:
+		f, err := os.OpenFile(spec, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return relay.NewJSONSink(f), f, nil
+	}
+}
+
+func newBTPRelayCmd(rpcClient *client.ClientV3) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "btprelay NETWORK_ID [HEIGHT]",
+		Short: "Relay verified BTP messages of the network",
+		Args:  ArgsWithDefaultErrorFunc(cobra.RangeArgs(1, 2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			nid, err := intconv.ParseInt(args[0], 64)
+			if err != nil {
+				return err
+			}
+			var height int64
+			if len(args) > 1 {
+				if height, err = intconv.ParseInt(args[1], 64); err != nil {
+					return err
+				}
+			}
+			sink, closer, err := newRelaySink(cmd.Flag("sink").Value.String())
+			if err != nil {
+				return err
+			}
+			if closer != nil {
+				defer closer.Close()
+			}
+			cancelCh := make(chan bool)
+			OnInterrupt(func() {
+				close(cancelCh)
+			})
+			r := relay.New(rpcClient, nid, sink, nil)
+			fs := cmd.Flags()
+			if trust, _ := fs.GetString("trust"); trust != "" {
+				b, err := hex.DecodeString(strings.TrimPrefix(trust, "0x"))
+				if err != nil {
+					return errors.IllegalArgumentError.Wrap(err, "invalid trust")
+				}
+				r.SetTrust(b)
+			}
+			if pc, _ := fs.GetString("trust_context"); pc != "" {
+				b, err := hex.DecodeString(strings.TrimPrefix(pc, "0x"))
+				if err != nil {
+					return errors.IllegalArgumentError.Wrap(err, "invalid trust_context")
+				}
+				r.SetTrustedProofContext(b)
+			}
+			insecure, _ := fs.GetBool("insecure")
+			r.SetInsecure(insecure)
+			return r.Run(height, cancelCh)
+		},
+	}
+	cmd.Flags().String("sink", "-",
+		"Destination of verified batches (\"-\" for stdout, http(s) URL or file path)")
+	cmd.Flags().String("trust", "",
+		"Hash of the trusted proof context for the first header")
+	cmd.Flags().String("trust_context", "",
+		"Trusted proof context for the first header in hex")
+	cmd.Flags().Bool("insecure", false,
+		"Start without a trusted proof context")
+	cmd.MarkFlagsMutuallyExclusive("trust", "trust_context", "insecure")
+	return cmd
+}
@@ -17,6 +17,8 @@
 package cli
 
 import (
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"os"
 
@@ -24,6 +26,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/icon-project/goloop/client"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/server/jsonrpc"
 	v3 "github.com/icon-project/goloop/server/v3"
 )
@@ -68,5 +71,112 @@ func NewDebugCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Comman
 	}
 	rootCmd.AddCommand(traceCmd)
 
+	stateDiffCmd := &cobra.Command{
+		Use:   "statediff FROM TO",
+		Short: "Get changes of the world state between two block heights",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &v3.StateDiffParam{}
+			fs := cmd.Flags()
+			if byState, _ := fs.GetBool("state"); byState {
+				param.FromState = jsonrpc.HexBytes(args[0])
+				param.ToState = jsonrpc.HexBytes(args[1])
+				fromExt, _ := fs.GetString("from_extension")
+				param.FromExtension = jsonrpc.HexBytes(fromExt)
+				toExt, _ := fs.GetString("to_extension")
+				param.ToExtension = jsonrpc.HexBytes(toExt)
+			} else {
+				from, err := intconv.ParseInt(args[0], 64)
+				if err != nil {
+					return err
+				}
+				to, err := intconv.ParseInt(args[1], 64)
+				if err != nil {
+					return err
+				}
+				param.From = jsonrpc.HexInt(intconv.FormatInt(from))
+				param.To = jsonrpc.HexInt(intconv.FormatInt(to))
+			}
+			if limit, _ := fs.GetInt("limit"); limit > 0 {
+				param.Limit = jsonrpc.HexInt(intconv.FormatInt(int64(limit)))
+			}
+			addrs, _ := fs.GetStringArray("address")
+			for _, addr := range addrs {
+				param.Addresses = append(param.Addresses, jsonrpc.Address(addr))
+			}
+			diff, err := debugClient.Do("debug_getStateDiff", param, nil)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, diff.Result)
+		},
+	}
+	rootCmd.AddCommand(stateDiffCmd)
+	stateDiffFlags := stateDiffCmd.Flags()
+	stateDiffFlags.Bool("state", false, "Use state hashes instead of block heights for FROM and TO")
+	stateDiffFlags.Int("limit", 0, "Maximum number of changed entries to report (0 for no limit)")
+	stateDiffFlags.StringArray("address", nil, "Address to resolve keys of changed accounts")
+	stateDiffFlags.String("from_extension", "", "Extension data for FROM state hash")
+	stateDiffFlags.String("to_extension", "", "Extension data for TO state hash")
+
+	storageCmd := &cobra.Command{
+		Use:   "storage ADDRESS",
+		Short: "Get entries of the storage of the contract",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &v3.ScoreStorageParam{
+				Address: jsonrpc.Address(args[0]),
+			}
+			fs := cmd.Flags()
+			if height, _ := fs.GetInt64("height"); height >= 0 {
+				param.Height = jsonrpc.HexInt(intconv.FormatInt(height))
+			}
+			if start, _ := fs.GetString("start"); start != "" {
+				param.Start = jsonrpc.HexBytes(start)
+			}
+			if limit, _ := fs.GetInt("limit"); limit > 0 {
+				param.Limit = jsonrpc.HexInt(intconv.FormatInt(int64(limit)))
+			}
+			if file, _ := fs.GetString("containers"); file != "" {
+				bs, err := ioutil.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				if err := json.Unmarshal(bs, &param.Containers); err != nil {
+					return err
+				}
+			}
+			all, _ := fs.GetBool("all")
+			var entries []interface{}
+			for {
+				res := &struct {
+					Entries []interface{}    `json:"entries"`
+					Next    jsonrpc.HexBytes `json:"next,omitempty"`
+				}{}
+				if _, err := debugClient.Do("debug_getScoreStorage", param, res); err != nil {
+					return err
+				}
+				if !all {
+					return JsonPrettyPrintln(os.Stdout, res)
+				}
+				entries = append(entries, res.Entries...)
+				if res.Next == "" {
+					break
+				}
+				param.Start = res.Next
+			}
+			return JsonPrettyPrintln(os.Stdout, map[string]interface{}{
+				"entries": entries,
+			})
+		},
+	}
+	rootCmd.AddCommand(storageCmd)
+	storageFlags := storageCmd.Flags()
+	storageFlags.Int64("height", -1, "Height of the block for the state (latest if negative)")
+	storageFlags.String("start", "", "Key to start with")
+	storageFlags.Int("limit", 0, "Maximum number of entries in a page (0 for default)")
+	storageFlags.String("containers", "", "JSON file describing containers of the contract for decoding keys")
+	storageFlags.Bool("all", false, "Get all entries following pages")
+
 	return rootCmd, vc
 }
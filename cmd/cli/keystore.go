@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/icon-project/goloop/btp/ntm"
 	"github.com/icon-project/goloop/common/wallet"
 )
 
@@ -129,6 +130,7 @@ func publickeyFromKeyStore(c string) *cobra.Command {
 	keystorePath := flags.StringP("keystore", "k", "keystore.json", "Keystore file path")
 	secret := flags.StringP("secret", "s", "", "KeySecret file path")
 	pass := flags.StringP("password", "p", "gochain", "Password for the keystore")
+	dsa := flags.String("dsa", "", "DSA of the BTP public key derived from the keystore (ex: bls/bls12-381)")
 	cmd.Run = func(cmd *cobra.Command, args []string) {
 		var pb []byte
 		if kb, err := ioutil.ReadFile(*keystorePath); err != nil {
@@ -146,7 +148,19 @@ func publickeyFromKeyStore(c string) *cobra.Command {
 			if err != nil {
 				log.Panicf("Fail to decrypt KeyStore err=%+v", err)
 			}
-			fmt.Println("0x" + hex.EncodeToString(w.PublicKey()))
+			if *dsa == "" {
+				fmt.Println("0x" + hex.EncodeToString(w.PublicKey()))
+				return
+			}
+			dw := ntm.NewDSAWallet(*dsa, w)
+			if dw == nil {
+				log.Panicf("Fail to derive wallet for dsa=%s", *dsa)
+			}
+			pk, err := ntm.PublicKeyForRegistration(dw)
+			if err != nil {
+				log.Panicf("Fail to get public key err=%+v", err)
+			}
+			fmt.Println("0x" + hex.EncodeToString(pk))
 		}
 	}
 	return cmd
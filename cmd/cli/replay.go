@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/icon-project/goloop/chain"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/node"
+)
+
+func NewReplayCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command, *viper.Viper) {
+	var adminClient node.UnixDomainSockHttpClient
+	rootCmd, vc := NewCommand(parentCmd, parentVc, "replay CID START [END]",
+		"Replay blocks of the stopped chain and compare results with stored ones")
+	rootCmd.PersistentPreRunE = AdminPersistentPreRunE(vc, &adminClient)
+	AddAdminRequiredFlags(rootCmd)
+	BindPFlags(vc, rootCmd.PersistentFlags())
+	rootCmd.Args = ArgsWithDefaultErrorFunc(cobra.RangeArgs(2, 3))
+	rootCmd.RunE = func(cmd *cobra.Command, args []string) error {
+		param := &chain.ReplayParams{}
+		var err error
+		if param.Start, err = intconv.ParseInt(args[1], 64); err != nil {
+			return err
+		}
+		param.End = param.Start
+		if len(args) > 2 {
+			if param.End, err = intconv.ParseInt(args[2], 64); err != nil {
+				return err
+			}
+		}
+		fs := cmd.Flags()
+		if report, _ := fs.GetString("report"); report != "" {
+			if param.Report, err = filepath.Abs(report); err != nil {
+				return err
+			}
+		}
+
+		var v string
+		reqUrl := node.UrlChain + "/" + args[0] + "/" + chain.ReplayTask
+		if _, err = adminClient.PostWithJson(reqUrl, param, &v); err != nil {
+			return err
+		}
+		if wait, _ := fs.GetBool("wait"); !wait {
+			fmt.Println(v)
+			return nil
+		}
+
+		var state string
+		for {
+			cv := new(node.ChainView)
+			if _, err = adminClient.Get(node.UrlChain+"/"+args[0], cv); err != nil {
+				return err
+			}
+			if cv.State != state {
+				state = cv.State
+				fmt.Fprintln(os.Stderr, state)
+			}
+			if state == chain.ReplayDone || state == chain.ReplayFailed ||
+				!strings.HasPrefix(state, "replay") {
+				if param.Report != "" {
+					if bs, err := ioutil.ReadFile(param.Report); err == nil {
+						fmt.Println(string(bs))
+					}
+				}
+				if state != chain.ReplayDone {
+					return fmt.Errorf("replay isn't completed state=%s err=%s",
+						state, cv.LastError)
+				}
+				return nil
+			}
+			time.Sleep(time.Second)
+		}
+	}
+	flags := rootCmd.Flags()
+	flags.String("report", "", "File path to write the report of the replay")
+	flags.Bool("wait", true, "Wait until the replay is done")
+	return rootCmd, vc
+}
@@ -125,6 +125,7 @@ func NewRpcCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command,
 
 	NewSendTxCmd(rootCmd, vc)
 	NewMonitorCmd(rootCmd, vc)
+	rootCmd.AddCommand(newBTPRelayCmd(&rpcClient))
 
 	rootCmd.AddCommand(
 		&cobra.Command{
@@ -479,6 +480,35 @@ func NewRpcCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command,
 	flags = scoreStatusCmd.Flags()
 	flags.Int("height", -1, "BlockHeight")
 
+	feeUsageCmd := &cobra.Command{
+		Use:   "feeusage ADDRESS",
+		Short: "Get steps paid by the smart contract for fee sharing",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &v3.FeeSharingUsageParam{Address: jsonrpc.Address(args[0])}
+			fs := cmd.Flags()
+			if from, _ := fs.GetInt64("from"); from != -1 {
+				param.From = jsonrpc.HexInt(intconv.FormatInt(from))
+			}
+			if to, _ := fs.GetInt64("to"); to != -1 {
+				param.To = jsonrpc.HexInt(intconv.FormatInt(to))
+			}
+			if limit, _ := fs.GetInt64("limit"); limit != -1 {
+				param.Limit = jsonrpc.HexInt(intconv.FormatInt(limit))
+			}
+			usage, err := rpcClient.GetFeeSharingUsage(param)
+			if err != nil {
+				return err
+			}
+			return JsonPrettyPrintln(os.Stdout, usage)
+		},
+	}
+	rootCmd.AddCommand(feeUsageCmd)
+	flags = feeUsageCmd.Flags()
+	flags.Int64("from", -1, "Start BlockHeight")
+	flags.Int64("to", -1, "End BlockHeight")
+	flags.Int64("limit", -1, "Max number of top callers")
+
 	networkInfoCmd := &cobra.Command{
 		Use: "networkinfo",
 		Short: "Get network info of the endpoint",
@@ -1110,6 +1140,15 @@ func NewMonitorCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comma
 			} else if includeLogs {
 				param.Logs = common.HexBool{Value: includeLogs}
 			}
+			if full, _ := cmd.Flags().GetBool("full"); full {
+				param.Full = common.HexBool{Value: full}
+			}
+			if bc, _ := cmd.Flags().GetBool("balance_changes"); bc {
+				if !param.Full.Value {
+					return fmt.Errorf("balance_changes requires full")
+				}
+				param.BalanceChanges = common.HexBool{Value: bc}
+			}
 
 			fs, err := cmd.Flags().GetStringArray("filter")
 			if err != nil {
@@ -1146,6 +1185,8 @@ func NewMonitorCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comma
 	monitorBlockFlags.StringArray("filter", nil,
 		"EventFilter raw json file or json string")
 	monitorBlockFlags.Bool("logs", false, "Includes logs")
+	monitorBlockFlags.Bool("full", false, "Includes the block with transactions and their results")
+	monitorBlockFlags.Bool("balance_changes", false, "Includes balance changes of the block (requires full)")
 
 	monitorEventCmd := &cobra.Command{
 		Use:   "event HEIGHT",
@@ -1193,6 +1234,28 @@ func NewMonitorCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comma
 			if addr := cmd.Flag("addr").Value.String(); addr != "" {
 				param.Addr = common.MustNewAddressFromString(addr)
 			}
+			if addrs, err := cmd.Flags().GetStringSlice("addrs"); err == nil && len(addrs) > 0 {
+				param.Addrs = make([]*common.Address, len(addrs))
+				for i, v := range addrs {
+					param.Addrs[i] = common.MustNewAddressFromString(v)
+				}
+			}
+			if sets, err := cmd.Flags().GetStringArray("indexed_set"); err == nil && len(sets) > 0 {
+				for _, set := range sets {
+					kv := strings.SplitN(set, "=", 2)
+					if len(kv) != 2 {
+						return fmt.Errorf("invalid indexed_set %s", set)
+					}
+					pos, err := intconv.ParseInt(kv[0], 32)
+					if err != nil || pos < 0 {
+						return fmt.Errorf("invalid position of indexed_set %s", set)
+					}
+					for int64(len(param.IndexedSets)) <= pos {
+						param.IndexedSets = append(param.IndexedSets, nil)
+					}
+					param.IndexedSets[pos] = strings.Split(kv[1], ",")
+				}
+			}
 			if evtIndexed, err := cmd.Flags().GetStringSlice("indexed"); err == nil && len(evtIndexed) > 0 {
 				param.Indexed = make([]*string, len(evtIndexed))
 				for i, v := range evtIndexed {
@@ -1219,7 +1282,9 @@ func NewMonitorCmd(parentCmd *cobra.Command, parentVc *viper.Viper) *cobra.Comma
 	rootCmd.AddCommand(monitorEventCmd)
 	monitorEventFlags := monitorEventCmd.Flags()
 	monitorEventFlags.String("addr", "", "SCORE Address")
-	monitorEventFlags.String("event", "", "Signature of Event")
+	monitorEventFlags.StringSlice("addrs", nil, "SCORE Addresses, comma-separated string")
+	monitorEventFlags.String("event", "", "Signature of Event, or prefix of the event name followed by '*'")
+	monitorEventFlags.StringArray("indexed_set", nil, "Values of the indexed argument to match any of them, POSITION=VALUE1,VALUE2,...")
 	monitorEventFlags.StringSlice("indexed", nil, "Indexed Arguments of Event, comma-separated string")
 	monitorEventFlags.StringSlice("data", nil, "Not indexed Arguments of Event, comma-separated string")
 	monitorEventFlags.String("raw", "", "EventFilter raw json file or json-string")
@@ -16,6 +16,7 @@ import (
 	"github.com/icon-project/goloop/common/wallet"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/node"
+	"github.com/icon-project/goloop/server"
 )
 
 type ServerConfig struct {
@@ -138,8 +139,12 @@ func NewServerCmd(parentCmd *cobra.Command, parentVc *viper.Viper, version, buil
 	rootPFlags := rootCmd.PersistentFlags()
 	rootPFlags.String("p2p", "127.0.0.1:8080", "Advertise ip-port of P2P")
 	rootPFlags.String("p2p_listen", "", "Listen ip-port of P2P")
+	rootPFlags.String("p2p_nat", "", "NAT traversal of P2P (none,auto,any,upnp,pmp[:<gateway>],extip:<ip>)")
 	rootPFlags.String("rpc_addr", ":9080", "Listen ip-port of JSON-RPC")
 	rootPFlags.Bool("rpc_dump", false, "JSON-RPC Request, Response Dump flag")
+	rootPFlags.String("grpc_addr", "", "Listen ip-port of gRPC streams (disabled if empty)")
+	rootPFlags.Int("grpc_max_conns", server.DefaultGRPCMaxConnections, "Maximum number of gRPC connections (0 for no limit)")
+	rootPFlags.Int("grpc_max_streams", server.DefaultGRPCMaxStreams, "Maximum number of gRPC streams for a connection (0 for no limit)")
 	rootPFlags.String("ee_socket", "", "Execution engine socket path")
 	rootPFlags.String("key_password", "", "Password for the KeyStore file")
 	rootPFlags.String("log_level", "debug", "Global log level (trace,debug,info,warn,error,fatal,panic)")
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/icon-project/goloop/chain"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/node"
+)
+
+func waitChainTask(adminClient *node.UnixDomainSockHttpClient, cid, prefix, done, failed string) error {
+	var state string
+	for {
+		cv := new(node.ChainView)
+		if _, err := adminClient.Get(node.UrlChain+"/"+cid, cv); err != nil {
+			return err
+		}
+		if cv.State != state {
+			state = cv.State
+			fmt.Fprintln(os.Stderr, state)
+		}
+		if state == done {
+			return nil
+		}
+		if state == failed || !strings.HasPrefix(state, prefix) {
+			return fmt.Errorf("%s isn't completed state=%s err=%s",
+				prefix, state, cv.LastError)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func NewSnapshotCmd(parentCmd *cobra.Command, parentVc *viper.Viper) (*cobra.Command, *viper.Viper) {
+	var adminClient node.UnixDomainSockHttpClient
+	rootCmd, vc := NewCommand(parentCmd, parentVc, "snapshot",
+		"Export or import portable state snapshots of the stopped chain")
+	rootCmd.PersistentPreRunE = AdminPersistentPreRunE(vc, &adminClient)
+	AddAdminRequiredFlags(rootCmd)
+	BindPFlags(vc, rootCmd.PersistentFlags())
+
+	exportCmd := &cobra.Command{
+		Use:   "export CID DIR [HEIGHT]",
+		Short: "Export the snapshot of the block at the height to the directory",
+		Args:  ArgsWithDefaultErrorFunc(cobra.RangeArgs(2, 3)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &chain.SnapshotExportParams{}
+			var err error
+			if param.Dir, err = filepath.Abs(args[1]); err != nil {
+				return err
+			}
+			if len(args) > 2 {
+				if param.Height, err = intconv.ParseInt(args[2], 64); err != nil {
+					return err
+				}
+			}
+			fs := cmd.Flags()
+			param.ChunkSize, _ = fs.GetInt("chunk_size")
+
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/" + chain.SnapshotExportTask
+			if _, err = adminClient.PostWithJson(reqUrl, param, &v); err != nil {
+				return err
+			}
+			if wait, _ := fs.GetBool("wait"); !wait {
+				fmt.Println(v)
+				return nil
+			}
+			return waitChainTask(&adminClient, args[0], "snapshot export",
+				chain.SnapshotExportDone, chain.SnapshotExportFailed)
+		},
+	}
+	rootCmd.AddCommand(exportCmd)
+	exportFlags := exportCmd.Flags()
+	exportFlags.Int("chunk_size", 0, "Maximum size of a chunk in bytes (default 16MiB)")
+	exportFlags.Bool("wait", true, "Wait until the export is done")
+
+	importCmd := &cobra.Command{
+		Use:   "import CID DIR",
+		Short: "Replace the database of the chain with the snapshot in the directory",
+		Args:  ArgsWithDefaultErrorFunc(cobra.ExactArgs(2)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			param := &chain.SnapshotImportParams{}
+			var err error
+			if param.Dir, err = filepath.Abs(args[1]); err != nil {
+				return err
+			}
+			fs := cmd.Flags()
+			bh, _ := fs.GetString("block_hash")
+			if len(bh) >= 2 && bh[:2] == "0x" {
+				bh = bh[2:]
+			}
+			if param.BlockHash, err = hex.DecodeString(bh); err != nil {
+				return err
+			}
+
+			var v string
+			reqUrl := node.UrlChain + "/" + args[0] + "/" + chain.SnapshotImportTask
+			if _, err = adminClient.PostWithJson(reqUrl, param, &v); err != nil {
+				return err
+			}
+			if wait, _ := fs.GetBool("wait"); !wait {
+				fmt.Println(v)
+				return nil
+			}
+			return waitChainTask(&adminClient, args[0], "snapshot import",
+				chain.SnapshotImportDone, chain.SnapshotImportFailed)
+		},
+	}
+	rootCmd.AddCommand(importCmd)
+	importFlags := importCmd.Flags()
+	importFlags.String("block_hash", "", "Hash of the block of the snapshot from a trusted source")
+	importFlags.Bool("wait", true, "Wait until the import is done")
+	MarkAnnotationRequired(importFlags, "block_hash")
+	return rootCmd, vc
+}
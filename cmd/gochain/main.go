@@ -34,17 +34,22 @@ const (
 
 type GoChainConfig struct {
 	chain.Config
-	P2PAddr       string `json:"p2p"`
-	P2PListenAddr string `json:"p2p_listen"`
-	EESocket      string `json:"ee_socket"`
-	RPCAddr       string `json:"rpc_addr"`
-	RPCDump       bool   `json:"rpc_dump"`
-	RPCDebug      bool   `json:"rpc_debug"`
-	RPCRosetta    bool   `json:"rpc_rosetta"`
-	RPCBatchLimit int    `json:"rpc_batch_limit,omitempty"`
-	EEInstances   int    `json:"ee_instances"`
-	Engines       string `json:"engines"`
-	WSMaxSession  int    `json:"ws_max_session"`
+	P2PAddr        string `json:"p2p"`
+	P2PListenAddr  string `json:"p2p_listen"`
+	P2PNAT         string `json:"p2p_nat,omitempty"`
+	EESocket       string `json:"ee_socket"`
+	RPCAddr        string `json:"rpc_addr"`
+	RPCDump        bool   `json:"rpc_dump"`
+	RPCDebug       bool   `json:"rpc_debug"`
+	RPCRosetta     bool   `json:"rpc_rosetta"`
+	RPCBatchLimit  int    `json:"rpc_batch_limit,omitempty"`
+	EEInstances    int    `json:"ee_instances"`
+	Engines        string `json:"engines"`
+	WSMaxSession   int    `json:"ws_max_session"`
+	RPCCacheSize   int    `json:"rpc_cache_size"`
+	GRPCAddr       string `json:"grpc_addr,omitempty"`
+	GRPCMaxConns   int    `json:"grpc_max_conns,omitempty"`
+	GRPCMaxStreams int    `json:"grpc_max_streams,omitempty"`
 
 	Key          []byte          `json:"key,omitempty"`
 	KeyStoreData json.RawMessage `json:"key_store"`
@@ -112,12 +117,16 @@ func main() {
 	flag.StringVar(&cfg.Channel, "channel", "default", "Channel name for the chain")
 	flag.StringVar(&cfg.P2PAddr, "p2p", "127.0.0.1:8080", "Advertise ip-port of P2P")
 	flag.StringVar(&cfg.P2PListenAddr, "p2p_listen", "", "Listen ip-port of P2P")
+	flag.StringVar(&cfg.P2PNAT, "p2p_nat", "", "NAT traversal of P2P (none,auto,any,upnp,pmp[:<gateway>],extip:<ip>)")
 	flag.IntVar(&cfg.NID, "nid", 0, "Chain Network ID")
 	flag.StringVar(&cfg.RPCAddr, "rpc", ":9080", "Listen ip-port of JSON-RPC")
 	flag.BoolVar(&cfg.RPCDump, "rpc_dump", false, "JSON-RPC Request, Response Dump flag")
 	flag.BoolVar(&cfg.RPCDebug, "rpc_debug", false, "JSON-RPC Debug enable")
 	flag.BoolVar(&cfg.RPCRosetta, "rpc_rosetta", false, "JSON-RPC Rosetta enable")
 	flag.IntVar(&cfg.RPCBatchLimit, "rpc_batch_limit", 10, "JSON-RPC batch limit")
+	flag.StringVar(&cfg.GRPCAddr, "grpc", "", "Listen ip-port of gRPC streams (disabled if empty)")
+	flag.IntVar(&cfg.GRPCMaxConns, "grpc_max_conns", server.DefaultGRPCMaxConnections, "Maximum number of gRPC connections (0 for no limit)")
+	flag.IntVar(&cfg.GRPCMaxStreams, "grpc_max_streams", server.DefaultGRPCMaxStreams, "Maximum number of gRPC streams for a connection (0 for no limit)")
 	flag.StringVar(&cfg.SeedAddr, "seed", "", "Ip-port of Seed")
 	flag.StringVar(&genesisStorage, "genesis_storage", "", "Genesis storage path")
 	flag.StringVar(&genesisPath, "genesis", "", "Genesis template directory or file")
@@ -133,11 +142,15 @@ func main() {
 	flag.StringVar(&chainDir, "chain_dir", "", "Chain data directory (default: .chain/<address>/<nid>)")
 	flag.IntVar(&cfg.EEInstances, "ee_instances", 1, "Number of execution engines")
 	flag.IntVar(&cfg.ConcurrencyLevel, "concurrency", 1, "Maximum number of executors to be used for concurrency")
+	flag.BoolVar(&cfg.OptimisticExec, "optimistic_execution", false, "Execute transactions optimistically with conflict detection (requires concurrency > 1)")
 	flag.IntVar(&cfg.NormalTxPoolSize, "normal_tx_pool", 0, "Normal transaction pool size")
 	flag.IntVar(&cfg.PatchTxPoolSize, "patch_tx_pool", 0, "Patch transaction pool size")
 	flag.IntVar(&cfg.MaxBlockTxBytes, "max_block_tx_bytes", 0, "Maximum size of transactions in a block")
 	flag.StringVar(&cfg.NodeCache, "node_cache", chain.NodeCacheDefault, "Node cache (none,small,large)")
 	flag.BoolVar(&cfg.ValidateTxOnSend, "validate_tx_on_send", false, "Validate transaction on send")
+	flag.StringVar(&cfg.BandwidthLimit, "bandwidth_limit", "", "Bandwidth limits in bytes/s for peers and protocols (ex: peer=1m/2m,statesync=512k)")
+	flag.StringVar(&cfg.PrivatePeers, "private_peers", "", "Private peers which are always connected and never gossiped ([<peer id>@]<ip>:<port>, comma separated)")
+	flag.BoolVar(&cfg.DisableDiscovery, "disable_discovery", false, "Disable peer discovery, connect to private peers only")
 	cfg.ChildrenLimit = flag.Int("children_limit", -1, "Maximum number of child connections (-1: uses system default value)")
 	cfg.NephewsLimit = flag.Int("nephews_limit", -1, "Maximum number of nephew connections (-1: uses system default value)")
 	flag.StringVar(&cfg.LogLevel, "log_level", "debug", "Main log level")
@@ -153,6 +166,7 @@ func main() {
 	flag.Int64Var(&cfg.TxTimeout, "tx_timeout", 0, "Transaction timeout in milli-second (0: uses system default value)")
 	flag.StringVar(&cfg.Engines, "engines", "python", "Execution engines, comma-separated (python,java)")
 	flag.IntVar(&cfg.WSMaxSession, "ws_max_session", server.DefaultWSMaxSession, "Websocket session limit (use -1 to disable)")
+	flag.IntVar(&cfg.RPCCacheSize, "rpc_cache_size", server.DefaultJSONRPCCacheSize, "JSON-RPC response cache size in bytes (use 0 to disable)")
 	flag.StringVar(&lwCfg.Filename, "log_writer_filename", "", "Log filename")
 	flag.IntVar(&lwCfg.MaxSize, "log_writer_maxsize", 100, "Log file max size")
 	flag.IntVar(&lwCfg.MaxAge, "log_writer_maxage", 0, "Log file max age")
@@ -466,6 +480,9 @@ func Execute(cmd *cobra.Command, args []string) {
 	if cfg.P2PListenAddr != "" {
 		_ = nt.SetListenAddress(cfg.P2PListenAddr)
 	}
+	if err := nt.SetNAT(cfg.P2PNAT); err != nil {
+		log.Panicf("FAIL to set P2P NAT err=%+v", err)
+	}
 	err := nt.Listen()
 	if err != nil {
 		log.Panicf("FAIL to listen P2P err=%+v", err)
@@ -492,6 +509,10 @@ func Execute(cmd *cobra.Command, args []string) {
 		JSONRPCRosetta:      cfg.RPCRosetta,
 		JSONRPCBatchLimit:   cfg.RPCBatchLimit,
 		WSMaxSession:        cfg.WSMaxSession,
+		JSONRPCCacheSize:    cfg.RPCCacheSize,
+		GRPCAddress:         cfg.GRPCAddr,
+		GRPCMaxConnections:  cfg.GRPCMaxConns,
+		GRPCMaxStreams:      cfg.GRPCMaxStreams,
 	}
 	srv := server.NewManager(config, wallet, logger)
 	hex.EncodeToString(wallet.Address().ID())
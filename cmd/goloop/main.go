@@ -46,6 +46,8 @@ func main() {
 	cli.NewStatsCmd(rootCmd, rootVc)
 	cli.NewRpcCmd(rootCmd, nil)
 	cli.NewDebugCmd(rootCmd, nil)
+	cli.NewReplayCmd(rootCmd, rootVc)
+	cli.NewSnapshotCmd(rootCmd, rootVc)
 	rootCmd.AddCommand(
 		cli.NewGStorageCmd("gs"),
 		cli.NewGenesisCmd("gn"),
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icsim"
+)
+
+var logLevel string
+
+func run(files []string) (bool, error) {
+	success := true
+	for _, file := range files {
+		sc, err := icsim.LoadScenario(file)
+		if err != nil {
+			return false, err
+		}
+		fmt.Printf("Scenario %s (%s)\n", sc.Name, file)
+		failures, err := icsim.RunScenario(sc, os.Stdout)
+		if err != nil {
+			return false, err
+		}
+		if failures > 0 {
+			fmt.Printf("Scenario %s FAILED failures=%d\n", sc.Name, failures)
+			success = false
+		} else {
+			fmt.Printf("Scenario %s PASSED\n", sc.Name)
+		}
+	}
+	return success, nil
+}
+
+func er(msg interface{}) {
+	_, _ = fmt.Fprintln(os.Stderr, "Error:", msg)
+	os.Exit(1)
+}
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   os.Args[0] + " <scenario file>...",
+		Short: "Run IISS scenarios on the simulator and check expectations",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New("requires at least one scenario file")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			lv, err := log.ParseLevel(logLevel)
+			if err != nil {
+				er(err)
+			}
+			log.GlobalLogger().SetLevel(lv)
+			log.GlobalLogger().SetConsoleLevel(lv)
+
+			success, err := run(args)
+			if err != nil {
+				er(err)
+			}
+			if !success {
+				os.Exit(1)
+			}
+		},
+	}
+
+	flag := rootCmd.PersistentFlags()
+	flag.StringVar(&logLevel, "log_level", "warn", "Log level of the simulator (trace, debug, info, warn, error)")
+	err := rootCmd.Execute()
+	if err != nil {
+		er(err)
+	}
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/icon-project/goloop/block"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/iiss"
+	"github.com/icon-project/goloop/icon/iiss/iccache"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+)
+
+var dbPath string
+var dbType string
+var codecType string
+var expectedHeight int64
+var maxDiffs int
+var interval time.Duration
+
+func codecForType(t string) codec.Codec {
+	if t == "mp" {
+		return codec.MP
+	}
+	return codec.RLP
+}
+
+// extensionSnapshotAt returns the extension snapshot in the result of the
+// block at the height, which is the state after executing the previous block.
+func extensionSnapshotAt(dbase db.Database, cod codec.Codec, height int64) (*iiss.ExtensionSnapshotImpl, error) {
+	result, err := block.GetBlockResultByHeight(dbase, cod, height)
+	if err != nil {
+		return nil, err
+	}
+	data, err := service.ExtensionDataFromResult(result)
+	if err != nil {
+		return nil, err
+	}
+	ess, ok := iiss.NewExtensionSnapshot(iccache.AttachStateNodeCache(dbase), data).(*iiss.ExtensionSnapshotImpl)
+	if !ok || ess == nil {
+		return nil, errors.Errorf("invalid extension data height=%d", height)
+	}
+	return ess, nil
+}
+
+func calculate(calc *iiss.Calculator) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- calc.WaitResult(calc.StartHeight())
+	}()
+	startTS := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			fmt.Printf("Calculation finished elapsed=%s\n", time.Since(startTS).Round(time.Millisecond))
+			return err
+		case <-ticker.C:
+			stage, updated := calc.Progress()
+			fmt.Printf("Calculating stage=%s updates=%d elapsed=%s\n",
+				stage, updated, time.Since(startTS).Round(time.Second))
+		}
+	}
+}
+
+func iscoreValue(is *icreward.IScore) *big.Int {
+	if is == nil {
+		return new(big.Int)
+	}
+	return is.Value()
+}
+
+func verify(dbase db.Database, cod codec.Codec, calc *iiss.Calculator, height int64) (bool, error) {
+	ess, err := extensionSnapshotAt(dbase, cod, height)
+	if err != nil {
+		return false, err
+	}
+	global, err := ess.Back2().GetGlobal()
+	if err != nil {
+		return false, err
+	}
+	if global != nil && global.GetStartHeight() == calc.StartHeight() {
+		return false, errors.Errorf("calculation result is not applied at height=%d", height)
+	}
+	expected := ess.Reward()
+	result := calc.Result()
+	fmt.Printf("Expected hash        : %#x\n", expected.Bytes())
+	if bytes.Equal(expected.Bytes(), result.Bytes()) {
+		return true, nil
+	}
+
+	var count int
+	sum := new(big.Int)
+	err = expected.DiffIScores(result, func(addr module.Address, exp, real *icreward.IScore) error {
+		diff := new(big.Int).Sub(iscoreValue(real), iscoreValue(exp))
+		sum.Add(sum, diff)
+		count++
+		if maxDiffs < 0 || count <= maxDiffs {
+			fmt.Printf("  %s expected=%s calculated=%s diff=%s\n",
+				addr, iscoreValue(exp), iscoreValue(real), diff)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	fmt.Printf("Accounts with different I-Score : %d (sum of diff=%s)\n", count, sum)
+	return false, nil
+}
+
+func run(height int64) error {
+	d, err := db.Open(dbPath, dbType, "")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := d.Close(); err != nil {
+			panic(err)
+		}
+	}()
+	// Calculator writes local data like reward details, so keep every change
+	// in memory not to modify the database of the node.
+	dbase := db.NewLayerDB(d)
+
+	cod := codecForType(codecType)
+	lastHeight, err := block.GetLastHeightWithCodec(dbase, cod)
+	if err != nil {
+		return err
+	}
+	if height > lastHeight {
+		return errors.Errorf("invalid height last=%d height=%d", lastHeight, height)
+	}
+	ess, err := extensionSnapshotAt(dbase, cod, height)
+	if err != nil {
+		return err
+	}
+	state := ess.NewState(true).(*iiss.ExtensionStateImpl).State
+	term := state.GetTermSnapshot()
+	if term == nil {
+		return errors.Errorf("no term information height=%d", height)
+	}
+
+	calc := iiss.NewCalculator(dbase, ess.Back2(), ess.Reward(), log.GlobalLogger())
+	if calc == nil {
+		return errors.Errorf("failed to create calculator height=%d", height)
+	}
+	if calc.StartHeight() == iiss.InitBlockHeight {
+		return errors.Errorf("nothing to calculate height=%d", height)
+	}
+	fmt.Printf("Block height         : %d\n", height)
+	fmt.Printf("Term                 : seq=%d start=%d end=%d\n",
+		term.Sequence(), term.StartHeight(), term.GetEndHeight())
+	fmt.Printf("Calculation target   : start=%d\n", calc.StartHeight())
+	if err = calculate(calc); err != nil {
+		return err
+	}
+	fmt.Printf("Calculated hash      : %#x\n", calc.Result().Bytes())
+	fmt.Printf("Calculated reward    : %s\n", calc.TotalReward())
+
+	// the result is applied on the first block of the next term, and it's
+	// stored in the result of the block after it.
+	if expectedHeight <= 0 {
+		expectedHeight = term.GetEndHeight() + 2
+	}
+	if expectedHeight > lastHeight {
+		fmt.Printf("Skip verification: no result of height=%d (last=%d)\n", expectedHeight, lastHeight)
+		return nil
+	}
+	ok, err := verify(dbase, cod, calc, expectedHeight)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Errorf("calculation result mismatch height=%d", expectedHeight)
+	}
+	fmt.Println("Verification succeeded")
+	return nil
+}
+
+func er(msg interface{}) {
+	_, _ = fmt.Fprintln(os.Stderr, "Error:", msg)
+	os.Exit(1)
+}
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   os.Args[0] + " <height>",
+		Short: "Recalculate IISS rewards of the term including the height and verify the result",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("requires one height argument")
+			}
+			_, err := strconv.ParseInt(args[0], 0, 64)
+			return err
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			height, _ := strconv.ParseInt(args[0], 0, 64)
+			if err := run(height); err != nil {
+				er(err)
+			}
+		},
+	}
+
+	flag := rootCmd.PersistentFlags()
+	flag.StringVar(&dbPath, "db_path", "", "DB path. For example, .chain/hxd81df51476cee82617f6fa658ebecc31d24ddce3/bfdc51/db/bfdc51/)")
+	flag.StringVar(&dbType, "db_type", "goleveldb",
+		fmt.Sprintf("Name of database system (%s)", strings.Join(db.GetSupportedTypes(), ", ")))
+	flag.StringVar(&codecType, "codec", "rlp", "Name of data codec (rlp, mp)")
+	flag.Int64Var(&expectedHeight, "expected_height", 0, "Height of the block having the result to compare with (default: end of the term + 2)")
+	flag.IntVar(&maxDiffs, "max_diffs", 100, "Maximum number of accounts to print differences (negative for all)")
+	flag.DurationVar(&interval, "interval", 10*time.Second, "Interval of progress output")
+	err := rootCmd.Execute()
+	if err != nil {
+		er(err)
+	}
+}
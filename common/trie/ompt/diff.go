@@ -0,0 +1,194 @@
+package ompt
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/trie"
+)
+
+// diffCursor points a node of the trie. For extension and leaf, off is
+// the number of nibbles of the keys already consumed.
+type diffCursor struct {
+	n   node
+	off int
+}
+
+type diffWalker struct {
+	m1, m2  *mpt
+	handler func(op int, key []byte, v1, v2 trie.Object) error
+}
+
+func (w *diffWalker) cursorOf(m *mpt, n node) (diffCursor, error) {
+	if n == nil {
+		return diffCursor{}, nil
+	}
+	rn, err := n.realize(m)
+	if err != nil {
+		return diffCursor{}, err
+	}
+	return diffCursor{n: rn}, nil
+}
+
+// expand returns the value and the children of the node as if it's
+// a branch.
+func (w *diffWalker) expand(m *mpt, c diffCursor) (trie.Object, [16]diffCursor, error) {
+	var children [16]diffCursor
+	var value trie.Object
+	var err error
+	switch n := c.n.(type) {
+	case nil:
+		return nil, children, nil
+	case *branch:
+		lock := n.rlock()
+		value = n.value
+		nodes := n.children
+		lock.Unlock()
+		for i, child := range nodes {
+			if children[i], err = w.cursorOf(m, child); err != nil {
+				return nil, children, err
+			}
+		}
+	case *extension:
+		lock := n.rlock()
+		keys, next := n.keys, n.next
+		lock.Unlock()
+		if c.off+1 < len(keys) {
+			children[keys[c.off]] = diffCursor{n: n, off: c.off + 1}
+		} else if children[keys[c.off]], err = w.cursorOf(m, next); err != nil {
+			return nil, children, err
+		}
+	case *leaf:
+		lock := n.rlock()
+		keys := n.keys
+		value = n.value
+		lock.Unlock()
+		if c.off < len(keys) {
+			children[keys[c.off]] = diffCursor{n: n, off: c.off + 1}
+			value = nil
+		}
+	default:
+		return nil, children, errors.InvalidStateError.Errorf(
+			"UnknownNodeType(%T)", c.n)
+	}
+	if value != nil {
+		if value, _, err = m.getObject(value); err != nil {
+			return nil, children, err
+		}
+	}
+	return value, children, nil
+}
+
+func isSameNode(c1, c2 diffCursor) bool {
+	if c1.n == nil || c2.n == nil {
+		return c1.n == nil && c2.n == nil
+	}
+	if c1.off != c2.off {
+		return false
+	}
+	if c1.n == c2.n {
+		return true
+	}
+	if c1.off != 0 {
+		return false
+	}
+	h1, h2 := c1.n.hash(), c2.n.hash()
+	return len(h1) > 0 && bytes.Equal(h1, h2)
+}
+
+func (w *diffWalker) walk(nibs []byte, c1, c2 diffCursor) error {
+	if isSameNode(c1, c2) {
+		return nil
+	}
+	v1, children1, err := w.expand(w.m1, c1)
+	if err != nil {
+		return err
+	}
+	v2, children2, err := w.expand(w.m2, c2)
+	if err != nil {
+		return err
+	}
+	if v1 != nil || v2 != nil {
+		var op int
+		switch {
+		case v2 == nil:
+			op = -1
+		case v1 == nil:
+			op = 1
+		case bytes.Equal(v1.Bytes(), v2.Bytes()):
+			op = 2
+		}
+		if op != 2 {
+			if err := w.handler(op, keysToBytes(string(nibs)), v1, v2); err != nil {
+				return err
+			}
+		}
+	}
+	for i := 0; i < 16; i++ {
+		if err := w.walk(append(nibs, byte(i)), children1[i], children2[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func diffMPT(m1, m2 *mpt, handler func(op int, key []byte, v1, v2 trie.Object) error) error {
+	if m1 == m2 {
+		return nil
+	}
+	m1.mutex.RLock()
+	defer m1.mutex.RUnlock()
+	m2.mutex.RLock()
+	defer m2.mutex.RUnlock()
+
+	w := &diffWalker{m1: m1, m2: m2, handler: handler}
+	c1, err := w.cursorOf(m1, m1.root)
+	if err != nil {
+		return err
+	}
+	c2, err := w.cursorOf(m2, m2.root)
+	if err != nil {
+		return err
+	}
+	return w.walk(make([]byte, 0, hashSize*2), c1, c2)
+}
+
+// DiffImmutableForObject calls handler for each key having different values
+// in the tries in the order of keys. op is -1 if the key exists only in t1,
+// 1 if it exists only in t2, and 0 if values are different. Sub-tries with
+// same hash are skipped without visiting. If handler returns an error, it
+// stops and returns the error.
+func DiffImmutableForObject(t1, t2 trie.ImmutableForObject,
+	handler func(op int, key []byte, v1, v2 trie.Object) error,
+) error {
+	m1, ok1 := t1.(*mpt)
+	m2, ok2 := t2.(*mpt)
+	if !ok1 || !ok2 {
+		return errors.IllegalArgumentError.Errorf(
+			"UnsupportedTrie(t1=%T,t2=%T)", t1, t2)
+	}
+	return diffMPT(m1, m2, handler)
+}
+
+// DiffImmutable is same as DiffImmutableForObject except that it's for
+// tries of bytes.
+func DiffImmutable(t1, t2 trie.Immutable,
+	handler func(op int, key []byte, v1, v2 []byte) error,
+) error {
+	m1, ok1 := t1.(*mptForBytes)
+	m2, ok2 := t2.(*mptForBytes)
+	if !ok1 || !ok2 {
+		return errors.IllegalArgumentError.Errorf(
+			"UnsupportedTrie(t1=%T,t2=%T)", t1, t2)
+	}
+	return diffMPT(m1.mpt, m2.mpt, func(op int, key []byte, v1, v2 trie.Object) error {
+		return handler(op, key, bytesOf(v1), bytesOf(v2))
+	})
+}
+
+func bytesOf(o trie.Object) []byte {
+	if o == nil {
+		return nil
+	}
+	return o.Bytes()
+}
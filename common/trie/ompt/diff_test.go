@@ -0,0 +1,104 @@
+package ompt
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/trie"
+)
+
+type diffItem struct {
+	op     int
+	key    string
+	v1, v2 string
+}
+
+func diffOfMaps(m1, m2 map[string]string) map[string]diffItem {
+	res := make(map[string]diffItem)
+	for k, v1 := range m1 {
+		if v2, ok := m2[k]; !ok {
+			res[k] = diffItem{-1, k, v1, ""}
+		} else if v1 != v2 {
+			res[k] = diffItem{0, k, v1, v2}
+		}
+	}
+	for k, v2 := range m2 {
+		if _, ok := m1[k]; !ok {
+			res[k] = diffItem{1, k, "", v2}
+		}
+	}
+	return res
+}
+
+func TestDiffImmutable(t *testing.T) {
+	dbase := db.NewMapDB()
+	r := rand.New(rand.NewSource(1))
+
+	m1 := make(map[string]string)
+	t1 := NewMutable(dbase, nil)
+	for i := 0; i < 300; i++ {
+		k := fmt.Sprintf("key%d", r.Intn(1000))
+		v := fmt.Sprintf("value%d", i)
+		m1[k] = v
+		t1.Set([]byte(k), []byte(v))
+	}
+	s1 := t1.GetSnapshot()
+	assert.NoError(t, s1.Flush())
+
+	m2 := make(map[string]string)
+	for k, v := range m1 {
+		m2[k] = v
+	}
+	t2 := NewMutableFromImmutable(NewImmutable(dbase, s1.Hash()))
+	for i := 0; i < 30; i++ {
+		k := fmt.Sprintf("key%d", r.Intn(1000))
+		switch r.Intn(3) {
+		case 0:
+			delete(m2, k)
+			t2.Delete([]byte(k))
+		default:
+			v := fmt.Sprintf("changed%d", i)
+			m2[k] = v
+			t2.Set([]byte(k), []byte(v))
+		}
+	}
+	m2["k"] = "short"
+	t2.Set([]byte("k"), []byte("short"))
+
+	expected := diffOfMaps(m1, m2)
+	check := func(i1, i2 trie.Immutable) {
+		var last []byte
+		count := 0
+		err := DiffImmutable(i1, i2, func(op int, key []byte, v1, v2 []byte) error {
+			assert.True(t, last == nil || bytes.Compare(last, key) < 0)
+			last = key
+			count += 1
+			assert.Equal(t, expected[string(key)], diffItem{op, string(key), string(v1), string(v2)})
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, len(expected), count)
+	}
+
+	// dirty nodes without hash
+	check(s1, t2.(trie.Immutable))
+
+	s2 := t2.GetSnapshot()
+	assert.NoError(t, s2.Flush())
+	check(s1, s2)
+	check(NewImmutable(dbase, s1.Hash()), NewImmutable(dbase, s2.Hash()))
+
+	err := DiffImmutable(s2, NewImmutable(dbase, s2.Hash()), func(op int, key []byte, v1, v2 []byte) error {
+		assert.Fail(t, "no difference is expected")
+		return nil
+	})
+	assert.NoError(t, err)
+
+	expected = diffOfMaps(map[string]string{}, m2)
+	check(NewImmutable(dbase, nil), s2)
+}
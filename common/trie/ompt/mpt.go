@@ -343,13 +343,26 @@ type iterator struct {
 	value  trie.Object
 	error  error
 	prefix string
+	start  string
 }
 
 func (i *iterator) Get() (trie.Object, []byte, error) {
 	return i.value, []byte(i.key), i.error
 }
 
+// checkStart returns whether the node at k may have keys not less than
+// the start key.
+func (i *iterator) checkStart(k string) bool {
+	if len(k) < len(i.start) {
+		return k >= i.start[:len(k)]
+	}
+	return k >= i.start
+}
+
 func (i *iterator) appendItem(k string, n node) (node, error) {
+	if len(i.start) > 0 && !i.checkStart(k) {
+		return n, nil
+	}
 	realized, err := n.realize(i.m)
 	if err == nil {
 		i.stack = append(i.stack, iteratorItem{k: k, n: realized})
@@ -409,6 +422,13 @@ func (i *iterator) Next() error {
 			return nil
 		}
 		if i.value != nil {
+			if len(i.start) > 0 {
+				if i.key < i.start {
+					continue
+				}
+				// following keys are greater than the start key
+				i.start = ""
+			}
 			i.key = string(keysToBytes(i.key))
 			return nil
 		}
@@ -428,6 +448,16 @@ func (m *mpt) Iterator() trie.IteratorForObject {
 }
 
 func (m *mpt) Filter(prefix []byte) trie.IteratorForObject {
+	return m.newIterator(prefix, nil)
+}
+
+// Seek returns the iterator for the keys which are not less than the start
+// key. Nodes having only smaller keys are not loaded.
+func (m *mpt) Seek(start []byte) trie.IteratorForObject {
+	return m.newIterator(nil, start)
+}
+
+func (m *mpt) newIterator(prefix, start []byte) trie.IteratorForObject {
 	lock := RLock(&m.mutex)
 	defer lock.Unlock()
 
@@ -449,6 +479,7 @@ func (m *mpt) Filter(prefix []byte) trie.IteratorForObject {
 		m:      m,
 		stack:  []iteratorItem{{k: "", n: root}},
 		prefix: string(bytesToNibs(prefix)),
+		start:  string(bytesToNibs(start)),
 	}
 	i.Next()
 	return i
@@ -514,3 +514,51 @@ func Test_mpt_Filter(t *testing.T) {
 		})
 	}
 }
+
+func Test_mpt_Seek(t *testing.T) {
+	data := []string{"a", "b", "ba", "bae", "bc", "bcdefg", "bcdefh", "bcf", "c", "\x12\x34", "\x23\x45\x67"}
+	tests := []struct {
+		name  string
+		start []byte
+		want  []string
+	}{
+		{"Nil", nil,
+			[]string{"\x12\x34", "\x23\x45\x67", "a", "b", "ba", "bae", "bc", "bcdefg", "bcdefh", "bcf", "c"}},
+		{"Existing", []byte("bc"),
+			[]string{"bc", "bcdefg", "bcdefh", "bcf", "c"}},
+		{"Middle", []byte("bcdefga"),
+			[]string{"bcdefh", "bcf", "c"}},
+		{"Prefix", []byte("bb"),
+			[]string{"bc", "bcdefg", "bcdefh", "bcf", "c"}},
+		{"Nibble", []byte{0x23, 0x40},
+			[]string{"\x23\x45\x67", "a", "b", "ba", "bae", "bc", "bcdefg", "bcdefh", "bcf", "c"}},
+		{"Last", []byte("c"),
+			[]string{"c"}},
+		{"End", []byte("d"),
+			nil},
+	}
+	dbase := db.NewMapDB()
+	m := NewMPTForBytes(dbase, nil)
+	for _, s := range data {
+		_, err := m.Set([]byte(s), []byte(s))
+		assert.NoError(t, err)
+	}
+	ss := m.GetSnapshot()
+	assert.NoError(t, ss.Flush())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the one in memory and the one loaded from the database
+			for _, m := range []*mptForBytes{m, NewMPTForBytes(dbase, ss.Hash())} {
+				var keys []string
+				for itr := m.Seek(tt.start); itr.Has(); itr.Next() {
+					value, key, err := itr.Get()
+					assert.NoError(t, err)
+					assert.True(t, bytes.Equal(key, value))
+					keys = append(keys, string(key))
+				}
+				assert.Equal(t, tt.want, keys)
+			}
+		})
+	}
+}
@@ -88,6 +88,12 @@ func (m *mptForBytes) Filter(prefix []byte) trie.Iterator {
 	return &iteratorForBytes{i}
 }
 
+// Seek returns the iterator for the keys which are not less than the start
+// key.
+func (m *mptForBytes) Seek(start []byte) trie.Iterator {
+	return &iteratorForBytes{m.mpt.Seek(start)}
+}
+
 func (m *mptForBytes) Equal(object trie.Immutable, exact bool) bool {
 	if m2, ok := object.(*mptForBytes); ok {
 		return m.mpt.Equal(m2.mpt, exact)
@@ -40,3 +40,20 @@ func SetCacheOfMutable(mutable trie.Mutable, cache *cache.NodeCache) {
 func SetCacheOfMutableForObject(mutable trie.MutableForObject, cache *cache.NodeCache) {
 	ompt.SetCacheOfMutableForObject(mutable, cache)
 }
+
+type BytesDiffHandler func(op int, key []byte, exp, real []byte) error
+
+// DiffImmutable is same as CompareImmutable, but it skips sub-tries having
+// same hash, so it's much faster for tries sharing most of nodes. It stops
+// if the handler returns an error.
+func DiffImmutable(exp, real trie.Immutable, handler BytesDiffHandler) error {
+	return ompt.DiffImmutable(exp, real, handler)
+}
+
+type ObjectDiffHandler func(op int, key []byte, exp, real trie.Object) error
+
+// DiffImmutableForObject is same as CompareImmutableForObject, but it skips
+// sub-tries having same hash. It stops if the handler returns an error.
+func DiffImmutableForObject(exp, real trie.ImmutableForObject, handler ObjectDiffHandler) error {
+	return ompt.DiffImmutableForObject(exp, real, handler)
+}
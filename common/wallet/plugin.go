@@ -44,6 +44,19 @@ func (w pluginWallet) Address() module.Address {
 	return w.addr
 }
 
+type secretDeriver interface {
+	DeriveSecret(label string, size int) ([]byte, error)
+}
+
+// DeriveSecret returns the secret derived by the plugin. It returns
+// UnsupportedError if the plugin can't derive secrets.
+func (w pluginWallet) DeriveSecret(label string, size int) ([]byte, error) {
+	if d, ok := w.walletImpl.(secretDeriver); ok {
+		return d.DeriveSecret(label, size)
+	}
+	return nil, errors.UnsupportedError.New("SecretDerivationNotSupported")
+}
+
 func OpenPlugin(p string, opts map[string]string) (wallet module.Wallet, ret error) {
 	mod, err := plugin.Open(p)
 	if err != nil {
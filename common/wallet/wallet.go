@@ -1,6 +1,11 @@
 package wallet
 
 import (
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/sha3"
+
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/module"
@@ -27,6 +32,21 @@ func (w *softwareWallet) PublicKey() []byte {
 	return w.pkey.SerializeCompressed()
 }
 
+// DeriveSecret returns the secret of the size derived from the private key
+// with HKDF. Secrets for different purposes shall use different labels.
+func (w *softwareWallet) DeriveSecret(label string, size int) ([]byte, error) {
+	return deriveSecret(w.skey.Bytes(), label, size)
+}
+
+func deriveSecret(key []byte, label string, size int) ([]byte, error) {
+	secret := make([]byte, size)
+	r := hkdf.New(sha3.New256, key, nil, []byte(label))
+	if _, err := io.ReadFull(r, secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
 func New() module.Wallet {
 	sk, pk := crypto.GenerateKeyPair()
 	return &softwareWallet{
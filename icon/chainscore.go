@@ -710,6 +710,26 @@ var chainMethods = []*chainMethod{
 		},
 		nil,
 	}, icmodule.RevisionBTP2, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getPRepNodeDSAPublicKey",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 2,
+		[]scoreapi.Parameter{
+			{"address", scoreapi.Address, nil, nil},
+			{"dsa", scoreapi.String, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Bytes,
+		},
+	}, icmodule.RevisionBTPMultipleDSA, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "setPRepNodeDSAPublicKey",
+		scoreapi.FlagExternal, 2,
+		[]scoreapi.Parameter{
+			{"dsa", scoreapi.String, nil, nil},
+			{"pubKey", scoreapi.Bytes, nil, nil},
+		},
+		nil,
+	}, icmodule.RevisionBTPMultipleDSA, 0},
 	{scoreapi.Method{
 		scoreapi.Function, "openBTPNetwork",
 		scoreapi.FlagExternal, 3,
@@ -739,6 +759,120 @@ var chainMethods = []*chainMethod{
 		},
 		nil,
 	}, icmodule.RevisionBTP2, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getIScoreDetails",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"address", scoreapi.Address, nil, nil},
+			{"startBlockHeight", scoreapi.Integer, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionRewardDetails, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "estimateReward",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"address", scoreapi.Address, nil, nil},
+			{"delegations", scoreapi.ListTypeOf(1, scoreapi.Struct), nil,
+				[]scoreapi.Field{
+					{"address", scoreapi.Address, nil},
+					{"value", scoreapi.Integer, nil},
+				},
+			},
+			{"bonds", scoreapi.ListTypeOf(1, scoreapi.Struct), nil,
+				[]scoreapi.Field{
+					{"address", scoreapi.Address, nil},
+					{"value", scoreapi.Integer, nil},
+				},
+			},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionRewardProjection, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getPenaltyHistoryOf",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"address", scoreapi.Address, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionPenaltyHistory, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getUnlockSchedule",
+		scoreapi.FlagReadOnly, 0,
+		[]scoreapi.Parameter{
+			{"startBlockHeight", scoreapi.Integer, nil, nil},
+			{"endBlockHeight", scoreapi.Integer, nil, nil},
+			{"address", scoreapi.Address, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionUnlockSchedule, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getValidatorsPreview",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 0,
+		nil,
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionValidatorsPreview, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getParameterHistory",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"name", scoreapi.String, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionParameterHistory, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getParameterValues",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 0,
+		[]scoreapi.Parameter{
+			{"blockHeight", scoreapi.Integer, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionParameterHistory, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "setPRepNodeKeyRotation",
+		scoreapi.FlagExternal, 3,
+		[]scoreapi.Parameter{
+			{"node", scoreapi.Address, nil, nil},
+			{"activationHeight", scoreapi.Integer, nil, nil},
+			{"publicKeys", scoreapi.ListTypeOf(1, scoreapi.Struct), nil,
+				[]scoreapi.Field{
+					{"dsa", scoreapi.String, nil},
+					{"publicKey", scoreapi.Bytes, nil},
+				},
+			},
+		},
+		nil,
+	}, icmodule.RevisionNodeKeyRotation, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "cancelPRepNodeKeyRotation",
+		scoreapi.FlagExternal, 0,
+		nil,
+		nil,
+	}, icmodule.RevisionNodeKeyRotation, 0},
+	{scoreapi.Method{
+		scoreapi.Function, "getPRepNodeKeyRotation",
+		scoreapi.FlagReadOnly | scoreapi.FlagExternal, 1,
+		[]scoreapi.Parameter{
+			{"address", scoreapi.Address, nil, nil},
+		},
+		[]scoreapi.DataType{
+			scoreapi.Dict,
+		},
+	}, icmodule.RevisionNodeKeyRotation, 0},
 }
 
 func applyStepLimits(fee *FeeConfig, as state.AccountState) error {
@@ -326,6 +326,12 @@ func (s *chainScore) handleRevisionChange(as state.AccountState, r1, r2 int) err
 	if r1 < icmodule.Revision21 && r2 >= icmodule.Revision21 && s.cc.ChainID() == CIDForMainNet {
 		s.blockAccounts2()
 	}
+	if r1 < icmodule.RevisionParameterHistory && r2 >= icmodule.RevisionParameterHistory {
+		// the parameter history starts with the values on activation
+		if err := newParamHistory(as).Snapshot(s.cc.BlockHeight(), s.paramValues(as)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -391,6 +397,11 @@ func (s *chainScore) Ex_setRevision(code *common.HexInt) error {
 	if err := scoredb.NewVarDB(as, state.VarRevision).Set(code); err != nil {
 		return err
 	}
+	if err := s.recordParamChangeOnRevision(
+		int(code.Int64()), ParamRevision, big.NewInt(r), code.Value(),
+	); err != nil {
+		return err
+	}
 	if err := s.handleRevisionChange(as, int(r), int(code.Int64())); err != nil {
 		return nil
 	}
@@ -561,7 +572,11 @@ func (s *chainScore) Ex_setStepPrice(price *common.HexInt) error {
 		return err
 	}
 	as := s.cc.GetAccountState(state.SystemID)
-	return scoredb.NewVarDB(as, state.VarStepPrice).Set(price)
+	stepPrice := scoredb.NewVarDB(as, state.VarStepPrice)
+	if err := s.recordParamChange(ParamStepPrice, stepPrice.BigInt(), price.Value()); err != nil {
+		return err
+	}
+	return stepPrice.Set(price)
 }
 
 func (s *chainScore) Ex_setStepCost(costType string, cost *common.HexInt) error {
@@ -575,7 +590,13 @@ func (s *chainScore) Ex_setStepCost(costType string, cost *common.HexInt) error
 	as := s.cc.GetAccountState(state.SystemID)
 	stepCostDB := scoredb.NewDictDB(as, state.VarStepCosts, 1)
 	stepTypes := scoredb.NewArrayDB(as, state.VarStepTypes)
-	if stepCostDB.Get(costType) == nil && !costZero {
+	oldCost := stepCostDB.Get(costType)
+	if oldCost != nil || !costZero {
+		if err := s.recordParamChange(ParamStepCostPrefix+costType, bigIntOf(oldCost), cost.Value()); err != nil {
+			return err
+		}
+	}
+	if oldCost == nil && !costZero {
 		if err := stepTypes.Put(costType); err != nil {
 			return err
 		}
@@ -605,7 +626,11 @@ func (s *chainScore) Ex_setMaxStepLimit(contextType string, cost *common.HexInt)
 	}
 	as := s.cc.GetAccountState(state.SystemID)
 	stepLimitDB := scoredb.NewDictDB(as, state.VarStepLimit, 1)
-	if stepLimitDB.Get(contextType) == nil {
+	oldLimit := stepLimitDB.Get(contextType)
+	if err := s.recordParamChange(ParamMaxStepLimitPrefix+contextType, bigIntOf(oldLimit), cost.Value()); err != nil {
+		return err
+	}
+	if oldLimit == nil {
 		stepLimitTypes := scoredb.NewArrayDB(as, state.VarStepLimitTypes)
 		if err := stepLimitTypes.Put(contextType); err != nil {
 			return err
@@ -789,3 +814,113 @@ func (s *chainScore) Ex_getUseSystemDeposit(address module.Address) (bool, error
 	}
 	return as.UseSystemDeposit(), nil
 }
+
+// recordParamChange records the change of the network parameter made by the
+// current transaction if the revision supports the parameter history.
+func (s *chainScore) recordParamChange(name string, old, new *big.Int) error {
+	return s.recordParamChangeOnRevision(s.cc.Revision().Value(), name, old, new)
+}
+
+func (s *chainScore) recordParamChangeOnRevision(revision int, name string, old, new *big.Int) error {
+	if revision < icmodule.RevisionParameterHistory {
+		return nil
+	}
+	as := s.cc.GetAccountState(state.SystemID)
+	return newParamHistory(as).Record(name, s.cc.BlockHeight(), old, new, s.cc.TransactionID())
+}
+
+// paramValues returns the stored values of the network parameters recorded
+// in the parameter history.
+func (s *chainScore) paramValues(as state.AccountState) map[string]*big.Int {
+	values := map[string]*big.Int{
+		ParamRevision:  scoredb.NewVarDB(as, state.VarRevision).BigInt(),
+		ParamStepPrice: scoredb.NewVarDB(as, state.VarStepPrice).BigInt(),
+	}
+	stepTypes := scoredb.NewArrayDB(as, state.VarStepTypes)
+	stepCostDB := scoredb.NewDictDB(as, state.VarStepCosts, 1)
+	for i := 0; i < stepTypes.Size(); i++ {
+		name := stepTypes.Get(i).String()
+		values[ParamStepCostPrefix+name] = bigIntOf(stepCostDB.Get(name))
+	}
+	stepLimitTypes := scoredb.NewArrayDB(as, state.VarStepLimitTypes)
+	stepLimitDB := scoredb.NewDictDB(as, state.VarStepLimit, 1)
+	for i := 0; i < stepLimitTypes.Size(); i++ {
+		name := stepLimitTypes.Get(i).String()
+		values[ParamMaxStepLimitPrefix+name] = bigIntOf(stepLimitDB.Get(name))
+	}
+	if es, ok := s.cc.GetExtensionState().(*iiss.ExtensionStateImpl); ok && es != nil {
+		if rf := es.State.GetRewardFund(); rf != nil {
+			values[ParamRewardFundIglobal] = rf.Iglobal
+			values[ParamRewardFundIprep] = rf.Iprep
+			values[ParamRewardFundIcps] = rf.Icps
+			values[ParamRewardFundIrelay] = rf.Irelay
+			values[ParamRewardFundIvoter] = rf.Ivoter
+		}
+		values[ParamSlashingRatePrefix+"ConsistentValidationPenalty"] =
+			big.NewInt(int64(es.State.GetConsistentValidationPenaltySlashRatio()))
+		values[ParamSlashingRatePrefix+"NonVotePenalty"] =
+			big.NewInt(int64(es.State.GetNonVotePenaltySlashRatio()))
+	}
+	return values
+}
+
+func (s *chainScore) Ex_getParameterHistory(name string) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(true); err != nil {
+		return nil, err
+	}
+	as := s.cc.GetAccountState(state.SystemID)
+	changes, err := newParamHistory(as).Changes(name)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]interface{}, 0, len(changes))
+	for _, c := range changes {
+		history = append(history, c.ToJSON())
+	}
+	return map[string]interface{}{
+		"name":    name,
+		"history": history,
+	}, nil
+}
+
+func (s *chainScore) Ex_getParameterValues(blockHeight *common.HexInt) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(true); err != nil {
+		return nil, err
+	}
+	height := s.cc.BlockHeight()
+	if blockHeight != nil {
+		height = blockHeight.Int64()
+	}
+	if height < 0 {
+		return nil, scoreresult.InvalidParameterError.Errorf("InvalidBlockHeight(%d)", height)
+	}
+	as := s.cc.GetAccountState(state.SystemID)
+	h := newParamHistory(as)
+	if sh, ok := h.SnapshotHeight(); !ok || height < sh {
+		// values before the snapshot are unknown
+		return map[string]interface{}{
+			"blockHeight": height,
+			"values":      nil,
+		}, nil
+	}
+	current := s.paramValues(as)
+	for _, name := range h.Names() {
+		if _, ok := current[name]; !ok {
+			current[name] = nil
+		}
+	}
+	values := make(map[string]interface{})
+	for name, cur := range current {
+		v, err := h.ValueAt(name, height, cur)
+		if err != nil {
+			return nil, err
+		}
+		if v != nil {
+			values[name] = v
+		}
+	}
+	return map[string]interface{}{
+		"blockHeight": height,
+		"values":      values,
+	}, nil
+}
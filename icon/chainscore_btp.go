@@ -17,6 +17,7 @@
 package icon
 
 import (
+	"github.com/icon-project/goloop/btp/ntm"
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/intconv"
@@ -48,9 +49,17 @@ func (s *chainScore) Ex_getBTPNetworkTypeID(name string) (int64, error) {
 	return s.newBTPContext().GetNetworkTypeIDByName(name), nil
 }
 
-const iconDSA = "ecdsa/secp256k1"
+const iconDSA = icstate.ConsensusDSA
 
 func (s *chainScore) Ex_getPRepNodePublicKey(address module.Address) ([]byte, error) {
+	return s.getPRepNodePublicKey(address, iconDSA)
+}
+
+func (s *chainScore) Ex_getPRepNodeDSAPublicKey(address module.Address, dsa string) ([]byte, error) {
+	return s.getPRepNodePublicKey(address, dsa)
+}
+
+func (s *chainScore) getPRepNodePublicKey(address module.Address, dsa string) ([]byte, error) {
 	if err := s.tryChargeCall(false); err != nil {
 		return nil, err
 	}
@@ -63,7 +72,7 @@ func (s *chainScore) Ex_getPRepNodePublicKey(address module.Address) ([]byte, er
 		return nil, icmodule.IllegalArgumentError.New("address is not P-Rep")
 	}
 
-	return s.newBTPContext().GetPublicKey(prep.NodeAddress(), iconDSA), nil
+	return s.newBTPContext().GetPublicKey(prep.NodeAddress(), dsa), nil
 }
 
 func (s *chainScore) Ex_setPRepNodePublicKey(pubKey []byte) error {
@@ -74,6 +83,50 @@ func (s *chainScore) Ex_registerPRepNodePublicKey(address module.Address, pubKey
 	return s.setPRepNodePublicKey(address, pubKey)
 }
 
+// Ex_setPRepNodeDSAPublicKey sets the public key of the DSA for the node of
+// the P-Rep. Public keys of DSAs other than iconDSA can't be related to the
+// node address, so they are set only by the P-Rep.
+func (s *chainScore) Ex_setPRepNodeDSAPublicKey(dsa string, pubKey []byte) error {
+	if dsa == iconDSA {
+		return s.setPRepNodePublicKey(nil, pubKey)
+	}
+	if err := s.tryChargeCall(false); err != nil {
+		return err
+	}
+	if s.from.IsContract() {
+		return scoreresult.New(module.StatusAccessDenied, "NoPermission")
+	}
+	if len(pubKey) == 0 {
+		return icmodule.IllegalArgumentError.New("Invalid pubKey")
+	}
+	if ntm.DSAModuleForName(dsa) == nil {
+		return icmodule.IllegalArgumentError.Errorf("Unknown DSA %s", dsa)
+	}
+	es, err := s.getExtensionState()
+	if err != nil {
+		return err
+	}
+	prep := es.GetPRep(s.from)
+	if prep == nil {
+		return icmodule.IllegalArgumentError.New("address is not P-Rep")
+	}
+	nodeAddress := prep.NodeAddress()
+
+	bc := s.newBTPContext()
+	bs, err := s.getBTPState()
+	if err != nil {
+		return err
+	}
+	if err = bs.SetPublicKey(bc, nodeAddress, dsa, pubKey); err != nil {
+		return err
+	}
+	prep.SetDSAMask(bc.GetPublicKeyMask(nodeAddress))
+	if err = es.OnSetPublicKey(s.newCallContext(s.cc), prep.Owner(), bc.GetDSAIndex(dsa)); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (s *chainScore) setPRepNodePublicKey(address module.Address, pubKey []byte) error {
 	if err := s.tryChargeCall(false); err != nil {
 		return err
@@ -143,6 +196,55 @@ func (s *chainScore) setPRepNodePublicKey(address module.Address, pubKey []byte)
 	return nil
 }
 
+// Ex_setPRepNodeKeyRotation schedules the change of the node address and
+// public keys of the P-Rep at activationHeight. Public keys of the current
+// node for DSAs not in publicKeys are moved to the new node.
+func (s *chainScore) Ex_setPRepNodeKeyRotation(
+	node module.Address, activationHeight *common.HexInt, publicKeys []interface{},
+) error {
+	if err := s.tryChargeCall(false); err != nil {
+		return err
+	}
+	if s.from.IsContract() {
+		return scoreresult.New(module.StatusAccessDenied, "NoPermission")
+	}
+	pubKeys, err := icstate.NewNodePublicKeys(publicKeys)
+	if err != nil {
+		return scoreresult.InvalidParameterError.Wrap(err, "Invalid publicKeys")
+	}
+	es, err := s.getExtensionState()
+	if err != nil {
+		return err
+	}
+	r := icstate.NewNodeKeyRotation(activationHeight.Int64(), node, pubKeys)
+	return es.ScheduleNodeKeyRotation(s.newCallContext(s.cc), r)
+}
+
+func (s *chainScore) Ex_cancelPRepNodeKeyRotation() error {
+	if err := s.tryChargeCall(false); err != nil {
+		return err
+	}
+	if s.from.IsContract() {
+		return scoreresult.New(module.StatusAccessDenied, "NoPermission")
+	}
+	es, err := s.getExtensionState()
+	if err != nil {
+		return err
+	}
+	return es.CancelNodeKeyRotation(s.newCallContext(s.cc))
+}
+
+func (s *chainScore) Ex_getPRepNodeKeyRotation(address module.Address) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(true); err != nil {
+		return nil, err
+	}
+	es, err := s.getExtensionState()
+	if err != nil {
+		return nil, err
+	}
+	return es.GetNodeKeyRotationInJSON(s.cc.BlockHeight(), address)
+}
+
 func (s *chainScore) Ex_openBTPNetwork(networkTypeName string, name string, owner module.Address) (int64, error) {
 	if err := s.checkGovernance(true); err != nil {
 		return 0, err
@@ -150,6 +252,10 @@ func (s *chainScore) Ex_openBTPNetwork(networkTypeName string, name string, owne
 	if bs, err := s.getBTPState(); err != nil {
 		return 0, err
 	} else {
+		if mod := ntm.ForUID(networkTypeName); mod != nil && mod.DSA() != iconDSA &&
+			s.cc.Revision().Value() < icmodule.RevisionBTPMultipleDSA {
+			return 0, icmodule.IllegalArgumentError.Errorf("Not supported BTP network type %s", networkTypeName)
+		}
 		bc := s.newBTPContext()
 		ntActivated := false
 		if bc.GetNetworkTypeIDByName(networkTypeName) <= 0 {
@@ -470,6 +470,79 @@ func (s *chainScore) Ex_queryIScore(address module.Address) (map[string]interfac
 	return jso, nil
 }
 
+// Ex_getIScoreDetails returns the breakdown of I-Score rewarded in the term.
+// Details are local data of the node, so it's allowed only for queries.
+func (s *chainScore) Ex_getIScoreDetails(address module.Address, startBlockHeight *common.HexInt) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(true); err != nil {
+		return nil, err
+	}
+	if s.cc.TransactionInfo() != nil {
+		return nil, scoreresult.AccessDeniedError.New("QueryOnly")
+	}
+	var height int64
+	if startBlockHeight != nil {
+		height = startBlockHeight.Int64()
+	}
+	details, err := iiss.GetRewardDetails(s.cc.Database(), address, height)
+	if err != nil {
+		if errors.NotFoundError.Equals(err) {
+			return nil, scoreresult.InvalidParameterError.Wrap(err, "NoRewardDetails")
+		}
+		return nil, err
+	}
+	jso := details.ToJSON()
+	jso["address"] = address
+	return jso, nil
+}
+
+// Ex_estimateReward returns I-Score expected for a term with delegations and
+// bonds. Current delegations or bonds of the address are used if they are
+// not given.
+func (s *chainScore) Ex_estimateReward(address module.Address, delegations []interface{}, bonds []interface{}) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(true); err != nil {
+		return nil, err
+	}
+	es, err := s.getExtensionState()
+	if err != nil {
+		return nil, err
+	}
+	revision := s.cc.Revision().Value()
+	var ds icstate.Delegations
+	if delegations != nil {
+		if ds, err = icstate.NewDelegations(delegations, es.State.GetDelegationSlotMax()); err != nil {
+			return nil, err
+		}
+		if ds == nil {
+			ds = icstate.Delegations{}
+		}
+	}
+	var bs icstate.Bonds
+	if bonds != nil {
+		if bs, err = icstate.NewBonds(bonds, revision); err != nil {
+			return nil, err
+		}
+		if bs == nil {
+			bs = icstate.Bonds{}
+		}
+	}
+	var dsaMask int64
+	if revision >= icmodule.RevisionBTP2 {
+		if bc := s.newCallContext(s.cc).GetBTPContext(); bc != nil {
+			dsaMask = bc.GetActiveDSAMask()
+		}
+	}
+	p, err := es.ProjectReward(address, ds, bs, dsaMask)
+	if err != nil {
+		if errors.InvalidStateError.Equals(err) {
+			return nil, scoreresult.InvalidRequestError.Wrap(err, "NotAvailable")
+		}
+		return nil, err
+	}
+	jso := p.ToJSON()
+	jso["address"] = address
+	return jso, nil
+}
+
 func (s *chainScore) Ex_estimateUnstakeLockPeriod() (map[string]interface{}, error) {
 	if err := s.tryChargeCall(true); err != nil {
 		return nil, err
@@ -588,6 +661,70 @@ func (s *chainScore) Ex_getPRepStatsOf(address module.Address) (map[string]inter
 	return es.State.GetPRepStatsOfInJSON(s.cc.Revision().Value(), s.cc.BlockHeight(), address)
 }
 
+func (s *chainScore) Ex_getPenaltyHistoryOf(address module.Address) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(true); err != nil {
+		return nil, err
+	}
+	es, err := s.getExtensionState()
+	if err != nil {
+		return nil, err
+	}
+	return es.State.GetPenaltyHistoryInJSON(s.cc.BlockHeight(), address)
+}
+
+// Ex_getUnlockSchedule returns the amount of unstakes and unbonds expiring at
+// each height in the range. It looks up timers of every height in the range
+// if address is not specified, so it's allowed only for queries.
+func (s *chainScore) Ex_getUnlockSchedule(
+	startBlockHeight, endBlockHeight *common.HexInt, address module.Address,
+) (map[string]interface{}, error) {
+	if err := s.tryChargeCall(true); err != nil {
+		return nil, err
+	}
+	if err := s.checkQueryMode(); err != nil {
+		return nil, err
+	}
+	blockHeight := s.cc.BlockHeight()
+	start := blockHeight + 1
+	if startBlockHeight != nil {
+		start = startBlockHeight.Int64()
+	}
+	end := start + icstate.MaxUnlockScheduleRange - 1
+	if endBlockHeight != nil {
+		end = endBlockHeight.Int64()
+	}
+	es, err := s.getExtensionState()
+	if err != nil {
+		return nil, err
+	}
+	jso, err := es.State.GetUnlockScheduleInJSON(blockHeight, address, start, end)
+	if err != nil {
+		if errors.IllegalArgumentError.Equals(err) {
+			return nil, scoreresult.InvalidParameterError.Wrap(err, "InvalidRange")
+		}
+		return nil, err
+	}
+	return jso, nil
+}
+
+func (s *chainScore) Ex_getValidatorsPreview() (map[string]interface{}, error) {
+	if err := s.tryChargeCall(true); err != nil {
+		return nil, err
+	}
+	es, err := s.getExtensionState()
+	if err != nil {
+		return nil, err
+	}
+	jso, err := es.GetValidatorsPreviewInJSON(s.newCallContext(s.cc))
+	if err != nil {
+		if errors.InvalidStateError.Equals(err) {
+			return nil, scoreresult.InvalidRequestError.Wrap(err, "NotAvailable")
+		}
+		return nil, err
+	}
+	return jso, nil
+}
+
 func (s *chainScore) Ex_disqualifyPRep(address module.Address) error {
 	if err := s.checkGovernance(true); err != nil {
 		return err
@@ -673,6 +810,9 @@ func (s *chainScore) Ex_setRewardFund(iglobal *common.HexInt) error {
 		return err
 	}
 	rf := es.State.GetRewardFund()
+	if err = s.recordParamChange(ParamRewardFundIglobal, rf.Iglobal, iglobal.Value()); err != nil {
+		return err
+	}
 	rf.Iglobal = iglobal.Value()
 	return es.State.SetRewardFund(rf)
 }
@@ -686,6 +826,20 @@ func (s *chainScore) Ex_setRewardFundAllocation(iprep *common.HexInt, icps *comm
 		return err
 	}
 	rf := es.State.GetRewardFund()
+	changes := []struct {
+		name     string
+		old, new *big.Int
+	}{
+		{ParamRewardFundIprep, rf.Iprep, iprep.Value()},
+		{ParamRewardFundIcps, rf.Icps, icps.Value()},
+		{ParamRewardFundIrelay, rf.Irelay, irelay.Value()},
+		{ParamRewardFundIvoter, rf.Ivoter, ivoter.Value()},
+	}
+	for _, c := range changes {
+		if err = s.recordParamChange(c.name, c.old, c.new); err != nil {
+			return err
+		}
+	}
 	rf.Iprep = &iprep.Int
 	rf.Icps = &icps.Int
 	rf.Irelay = &irelay.Int
@@ -802,12 +956,18 @@ func (s *chainScore) Ex_setConsistentValidationSlashingRate(slashingRate *common
 	if err != nil {
 		return err
 	}
+	oldRate := es.State.GetConsistentValidationPenaltySlashRatio()
 	if err = es.State.SetConsistentValidationPenaltySlashRatio(int(slashingRate.Int64())); err != nil {
 		if errors.IllegalArgumentError.Equals(err) {
 			return icmodule.IllegalArgumentError.Errorf("Invalid range")
 		}
 		return err
 	}
+	if err = s.recordParamChange(
+		ParamSlashingRatePrefix+"ConsistentValidationPenalty", big.NewInt(int64(oldRate)), slashingRate.Value(),
+	); err != nil {
+		return err
+	}
 	s.onSlashingRateChangedEvent("ConsistentValidationPenalty", slashingRate.Int64())
 	return nil
 }
@@ -823,12 +983,18 @@ func (s *chainScore) Ex_setNonVoteSlashingRate(slashingRate *common.HexInt) erro
 	if err != nil {
 		return err
 	}
+	oldRate := es.State.GetNonVotePenaltySlashRatio()
 	if err = es.State.SetNonVotePenaltySlashRatio(int(slashingRate.Int64())); err != nil {
 		if errors.IllegalArgumentError.Equals(err) {
 			return icmodule.IllegalArgumentError.Errorf("Invalid range")
 		}
 		return err
 	}
+	if err = s.recordParamChange(
+		ParamSlashingRatePrefix+"NonVotePenalty", big.NewInt(int64(oldRate)), slashingRate.Value(),
+	); err != nil {
+		return err
+	}
 	s.onSlashingRateChangedEvent("NonVotePenalty", slashingRate.Int64())
 	return nil
 }
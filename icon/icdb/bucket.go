@@ -26,4 +26,8 @@ const (
 	// BlockMerkle basically maps node hash to block merkle node for v1 block.
 	// In addition, it also has merkleTreeData.
 	BlockMerkle db.BucketID = "H"
+
+	// RewardDetail keeps the breakdown of I-Score rewards for each term.
+	// It's local data written by the calculator, not a part of the state.
+	RewardDetail db.BucketID = "R"
 )
@@ -26,6 +26,7 @@ type WorldContext interface {
 	GetScoreOwner(score module.Address) (module.Address, error)
 	SetScoreOwner(from module.Address, score module.Address, owner module.Address) error
 	GetBTPContext() state.BTPContext
+	GetBTPState() state.BTPState
 }
 
 type CallContext interface {
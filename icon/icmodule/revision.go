@@ -41,13 +41,15 @@ const (
 	Revision20
 	Revision21
 	Revision22
+	Revision23
+	Revision24
 	RevisionReserved
 )
 
 const (
 	DefaultRevision = Revision1
 	MaxRevision     = RevisionReserved - 1
-	LatestRevision  = Revision22
+	LatestRevision  = Revision24
 )
 
 const (
@@ -108,6 +110,16 @@ const (
 	RevisionBTP2 = Revision21
 
 	RevisionUpdatePRepStats = Revision22
+
+	RevisionBTPMultipleDSA = Revision23
+
+	RevisionRewardDetails     = Revision24
+	RevisionRewardProjection  = Revision24
+	RevisionPenaltyHistory    = Revision24
+	RevisionUnlockSchedule    = Revision24
+	RevisionParameterHistory  = Revision24
+	RevisionNodeKeyRotation   = Revision24
+	RevisionValidatorsPreview = Revision24
 )
 
 var revisionFlags = []module.Revision{
@@ -157,6 +169,10 @@ var revisionFlags = []module.Revision{
 	module.MultipleFeePayers,
 	// Revision22
 	0,
+	// Revision23
+	0,
+	// Revision24
+	0,
 }
 
 func init() {
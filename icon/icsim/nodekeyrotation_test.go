@@ -0,0 +1,139 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/module"
+)
+
+const ed25519DSA = "eddsa/ed25519"
+
+func newNodeKey() (module.Address, []byte) {
+	_, pk := crypto.GenerateKeyPair()
+	return common.NewAccountAddressFromPublicKey(pk), pk.SerializeCompressed()
+}
+
+func hasValidator(vl []module.Validator, node module.Address) bool {
+	for _, v := range vl {
+		if v.Address().Equal(node) {
+			return true
+		}
+	}
+	return false
+}
+
+func Test_NodeKeyRotation(t *testing.T) {
+	var err error
+	var csi module.ConsensusInfo
+	var receipts []Receipt
+
+	c := NewConfig()
+	c.TermPeriod = 100
+	env := initEnv(t, c, icmodule.Revision13)
+	sim := env.sim
+
+	receipts, err = sim.GoByTransaction(sim.SetRevision(icmodule.RevisionNodeKeyRotation), csi)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+
+	// The main P-Rep uses the old node with public keys of two DSAs
+	owner := env.preps[0]
+	oldNode, oldKey := newNodeKey()
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	block := NewBlock()
+	block.AddTransaction(sim.SetPRepNodeKey(owner, oldKey))
+	block.AddTransaction(sim.SetBTPPublicKey(owner, icstate.ConsensusDSA, oldKey))
+	block.AddTransaction(sim.SetBTPPublicKey(owner, ed25519DSA, edKey))
+	receipts, err = sim.ExecuteBlock(block, csi)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+	assert.True(t, hasValidator(sim.ValidatorList(), oldNode))
+	dsaMask := sim.GetPRep(owner).GetDSAMask()
+	assert.NotZero(t, dsaMask)
+
+	// Schedule the rotation with the new consensus key only
+	newNode, newKey := newNodeKey()
+	activationHeight := sim.BlockHeight() + 5
+	r := icstate.NewNodeKeyRotation(activationHeight, newNode, []*icstate.NodePublicKey{
+		icstate.NewNodePublicKey(icstate.ConsensusDSA, newKey),
+	})
+	receipts, err = sim.GoByTransaction(sim.SetPRepNodeKeyRotation(owner, r), csi)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+
+	// The old node is used until the activation
+	err = sim.GoTo(activationHeight-1, csi)
+	assert.NoError(t, err)
+	vl := sim.ValidatorList()
+	assert.True(t, hasValidator(vl, oldNode))
+	assert.False(t, hasValidator(vl, newNode))
+	assert.True(t, oldNode.Equal(sim.GetPRep(owner).NodeAddress()))
+
+	// The new node replaces the old one from the next block of the activation
+	err = sim.Go(1, csi)
+	assert.NoError(t, err)
+	vl = sim.ValidatorList()
+	assert.False(t, hasValidator(vl, oldNode))
+	assert.True(t, hasValidator(vl, newNode))
+	assert.Equal(t, int(c.MainPRepCount), len(vl))
+
+	prep := sim.GetPRep(owner)
+	assert.True(t, newNode.Equal(prep.NodeAddress()))
+	assert.Equal(t, dsaMask, prep.GetDSAMask())
+	assert.Equal(t, newKey, sim.GetBTPPublicKey(newNode, icstate.ConsensusDSA))
+	assert.Equal(t, []byte(edKey), sim.GetBTPPublicKey(newNode, ed25519DSA))
+	assert.Nil(t, sim.GetBTPPublicKey(oldNode, icstate.ConsensusDSA))
+	assert.Nil(t, sim.GetBTPPublicKey(oldNode, ed25519DSA))
+
+	// The rotation keeping the node should change public keys
+	r = icstate.NewNodeKeyRotation(sim.BlockHeight()+5, newNode, []*icstate.NodePublicKey{
+		icstate.NewNodePublicKey(icstate.ConsensusDSA, newKey),
+	})
+	receipts, err = sim.GoByTransaction(sim.SetPRepNodeKeyRotation(owner, r), csi)
+	assert.NoError(t, err)
+	assert.False(t, checkReceipts(receipts))
+
+	// The rotation keeping the node with a new key of other DSA
+	edKey2, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	activationHeight = sim.BlockHeight() + 5
+	r = icstate.NewNodeKeyRotation(activationHeight, newNode, []*icstate.NodePublicKey{
+		icstate.NewNodePublicKey(icstate.ConsensusDSA, newKey),
+		icstate.NewNodePublicKey(ed25519DSA, edKey2),
+	})
+	receipts, err = sim.GoByTransaction(sim.SetPRepNodeKeyRotation(owner, r), csi)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+
+	err = sim.GoTo(activationHeight+1, csi)
+	assert.NoError(t, err)
+	assert.True(t, hasValidator(sim.ValidatorList(), newNode))
+	assert.True(t, newNode.Equal(sim.GetPRep(owner).NodeAddress()))
+	assert.Equal(t, newKey, sim.GetBTPPublicKey(newNode, icstate.ConsensusDSA))
+	assert.Equal(t, []byte(edKey2), sim.GetBTPPublicKey(newNode, ed25519DSA))
+}
@@ -0,0 +1,647 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// Scenario describes a simulation with accounts, blocks and expectations.
+// It can be written in YAML or JSON.
+//
+// Amounts are integers in loop, or decimals followed by "icx".
+// Expected values may have one of comparison operators(=, !=, <, <=, >, >=)
+// as a prefix. Accounts are referred by their names or addresses.
+type Scenario struct {
+	Name       string             `yaml:"name"`
+	Revision   int                `yaml:"revision"`
+	Config     yaml.Node          `yaml:"config"`
+	Accounts   []*ScenarioAccount `yaml:"accounts"`
+	Validators []string           `yaml:"validators"`
+	Steps      []*ScenarioStep    `yaml:"steps"`
+}
+
+type ScenarioAccount struct {
+	Name    string `yaml:"name"`
+	Address string `yaml:"address"`
+	Balance string `yaml:"balance"`
+}
+
+// ScenarioStep makes a block with Txs if there is any, then makes Blocks
+// empty blocks, blocks up to GoTo and blocks up to the end of the term
+// in order if they are specified. Expectations are checked at last.
+// Validators in NonVoters don't vote for the empty blocks.
+type ScenarioStep struct {
+	Name        string                 `yaml:"name"`
+	Txs         []*ScenarioTx          `yaml:"txs"`
+	Blocks      int64                  `yaml:"blocks"`
+	GoTo        int64                  `yaml:"goTo"`
+	GoToTermEnd bool                   `yaml:"goToTermEnd"`
+	NonVoters   []string               `yaml:"nonVoters"`
+	Expect      []*ScenarioExpectation `yaml:"expect"`
+}
+
+type ScenarioVote struct {
+	To     string `yaml:"to"`
+	Amount string `yaml:"amount"`
+}
+
+type ScenarioPRepInfo struct {
+	Name     *string `yaml:"name"`
+	Email    *string `yaml:"email"`
+	Website  *string `yaml:"website"`
+	Details  *string `yaml:"details"`
+	Endpoint *string `yaml:"endpoint"`
+	City     *string `yaml:"city"`
+	Country  *string `yaml:"country"`
+	Node     string  `yaml:"node"`
+}
+
+type ScenarioTx struct {
+	Type        string            `yaml:"type"`
+	From        string            `yaml:"from"`
+	Amount      string            `yaml:"amount"`
+	Delegations []*ScenarioVote   `yaml:"delegations"`
+	Bonds       []*ScenarioVote   `yaml:"bonds"`
+	Bonders     []string          `yaml:"bonders"`
+	PRep        *ScenarioPRepInfo `yaml:"prep"`
+	Address     string            `yaml:"address"`
+	PReps       []string          `yaml:"preps"`
+	Revision    int               `yaml:"revision"`
+	PubKey      string            `yaml:"pubKey"`
+	Rates       map[string]int    `yaml:"rates"`
+	Height      int64             `yaml:"height"`
+	Fail        bool              `yaml:"fail"`
+}
+
+type ScenarioExpectation struct {
+	Account   string `yaml:"account"`
+	Balance   string `yaml:"balance"`
+	Stake     string `yaml:"stake"`
+	IScore    string `yaml:"iscore"`
+	Delegated string `yaml:"delegated"`
+	Bonded    string `yaml:"bonded"`
+	Grade     string `yaml:"grade"`
+	Status    string `yaml:"status"`
+	Penalties string `yaml:"penalties"`
+	Timer     int64  `yaml:"timer"`
+}
+
+func ParseScenario(bs []byte) (*Scenario, error) {
+	sc := new(Scenario)
+	if err := yaml.Unmarshal(bs, sc); err != nil {
+		return nil, errors.IllegalArgumentError.Wrap(err, "InvalidScenario")
+	}
+	return sc, nil
+}
+
+func LoadScenario(path string) (*Scenario, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseScenario(bs)
+}
+
+func parseAmount(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if v := strings.TrimSuffix(s, "icx"); v != s {
+		r, ok := new(big.Rat).SetString(strings.TrimSpace(v))
+		if !ok {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidAmount(%s)", s)
+		}
+		r.Mul(r, new(big.Rat).SetInt(icmodule.BigIntICX))
+		if !r.IsInt() {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidAmount(%s)", s)
+		}
+		return r.Num(), nil
+	}
+	v := new(big.Int)
+	if err := intconv.ParseBigInt(v, s); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidAmount(%s)", s)
+	}
+	return v, nil
+}
+
+// compareValue checks whether the value satisfies the expression like ">=10".
+func compareValue(expr string, value *big.Int) (bool, error) {
+	expr = strings.TrimSpace(expr)
+	op := "="
+	for _, o := range []string{"!=", ">=", "<=", "=", ">", "<"} {
+		if strings.HasPrefix(expr, o) {
+			op = o
+			expr = expr[len(o):]
+			break
+		}
+	}
+	exp, err := parseAmount(expr)
+	if err != nil {
+		return false, err
+	}
+	c := value.Cmp(exp)
+	switch op {
+	case "!=":
+		return c != 0, nil
+	case ">=":
+		return c >= 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case "<":
+		return c < 0, nil
+	default:
+		return c == 0, nil
+	}
+}
+
+func gradeName(g icstate.Grade) string {
+	switch g {
+	case icstate.GradeMain:
+		return "main"
+	case icstate.GradeSub:
+		return "sub"
+	case icstate.GradeCandidate:
+		return "candidate"
+	default:
+		return "none"
+	}
+}
+
+func statusName(s icstate.Status) string {
+	switch s {
+	case icstate.Active:
+		return "active"
+	case icstate.Unregistered:
+		return "unregistered"
+	case icstate.Disqualified:
+		return "disqualified"
+	default:
+		return "notReady"
+	}
+}
+
+type scenarioRunner struct {
+	sc       *Scenario
+	sim      Simulator
+	out      io.Writer
+	accounts map[string]module.Address
+	preps    int
+	failures int
+}
+
+func (r *scenarioRunner) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(r.out, format, args...)
+}
+
+func (r *scenarioRunner) fail(format string, args ...interface{}) {
+	r.failures++
+	r.printf("  FAIL "+format+"\n", args...)
+}
+
+func (r *scenarioRunner) address(ref string) (module.Address, error) {
+	if addr, ok := r.accounts[ref]; ok {
+		return addr, nil
+	}
+	addr, err := common.NewAddressFromString(ref)
+	if err != nil {
+		return nil, errors.IllegalArgumentError.Errorf("UnknownAccount(%s)", ref)
+	}
+	return addr, nil
+}
+
+func (r *scenarioRunner) addresses(refs []string) ([]module.Address, error) {
+	addrs := make([]module.Address, len(refs))
+	for i, ref := range refs {
+		addr, err := r.address(ref)
+		if err != nil {
+			return nil, err
+		}
+		addrs[i] = addr
+	}
+	return addrs, nil
+}
+
+// nameToAddress makes an address for the account without address, so that
+// the same name always has the same address.
+func nameToAddress(name string) module.Address {
+	return common.NewAccountAddress(crypto.SHA3Sum256([]byte(name))[:common.AddressIDBytes])
+}
+
+func (r *scenarioRunner) init() error {
+	sc := r.sc
+	c := NewConfig()
+	if !sc.Config.IsZero() {
+		if err := sc.Config.Decode(c); err != nil {
+			return errors.IllegalArgumentError.Wrap(err, "InvalidConfig")
+		}
+	}
+
+	balances := make(map[string]*big.Int)
+	for _, a := range sc.Accounts {
+		var addr module.Address
+		if a.Address != "" {
+			var err error
+			if addr, err = common.NewAddressFromString(a.Address); err != nil {
+				return errors.IllegalArgumentError.Wrapf(err, "InvalidAddress(%s)", a.Address)
+			}
+		} else if a.Name != "" {
+			addr = nameToAddress(a.Name)
+		} else {
+			return errors.IllegalArgumentError.New("AccountWithoutNameAndAddress")
+		}
+		if a.Name != "" {
+			if _, ok := r.accounts[a.Name]; ok {
+				return errors.IllegalArgumentError.Errorf("DuplicateAccount(%s)", a.Name)
+			}
+			r.accounts[a.Name] = addr
+		}
+		if a.Balance != "" {
+			balance, err := parseAmount(a.Balance)
+			if err != nil {
+				return err
+			}
+			balances[icutils.ToKey(addr)] = balance
+		}
+	}
+
+	var validators []module.Validator
+	if len(sc.Validators) > 0 {
+		addrs, err := r.addresses(sc.Validators)
+		if err != nil {
+			return err
+		}
+		for _, addr := range addrs {
+			v, err := state.ValidatorFromAddress(addr)
+			if err != nil {
+				return err
+			}
+			validators = append(validators, v)
+		}
+	} else {
+		for i := 0; i < int(c.MainPRepCount); i++ {
+			v, _ := state.ValidatorFromAddress(newDummyAddress(4000 + i))
+			validators = append(validators, v)
+		}
+	}
+
+	revision := sc.Revision
+	if revision == 0 {
+		revision = icmodule.LatestRevision
+	}
+	r.sim = NewSimulator(icmodule.ValueToRevision(revision), validators, balances, c)
+	if r.sim == nil {
+		return errors.InvalidStateError.New("FailedToInitializeSimulator")
+	}
+	return nil
+}
+
+func (r *scenarioRunner) prepInfo(p *ScenarioPRepInfo, register bool) (*icstate.PRepInfo, error) {
+	info := new(icstate.PRepInfo)
+	if register {
+		info = newDummyPRepInfo(r.preps)
+	}
+	if p == nil {
+		return info, nil
+	}
+	for _, f := range []struct {
+		dst **string
+		src *string
+	}{
+		{&info.Name, p.Name},
+		{&info.Email, p.Email},
+		{&info.WebSite, p.Website},
+		{&info.Details, p.Details},
+		{&info.P2PEndpoint, p.Endpoint},
+		{&info.City, p.City},
+		{&info.Country, p.Country},
+	} {
+		if f.src != nil {
+			*f.dst = f.src
+		}
+	}
+	if p.Node != "" {
+		node, err := r.address(p.Node)
+		if err != nil {
+			return nil, err
+		}
+		info.Node = node
+	}
+	return info, nil
+}
+
+func (r *scenarioRunner) votes(vs []*ScenarioVote) ([]*common.Address, []*big.Int, error) {
+	addrs := make([]*common.Address, len(vs))
+	amounts := make([]*big.Int, len(vs))
+	for i, v := range vs {
+		addr, err := r.address(v.To)
+		if err != nil {
+			return nil, nil, err
+		}
+		amount, err := parseAmount(v.Amount)
+		if err != nil {
+			return nil, nil, err
+		}
+		addrs[i] = common.AddressToPtr(addr)
+		amounts[i] = amount
+	}
+	return addrs, amounts, nil
+}
+
+func (r *scenarioRunner) transaction(tx *ScenarioTx) (Transaction, error) {
+	sim := r.sim
+	var from module.Address
+	if tx.From != "" {
+		var err error
+		if from, err = r.address(tx.From); err != nil {
+			return nil, err
+		}
+	} else if tx.Type != "setRevision" {
+		return nil, errors.IllegalArgumentError.Errorf("NoSender(type=%s)", tx.Type)
+	}
+
+	switch tx.Type {
+	case "setStake":
+		amount, err := parseAmount(tx.Amount)
+		if err != nil {
+			return nil, err
+		}
+		return sim.SetStake(from, amount), nil
+	case "setDelegation":
+		addrs, amounts, err := r.votes(tx.Delegations)
+		if err != nil {
+			return nil, err
+		}
+		ds := make(icstate.Delegations, len(addrs))
+		for i := range addrs {
+			ds[i] = icstate.NewDelegation(addrs[i], amounts[i])
+		}
+		return sim.SetDelegation(from, ds), nil
+	case "setBond":
+		addrs, amounts, err := r.votes(tx.Bonds)
+		if err != nil {
+			return nil, err
+		}
+		bonds := make(icstate.Bonds, len(addrs))
+		for i := range addrs {
+			bonds[i] = icstate.NewBond(addrs[i], amounts[i])
+		}
+		return sim.SetBond(from, bonds), nil
+	case "setBonderList":
+		addrs, err := r.addresses(tx.Bonders)
+		if err != nil {
+			return nil, err
+		}
+		bl := make(icstate.BonderList, len(addrs))
+		for i, addr := range addrs {
+			bl[i] = common.AddressToPtr(addr)
+		}
+		return sim.SetBonderList(from, bl), nil
+	case "registerPRep":
+		info, err := r.prepInfo(tx.PRep, true)
+		if err != nil {
+			return nil, err
+		}
+		r.preps++
+		return sim.RegisterPRep(from, info), nil
+	case "setPRep":
+		info, err := r.prepInfo(tx.PRep, false)
+		if err != nil {
+			return nil, err
+		}
+		return sim.SetPRep(from, info), nil
+	case "unregisterPRep":
+		return sim.UnregisterPRep(from), nil
+	case "disqualifyPRep":
+		addr, err := r.address(tx.Address)
+		if err != nil {
+			return nil, err
+		}
+		return sim.DisqualifyPRep(from, addr), nil
+	case "setRevision":
+		return sim.SetRevision(icmodule.ValueToRevision(tx.Revision)), nil
+	case "claimIScore":
+		return sim.ClaimIScore(from), nil
+	case "setPRepNodeKey":
+		pubKey, err := hex.DecodeString(strings.TrimPrefix(tx.PubKey, "0x"))
+		if err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidPubKey(%s)", tx.PubKey)
+		}
+		return sim.SetPRepNodeKey(from, pubKey), nil
+	case "penalizeNonVoters":
+		preps, err := r.addresses(tx.PReps)
+		if err != nil {
+			return nil, err
+		}
+		return sim.PenalizeNonVoters(from, preps), nil
+	case "setSlashingRates":
+		return sim.SetSlashingRates(from, tx.Rates), nil
+	case "addTimer":
+		return sim.AddTimer(from, tx.Height), nil
+	case "removeTimer":
+		return sim.RemoveTimer(from, tx.Height), nil
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("UnknownTxType(%s)", tx.Type)
+	}
+}
+
+// consensusInfo returns consensus information where the validators in
+// nonVoters didn't vote.
+func (r *scenarioRunner) consensusInfo(nonVoters []string) (module.ConsensusInfo, error) {
+	if len(nonVoters) == 0 {
+		return nil, nil
+	}
+	addrs, err := r.addresses(nonVoters)
+	if err != nil {
+		return nil, err
+	}
+	vl := r.sim.ValidatorList()
+	if len(vl) == 0 {
+		return nil, nil
+	}
+	vss, err := state.ValidatorSnapshotFromSlice(r.sim.Database(), vl)
+	if err != nil {
+		return nil, err
+	}
+	voted := make([]bool, len(vl))
+	for i, v := range vl {
+		voted[i] = true
+		for _, addr := range addrs {
+			if v.Address().Equal(addr) {
+				voted[i] = false
+				break
+			}
+		}
+	}
+	return common.NewConsensusInfo(vl[len(vl)-1].Address(), vss, voted), nil
+}
+
+func (r *scenarioRunner) runStep(step *ScenarioStep) error {
+	sim := r.sim
+	csi, err := r.consensusInfo(step.NonVoters)
+	if err != nil {
+		return err
+	}
+	if len(step.Txs) > 0 {
+		block := NewBlock()
+		for _, tx := range step.Txs {
+			t, err := r.transaction(tx)
+			if err != nil {
+				return err
+			}
+			block.AddTransaction(t)
+		}
+		receipts, err := sim.ExecuteBlock(block, csi)
+		if err != nil {
+			return err
+		}
+		for i, rct := range receipts {
+			tx := step.Txs[i]
+			if failed := rct.Status() != Success; failed != tx.Fail {
+				r.fail("tx[%d] %s from=%s expected fail=%t err=%v", i, tx.Type, tx.From, tx.Fail, rct.Error())
+			}
+		}
+	}
+
+	if step.Blocks > 0 {
+		if err = sim.Go(step.Blocks, csi); err != nil {
+			return err
+		}
+	}
+	if step.GoTo > 0 {
+		if err = sim.GoTo(step.GoTo, csi); err != nil {
+			return err
+		}
+	}
+	if step.GoToTermEnd {
+		if err = sim.GoToTermEnd(csi); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range step.Expect {
+		if err = r.check(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *scenarioRunner) checkValue(account, name, expr string, value *big.Int) error {
+	if expr == "" {
+		return nil
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+	ok, err := compareValue(expr, value)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		r.fail("%s %s: expected %s actual %s", account, name, expr, value)
+	}
+	return nil
+}
+
+func (r *scenarioRunner) check(e *ScenarioExpectation) error {
+	sim := r.sim
+	addr, err := r.address(e.Account)
+	if err != nil {
+		return err
+	}
+	name := e.Account
+	if err = r.checkValue(name, "balance", e.Balance, sim.GetBalance(addr)); err != nil {
+		return err
+	}
+	if e.Stake != "" {
+		stake, _ := sim.GetStake(addr)["stake"].(*big.Int)
+		if err = r.checkValue(name, "stake", e.Stake, stake); err != nil {
+			return err
+		}
+	}
+	if err = r.checkValue(name, "iscore", e.IScore, sim.QueryIScore(addr)); err != nil {
+		return err
+	}
+	if e.Timer > 0 && !sim.HasTimer(addr, e.Timer) {
+		r.fail("%s timer: expected at %d", name, e.Timer)
+	}
+
+	if e.Delegated == "" && e.Bonded == "" && e.Grade == "" && e.Status == "" && e.Penalties == "" {
+		return nil
+	}
+	prep := sim.GetPRep(addr)
+	if prep == nil {
+		r.fail("%s is not P-Rep", name)
+		return nil
+	}
+	if err = r.checkValue(name, "delegated", e.Delegated, prep.Delegated()); err != nil {
+		return err
+	}
+	if err = r.checkValue(name, "bonded", e.Bonded, prep.Bonded()); err != nil {
+		return err
+	}
+	if e.Grade != "" && !strings.EqualFold(e.Grade, gradeName(prep.Grade())) {
+		r.fail("%s grade: expected %s actual %s", name, e.Grade, gradeName(prep.Grade()))
+	}
+	if e.Status != "" && !strings.EqualFold(e.Status, statusName(prep.Status())) {
+		r.fail("%s status: expected %s actual %s", name, e.Status, statusName(prep.Status()))
+	}
+	return r.checkValue(name, "penalties", e.Penalties, big.NewInt(int64(prep.GetVPenaltyCount())))
+}
+
+// RunScenario runs the scenario printing progress to out, and returns the
+// number of failed expectations.
+func RunScenario(sc *Scenario, out io.Writer) (int, error) {
+	r := &scenarioRunner{
+		sc:       sc,
+		out:      out,
+		accounts: make(map[string]module.Address),
+	}
+	if err := r.init(); err != nil {
+		return 0, err
+	}
+	for i, step := range sc.Steps {
+		failures := r.failures
+		if err := r.runStep(step); err != nil {
+			return r.failures, errors.Wrapf(err, "Failed to run step %d(%s)", i, step.Name)
+		}
+		result := "OK"
+		if r.failures > failures {
+			result = "FAIL"
+		}
+		r.printf("[%s] step %d %s height=%d\n", result, i, step.Name, r.sim.BlockHeight())
+	}
+	return r.failures, nil
+}
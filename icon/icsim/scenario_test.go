@@ -0,0 +1,159 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icsim
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/icon/icmodule"
+)
+
+const basicScenario = `
+name: basic
+
+config:
+  termPeriod: 20
+  mainPRepCount: 2
+  subPRepCount: 2
+  validationPenaltyCondition: 2
+accounts:
+  - {name: prep1, balance: 3000icx}
+  - {name: prep2, balance: 3000icx}
+  - {name: user, balance: 10000icx}
+steps:
+  - name: genesis term
+    goToTermEnd: true
+  - name: register
+    txs:
+      - {type: registerPRep, from: prep1}
+      - {type: registerPRep, from: prep2}
+      - {type: setStake, from: prep1, amount: 500icx}
+      - {type: setStake, from: prep2, amount: 500icx}
+      - {type: setStake, from: user, amount: 5000icx}
+  - name: vote
+    txs:
+      - {type: setBonderList, from: prep1, bonders: [prep1]}
+      - {type: setBonderList, from: prep2, bonders: [prep2]}
+      - {type: setBond, from: prep1, bonds: [{to: prep1, amount: 100icx}]}
+      - {type: setBond, from: prep2, bonds: [{to: prep2, amount: 100icx}]}
+      - {type: setDelegation, from: user, delegations: [{to: prep1, amount: 2000icx}, {to: prep2, amount: 1000icx}]}
+      - {type: setDelegation, from: user, delegations: [{to: prep1, amount: 100000icx}], fail: true}
+  - name: decentralize
+    goToTermEnd: true
+  - goToTermEnd: true
+    expect:
+      - {account: prep1, grade: main, delegated: 2000icx, bonded: 100icx, status: active}
+      - {account: user, stake: 5000icx, balance: 10000icx}
+  - name: rewards
+    txs:
+      - {type: setDelegation, from: user, delegations: [{to: prep1, amount: 2500icx}]}
+    blocks: 100
+    expect:
+      - {account: user, iscore: ">0"}
+  - name: penalty
+    nonVoters: [prep2]
+    blocks: 3
+    expect:
+      - {account: prep2, penalties: 1, grade: candidate}
+`
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		value string
+		exp   *big.Int
+		ok    bool
+	}{
+		{"100", big.NewInt(100), true},
+		{"0x64", big.NewInt(100), true},
+		{"1icx", icmodule.BigIntICX, true},
+		{"1.5icx", new(big.Int).Div(new(big.Int).Mul(icmodule.BigIntICX, big.NewInt(3)), big.NewInt(2)), true},
+		{"abc", nil, false},
+		{"1.5", nil, false},
+	}
+	for _, c := range cases {
+		v, err := parseAmount(c.value)
+		if c.ok {
+			assert.NoError(t, err, c.value)
+			assert.Zero(t, c.exp.Cmp(v), c.value)
+		} else {
+			assert.Error(t, err, c.value)
+		}
+	}
+}
+
+func TestCompareValue(t *testing.T) {
+	value := big.NewInt(10)
+	cases := []struct {
+		expr string
+		exp  bool
+	}{
+		{"10", true},
+		{"=10", true},
+		{"!=10", false},
+		{">0", true},
+		{">10", false},
+		{">=10", true},
+		{"<10", false},
+		{"<=10", true},
+		{"0.00000000000000001icx", true},
+	}
+	for _, c := range cases {
+		ok, err := compareValue(c.expr, value)
+		assert.NoError(t, err, c.expr)
+		assert.Equal(t, c.exp, ok, c.expr)
+	}
+}
+
+func TestRunScenario(t *testing.T) {
+	sc, err := ParseScenario([]byte(basicScenario))
+	assert.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	failures, err := RunScenario(sc, out)
+	assert.NoError(t, err)
+	assert.Zero(t, failures, out.String())
+	assert.Contains(t, out.String(), "[OK] step 6 penalty")
+}
+
+func TestRunScenario_Failure(t *testing.T) {
+	sc, err := ParseScenario([]byte(`
+name: failure
+accounts:
+  - {name: user, balance: 100icx}
+steps:
+  - name: stake
+    txs:
+      - {type: setStake, from: user, amount: 10icx}
+    expect:
+      - {account: user, stake: 20icx}
+      - {account: user, balance: 100icx}
+  - name: invalid tx
+    txs:
+      - {type: unknownTx, from: user}
+`))
+	assert.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	failures, err := RunScenario(sc, out)
+	assert.Error(t, err)
+	assert.Equal(t, 1, failures)
+	assert.Contains(t, out.String(), "[FAIL] step 0 stake")
+}
@@ -36,6 +36,13 @@ const (
 	TypeSetPRep
 	TypeSetRevision
 	TypeClaimIScore
+	TypeSetPRepNodeKey
+	TypePenalizeNonVoters
+	TypeSetSlashingRates
+	TypeAddTimer
+	TypeRemoveTimer
+	TypeSetBTPPublicKey
+	TypeSetPRepNodeKeyRotation
 )
 
 type Transaction interface {
@@ -87,6 +94,7 @@ type Simulator interface {
 	GoTo(blockHeight int64, csi module.ConsensusInfo) error
 	GoToTermEnd(csi module.ConsensusInfo) error
 	GoByBlock(block Block, csi module.ConsensusInfo) ([]Receipt, error)
+	ExecuteBlock(block Block, csi module.ConsensusInfo) ([]Receipt, error)
 	GoByTransaction(tx Transaction, csi module.ConsensusInfo) ([]Receipt, error)
 
 	SetRevision(revision module.Revision) Transaction
@@ -98,6 +106,7 @@ type Simulator interface {
 	ClaimIScore(from module.Address) Transaction
 
 	GetPRepStats(address module.Address) map[string]interface{}
+	GetPenaltyHistory(address module.Address) []*icstate.PenaltyRecord
 	GetPRep(address module.Address) *icstate.PRep
 	SetPRep(from module.Address, info *icstate.PRepInfo) Transaction
 
@@ -113,4 +122,14 @@ type Simulator interface {
 	RegisterPRep(from module.Address, info *icstate.PRepInfo) Transaction
 	UnregisterPRep(from module.Address) Transaction
 	DisqualifyPRep(from module.Address, address module.Address) Transaction
+	SetPRepNodeKey(from module.Address, pubKey []byte) Transaction
+	SetPRepNodeKeyRotation(from module.Address, r *icstate.NodeKeyRotation) Transaction
+	GetBTPPublicKey(node module.Address, dsa string) []byte
+	SetBTPPublicKey(from module.Address, dsa string, pubKey []byte) Transaction
+	PenalizeNonVoters(from module.Address, preps []module.Address) Transaction
+	SetSlashingRates(from module.Address, rates map[string]int) Transaction
+
+	HasTimer(address module.Address, blockHeight int64) bool
+	AddTimer(from module.Address, blockHeight int64) Transaction
+	RemoveTimer(from module.Address, blockHeight int64) Transaction
 }
@@ -721,3 +721,121 @@ func TestSimulator_ReplaceBondedDelegationWithPower(t *testing.T) {
 	_, ok = jso["totalPower"].(*big.Int)
 	assert.True(t, ok)
 }
+
+func setRevisionAndGoToTermEnd(t *testing.T, sim Simulator, revision module.Revision, voted []bool) {
+	csi := newConsensusInfo(sim.Database(), sim.ValidatorList(), voted)
+	receipts, err := sim.GoByTransaction(sim.SetRevision(revision), csi)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+	err = sim.GoToTermEnd(csi)
+	assert.NoError(t, err)
+}
+
+func TestSimulator_PenaltyHistory(t *testing.T) {
+	const (
+		termPeriod                           = 100
+		mainPRepCount                        = 22
+		validationPenaltyCondition           = 5
+		consistentValidationPenaltyCondition = 1
+		slashRatio                           = 10
+	)
+
+	var receipts []Receipt
+
+	c := NewConfig()
+	c.MainPRepCount = mainPRepCount
+	c.TermPeriod = termPeriod
+	c.ValidationPenaltyCondition = validationPenaltyCondition
+	c.ConsistentValidationPenaltyCondition = consistentValidationPenaltyCondition
+	c.ConsistentValidationPenaltySlashRatio = slashRatio
+	c.BondedPRepCount = mainPRepCount
+
+	voted := make([]bool, mainPRepCount)
+	for i := 0; i < len(voted); i++ {
+		voted[i] = true
+	}
+
+	env := initEnv(t, c, icmodule.Revision13)
+	sim := env.sim
+	setRevisionAndGoToTermEnd(t, sim, icmodule.RevisionPenaltyHistory, voted)
+	for _, prep := range env.preps {
+		assert.Zero(t, len(sim.GetPenaltyHistory(prep)))
+	}
+
+	// prep0 is penalized for block validation and its bond is slashed
+	var err error
+	oldBonded := sim.GetPRep(env.preps[0]).Bonded()
+	vl := sim.ValidatorList()
+	voted[0] = false
+	csi := newConsensusInfo(sim.Database(), vl, voted)
+	err = sim.Go(validationPenaltyCondition, csi)
+	assert.NoError(t, err)
+	blockHeight := sim.BlockHeight()
+
+	history := sim.GetPenaltyHistory(env.preps[0])
+	assert.Equal(t, 1, len(history))
+	record := history[0]
+	assert.Equal(t, blockHeight, record.Height())
+	assert.Equal(t, icmodule.PenaltyBlockValidation, record.Penalty())
+	assert.Equal(t, icstate.GradeCandidate, record.Grade())
+	assert.Equal(t, icstate.Active, record.Status())
+	assert.Equal(t, 1, len(record.Slashes()))
+	slashed := new(big.Int).Sub(oldBonded, sim.GetPRep(env.preps[0]).Bonded())
+	assert.True(t, slashed.Sign() > 0)
+	assert.True(t, record.Slashes()[0].Bonder().Equal(env.bonders[0]))
+	assert.Zero(t, slashed.Cmp(record.Slashes()[0].Bond()))
+	assert.Zero(t, record.Slashes()[0].Unbond().Sign())
+
+	// prep1 is penalized for not voting without slashing
+	vl = sim.ValidatorList()
+	voted[0] = true
+	csi = newConsensusInfo(sim.Database(), vl, voted)
+	tx := sim.PenalizeNonVoters(env.preps[0], []module.Address{env.preps[1]})
+	receipts, err = sim.GoByTransaction(tx, csi)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+
+	history = sim.GetPenaltyHistory(env.preps[1])
+	assert.Equal(t, 1, len(history))
+	assert.Equal(t, icmodule.PenaltyNonVote, history[0].Penalty())
+	assert.Zero(t, len(history[0].Slashes()))
+
+	// prep2 is disqualified
+	tx = sim.DisqualifyPRep(env.preps[0], env.preps[2])
+	receipts, err = sim.GoByTransaction(tx, csi)
+	assert.NoError(t, err)
+	assert.True(t, checkReceipts(receipts))
+
+	history = sim.GetPenaltyHistory(env.preps[2])
+	assert.Equal(t, 1, len(history))
+	assert.Equal(t, icmodule.PenaltyPRepDisqualification, history[0].Penalty())
+	assert.Equal(t, icstate.Disqualified, history[0].Status())
+
+	// the history of prep0 is kept
+	assert.Equal(t, 1, len(sim.GetPenaltyHistory(env.preps[0])))
+}
+
+func TestSimulator_PenaltyHistoryIsNotRecordedBeforeRevision(t *testing.T) {
+	c := NewConfig()
+	c.MainPRepCount = 22
+	c.TermPeriod = 100
+	c.ValidationPenaltyCondition = 5
+	c.BondedPRepCount = 22
+
+	voted := make([]bool, c.MainPRepCount)
+	for i := 0; i < len(voted); i++ {
+		voted[i] = true
+	}
+
+	env := initEnv(t, c, icmodule.Revision13)
+	sim := env.sim
+	setRevisionAndGoToTermEnd(t, sim, icmodule.RevisionPenaltyHistory-1, voted)
+
+	voted[0] = false
+	csi := newConsensusInfo(sim.Database(), sim.ValidatorList(), voted)
+	err := sim.Go(5, csi)
+	assert.NoError(t, err)
+
+	assert.Equal(t, icstate.GradeCandidate, sim.GetPRep(env.preps[0]).Grade())
+	assert.Zero(t, len(sim.GetPenaltyHistory(env.preps[0])))
+}
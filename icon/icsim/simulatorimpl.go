@@ -20,6 +20,7 @@ import (
 	"math/big"
 
 	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
@@ -300,7 +301,19 @@ func (sim *simulatorImpl) GoToTermEnd(csi module.ConsensusInfo) error {
 	return sim.Go(blocks, csi)
 }
 
+// GoByBlock generates a block including transactions in a given block
+// without handling the beginning and the end of the block.
 func (sim *simulatorImpl) GoByBlock(block Block, csi module.ConsensusInfo) ([]Receipt, error) {
+	return sim.goByBlock(block, csi, false)
+}
+
+// ExecuteBlock generates a block including transactions in a given block
+// like Go, handling the beginning and the end of the block.
+func (sim *simulatorImpl) ExecuteBlock(block Block, csi module.ConsensusInfo) ([]Receipt, error) {
+	return sim.goByBlock(block, csi, true)
+}
+
+func (sim *simulatorImpl) goByBlock(block Block, csi module.ConsensusInfo, hooks bool) ([]Receipt, error) {
 	var err error
 	wss := sim.wss
 	ws := newWorldState(wss, false)
@@ -309,6 +322,16 @@ func (sim *simulatorImpl) GoByBlock(block Block, csi module.ConsensusInfo) ([]Re
 	blockHeight := sim.blockHeight + 1
 	receipts := make([]Receipt, len(block.Txs()))
 
+	if hooks {
+		wc := NewWorldContext(ws, blockHeight, sim.Revision(), csi, sim.stepPrice)
+		if err = sim.onExecutionBegin(wc); err != nil {
+			return nil, err
+		}
+		if err = sim.onBaseTx(wc); err != nil {
+			return nil, err
+		}
+	}
+
 	for i, tx := range block.Txs() {
 		wss = ws.GetSnapshot()
 		err = sim.executeTx(csi, ws, tx)
@@ -321,11 +344,21 @@ func (sim *simulatorImpl) GoByBlock(block Block, csi module.ConsensusInfo) ([]Re
 		}
 	}
 
+	if hooks {
+		wc := NewWorldContext(ws, blockHeight, sim.Revision(), csi, sim.stepPrice)
+		if err = sim.onExecutionEnd(wc); err != nil {
+			return nil, err
+		}
+	}
+
 	wss = ws.GetSnapshot()
 	if err = wss.Flush(); err != nil {
 		return nil, err
 	}
 
+	if hooks {
+		sim.onFinalize(wss)
+	}
 	sim.wss = wss
 	sim.blockHeight = blockHeight
 	return receipts, nil
@@ -365,6 +398,20 @@ func (sim *simulatorImpl) executeTx(csi module.ConsensusInfo, ws state.WorldStat
 		err = sim.setRevision(wc, tx)
 	case TypeClaimIScore:
 		err = sim.claimIScore(es, wc, tx)
+	case TypeSetPRepNodeKey:
+		err = sim.setPRepNodeKey(es, wc, tx)
+	case TypePenalizeNonVoters:
+		err = sim.penalizeNonVoters(es, wc, tx)
+	case TypeSetSlashingRates:
+		err = sim.setSlashingRates(es, tx)
+	case TypeAddTimer:
+		err = sim.addTimer(es, tx)
+	case TypeRemoveTimer:
+		err = sim.removeTimer(es, tx)
+	case TypeSetBTPPublicKey:
+		err = sim.setBTPPublicKey(es, wc, tx)
+	case TypeSetPRepNodeKeyRotation:
+		err = sim.setPRepNodeKeyRotation(es, wc, tx)
 	default:
 		return errors.Errorf("Unexpected transaction: %v", tx.Type())
 	}
@@ -421,6 +468,152 @@ func (sim *simulatorImpl) setPRep(es *iiss.ExtensionStateImpl, wc WorldContext,
 	return es.SetPRep(cc, info, false)
 }
 
+func (sim *simulatorImpl) SetPRepNodeKey(from module.Address, pubKey []byte) Transaction {
+	return NewTransaction(TypeSetPRepNodeKey, []interface{}{from, pubKey})
+}
+
+// setPRepNodeKey updates the node address of the P-Rep with the public key.
+// BTP public keys are not managed by the simulator.
+func (sim *simulatorImpl) setPRepNodeKey(es *iiss.ExtensionStateImpl, wc WorldContext, tx Transaction) error {
+	args := tx.Args()
+	from := args[0].(module.Address)
+	pubKey := args[1].([]byte)
+	prep := es.GetPRep(from)
+	if prep == nil {
+		return errors.Errorf("NotPRep(%s)", from)
+	}
+	pk, err := crypto.ParsePublicKey(pubKey)
+	if err != nil {
+		return errors.IllegalArgumentError.Wrap(err, "Failed to parse public key")
+	}
+	node := common.NewAccountAddressFromPublicKey(pk)
+	if node.Equal(prep.NodeAddress()) {
+		return nil
+	}
+	cc := NewCallContext(wc, from)
+	return es.SetPRep(cc, &icstate.PRepInfo{Node: node}, true)
+}
+
+func (sim *simulatorImpl) SetPRepNodeKeyRotation(from module.Address, r *icstate.NodeKeyRotation) Transaction {
+	return NewTransaction(TypeSetPRepNodeKeyRotation, []interface{}{from, r})
+}
+
+func (sim *simulatorImpl) setPRepNodeKeyRotation(es *iiss.ExtensionStateImpl, wc WorldContext, tx Transaction) error {
+	args := tx.Args()
+	from := args[0].(module.Address)
+	r := args[1].(*icstate.NodeKeyRotation)
+	cc := NewCallContext(wc, from)
+	return es.ScheduleNodeKeyRotation(cc, r)
+}
+
+func (sim *simulatorImpl) GetBTPPublicKey(node module.Address, dsa string) []byte {
+	return sim.newCallContext().GetBTPContext().GetPublicKey(node, dsa)
+}
+
+func (sim *simulatorImpl) SetBTPPublicKey(from module.Address, dsa string, pubKey []byte) Transaction {
+	return NewTransaction(TypeSetBTPPublicKey, []interface{}{from, dsa, pubKey})
+}
+
+// setBTPPublicKey sets the public key of the node of the P-Rep like
+// setBTPPublicKey of chain SCORE. Events of public keys are not recorded.
+func (sim *simulatorImpl) setBTPPublicKey(es *iiss.ExtensionStateImpl, wc WorldContext, tx Transaction) error {
+	args := tx.Args()
+	from := args[0].(module.Address)
+	dsa := args[1].(string)
+	pubKey := args[2].([]byte)
+	prep := es.GetPRep(from)
+	if prep == nil {
+		return errors.Errorf("NotPRep(%s)", from)
+	}
+	bs, ok := wc.GetBTPState().(*state.BTPStateImpl)
+	if !ok {
+		return errors.InvalidStateError.New("InvalidBTPState")
+	}
+	bc := wc.GetBTPContext()
+	node := prep.NodeAddress()
+	if err := bs.SetPublicKey(bc, node, dsa, pubKey); err != nil {
+		return err
+	}
+	prep.SetDSAMask(bc.GetPublicKeyMask(node))
+	return nil
+}
+
+func (sim *simulatorImpl) PenalizeNonVoters(from module.Address, preps []module.Address) Transaction {
+	return NewTransaction(TypePenalizeNonVoters, []interface{}{from, preps})
+}
+
+func (sim *simulatorImpl) penalizeNonVoters(es *iiss.ExtensionStateImpl, wc WorldContext, tx Transaction) error {
+	args := tx.Args()
+	from := args[0].(module.Address)
+	preps := args[1].([]module.Address)
+	cc := NewCallContext(wc, from)
+	for _, prep := range preps {
+		if err := es.PenalizeNonVoters(cc, prep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	SlashingRateConsistentValidation = "ConsistentValidationPenalty"
+	SlashingRateNonVote              = "NonVotePenalty"
+)
+
+func (sim *simulatorImpl) SetSlashingRates(from module.Address, rates map[string]int) Transaction {
+	return NewTransaction(TypeSetSlashingRates, []interface{}{from, rates})
+}
+
+func (sim *simulatorImpl) setSlashingRates(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	rates := args[1].(map[string]int)
+	for name, rate := range rates {
+		var err error
+		switch name {
+		case SlashingRateConsistentValidation:
+			err = es.State.SetConsistentValidationPenaltySlashRatio(rate)
+		case SlashingRateNonVote:
+			err = es.State.SetNonVotePenaltySlashRatio(rate)
+		default:
+			err = errors.IllegalArgumentError.Errorf("UnknownPenalty(%s)", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sim *simulatorImpl) HasTimer(address module.Address, blockHeight int64) bool {
+	es := sim.getExtensionState(true)
+	ts := es.State.GetNetworkScoreTimerSnapshot(blockHeight)
+	return ts != nil && ts.Contains(address)
+}
+
+func (sim *simulatorImpl) AddTimer(from module.Address, blockHeight int64) Transaction {
+	return NewTransaction(TypeAddTimer, []interface{}{from, blockHeight})
+}
+
+func (sim *simulatorImpl) addTimer(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	from := args[0].(module.Address)
+	blockHeight := args[1].(int64)
+	es.State.GetNetworkScoreTimerState(blockHeight).Add(from)
+	return nil
+}
+
+func (sim *simulatorImpl) RemoveTimer(from module.Address, blockHeight int64) Transaction {
+	return NewTransaction(TypeRemoveTimer, []interface{}{from, blockHeight})
+}
+
+func (sim *simulatorImpl) removeTimer(es *iiss.ExtensionStateImpl, tx Transaction) error {
+	args := tx.Args()
+	from := args[0].(module.Address)
+	blockHeight := args[1].(int64)
+	es.State.GetNetworkScoreTimerState(blockHeight).Delete(from)
+	return nil
+}
+
 func (sim *simulatorImpl) GetDelegation(from module.Address) map[string]interface{} {
 	es := sim.getExtensionState(true)
 	ia := es.State.GetAccountSnapshot(from)
@@ -526,6 +719,11 @@ func (sim *simulatorImpl) GetPRep(address module.Address) *icstate.PRep {
 	return es.State.GetPRepByOwner(address)
 }
 
+func (sim *simulatorImpl) GetPenaltyHistory(address module.Address) []*icstate.PenaltyRecord {
+	es := sim.getExtensionState(true)
+	return es.State.GetPenaltyHistory(address)
+}
+
 func (sim *simulatorImpl) GetPRepStats(address module.Address) map[string]interface{} {
 	es := sim.getExtensionState(true)
 	ps := es.State.GetPRepStatusByOwner(address, false)
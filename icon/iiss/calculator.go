@@ -21,6 +21,7 @@ import (
 	"math/big"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/icon-project/goloop/common"
@@ -65,6 +66,36 @@ var (
 	BigIntMinDelegation = big.NewInt(int64(MinDelegation))
 )
 
+type CalculationStage int32
+
+const (
+	StagePrepare CalculationStage = iota
+	StageBlockProduce
+	StageVoted
+	StageVoting
+	StagePostWork
+	StageDone
+)
+
+func (s CalculationStage) String() string {
+	switch s {
+	case StagePrepare:
+		return "prepare"
+	case StageBlockProduce:
+		return "blockProduce"
+	case StageVoted:
+		return "voted"
+	case StageVoting:
+		return "voting"
+	case StagePostWork:
+		return "postWork"
+	case StageDone:
+		return "done"
+	default:
+		return "unknown"
+	}
+}
+
 type Calculator struct {
 	log log.Logger
 
@@ -75,6 +106,10 @@ type Calculator struct {
 	global      icstage.Global
 	temp        *icreward.State
 	stats       *statistics
+	details     *rewardDetails
+
+	stage   int32
+	updated int64
 
 	lock    sync.Mutex
 	waiters []*sync.Cond
@@ -110,6 +145,16 @@ func (c *Calculator) Error() error {
 	return c.err
 }
 
+// Progress returns current stage of the calculation and the number of
+// I-Score updates made so far.
+func (c *Calculator) Progress() (CalculationStage, int64) {
+	return CalculationStage(atomic.LoadInt32(&c.stage)), atomic.LoadInt64(&c.updated)
+}
+
+func (c *Calculator) setStage(stage CalculationStage) {
+	atomic.StoreInt32(&c.stage, int32(stage))
+}
+
 func (c *Calculator) WaitResult(blockHeight int64) error {
 	if c.startHeight == InitBlockHeight {
 		return nil
@@ -190,24 +235,28 @@ func (c *Calculator) run() (err error) {
 		return
 	}
 	prepareTS := time.Now()
+	c.setStage(StageBlockProduce)
 
 	if err = c.calculateBlockProduce(); err != nil {
 		err = icmodule.CalculationFailedError.Wrapf(err, "Failed to calculate block produce reward")
 		return
 	}
 	bpTS := time.Now()
+	c.setStage(StageVoted)
 
 	if err = c.calculateVotedReward(); err != nil {
 		err = icmodule.CalculationFailedError.Wrapf(err, "Failed to calculate P-Rep voted reward")
 		return
 	}
 	votedTS := time.Now()
+	c.setStage(StageVoting)
 
 	if err = c.calculateVotingReward(); err != nil {
 		err = icmodule.CalculationFailedError.Wrapf(err, "Failed to calculate ICONist voting reward")
 		return
 	}
 	votingTS := time.Now()
+	c.setStage(StagePostWork)
 
 	if err = c.postWork(); err != nil {
 		err = icmodule.CalculationFailedError.Wrapf(err, "Failed to do post work of calculator")
@@ -222,7 +271,16 @@ func (c *Calculator) run() (err error) {
 	c.log.Infof("Calculation statistics: Total=%d BlockProduce=%s Voted=%s Voting=%s",
 		c.stats.TotalReward(), c.stats.BlockProduce(), c.stats.Voted(), c.stats.Voting())
 
+	c.setStage(StageDone)
 	c.setResult(c.temp.GetSnapshot(), nil)
+
+	// reward details are not a part of the state, so failure on writing them
+	// doesn't affect the result.
+	if c.Error() == nil {
+		if err := c.details.write(c.database, c.global); err != nil {
+			c.log.Warnf("Failed to write reward details. %+v", err)
+		}
+	}
 	return nil
 }
 
@@ -308,6 +366,23 @@ func (c *Calculator) replayBugDisabledPRep() error {
 }
 
 func (c *Calculator) updateIScore(addr module.Address, reward *big.Int, t RewardType) error {
+	if err := c.addIScore(addr, reward, t); err != nil {
+		return err
+	}
+	c.details.add(addr, t, "", reward)
+	return nil
+}
+
+// updateVotingIScore updates voting reward of the account with rewards by P-Reps.
+func (c *Calculator) updateVotingIScore(addr module.Address, reward *big.Int, byPRep rewardsByPRep) error {
+	if err := c.addIScore(addr, reward, TypeVoting); err != nil {
+		return err
+	}
+	c.details.addVoting(addr, reward, byPRep)
+	return nil
+}
+
+func (c *Calculator) addIScore(addr module.Address, reward *big.Int, t RewardType) error {
 	iScore, err := c.temp.GetIScore(addr)
 	if err != nil {
 		return err
@@ -317,6 +392,7 @@ func (c *Calculator) updateIScore(addr module.Address, reward *big.Int, t Reward
 		return err
 	}
 	c.log.Tracef("Update IScore %s by %d: %+v + %s = %+v", addr, t, iScore, reward, nIScore)
+	atomic.AddInt64(&c.updated, 1)
 
 	switch t {
 	case TypeBlockProduce:
@@ -866,6 +942,7 @@ func (c *Calculator) processVoting(
 			return err
 		}
 		var reward *big.Int
+		byPRep := make(rewardsByPRep)
 		if _, ok := eventMap[string(addr.Bytes())]; ok {
 			continue
 		} else {
@@ -874,9 +951,9 @@ func (c *Calculator) processVoting(
 				c.log.Errorf("Failed to convert data to voting instance")
 				continue
 			}
-			reward = c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator())
+			reward = c.votingRewardByPRep(multiplier, divider, from, to, prepInfo, voting.Iterator(), byPRep)
 		}
-		if err = c.updateIScore(addr, reward, TypeVoting); err != nil {
+		if err = c.updateVotingIScore(addr, reward, byPRep); err != nil {
 			return err
 		}
 	}
@@ -901,6 +978,20 @@ func (c *Calculator) votingReward(
 	to int,
 	prepInfo map[string]*pRepEnable,
 	iter icstate.VotingIterator,
+) *big.Int {
+	return c.votingRewardByPRep(multiplier, divider, from, to, prepInfo, iter, nil)
+}
+
+// votingRewardByPRep is same as votingReward, but it also adds rewards
+// to byPRep for each voted P-Rep if it's not nil.
+func (c *Calculator) votingRewardByPRep(
+	multiplier *big.Int,
+	divider *big.Int,
+	from int,
+	to int,
+	prepInfo map[string]*pRepEnable,
+	iter icstate.VotingIterator,
+	byPRep rewardsByPRep,
 ) *big.Int {
 	total := new(big.Int)
 	checkMinVoting := c.global.GetIISSVersion() == icstate.IISSVersion2
@@ -928,6 +1019,7 @@ func (c *Calculator) votingReward(
 				reward.Mul(reward, big.NewInt(int64(period)))
 				reward.Div(reward, divider)
 				total.Add(total, reward)
+				byPRep.add(voting.To(), reward)
 				c.log.Tracef("VotingReward %s: %s = %s * %s * %d / %s",
 					voting.To(), reward, multiplier, voting.Amount(), period, divider)
 			}
@@ -947,6 +1039,7 @@ func (c *Calculator) processVotingEvent(
 	for key, events := range eventMap { // each account
 		addr, _ := common.NewAddress([]byte(key))
 		reward := new(big.Int)
+		byPRep := make(rewardsByPRep)
 		offsets := make([]int, 0, len(events))
 		for offset, _ := range events {
 			offsets = append(offsets, offset)
@@ -968,15 +1061,17 @@ func (c *Calculator) processVotingEvent(
 			to := offsets[i]
 			switch iissVersion {
 			case icstate.IISSVersion2:
-				ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator())
+				ret := c.votingRewardByPRep(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator(), byPRep)
 				reward.Add(reward, ret)
 				c.log.Tracef("VotingEvent %s %d add: %d-%d %s", addr, i, from, offsetLimit, ret)
-				ret = c.votingReward(multiplier, divider, to, offsetLimit, prepInfo, voting.Iterator())
+				sub := make(rewardsByPRep)
+				ret = c.votingRewardByPRep(multiplier, divider, to, offsetLimit, prepInfo, voting.Iterator(), sub)
 				reward.Sub(reward, ret)
+				byPRep.addAll(sub, -1)
 				c.log.Tracef("VotingEvent %s %d sub: %d-%d %s", addr, i, to, offsetLimit, ret)
 			case icstate.IISSVersion3:
 				to = offsets[i]
-				ret := c.votingReward(multiplier, divider, from, to, prepInfo, voting.Iterator())
+				ret := c.votingRewardByPRep(multiplier, divider, from, to, prepInfo, voting.Iterator(), byPRep)
 				reward.Add(reward, ret)
 				c.log.Tracef("VotingEvent %s %d: %d-%d %s", addr, i, from, to, ret)
 			}
@@ -991,14 +1086,14 @@ func (c *Calculator) processVotingEvent(
 			from = to
 		}
 		// calculate reward for last event
-		ret := c.votingReward(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator())
+		ret := c.votingRewardByPRep(multiplier, divider, from, offsetLimit, prepInfo, voting.Iterator(), byPRep)
 		reward.Add(reward, ret)
 		c.log.Tracef("VotingEvent %s last: %d, %d: %s", addr, from, offsetLimit, ret)
 
 		if err = c.writeVoting(addr, voting); err != nil {
 			return nil
 		}
-		if err = c.updateIScore(addr, reward, TypeVoting); err != nil {
+		if err = c.updateVotingIScore(addr, reward, byPRep); err != nil {
 			return err
 		}
 	}
@@ -1141,6 +1236,7 @@ func NewCalculator(database db.Database, back *icstage.Snapshot, reward *icrewar
 		global:      global,
 		startHeight: startHeight,
 		stats:       newStatistics(),
+		details:     newRewardDetails(),
 	}
 	if startHeight != InitBlockHeight {
 		go c.run()
@@ -1151,6 +1247,25 @@ func NewCalculator(database db.Database, back *icstage.Snapshot, reward *icrewar
 type CalculatorHolder struct {
 	lock   sync.Mutex
 	runner *Calculator
+	term   int64
+}
+
+// recordTerm keeps the sequence of the current term for reward details,
+// since the calculator doesn't know it.
+func (h *CalculatorHolder) recordTerm(ess state.ExtensionSnapshot, logger log.Logger) {
+	essi, ok := ess.(*ExtensionSnapshotImpl)
+	if !ok || essi.state == nil {
+		return
+	}
+	term := icstate.NewStateFromSnapshot(essi.state, true, logger).GetTermSnapshot()
+	if term == nil || term.StartHeight() == h.term {
+		return
+	}
+	if err := recordTermSequence(essi.database, term.StartHeight(), term.Sequence()); err != nil {
+		logger.Warnf("Failed to record term sequence. %+v", err)
+		return
+	}
+	h.term = term.StartHeight()
 }
 
 func (h *CalculatorHolder) Start(ess state.ExtensionSnapshot, logger log.Logger) {
@@ -1158,6 +1273,7 @@ func (h *CalculatorHolder) Start(ess state.ExtensionSnapshot, logger log.Logger)
 	defer h.lock.Unlock()
 
 	if ess != nil {
+		h.recordTerm(ess, logger)
 		h.runner = UpdateCalculator(h.runner, ess, logger)
 	} else {
 		if h.runner != nil {
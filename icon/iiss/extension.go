@@ -30,6 +30,7 @@ import (
 	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/common/merkle"
+	"github.com/icon-project/goloop/common/trie/trie_manager"
 	"github.com/icon-project/goloop/icon/icmodule"
 	"github.com/icon-project/goloop/icon/iiss/icobject"
 	"github.com/icon-project/goloop/icon/iiss/icreward"
@@ -110,6 +111,38 @@ func (s *ExtensionSnapshotImpl) Flush() error {
 	return nil
 }
 
+func extensionDiffHandler(part string, handler state.ExtensionDiffHandler) trie_manager.BytesDiffHandler {
+	return func(op int, key []byte, v1, v2 []byte) error {
+		return handler(&state.ExtensionDiff{
+			Part: part,
+			Op:   op,
+			Key:  key,
+			Old:  v1,
+			New:  v2,
+		})
+	}
+}
+
+func (s *ExtensionSnapshotImpl) DiffWith(ess state.ExtensionSnapshot, handler state.ExtensionDiffHandler) error {
+	s2, ok := ess.(*ExtensionSnapshotImpl)
+	if !ok {
+		return errors.IllegalArgumentError.Errorf("InvalidExtensionSnapshot(type=%T)", ess)
+	}
+	if err := s.state.Diff(s2.state, extensionDiffHandler("state", handler)); err != nil {
+		return err
+	}
+	if err := s.front.Diff(s2.front, extensionDiffHandler("front", handler)); err != nil {
+		return err
+	}
+	if err := s.back1.Diff(s2.back1, extensionDiffHandler("back1", handler)); err != nil {
+		return err
+	}
+	if err := s.back2.Diff(s2.back2, extensionDiffHandler("back2", handler)); err != nil {
+		return err
+	}
+	return s.reward.Diff(s2.reward, extensionDiffHandler("reward", handler))
+}
+
 func (s *ExtensionSnapshotImpl) NewState(readonly bool) state.ExtensionState {
 	logger := icutils.NewIconLogger(nil)
 
@@ -592,7 +625,7 @@ func (es *ExtensionStateImpl) DisqualifyPRep(cc icmodule.CallContext, address mo
 			intconv.Int64ToBytes(int64(icmodule.PenaltyPRepDisqualification)),
 		},
 	)
-	return nil
+	return es.recordPenalty(cc, address, icmodule.PenaltyPRepDisqualification, nil)
 }
 
 func (es *ExtensionStateImpl) PenalizeNonVoters(cc icmodule.CallContext, address module.Address) error {
@@ -606,7 +639,11 @@ func (es *ExtensionStateImpl) PenalizeNonVoters(cc icmodule.CallContext, address
 		},
 	)
 
-	return es.slash(cc, address, es.State.GetNonVotePenaltySlashRatio())
+	slashes, err := es.slash(cc, address, es.State.GetNonVotePenaltySlashRatio())
+	if err != nil {
+		return err
+	}
+	return es.recordPenalty(cc, address, icmodule.PenaltyNonVote, slashes)
 }
 
 func (es *ExtensionStateImpl) SetBond(blockHeight int64, from module.Address, bonds icstate.Bonds) error {
@@ -837,6 +874,12 @@ func (es *ExtensionStateImpl) OnExecutionEnd(wc icmodule.WorldContext, totalFee
 		return nil
 	}
 
+	if wc.Revision().Value() >= icmodule.RevisionNodeKeyRotation {
+		if err = es.handleNodeKeyRotation(wc); err != nil {
+			return err
+		}
+	}
+
 	if term.IsDecentralized() {
 		if err = es.setIssuePrevBlockFee(totalFee); err != nil {
 			return err
@@ -947,17 +990,24 @@ func (es *ExtensionStateImpl) regulateIssue(iScore *big.Int) error {
 	return nil
 }
 
-func (es *ExtensionStateImpl) onTermEnd(wc icmodule.WorldContext) error {
-	var err error
-
-	revision := wc.Revision().Value()
-	br := es.State.GetBondRequirement()
+// electionConfig returns the number of main, sub and extra main P-Reps to be
+// elected on term end.
+func (es *ExtensionStateImpl) electionConfig(revision int) (int, int, int) {
 	mainPRepCount := int(es.State.GetMainPRepCount())
 	subPRepCount := int(es.State.GetSubPRepCount())
 	extraMainPRepCount := 0
 	if revision >= icmodule.RevisionExtraMainPReps {
 		extraMainPRepCount = int(es.State.GetExtraMainPRepCount())
 	}
+	return mainPRepCount, subPRepCount, extraMainPRepCount
+}
+
+func (es *ExtensionStateImpl) onTermEnd(wc icmodule.WorldContext) error {
+	var err error
+
+	revision := wc.Revision().Value()
+	br := es.State.GetBondRequirement()
+	mainPRepCount, subPRepCount, extraMainPRepCount := es.electionConfig(revision)
 	electedPRepCount := mainPRepCount + subPRepCount
 
 	totalSupply := wc.GetTotalSupply()
@@ -18,6 +18,7 @@ package icobject
 
 import (
 	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/common/trie/trie_manager"
 )
 
 type bytesConverter struct{}
@@ -52,6 +53,22 @@ func (o *ObjectStoreSnapshot) Delete(_ []byte) (trie.Object, error) {
 	panic("invalid usage")
 }
 
+// Diff calls handler with serialized values for each key having different
+// values between the snapshots.
+func (o *ObjectStoreSnapshot) Diff(o2 *ObjectStoreSnapshot, handler trie_manager.BytesDiffHandler) error {
+	return trie_manager.DiffImmutableForObject(o.ImmutableForObject, o2.ImmutableForObject,
+		func(op int, key []byte, v1, v2 trie.Object) error {
+			var b1, b2 []byte
+			if v1 != nil {
+				b1 = v1.Bytes()
+			}
+			if v2 != nil {
+				b2 = v2.Bytes()
+			}
+			return handler(op, key, b1, b2)
+		})
+}
+
 func NewObjectStoreSnapshot(t trie.ImmutableForObject) *ObjectStoreSnapshot {
 	return &ObjectStoreSnapshot{t, bytesConverter{}}
 }
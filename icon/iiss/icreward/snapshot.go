@@ -17,6 +17,10 @@
 package icreward
 
 import (
+	"bytes"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/containerdb"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/common/merkle"
 	"github.com/icon-project/goloop/common/trie"
@@ -36,6 +40,42 @@ func (ss *Snapshot) Flush() error {
 	return nil
 }
 
+func (ss *Snapshot) Diff(ss2 *Snapshot, handler trie_manager.BytesDiffHandler) error {
+	return ss.store.Diff(ss2.store, handler)
+}
+
+// IScoreDiffHandler is called with I-Scores of an account in both snapshots.
+// nil is passed for the snapshot not having I-Score of the account.
+type IScoreDiffHandler func(addr module.Address, exp, real *IScore) error
+
+// DiffIScores calls handler for each account having different I-Score
+// between the snapshots.
+func (ss *Snapshot) DiffIScores(ss2 *Snapshot, handler IScoreDiffHandler) error {
+	prefix := IScoreKey.Build()
+	return ss.store.Diff(ss2.store, func(op int, key []byte, _, _ []byte) error {
+		if !bytes.HasPrefix(key, prefix) {
+			return nil
+		}
+		keys, err := containerdb.SplitKeys(key)
+		if err != nil {
+			return err
+		}
+		addr, err := common.NewAddress(keys[1])
+		if err != nil {
+			return err
+		}
+		exp, err := ss.store.Get(key)
+		if err != nil {
+			return err
+		}
+		real, err := ss2.store.Get(key)
+		if err != nil {
+			return err
+		}
+		return handler(addr, ToIScore(exp), ToIScore(real))
+	})
+}
+
 func (ss *Snapshot) Bytes() []byte {
 	return ss.store.Hash()
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/icon-project/goloop/common/containerdb"
 	"github.com/icon-project/goloop/common/db"
 	"github.com/icon-project/goloop/icon/iiss/icobject"
+	"github.com/icon-project/goloop/module"
 )
 
 func TestState_NewState(t *testing.T) {
@@ -139,3 +140,42 @@ func TestState_SetVoted(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, voted2.Equal(v2))
 }
+
+func TestSnapshot_DiffIScores(t *testing.T) {
+	database := icobject.AttachObjectFactory(db.NewMapDB(), NewObjectImpl)
+	addr1 := common.MustNewAddressFromString("hx1")
+	addr2 := common.MustNewAddressFromString("hx2")
+	addr3 := common.MustNewAddressFromString("hx3")
+
+	s := NewState(database, nil)
+	assert.NoError(t, s.SetIScore(addr1, NewIScore(big.NewInt(10))))
+	assert.NoError(t, s.SetIScore(addr2, NewIScore(big.NewInt(20))))
+	assert.NoError(t, s.SetVoted(addr1, NewVoted()))
+	exp := s.GetSnapshot()
+
+	s = exp.NewState()
+	assert.NoError(t, s.SetIScore(addr2, NewIScore(big.NewInt(21))))
+	assert.NoError(t, s.SetIScore(addr3, NewIScore(big.NewInt(30))))
+	assert.NoError(t, s.SetVoted(addr2, NewVoted()))
+	real := s.GetSnapshot()
+
+	diffs := make(map[string][2]*IScore)
+	err := exp.DiffIScores(real, func(addr module.Address, e, r *IScore) error {
+		diffs[addr.String()] = [2]*IScore{e, r}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(diffs))
+	d := diffs[addr2.String()]
+	assert.Equal(t, int64(20), d[0].Value().Int64())
+	assert.Equal(t, int64(21), d[1].Value().Int64())
+	d = diffs[addr3.String()]
+	assert.Nil(t, d[0])
+	assert.Equal(t, int64(30), d[1].Value().Int64())
+
+	err = exp.DiffIScores(exp, func(addr module.Address, e, r *IScore) error {
+		t.Errorf("unexpected difference for %s", addr)
+		return nil
+	})
+	assert.NoError(t, err)
+}
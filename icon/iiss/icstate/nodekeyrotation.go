@@ -0,0 +1,374 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icstate
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/icon-project/goloop/btp/ntm"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icobject"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoredb"
+)
+
+var (
+	nodeKeyRotationPrefix = containerdb.ToKey(
+		containerdb.HashBuilder, scoredb.DictDBPrefix, "prep_node_key_rotation",
+	)
+	nodeKeyRotationHeightPrefix = containerdb.ToKey(
+		containerdb.HashBuilder, scoredb.ArrayDBPrefix, "prep_node_key_rotation_height",
+	)
+)
+
+const (
+	// ConsensusDSA is the DSA of the key for the node address, which is used
+	// for consensus.
+	ConsensusDSA = "ecdsa/secp256k1"
+
+	// MaxNodeKeyRotationDelay is the maximum number of blocks from scheduling
+	// a node key rotation to its activation.
+	MaxNodeKeyRotationDelay = icmodule.MonthBlock
+
+	nodeKeyRotationVersion = 1
+)
+
+// NodePublicKey is a public key of the DSA for the node of a P-Rep.
+type NodePublicKey struct {
+	dsa    string
+	pubKey []byte
+}
+
+func NewNodePublicKey(dsa string, pubKey []byte) *NodePublicKey {
+	return &NodePublicKey{dsa: dsa, pubKey: pubKey}
+}
+
+func (k *NodePublicKey) DSA() string {
+	return k.dsa
+}
+
+func (k *NodePublicKey) PublicKey() []byte {
+	return k.pubKey
+}
+
+func (k *NodePublicKey) Equal(k2 *NodePublicKey) bool {
+	return k.dsa == k2.dsa && bytes.Equal(k.pubKey, k2.pubKey)
+}
+
+func (k *NodePublicKey) RLPEncodeSelf(e codec.Encoder) error {
+	return e.EncodeListOf(k.dsa, k.pubKey)
+}
+
+func (k *NodePublicKey) RLPDecodeSelf(d codec.Decoder) error {
+	return d.DecodeListOf(&k.dsa, &k.pubKey)
+}
+
+func (k *NodePublicKey) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"dsa":       k.dsa,
+		"publicKey": k.pubKey,
+	}
+}
+
+// NewNodePublicKeys returns public keys from the list of structs having
+// dsa and publicKey fields.
+func NewNodePublicKeys(param []interface{}) ([]*NodePublicKey, error) {
+	pubKeys := make([]*NodePublicKey, 0, len(param))
+	for _, p := range param {
+		m, ok := p.(map[string]interface{})
+		if !ok {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidPublicKeyEntry(%v)", p)
+		}
+		dsa, _ := m["dsa"].(string)
+		pubKey, _ := m["publicKey"].([]byte)
+		if len(dsa) == 0 || len(pubKey) == 0 {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidPublicKeyEntry(%v)", p)
+		}
+		pubKeys = append(pubKeys, NewNodePublicKey(dsa, pubKey))
+	}
+	return pubKeys, nil
+}
+
+// NodeKeyRotation is a pending change of the node address and public keys
+// of a P-Rep, which is activated at the height.
+type NodeKeyRotation struct {
+	icobject.NoDatabase
+
+	height  int64
+	node    *common.Address
+	pubKeys []*NodePublicKey
+}
+
+func newNodeKeyRotationWithTag(_ icobject.Tag) *NodeKeyRotation {
+	return new(NodeKeyRotation)
+}
+
+func NewNodeKeyRotation(height int64, node module.Address, pubKeys []*NodePublicKey) *NodeKeyRotation {
+	return &NodeKeyRotation{
+		height:  height,
+		node:    common.AddressToPtr(node),
+		pubKeys: pubKeys,
+	}
+}
+
+func (r *NodeKeyRotation) Version() int {
+	return nodeKeyRotationVersion
+}
+
+func (r *NodeKeyRotation) Height() int64 {
+	return r.height
+}
+
+func (r *NodeKeyRotation) Node() module.Address {
+	return r.node
+}
+
+func (r *NodeKeyRotation) PublicKeys() []*NodePublicKey {
+	return r.pubKeys
+}
+
+// PublicKey returns the public key of the DSA, or nil if it isn't rotated.
+func (r *NodeKeyRotation) PublicKey(dsa string) []byte {
+	for _, k := range r.pubKeys {
+		if k.dsa == dsa {
+			return k.pubKey
+		}
+	}
+	return nil
+}
+
+func (r *NodeKeyRotation) validate(blockHeight int64) error {
+	if r.height <= blockHeight || r.height > blockHeight+MaxNodeKeyRotationDelay {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidActivationHeight(height=%d,bh=%d,maxDelay=%d)",
+			r.height, blockHeight, MaxNodeKeyRotationDelay)
+	}
+	if r.node == nil || r.node.IsContract() {
+		return errors.IllegalArgumentError.Errorf("InvalidNode(%s)", r.node)
+	}
+	dsas := make(map[string]struct{})
+	for _, k := range r.pubKeys {
+		if _, ok := dsas[k.dsa]; ok {
+			return errors.IllegalArgumentError.Errorf("DuplicatedDSA(%s)", k.dsa)
+		}
+		dsas[k.dsa] = struct{}{}
+		dsa := ntm.DSAModuleForName(k.dsa)
+		if dsa == nil {
+			return errors.IllegalArgumentError.Errorf("UnknownDSA(%s)", k.dsa)
+		}
+		if _, err := dsa.Canonicalize(k.pubKey); err != nil {
+			return errors.IllegalArgumentError.Wrapf(err, "InvalidPublicKey(dsa=%s)", k.dsa)
+		}
+	}
+	pubKey := r.PublicKey(ConsensusDSA)
+	if pubKey == nil {
+		return errors.IllegalArgumentError.Errorf("NoPublicKey(dsa=%s)", ConsensusDSA)
+	}
+	pk, err := crypto.ParsePublicKey(pubKey)
+	if err != nil {
+		return errors.IllegalArgumentError.Wrap(err, "InvalidPublicKey")
+	}
+	if addr := common.NewAccountAddressFromPublicKey(pk); !addr.Equal(r.node) {
+		return errors.IllegalArgumentError.Errorf(
+			"PublicKeyMismatch(node=%s,addr=%s)", r.node, addr)
+	}
+	return nil
+}
+
+func (r *NodeKeyRotation) RLPDecodeFields(decoder codec.Decoder) error {
+	return decoder.DecodeAll(&r.height, &r.node, &r.pubKeys)
+}
+
+func (r *NodeKeyRotation) RLPEncodeFields(encoder codec.Encoder) error {
+	return encoder.EncodeMulti(r.height, r.node, r.pubKeys)
+}
+
+func (r *NodeKeyRotation) Equal(o icobject.Impl) bool {
+	r2, ok := o.(*NodeKeyRotation)
+	if !ok {
+		return false
+	}
+	if r == r2 {
+		return true
+	}
+	if r.height != r2.height || !r.node.Equal(r2.node) || len(r.pubKeys) != len(r2.pubKeys) {
+		return false
+	}
+	for i, k := range r.pubKeys {
+		if !k.Equal(r2.pubKeys[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *NodeKeyRotation) ToJSON() map[string]interface{} {
+	pubKeys := make([]interface{}, 0, len(r.pubKeys))
+	for _, k := range r.pubKeys {
+		pubKeys = append(pubKeys, k.ToJSON())
+	}
+	return map[string]interface{}{
+		"activationHeight": r.height,
+		"nodeAddress":      r.node,
+		"publicKeys":       pubKeys,
+	}
+}
+
+func (r *NodeKeyRotation) Format(f fmt.State, c rune) {
+	switch c {
+	case 'v':
+		if f.Flag('+') {
+			fmt.Fprintf(f, "NodeKeyRotation{height=%d node=%s pubKeys=%d}",
+				r.height, r.node, len(r.pubKeys))
+		} else {
+			fmt.Fprintf(f, "NodeKeyRotation{%d %s %d}", r.height, r.node, len(r.pubKeys))
+		}
+	}
+}
+
+func (s *State) nodeKeyRotationDB() *containerdb.DictDB {
+	return containerdb.NewDictDB(s.store, 1, nodeKeyRotationPrefix)
+}
+
+func (s *State) nodeKeyRotationsAt(height int64) *containerdb.ArrayDB {
+	return containerdb.NewArrayDB(s.store, nodeKeyRotationHeightPrefix.Append(height))
+}
+
+// GetNodeKeyRotation returns the pending node key rotation of the P-Rep.
+func (s *State) GetNodeKeyRotation(owner module.Address) *NodeKeyRotation {
+	v := s.nodeKeyRotationDB().Get(owner)
+	if v == nil {
+		return nil
+	}
+	return ToNodeKeyRotation(v.Object())
+}
+
+// checkNodeForRotation checks whether the P-Rep can use the node.
+func (s *State) checkNodeForRotation(owner, node module.Address) error {
+	ps := s.GetPRepStatusByOwner(owner, false)
+	if ps == nil || ps.Status() != Active {
+		return errors.InvalidStateError.Errorf("NotActivePRep(%s)", owner)
+	}
+	if !node.Equal(owner) {
+		if ps := s.GetPRepStatusByOwner(node, false); ps != nil && ps.Status() != NotReady {
+			return errors.InvalidStateError.Errorf("AlreadyUsedByPRep(node=%s)", node)
+		}
+	}
+	if cur := s.nodeOwnerCache.get(node, nil); cur != nil && !cur.Equal(owner) {
+		return errors.InvalidStateError.Errorf("AlreadyUsedByPRep(node=%s,owner=%s)", node, cur)
+	}
+	return nil
+}
+
+// ScheduleNodeKeyRotation validates the rotation and schedules it to be
+// activated at its height. It replaces the pending one of the P-Rep.
+// The node isn't bound to the P-Rep until the activation, so it's checked
+// again on activation.
+func (s *State) ScheduleNodeKeyRotation(blockHeight int64, owner module.Address, r *NodeKeyRotation) error {
+	if err := r.validate(blockHeight); err != nil {
+		return err
+	}
+	if err := s.checkNodeForRotation(owner, r.node); err != nil {
+		return err
+	}
+	old := s.GetNodeKeyRotation(owner)
+	if err := s.nodeKeyRotationDB().Set(owner, icobject.New(TypeNodeKeyRotation, r)); err != nil {
+		return err
+	}
+	// Entries of rotations replaced or canceled are ignored on activation
+	if old == nil || old.height != r.height {
+		return s.nodeKeyRotationsAt(r.height).Put(owner)
+	}
+	return nil
+}
+
+// CancelNodeKeyRotation removes the pending node key rotation of the P-Rep.
+func (s *State) CancelNodeKeyRotation(owner module.Address) error {
+	if s.GetNodeKeyRotation(owner) == nil {
+		return errors.NotFoundError.Errorf("NoNodeKeyRotation(%s)", owner)
+	}
+	return s.nodeKeyRotationDB().Delete(owner)
+}
+
+// PopNodeKeyRotations removes node key rotations to be activated at the
+// height and returns owners and rotations of them. Rotations which can't
+// be activated any more, as the P-Rep isn't active or the node is taken by
+// others, are dropped. If rotations at the height have the same node, the
+// first one is activated.
+func (s *State) PopNodeKeyRotations(height int64) ([]module.Address, []*NodeKeyRotation, error) {
+	adb := s.nodeKeyRotationsAt(height)
+	size := adb.Size()
+	if size == 0 {
+		return nil, nil, nil
+	}
+	owners := make([]module.Address, 0, size)
+	rotations := make([]*NodeKeyRotation, 0, size)
+	nodes := make(map[string]struct{}, size)
+	for i := 0; i < size; i++ {
+		owner := adb.Get(i).Address()
+		r := s.GetNodeKeyRotation(owner)
+		if r == nil || r.height != height {
+			continue
+		}
+		if err := s.nodeKeyRotationDB().Delete(owner); err != nil {
+			return nil, nil, err
+		}
+		if err := s.checkNodeForRotation(owner, r.node); err != nil {
+			s.logger.Warnf("Drop node key rotation: bh=%d owner=%s %+v err=%v", height, owner, r, err)
+			continue
+		}
+		key := icutils.ToKey(r.node)
+		if _, ok := nodes[key]; ok {
+			s.logger.Warnf("Drop node key rotation: bh=%d owner=%s %+v err=DuplicatedNode", height, owner, r)
+			continue
+		}
+		nodes[key] = struct{}{}
+		owners = append(owners, owner)
+		rotations = append(rotations, r)
+	}
+	for adb.Size() > 0 {
+		if adb.Pop() == nil {
+			return nil, nil, errors.InvalidStateError.Errorf("FailToPopNodeKeyRotation(height=%d)", height)
+		}
+	}
+	return owners, rotations, nil
+}
+
+func (s *State) GetNodeKeyRotationInJSON(blockHeight int64, owner module.Address) (map[string]interface{}, error) {
+	if owner == nil {
+		return nil, errors.IllegalArgumentError.New("InvalidAddress")
+	}
+	if s.GetPRepBaseByOwner(owner, false) == nil {
+		return nil, errors.IllegalArgumentError.Errorf("PRepNotFound(address=%s)", owner)
+	}
+	jso := map[string]interface{}{
+		"blockHeight": blockHeight,
+		"address":     owner,
+		"nodeAddress": s.GetNodeByOwner(owner),
+	}
+	if r := s.GetNodeKeyRotation(owner); r != nil {
+		jso["pending"] = r.ToJSON()
+	}
+	return jso, nil
+}
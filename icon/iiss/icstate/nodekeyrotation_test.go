@@ -0,0 +1,159 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icstate
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+func newNodeKey() (module.Address, []byte) {
+	_, pk := crypto.GenerateKeyPair()
+	return common.NewAccountAddressFromPublicKey(pk), pk.SerializeCompressed()
+}
+
+func TestNodeKeyRotation_validate(t *testing.T) {
+	node, pubKey := newNodeKey()
+	_, otherKey := newNodeKey()
+	keys := []*NodePublicKey{NewNodePublicKey(ConsensusDSA, pubKey)}
+
+	tests := []struct {
+		name   string
+		height int64
+		node   module.Address
+		keys   []*NodePublicKey
+		ok     bool
+	}{
+		{"ok", 110, node, keys, true},
+		{"max delay", 100 + MaxNodeKeyRotationDelay, node, keys, true},
+		{"past height", 100, node, keys, false},
+		{"too far", 101 + MaxNodeKeyRotationDelay, node, keys, false},
+		{"contract node", 110, common.MustNewAddressFromString("cx0000000000000000000000000000000000000001"), keys, false},
+		{"no consensus key", 110, node, nil, false},
+		{"key mismatch", 110, node, []*NodePublicKey{NewNodePublicKey(ConsensusDSA, otherKey)}, false},
+		{"unknown dsa", 110, node, append(keys, NewNodePublicKey("unknown", pubKey)), false},
+		{"duplicated dsa", 110, node, append(keys, keys[0]), false},
+		{"invalid key", 110, node, []*NodePublicKey{NewNodePublicKey(ConsensusDSA, []byte{1, 2})}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewNodeKeyRotation(tt.height, tt.node, tt.keys).validate(100)
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, errors.IllegalArgumentError.Equals(err))
+			}
+		})
+	}
+}
+
+func TestNewNodePublicKeys(t *testing.T) {
+	keys, err := NewNodePublicKeys([]interface{}{
+		map[string]interface{}{"dsa": ConsensusDSA, "publicKey": []byte{1}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(keys))
+	assert.Equal(t, ConsensusDSA, keys[0].DSA())
+
+	_, err = NewNodePublicKeys([]interface{}{map[string]interface{}{"dsa": ConsensusDSA}})
+	assert.Error(t, err)
+}
+
+func TestState_NodeKeyRotation(t *testing.T) {
+	s := newDummyState(false)
+	owner := newDummyAddress(1)
+	other := newDummyAddress(2)
+	node, pubKey := newNodeKey()
+	r := NewNodeKeyRotation(110, node, []*NodePublicKey{NewNodePublicKey(ConsensusDSA, pubKey)})
+
+	// only active P-Reps can schedule
+	assert.Error(t, s.ScheduleNodeKeyRotation(100, owner, r))
+	assert.NoError(t, s.RegisterPRep(owner, newDummyPRepInfo(1), big.NewInt(0), 0))
+	assert.NoError(t, s.RegisterPRep(other, newDummyPRepInfo(2), big.NewInt(0), 0))
+
+	_, err := s.GetNodeKeyRotationInJSON(100, newDummyAddress(3))
+	assert.Error(t, err)
+	assert.True(t, errors.NotFoundError.Equals(s.CancelNodeKeyRotation(owner)))
+
+	assert.NoError(t, s.ScheduleNodeKeyRotation(100, owner, r))
+	assert.True(t, r.Equal(s.GetNodeKeyRotation(owner)))
+
+	// the node isn't bound to the owner before the activation
+	assert.Nil(t, s.nodeOwnerCache.get(node, nil))
+
+	s = flushAndNewState(s, false)
+	jso, err := s.GetNodeKeyRotationInJSON(105, owner)
+	assert.NoError(t, err)
+	assert.True(t, owner.Equal(jso["nodeAddress"].(module.Address)))
+	pending := jso["pending"].(map[string]interface{})
+	assert.Equal(t, int64(110), pending["activationHeight"])
+
+	// reschedule at other height, then the old entry is ignored
+	r3 := NewNodeKeyRotation(120, node, r.PublicKeys())
+	assert.NoError(t, s.ScheduleNodeKeyRotation(105, owner, r3))
+	owners, rotations, err := s.PopNodeKeyRotations(110)
+	assert.NoError(t, err)
+	assert.Zero(t, len(owners))
+	assert.Zero(t, len(rotations))
+
+	owners, rotations, err = s.PopNodeKeyRotations(120)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(owners))
+	assert.True(t, owner.Equal(owners[0]))
+	assert.True(t, r3.Equal(rotations[0]))
+	assert.Nil(t, s.GetNodeKeyRotation(owner))
+	assert.Zero(t, s.nodeKeyRotationsAt(120).Size())
+
+	// canceled rotation is not activated, and other P-Reps can use the node
+	r4 := NewNodeKeyRotation(130, node, r.PublicKeys())
+	assert.NoError(t, s.ScheduleNodeKeyRotation(120, owner, r4))
+	assert.NoError(t, s.CancelNodeKeyRotation(owner))
+	owners, _, err = s.PopNodeKeyRotations(130)
+	assert.NoError(t, err)
+	assert.Zero(t, len(owners))
+	assert.Nil(t, s.nodeOwnerCache.get(node, nil))
+	_, err = s.SetPRep(130, other, &PRepInfo{Node: node})
+	assert.NoError(t, err)
+	assert.True(t, other.Equal(s.GetOwnerByNode(node)))
+}
+
+func TestState_PopNodeKeyRotationsWithSameNode(t *testing.T) {
+	s := newDummyState(false)
+	owner1 := newDummyAddress(1)
+	owner2 := newDummyAddress(2)
+	assert.NoError(t, s.RegisterPRep(owner1, newDummyPRepInfo(1), big.NewInt(0), 0))
+	assert.NoError(t, s.RegisterPRep(owner2, newDummyPRepInfo(2), big.NewInt(0), 0))
+
+	node, pubKey := newNodeKey()
+	keys := []*NodePublicKey{NewNodePublicKey(ConsensusDSA, pubKey)}
+	assert.NoError(t, s.ScheduleNodeKeyRotation(100, owner1, NewNodeKeyRotation(110, node, keys)))
+	assert.NoError(t, s.ScheduleNodeKeyRotation(100, owner2, NewNodeKeyRotation(110, node, keys)))
+
+	owners, rotations, err := s.PopNodeKeyRotations(110)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(owners))
+	assert.True(t, owner1.Equal(owners[0]))
+	assert.True(t, node.Equal(rotations[0].Node()))
+	assert.Nil(t, s.GetNodeKeyRotation(owner2))
+}
@@ -33,6 +33,8 @@ const (
 	TypeValidators
 	TypeBlockVoters
 	TypeIllegalDelegation
+	TypePenaltyRecord
+	TypeNodeKeyRotation
 )
 
 func NewObjectImpl(tag icobject.Tag) (icobject.Impl, error) {
@@ -57,6 +59,10 @@ func NewObjectImpl(tag icobject.Tag) (icobject.Impl, error) {
 		return NewBlockVotersWithTag(tag), nil
 	case TypeIllegalDelegation:
 		return NewIllegalDelegationWithTag(tag), nil
+	case TypePenaltyRecord:
+		return newPenaltyRecordWithTag(tag), nil
+	case TypeNodeKeyRotation:
+		return newNodeKeyRotationWithTag(tag), nil
 	default:
 		return nil, errors.IllegalArgumentError.Errorf(
 			"UnknownTypeTag(tag=%#x)", tag)
@@ -135,3 +141,17 @@ func ToIllegalDelegation(object trie.Object) *IllegalDelegation {
 	}
 	return object.(*icobject.Object).Real().(*IllegalDelegation)
 }
+
+func ToNodeKeyRotation(object trie.Object) *NodeKeyRotation {
+	if object == nil {
+		return nil
+	}
+	return object.(*icobject.Object).Real().(*NodeKeyRotation)
+}
+
+func ToPenaltyRecord(object trie.Object) *PenaltyRecord {
+	if object == nil {
+		return nil
+	}
+	return object.(*icobject.Object).Real().(*PenaltyRecord)
+}
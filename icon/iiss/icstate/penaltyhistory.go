@@ -0,0 +1,244 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icstate
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icobject"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/scoreresult"
+)
+
+var penaltyHistoryPrefix = containerdb.ToKey(
+	containerdb.HashBuilder, scoredb.ArrayDBPrefix, "prep_penalty_history",
+)
+
+const penaltyRecordVersion = 1
+
+// Slash is the amount slashed from a bonder of the P-Rep.
+type Slash struct {
+	bonder *common.Address
+	bond   *big.Int
+	unbond *big.Int
+}
+
+func NewSlash(bonder module.Address, bond, unbond *big.Int) *Slash {
+	return &Slash{
+		bonder: common.AddressToPtr(bonder),
+		bond:   bond,
+		unbond: unbond,
+	}
+}
+
+func (s *Slash) Bonder() module.Address {
+	return s.bonder
+}
+
+func (s *Slash) Bond() *big.Int {
+	return s.bond
+}
+
+func (s *Slash) Unbond() *big.Int {
+	return s.unbond
+}
+
+func (s *Slash) Equal(s2 *Slash) bool {
+	return s.bonder.Equal(s2.bonder) &&
+		s.bond.Cmp(s2.bond) == 0 &&
+		s.unbond.Cmp(s2.unbond) == 0
+}
+
+func (s *Slash) RLPEncodeSelf(e codec.Encoder) error {
+	return e.EncodeListOf(s.bonder, s.bond, s.unbond)
+}
+
+func (s *Slash) RLPDecodeSelf(d codec.Decoder) error {
+	return d.DecodeListOf(&s.bonder, &s.bond, &s.unbond)
+}
+
+func (s *Slash) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"bonder": s.bonder,
+		"bond":   s.bond,
+		"unbond": s.unbond,
+	}
+}
+
+// PenaltyRecord is an entry of the penalty history of a P-Rep.
+type PenaltyRecord struct {
+	icobject.NoDatabase
+
+	height  int64
+	penalty int
+	grade   int
+	status  int
+	slashes []*Slash
+}
+
+func newPenaltyRecordWithTag(_ icobject.Tag) *PenaltyRecord {
+	return new(PenaltyRecord)
+}
+
+func NewPenaltyRecord(
+	height int64, penalty icmodule.PenaltyType, grade Grade, status Status, slashes []*Slash,
+) *PenaltyRecord {
+	return &PenaltyRecord{
+		height:  height,
+		penalty: int(penalty),
+		grade:   int(grade),
+		status:  int(status),
+		slashes: slashes,
+	}
+}
+
+func (r *PenaltyRecord) Version() int {
+	return penaltyRecordVersion
+}
+
+func (r *PenaltyRecord) Height() int64 {
+	return r.height
+}
+
+func (r *PenaltyRecord) Penalty() icmodule.PenaltyType {
+	return icmodule.PenaltyType(r.penalty)
+}
+
+func (r *PenaltyRecord) Grade() Grade {
+	return Grade(r.grade)
+}
+
+func (r *PenaltyRecord) Status() Status {
+	return Status(r.status)
+}
+
+func (r *PenaltyRecord) Slashes() []*Slash {
+	return r.slashes
+}
+
+func (r *PenaltyRecord) RLPDecodeFields(decoder codec.Decoder) error {
+	return decoder.DecodeAll(
+		&r.height,
+		&r.penalty,
+		&r.grade,
+		&r.status,
+		&r.slashes,
+	)
+}
+
+func (r *PenaltyRecord) RLPEncodeFields(encoder codec.Encoder) error {
+	return encoder.EncodeMulti(
+		r.height,
+		r.penalty,
+		r.grade,
+		r.status,
+		r.slashes,
+	)
+}
+
+func (r *PenaltyRecord) Equal(o icobject.Impl) bool {
+	r2, ok := o.(*PenaltyRecord)
+	if !ok {
+		return false
+	}
+	if r == r2 {
+		return true
+	}
+	if r.height != r2.height || r.penalty != r2.penalty ||
+		r.grade != r2.grade || r.status != r2.status ||
+		len(r.slashes) != len(r2.slashes) {
+		return false
+	}
+	for i, s := range r.slashes {
+		if !s.Equal(r2.slashes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *PenaltyRecord) ToJSON() map[string]interface{} {
+	slashes := make([]interface{}, 0, len(r.slashes))
+	for _, s := range r.slashes {
+		slashes = append(slashes, s.ToJSON())
+	}
+	return map[string]interface{}{
+		"blockHeight": r.height,
+		"penalty":     r.penalty,
+		"grade":       r.grade,
+		"status":      r.status,
+		"slashes":     slashes,
+	}
+}
+
+func (r *PenaltyRecord) Format(f fmt.State, c rune) {
+	switch c {
+	case 'v':
+		if f.Flag('+') {
+			fmt.Fprintf(f, "PenaltyRecord{height=%d penalty=%d grade=%d status=%d slashes=%d}",
+				r.height, r.penalty, r.grade, r.status, len(r.slashes))
+		} else {
+			fmt.Fprintf(f, "PenaltyRecord{%d %d %d %d %d}",
+				r.height, r.penalty, r.grade, r.status, len(r.slashes))
+		}
+	}
+}
+
+func (s *State) penaltyHistoryOf(owner module.Address) *containerdb.ArrayDB {
+	return containerdb.NewArrayDB(s.store, penaltyHistoryPrefix.Append(owner))
+}
+
+// AddPenaltyRecord appends the record to the penalty history of the P-Rep.
+func (s *State) AddPenaltyRecord(owner module.Address, record *PenaltyRecord) error {
+	return s.penaltyHistoryOf(owner).Put(icobject.New(TypePenaltyRecord, record))
+}
+
+// GetPenaltyHistory returns penalty records of the P-Rep in imposed order.
+func (s *State) GetPenaltyHistory(owner module.Address) []*PenaltyRecord {
+	db := s.penaltyHistoryOf(owner)
+	size := db.Size()
+	records := make([]*PenaltyRecord, 0, size)
+	for i := 0; i < size; i++ {
+		records = append(records, ToPenaltyRecord(db.Get(i).Object()))
+	}
+	return records
+}
+
+func (s *State) GetPenaltyHistoryInJSON(blockHeight int64, owner module.Address) (map[string]interface{}, error) {
+	if owner == nil {
+		return nil, scoreresult.InvalidParameterError.New("InvalidAddress")
+	}
+	if s.GetPRepBaseByOwner(owner, false) == nil {
+		return nil, scoreresult.InvalidParameterError.Errorf("PRepNotFound(address=%s)", owner)
+	}
+	records := s.GetPenaltyHistory(owner)
+	history := make([]interface{}, 0, len(records))
+	for _, r := range records {
+		history = append(history, r.ToJSON())
+	}
+	return map[string]interface{}{
+		"blockHeight": blockHeight,
+		"address":     owner,
+		"history":     history,
+	}, nil
+}
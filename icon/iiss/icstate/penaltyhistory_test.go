@@ -0,0 +1,70 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icstate
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/icon/icmodule"
+)
+
+func TestState_PenaltyHistory(t *testing.T) {
+	s := newDummyState(false)
+	owner := newDummyAddress(1)
+	bonder := newDummyAddress(2)
+
+	assert.Zero(t, len(s.GetPenaltyHistory(owner)))
+	_, err := s.GetPenaltyHistoryInJSON(100, owner)
+	assert.Error(t, err)
+
+	records := []*PenaltyRecord{
+		NewPenaltyRecord(100, icmodule.PenaltyBlockValidation, GradeCandidate, Active, nil),
+		NewPenaltyRecord(200, icmodule.PenaltyNonVote, GradeCandidate, Active, []*Slash{
+			NewSlash(bonder, big.NewInt(10), big.NewInt(5)),
+		}),
+		NewPenaltyRecord(300, icmodule.PenaltyPRepDisqualification, GradeNone, Disqualified, nil),
+	}
+	for _, r := range records {
+		assert.NoError(t, s.AddPenaltyRecord(owner, r))
+	}
+	assert.NoError(t, s.RegisterPRep(owner, newDummyPRepInfo(1), big.NewInt(0), 0))
+
+	s = flushAndNewState(s, false)
+	history := s.GetPenaltyHistory(owner)
+	assert.Equal(t, len(records), len(history))
+	for i, r := range records {
+		assert.True(t, r.Equal(history[i]))
+	}
+	assert.Equal(t, icmodule.PenaltyNonVote, history[1].Penalty())
+	assert.True(t, history[1].Slashes()[0].Bonder().Equal(bonder))
+	assert.Zero(t, len(s.GetPenaltyHistory(bonder)))
+
+	jso, err := s.GetPenaltyHistoryInJSON(400, owner)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(400), jso["blockHeight"])
+	jsoHistory := jso["history"].([]interface{})
+	assert.Equal(t, len(records), len(jsoHistory))
+	r1 := jsoHistory[1].(map[string]interface{})
+	assert.Equal(t, int64(200), r1["blockHeight"])
+	assert.Equal(t, int(icmodule.PenaltyNonVote), r1["penalty"])
+	slash := r1["slashes"].([]interface{})[0].(map[string]interface{})
+	assert.Zero(t, big.NewInt(10).Cmp(slash["bond"].(*big.Int)))
+	assert.Zero(t, big.NewInt(5).Cmp(slash["unbond"].(*big.Int)))
+}
@@ -82,6 +82,7 @@ func NewPRep(owner module.Address, state *State) *PRep {
 
 type PRepSet interface {
 	OnTermEnd(revision, mainPRepCount, subPRepCount, extraMainPRepCount, limit int, br int64) error
+	ElectGrades(revision, mainPRepCount, subPRepCount, extraMainPRepCount int, br int64) []Grade
 	GetPRepSize(grade Grade) int
 	GetElectedPRepSize() int
 	Size() int
@@ -157,29 +158,42 @@ type prepSetImpl struct {
 	entries        []PRepSetEntry
 }
 
-// OnTermEnd initializes all prep status including grade on term end
-func (p *prepSetImpl) OnTermEnd(revision, mainPRepCount, subPRepCount, extraMainPRepCount, limit int, br int64) error {
-	mainPReps := 0
-	subPReps := 0
+// ElectGrades returns grades of entries in the current order to be applied
+// on term end. It doesn't change the status of P-Reps.
+func (p *prepSetImpl) ElectGrades(revision, mainPRepCount, subPRepCount, extraMainPRepCount int, br int64) []Grade {
 	electedPRepCount := mainPRepCount + subPRepCount
-
-	var newGrade Grade
+	grades := make([]Grade, len(p.entries))
 	for i, entry := range p.entries {
 		if revision >= icmodule.RevisionBTP2 &&
 			(entry.Power(br).Sign() == 0 || entry.HasPubKey() == false) {
-			newGrade = GradeCandidate
+			grades[i] = GradeCandidate
 		} else if i < mainPRepCount {
-			newGrade = GradeMain
-			mainPReps++
+			grades[i] = GradeMain
 		} else if i < mainPRepCount+extraMainPRepCount && entry.Power(br).Sign() > 0 {
 			// Prevent a prep with 0 power from being an extra main prep
-			newGrade = GradeMain
-			mainPReps++
+			grades[i] = GradeMain
 		} else if i < electedPRepCount {
-			newGrade = GradeSub
-			subPReps++
+			grades[i] = GradeSub
 		} else {
-			newGrade = GradeCandidate
+			grades[i] = GradeCandidate
+		}
+	}
+	return grades
+}
+
+// OnTermEnd initializes all prep status including grade on term end
+func (p *prepSetImpl) OnTermEnd(revision, mainPRepCount, subPRepCount, extraMainPRepCount, limit int, br int64) error {
+	mainPReps := 0
+	subPReps := 0
+
+	grades := p.ElectGrades(revision, mainPRepCount, subPRepCount, extraMainPRepCount, br)
+	for i, entry := range p.entries {
+		newGrade := grades[i]
+		switch newGrade {
+		case GradeMain:
+			mainPReps++
+		case GradeSub:
+			subPReps++
 		}
 
 		prep := entry.PRep()
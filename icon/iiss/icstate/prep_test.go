@@ -325,6 +325,46 @@ func TestPRepSet_Sort_OnTermEnd(t *testing.T) {
 	}
 }
 
+func TestPRepSet_ElectGrades(t *testing.T) {
+	br := int64(5)
+	powers := []int{5, 4, 3, 2, 0, 1}
+	pubKeys := []bool{true, true, false, true, true, true}
+	prepSetEntries := make([]PRepSetEntry, len(powers))
+	for i, power := range powers {
+		prep := newDummyPRep(i + 1)
+		prep.grade = GradeCandidate
+		prepSetEntries[i] = newDummyPRepSetEntry(prep, GradeCandidate, power, power, power, pubKeys[i])
+	}
+	prepSet := NewPRepSet(prepSetEntries)
+
+	rev := icmodule.RevisionBTP2
+	main, sub, extra := 1, 3, 1
+	prepSet.Sort(main, sub, extra, br, rev)
+	grades := prepSet.ElectGrades(rev, main, sub, extra, br)
+	assert.Equal(t, prepSet.Size(), len(grades))
+
+	// grades of P-Reps are not changed by ElectGrades
+	for i := 0; i < prepSet.Size(); i++ {
+		assert.Equal(t, GradeCandidate, prepSet.GetByIndex(i).PRep().Grade())
+	}
+
+	// it returns the grades applied on term end
+	assert.NoError(t, prepSet.OnTermEnd(rev, main, sub, extra, 0, br))
+	mains := 0
+	for i, grade := range grades {
+		entry := prepSet.GetByIndex(i)
+		assert.Equal(t, grade, entry.PRep().Grade())
+		if !entry.HasPubKey() || entry.Power(br).Sign() == 0 {
+			assert.Equal(t, GradeCandidate, grade)
+		}
+		if grade == GradeMain {
+			mains++
+		}
+	}
+	assert.Equal(t, main+extra, mains)
+	assert.Equal(t, mains, prepSet.GetPRepSize(GradeMain))
+}
+
 func TestPRepSet_SortForQuery(t *testing.T) {
 	br := int64(5)
 	prep1 := newDummyPRep(1)
@@ -39,6 +39,10 @@ func (ss *Snapshot) Flush() error {
 	return nil
 }
 
+func (ss *Snapshot) Diff(ss2 *Snapshot, handler trie_manager.BytesDiffHandler) error {
+	return ss.store.Diff(ss2.store, handler)
+}
+
 func (ss *Snapshot) GetValue(key []byte) ([]byte, error) {
 	var value []byte
 	o, err := ss.store.Get(key)
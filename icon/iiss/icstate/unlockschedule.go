@@ -0,0 +1,169 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icstate
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/module"
+)
+
+// MaxUnlockScheduleRange is the maximum number of blocks in the range of
+// unlock schedule across all accounts, as it looks up timers of every height.
+const MaxUnlockScheduleRange = icmodule.DayBlock
+
+// UnlockSchedule is the amount of unstake and unbond expiring at the height.
+// Expired unstakes are deposited to the balance, and expired unbonds become
+// the stake that can be unstaked.
+type UnlockSchedule struct {
+	height  int64
+	unstake *big.Int
+	unbond  *big.Int
+}
+
+func (u *UnlockSchedule) Height() int64 {
+	return u.height
+}
+
+func (u *UnlockSchedule) Unstake() *big.Int {
+	return u.unstake
+}
+
+func (u *UnlockSchedule) Unbond() *big.Int {
+	return u.unbond
+}
+
+func (u *UnlockSchedule) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"blockHeight": u.height,
+		"unstake":     u.unstake,
+		"unbond":      u.unbond,
+	}
+}
+
+type unlockScheduleBuilder map[int64]*UnlockSchedule
+
+func (b unlockScheduleBuilder) get(height int64) *UnlockSchedule {
+	u, ok := b[height]
+	if !ok {
+		u = &UnlockSchedule{
+			height:  height,
+			unstake: new(big.Int),
+			unbond:  new(big.Int),
+		}
+		b[height] = u
+	}
+	return u
+}
+
+func (b unlockScheduleBuilder) addAccount(as *AccountSnapshot, start, end int64, unstake, unbond bool) {
+	if as == nil {
+		return
+	}
+	if unstake {
+		for _, us := range as.UnStakes() {
+			if h := us.GetExpire(); h >= start && h <= end {
+				u := b.get(h)
+				u.unstake.Add(u.unstake, us.GetValue())
+			}
+		}
+	}
+	if unbond {
+		for _, ub := range as.Unbonds() {
+			if h := ub.Expire(); h >= start && h <= end {
+				u := b.get(h)
+				u.unbond.Add(u.unbond, ub.Value())
+			}
+		}
+	}
+}
+
+func (b unlockScheduleBuilder) build() []*UnlockSchedule {
+	schedule := make([]*UnlockSchedule, 0, len(b))
+	for _, u := range b {
+		schedule = append(schedule, u)
+	}
+	sort.Slice(schedule, func(i, j int) bool {
+		return schedule[i].height < schedule[j].height
+	})
+	return schedule
+}
+
+// GetUnlockSchedule returns the amount of unstakes and unbonds expiring in
+// the range from start to end (inclusive) ordered by height. If address is
+// nil, it aggregates entries of all accounts using timers.
+func (s *State) GetUnlockSchedule(address module.Address, start, end int64) ([]*UnlockSchedule, error) {
+	if start < 0 || start > end {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidRange(start=%d,end=%d)", start, end)
+	}
+	b := make(unlockScheduleBuilder)
+	if address != nil {
+		b.addAccount(s.GetAccountSnapshot(address), start, end, true, true)
+		return b.build(), nil
+	}
+	if end-start >= MaxUnlockScheduleRange {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"TooLargeRange(start=%d,end=%d,max=%d)", start, end, MaxUnlockScheduleRange)
+	}
+	for h := start; h <= end; h++ {
+		if ts := s.GetUnstakingTimerSnapshot(h); ts != nil {
+			for itr := ts.Iterator(); itr.Has(); itr.Next() {
+				a, _ := itr.Get()
+				b.addAccount(s.GetAccountSnapshot(a), h, h, true, false)
+			}
+		}
+		if ts := s.GetUnbondingTimerSnapshot(h); ts != nil {
+			for itr := ts.Iterator(); itr.Has(); itr.Next() {
+				a, _ := itr.Get()
+				b.addAccount(s.GetAccountSnapshot(a), h, h, false, true)
+			}
+		}
+	}
+	return b.build(), nil
+}
+
+func (s *State) GetUnlockScheduleInJSON(
+	blockHeight int64, address module.Address, start, end int64,
+) (map[string]interface{}, error) {
+	schedule, err := s.GetUnlockSchedule(address, start, end)
+	if err != nil {
+		return nil, err
+	}
+	totalUnstake := new(big.Int)
+	totalUnbond := new(big.Int)
+	entries := make([]interface{}, 0, len(schedule))
+	for _, u := range schedule {
+		totalUnstake.Add(totalUnstake, u.unstake)
+		totalUnbond.Add(totalUnbond, u.unbond)
+		entries = append(entries, u.ToJSON())
+	}
+	jso := map[string]interface{}{
+		"blockHeight":      blockHeight,
+		"startBlockHeight": start,
+		"endBlockHeight":   end,
+		"totalUnstake":     totalUnstake,
+		"totalUnbond":      totalUnbond,
+		"schedule":         entries,
+	}
+	if address != nil {
+		jso["address"] = address
+	}
+	return jso, nil
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icstate
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+)
+
+func addUnstake(t *testing.T, s *State, addr module.Address, v int64, h int64) {
+	as := s.GetAccountState(addr)
+	tl, err := as.IncreaseUnstake(big.NewInt(v), h, 10, icmodule.LatestRevision)
+	assert.NoError(t, err)
+	for _, j := range tl {
+		ScheduleTimerJob(s.GetUnstakingTimerState(j.Height), j, addr)
+	}
+}
+
+func addUnbond(t *testing.T, s *State, addr, prep module.Address, v int64, h int64) {
+	as := s.GetAccountState(addr)
+	delta := map[string]*big.Int{icutils.ToKey(prep): big.NewInt(-v)}
+	tl, err := as.UpdateUnbonds(delta, h)
+	assert.NoError(t, err)
+	for _, j := range tl {
+		ScheduleTimerJob(s.GetUnbondingTimerState(j.Height), j, addr)
+	}
+}
+
+func checkUnlockSchedule(t *testing.T, exp [][3]int64, schedule []*UnlockSchedule) {
+	if !assert.Equal(t, len(exp), len(schedule)) {
+		return
+	}
+	for i, e := range exp {
+		assert.Equal(t, e[0], schedule[i].Height())
+		assert.Equal(t, e[1], schedule[i].Unstake().Int64())
+		assert.Equal(t, e[2], schedule[i].Unbond().Int64())
+	}
+}
+
+func TestState_GetUnlockSchedule(t *testing.T) {
+	s := newDummyState(false)
+	user1 := newDummyAddress(1)
+	user2 := newDummyAddress(2)
+	prep := newDummyAddress(100)
+
+	addUnstake(t, s, user1, 10, 100)
+	addUnstake(t, s, user1, 5, 150)
+	addUnstake(t, s, user2, 20, 100)
+	addUnbond(t, s, user2, prep, 30, 120)
+	s = flushAndNewState(s, false)
+
+	schedule, err := s.GetUnlockSchedule(nil, 1, 200)
+	assert.NoError(t, err)
+	checkUnlockSchedule(t, [][3]int64{
+		{100, 30, 0},
+		{120, 0, 30},
+		{150, 5, 0},
+	}, schedule)
+
+	schedule, err = s.GetUnlockSchedule(nil, 101, 149)
+	assert.NoError(t, err)
+	checkUnlockSchedule(t, [][3]int64{
+		{120, 0, 30},
+	}, schedule)
+
+	schedule, err = s.GetUnlockSchedule(user1, 1, 200)
+	assert.NoError(t, err)
+	checkUnlockSchedule(t, [][3]int64{
+		{100, 10, 0},
+		{150, 5, 0},
+	}, schedule)
+
+	schedule, err = s.GetUnlockSchedule(user2, 110, 200)
+	assert.NoError(t, err)
+	checkUnlockSchedule(t, [][3]int64{
+		{120, 0, 30},
+	}, schedule)
+
+	schedule, err = s.GetUnlockSchedule(prep, 1, 200)
+	assert.NoError(t, err)
+	assert.Zero(t, len(schedule))
+
+	// per address query isn't limited by the range
+	_, err = s.GetUnlockSchedule(user1, 1, MaxUnlockScheduleRange*2)
+	assert.NoError(t, err)
+
+	_, err = s.GetUnlockSchedule(nil, 1, MaxUnlockScheduleRange+1)
+	assert.Error(t, err)
+	_, err = s.GetUnlockSchedule(nil, 200, 100)
+	assert.Error(t, err)
+
+	jso, err := s.GetUnlockScheduleInJSON(50, nil, 51, 200)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(35), jso["totalUnstake"].(*big.Int).Int64())
+	assert.Equal(t, int64(30), jso["totalUnbond"].(*big.Int).Int64())
+	assert.Equal(t, 3, len(jso["schedule"].([]interface{})))
+	_, ok := jso["address"]
+	assert.False(t, ok)
+}
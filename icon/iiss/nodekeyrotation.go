@@ -0,0 +1,185 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/icon-project/goloop/btp/ntm"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoreresult"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// checkNodePublicKeys returns an error if one of public keys belongs to
+// a node other than the current and the new node of the P-Rep.
+func checkNodePublicKeys(bc state.BTPContext, prep *icstate.PRep, r *icstate.NodeKeyRotation) error {
+	for _, k := range r.PublicKeys() {
+		owner, err := state.GetPublicKeyOwner(bc, k.DSA(), k.PublicKey())
+		if err != nil {
+			return err
+		}
+		if owner != nil && !owner.Equal(r.Node()) && !owner.Equal(prep.NodeAddress()) {
+			return scoreresult.InvalidParameterError.Errorf(
+				"PublicKeyInUse(dsa=%s,node=%s)", k.DSA(), owner)
+		}
+	}
+	return nil
+}
+
+// changesPublicKeys returns whether the rotation changes one of public keys
+// of its node. Public keys of DSAs not in the rotation are kept.
+func changesPublicKeys(bc state.BTPContext, r *icstate.NodeKeyRotation) bool {
+	for _, k := range r.PublicKeys() {
+		pubKey := k.PublicKey()
+		if dsa := ntm.DSAModuleForName(k.DSA()); dsa != nil {
+			if cpk, err := dsa.Canonicalize(pubKey); err == nil {
+				pubKey = cpk
+			}
+		}
+		if !bytes.Equal(bc.GetPublicKey(r.Node(), k.DSA()), pubKey) {
+			return true
+		}
+	}
+	return false
+}
+
+func (es *ExtensionStateImpl) ScheduleNodeKeyRotation(cc icmodule.CallContext, r *icstate.NodeKeyRotation) error {
+	from := cc.From()
+	prep := es.GetPRep(from)
+	if prep == nil {
+		return scoreresult.InvalidParameterError.Errorf("PRepNotFound(%s)", from)
+	}
+	bc := cc.GetBTPContext()
+	if err := checkNodePublicKeys(bc, prep, r); err != nil {
+		return err
+	}
+	// The node may be kept if public keys of other DSAs are changed
+	if r.Node().Equal(prep.NodeAddress()) && !changesPublicKeys(bc, r) {
+		return scoreresult.InvalidParameterError.Errorf("NoChangeOfNodeKey(node=%s)", r.Node())
+	}
+	if err := es.State.ScheduleNodeKeyRotation(cc.BlockHeight(), from, r); err != nil {
+		return scoreresult.InvalidParameterError.Wrapf(err, "Failed to schedule node key rotation: from=%v", from)
+	}
+	cc.OnEvent(state.SystemAddress,
+		[][]byte{[]byte("PRepNodeKeyRotationScheduled(Address,Address,int)"), from.Bytes()},
+		[][]byte{r.Node().Bytes(), intconv.Int64ToBytes(r.Height())},
+	)
+	return nil
+}
+
+func (es *ExtensionStateImpl) CancelNodeKeyRotation(cc icmodule.CallContext) error {
+	from := cc.From()
+	if err := es.State.CancelNodeKeyRotation(from); err != nil {
+		return scoreresult.InvalidParameterError.Wrapf(err, "Failed to cancel node key rotation: from=%v", from)
+	}
+	cc.OnEvent(state.SystemAddress,
+		[][]byte{[]byte("PRepNodeKeyRotationCanceled(Address)"), from.Bytes()},
+		nil,
+	)
+	return nil
+}
+
+// nodeDSAs returns names of DSAs which can be used for public keys of nodes.
+func nodeDSAs() []string {
+	names := make(map[string]struct{})
+	for _, mod := range ntm.Modules() {
+		names[mod.DSA()] = struct{}{}
+	}
+	dsas := make([]string, 0, len(names))
+	for name := range names {
+		dsas = append(dsas, name)
+	}
+	sort.Strings(dsas)
+	return dsas
+}
+
+// handleNodeKeyRotation activates node key rotations scheduled at the block.
+// Validators are updated with the new node at the end of the block.
+func (es *ExtensionStateImpl) handleNodeKeyRotation(wc icmodule.WorldContext) error {
+	blockHeight := wc.BlockHeight()
+	owners, rotations, err := es.State.PopNodeKeyRotations(blockHeight)
+	if err != nil || len(owners) == 0 {
+		return err
+	}
+	bs, ok := wc.GetBTPState().(*state.BTPStateImpl)
+	if !ok {
+		return errors.InvalidStateError.New("InvalidBTPState")
+	}
+	bc := wc.GetBTPContext()
+	offset := int(blockHeight - es.State.GetTermSnapshot().StartHeight())
+	// BTP public key events of rotations use negative indexes to avoid
+	// collisions with the ones of transactions in the block.
+	index := 0
+	for i, owner := range owners {
+		r := rotations[i]
+		prep := es.GetPRep(owner)
+		if err = checkNodePublicKeys(bc, prep, r); err != nil {
+			es.logger.Warnf("Drop node key rotation: bh=%d owner=%s %+v err=%v", blockHeight, owner, r, err)
+			continue
+		}
+		oldNode := prep.NodeAddress()
+		newNode := r.Node()
+
+		// Public keys of the old node are removed first, as a public key
+		// can't belong to multiple nodes. Keys not in the rotation move to
+		// the new node.
+		pubKeys := r.PublicKeys()
+		for _, dsa := range nodeDSAs() {
+			pubKey := bc.GetPublicKey(oldNode, dsa)
+			if pubKey == nil {
+				continue
+			}
+			if r.PublicKey(dsa) == nil {
+				pubKeys = append(pubKeys, icstate.NewNodePublicKey(dsa, pubKey))
+			}
+			if err = bs.SetPublicKey(bc, oldNode, dsa, []byte{}); err != nil {
+				return err
+			}
+		}
+		if !newNode.Equal(oldNode) {
+			if _, err = es.State.SetPRep(blockHeight, owner, &icstate.PRepInfo{Node: newNode}); err != nil {
+				return err
+			}
+		}
+		for _, k := range pubKeys {
+			if err = bs.SetPublicKey(bc, newNode, k.DSA(), k.PublicKey()); err != nil {
+				return err
+			}
+			index -= 1
+			if err = es.Front.AddBTPPublicKey(offset, index, owner, bc.GetDSAIndex(k.DSA())); err != nil {
+				return err
+			}
+		}
+		prep.SetDSAMask(bc.GetPublicKeyMask(newNode))
+		es.logger.Infof("Node key rotated: bh=%d owner=%s old=%s new=%s", blockHeight, owner, oldNode, newNode)
+	}
+	return nil
+}
+
+func (es *ExtensionStateImpl) GetNodeKeyRotationInJSON(blockHeight int64, owner module.Address) (map[string]interface{}, error) {
+	jso, err := es.State.GetNodeKeyRotationInJSON(blockHeight, owner)
+	if err != nil {
+		return nil, scoreresult.InvalidParameterError.Wrap(err, "Failed to get node key rotation")
+	}
+	return jso, nil
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/icon/icmodule"
 	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
 	"github.com/icon-project/goloop/icon/iiss/icutils"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/service/state"
@@ -58,21 +59,43 @@ func (es *ExtensionStateImpl) handlePenalty(cc icmodule.CallContext, owner modul
 	)
 
 	// Slashing
+	var slashes []*icstate.Slash
 	revision := cc.Revision().Value()
 	if es.State.CheckConsistentValidationPenalty(revision, ps) {
 		slashRatio := es.State.GetConsistentValidationPenaltySlashRatio()
-		if err = es.slash(cc, owner, slashRatio); err != nil {
+		if slashes, err = es.slash(cc, owner, slashRatio); err != nil {
 			return err
 		}
 	}
+	if err = es.recordPenalty(cc, owner, icmodule.PenaltyBlockValidation, slashes); err != nil {
+		return err
+	}
 
 	// Record event for reward calculation
 	return es.addEventEnable(blockHeight, owner, icstage.ESDisableTemp)
 }
 
-func (es *ExtensionStateImpl) slash(cc icmodule.CallContext, owner module.Address, ratio int) error {
+// recordPenalty appends the penalty imposed on the P-Rep to its penalty history,
+// so that bonders can find out when and why they were slashed.
+func (es *ExtensionStateImpl) recordPenalty(
+	cc icmodule.CallContext, owner module.Address, penalty icmodule.PenaltyType, slashes []*icstate.Slash,
+) error {
+	if cc.Revision().Value() < icmodule.RevisionPenaltyHistory {
+		return nil
+	}
+	ps := es.State.GetPRepStatusByOwner(owner, false)
+	if ps == nil {
+		return errors.Errorf("PRep not found: %s", owner)
+	}
+	record := icstate.NewPenaltyRecord(cc.BlockHeight(), penalty, ps.Grade(), ps.Status(), slashes)
+	return es.State.AddPenaltyRecord(owner, record)
+}
+
+// slash reduces bonds and unbonds of all bonders of the P-Rep by the ratio,
+// and returns the amount slashed from each bonder.
+func (es *ExtensionStateImpl) slash(cc icmodule.CallContext, owner module.Address, ratio int) ([]*icstate.Slash, error) {
 	if ratio < 0 || 100 < ratio {
-		return errors.Errorf("Invalid slash ratio %d", ratio)
+		return nil, errors.Errorf("Invalid slash ratio %d", ratio)
 	}
 
 	logger := cc.FrameLogger()
@@ -80,9 +103,10 @@ func (es *ExtensionStateImpl) slash(cc icmodule.CallContext, owner module.Addres
 
 	pb := es.State.GetPRepBaseByOwner(owner, false)
 	if pb == nil {
-		return errors.Errorf("PRep not found: %s", owner)
+		return nil, errors.Errorf("PRep not found: %s", owner)
 	}
 	bonders := pb.BonderList()
+	var slashes []*icstate.Slash
 	slashedBondSum := new(big.Int)
 	slashedStakeSum := new(big.Int)
 
@@ -108,14 +132,14 @@ func (es *ExtensionStateImpl) slash(cc icmodule.CallContext, owner module.Addres
 				if timer != nil {
 					timer.Delete(owner)
 				} else {
-					return errors.Errorf("timer doesn't exist for height %d", expire)
+					return nil, errors.Errorf("timer doesn't exist for height %d", expire)
 				}
 			}
 
 			// stake
 			slashedStake.Add(slashedBond, slashedUnbond)
 			if err := account.SlashStake(slashedStake); err != nil {
-				return err
+				return nil, err
 			}
 			slashedStakeSum.Add(slashedStakeSum, slashedStake)
 
@@ -124,7 +148,10 @@ func (es *ExtensionStateImpl) slash(cc icmodule.CallContext, owner module.Addres
 				icutils.ToKey(owner): new(big.Int).Neg(slashedBond),
 			}
 			if err := es.AddEventBond(cc.BlockHeight(), bonder, delta); err != nil {
-				return err
+				return nil, err
+			}
+			if slashedStake.Sign() > 0 {
+				slashes = append(slashes, icstate.NewSlash(bonder, slashedBond, slashedUnbond))
 			}
 		}
 
@@ -145,10 +172,10 @@ func (es *ExtensionStateImpl) slash(cc icmodule.CallContext, owner module.Addres
 	oldTotalStake := es.State.GetTotalStake()
 	newTotalStake := new(big.Int).Sub(oldTotalStake, slashedStakeSum)
 	if err := es.State.SetTotalStake(newTotalStake); err != nil {
-		return err
+		return nil, err
 	}
 	if err := es.State.ReducePRepBonded(owner, slashedBondSum); err != nil {
-		return err
+		return nil, err
 	}
 	err := cc.HandleBurn(state.SystemAddress, slashedStakeSum)
 
@@ -156,5 +183,5 @@ func (es *ExtensionStateImpl) slash(cc icmodule.CallContext, owner module.Addres
 		"IISS slash end owner=%s slashedBondSum=%v slashedStakeSum=%v oldTotalStake=%v newTotalStake=%v",
 		owner, slashedBondSum, slashedStakeSum, oldTotalStake, newTotalStake,
 	)
-	return err
+	return slashes, err
 }
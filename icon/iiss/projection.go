@@ -0,0 +1,172 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"math/big"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/icon/iiss/icreward"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+)
+
+// RewardProjection is I-Score expected to be rewarded to an account for a
+// whole term with the parameters of the current term, assuming that P-Rep
+// status and votes of other accounts don't change.
+type RewardProjection struct {
+	Period  int
+	Voted   *big.Int
+	Voting  *big.Int
+	Details []*RewardDetail
+}
+
+func (p *RewardProjection) IScore() *big.Int {
+	return new(big.Int).Add(p.Voted, p.Voting)
+}
+
+func (p *RewardProjection) ToJSON() map[string]interface{} {
+	details := make([]interface{}, 0, len(p.Details))
+	for _, d := range p.Details {
+		details = append(details, d.ToJSON())
+	}
+	iscore := p.IScore()
+	return map[string]interface{}{
+		"period":       intconv.FormatInt(int64(p.Period)),
+		"voted":        intconv.FormatBigInt(p.Voted),
+		"voting":       intconv.FormatBigInt(p.Voting),
+		"iscore":       intconv.FormatBigInt(iscore),
+		"estimatedICX": intconv.FormatBigInt(icutils.IScoreToICX(iscore)),
+		"rewards":      details,
+	}
+}
+
+// ProjectReward estimates I-Score of the account for a term with delegations
+// and bonds. Current ones of the account are used for nil values.
+// dsaMask is the mask of active DSAs which P-Reps need for voted reward.
+func (es *ExtensionStateImpl) ProjectReward(
+	addr module.Address, ds icstate.Delegations, bonds icstate.Bonds, dsaMask int64,
+) (*RewardProjection, error) {
+	global, err := es.Front.GetGlobal()
+	if err != nil {
+		return nil, err
+	}
+	if global == nil {
+		return nil, errors.InvalidStateError.New("NoRewardParameters")
+	}
+	account := es.State.GetAccountSnapshot(addr)
+	if account == nil {
+		account = icstate.GetEmptyAccountSnapshot()
+	}
+	if ds == nil {
+		ds = account.Delegations()
+	}
+	if bonds == nil {
+		bonds = account.Bonds()
+	}
+
+	vInfo, prepInfo, err := es.loadCurrentVotedInfo(global, dsaMask, account, ds, bonds)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Calculator{
+		log:    es.Logger(),
+		global: global,
+	}
+	offsetLimit := global.GetOffsetLimit()
+	p := &RewardProjection{
+		Period: offsetLimit + 1,
+		Voted:  new(big.Int),
+		Voting: new(big.Int),
+	}
+
+	multiplier, divider := varForVotedReward(global)
+	vInfo.CalculateReward(multiplier, divider, p.Period)
+	if data := vInfo.GetPRepByAddress(addr); data != nil {
+		p.Voted.Set(data.IScore())
+	}
+
+	details := newRewardDetails()
+	details.add(addr, TypeVoted, "", p.Voted)
+	multiplier, divider = varForVotingReward(global, vInfo.TotalVoted())
+	if multiplier.Sign() != 0 && divider.Sign() != 0 {
+		byPRep := make(rewardsByPRep)
+		p.Voting.Add(p.Voting,
+			c.votingRewardByPRep(multiplier, divider, -1, offsetLimit, prepInfo, ds.Iterator(), byPRep))
+		p.Voting.Add(p.Voting,
+			c.votingRewardByPRep(multiplier, divider, -1, offsetLimit, prepInfo, bonds.Iterator(), byPRep))
+		details.addVoting(addr, p.Voting, byPRep)
+	}
+	if p.Details, err = details.rewardsOf(icutils.ToKey(addr)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// loadCurrentVotedInfo returns votedInfo of active P-Reps with current votes
+// replacing votes of the account with ds and bonds.
+func (es *ExtensionStateImpl) loadCurrentVotedInfo(
+	global icstage.Global, dsaMask int64, account *icstate.AccountSnapshot,
+	ds icstate.Delegations, bonds icstate.Bonds,
+) (*votedInfo, map[string]*pRepEnable, error) {
+	vInfo := newVotedInfo(global.GetElectedPRepCount())
+	prepInfo := make(map[string]*pRepEnable)
+	for _, prep := range es.State.GetPReps(true) {
+		voted := icreward.NewVoted()
+		voted.SetEnable(true)
+		voted.SetDelegated(prep.Delegated())
+		voted.SetBonded(prep.Bonded())
+		data := newVotedData(voted)
+		data.SetPubKey(prep.GetDSAMask()&dsaMask == dsaMask)
+		vInfo.AddVotedData(prep.Owner(), data)
+		prepInfo[icutils.ToKey(prep.Owner())] = new(pRepEnable)
+	}
+
+	delegated, err := deltaToVotes(account.Delegations().Delta(ds))
+	if err != nil {
+		return nil, nil, err
+	}
+	bonded, err := deltaToVotes(account.Bonds().Delta(bonds))
+	if err != nil {
+		return nil, nil, err
+	}
+	// votes for inactive P-Reps don't affect rewards
+	vInfo.UpdateDelegated(votesForPReps(delegated, prepInfo))
+	vInfo.UpdateBonded(votesForPReps(bonded, prepInfo))
+
+	bondRequirement := global.GetBondRequirement()
+	for _, data := range vInfo.PReps() {
+		data.UpdateBondedDelegation(bondRequirement)
+	}
+	vInfo.Sort()
+	vInfo.UpdateTotalBondedDelegation()
+	return vInfo, prepInfo, nil
+}
+
+func votesForPReps(votes icstage.VoteList, prepInfo map[string]*pRepEnable) icstage.VoteList {
+	filtered := make(icstage.VoteList, 0, len(votes))
+	for _, v := range votes {
+		if _, ok := prepInfo[icutils.ToKey(v.To())]; ok {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icobject"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+)
+
+func newTestExtensionStateForProjection() *ExtensionStateImpl {
+	database := icobject.AttachObjectFactory(db.NewMapDB(), icstate.NewObjectImpl)
+	logger := log.New()
+	return &ExtensionStateImpl{
+		database: database,
+		logger:   logger,
+		State:    icstate.NewStateFromSnapshot(icstate.NewSnapshot(database, nil), false, logger),
+		Front:    icstage.NewState(database),
+	}
+}
+
+func TestExtensionStateImpl_ProjectReward(t *testing.T) {
+	es := newTestExtensionStateForProjection()
+	user := common.MustNewAddressFromString("hx10")
+	prep1 := common.MustNewAddressFromString("hx1")
+	prep2 := common.MustNewAddressFromString("hx2")
+
+	_, err := es.ProjectReward(user, nil, nil, 0)
+	assert.True(t, errors.InvalidStateError.Equals(err))
+
+	for i, p := range []struct {
+		owner     *common.Address
+		delegated int64
+	}{
+		{prep1, 1000},
+		{prep2, 3000},
+	} {
+		node := common.MustNewAddressFromString("hx" + string(rune('a'+i)))
+		err = es.State.RegisterPRep(p.owner, &icstate.PRepInfo{Node: node}, icmodule.BigIntInitialIRep, 0)
+		assert.NoError(t, err)
+		es.State.GetPRepStatusByOwner(p.owner, false).SetDelegated(big.NewInt(p.delegated))
+	}
+
+	iglobal := int64(10000)
+	err = es.Front.AddGlobalV2(icmodule.RevisionEnableIISS3, 100, MonthBlock-1,
+		big.NewInt(iglobal), big.NewInt(50), big.NewInt(50), big.NewInt(0), big.NewInt(0), 22, 0)
+	assert.NoError(t, err)
+
+	// multiplier = iglobal * ivoter * IScoreICXRatio, divider = 100 * MonthBlock * totalVoted
+	ds := icstate.Delegations{icstate.NewDelegation(prep1, big.NewInt(1000))}
+	p, err := es.ProjectReward(user, ds, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, MonthBlock, p.Period)
+	assert.Zero(t, p.Voted.Sign())
+	assert.Equal(t, iglobal*50*icmodule.IScoreICXRatio*1000/(100*5000), p.Voting.Int64())
+	if assert.Equal(t, 1, len(p.Details)) {
+		assert.Equal(t, TypeVoting, p.Details[0].Type)
+		assert.True(t, prep1.Equal(p.Details[0].PRep))
+	}
+
+	// current delegation of the user is empty
+	p, err = es.ProjectReward(user, nil, nil, 0)
+	assert.NoError(t, err)
+	assert.Zero(t, p.IScore().Sign())
+
+	// multiplier = iglobal * iprep * IScoreICXRatio, divider = 100 * MonthBlock
+	p, err = es.ProjectReward(prep1, nil, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, iglobal*50*icmodule.IScoreICXRatio*1000/(100*4000), p.Voted.Int64())
+	assert.Zero(t, p.Voting.Sign())
+
+	// replacing current delegation
+	es.State.GetAccountState(user).SetDelegation(ds)
+	es.State.GetPRepStatusByOwner(prep1, false).SetDelegated(big.NewInt(2000))
+	p, err = es.ProjectReward(user, icstate.Delegations{icstate.NewDelegation(prep2, big.NewInt(1000))}, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, iglobal*50*icmodule.IScoreICXRatio*1000/(100*5000), p.Voting.Int64())
+	if assert.Equal(t, 1, len(p.Details)) {
+		assert.True(t, prep2.Equal(p.Details[0].PRep))
+	}
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/icon/icdb"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icutils"
+	"github.com/icon-project/goloop/module"
+)
+
+// Keys in icdb.RewardDetail bucket
+const (
+	rewardDetailPrefixReward   = byte(0x01) // + address + startHeight
+	rewardDetailPrefixTerm     = byte(0x02) // + startHeight
+	rewardDetailPrefixSequence = byte(0x03) // + startHeight
+	rewardDetailKeyLatest      = byte(0x04)
+)
+
+func (t RewardType) String() string {
+	switch t {
+	case TypeBlockProduce:
+		return "blockProduce"
+	case TypeVoted:
+		return "voted"
+	case TypeVoting:
+		return "voting"
+	default:
+		return "unknown"
+	}
+}
+
+// RewardDetail is the I-Score rewarded for a type in a term.
+// PRep is the P-Rep voted by the account for TypeVoting, and it's nil for
+// other types or for the voting reward without the P-Rep.
+type RewardDetail struct {
+	Type   RewardType
+	PRep   *common.Address
+	IScore *big.Int
+}
+
+func (d *RewardDetail) ToJSON() map[string]interface{} {
+	jso := map[string]interface{}{
+		"type":   d.Type.String(),
+		"iscore": intconv.FormatBigInt(d.IScore),
+	}
+	if d.PRep != nil {
+		jso["prep"] = d.PRep
+	}
+	return jso
+}
+
+// TermRewardDetails is the breakdown of I-Score rewarded to an account in
+// the term starting at StartHeight. Sequence is the term sequence, or -1
+// if it's unknown.
+type TermRewardDetails struct {
+	StartHeight int64
+	EndHeight   int64
+	Sequence    int
+	Rewards     []*RewardDetail
+}
+
+func (d *TermRewardDetails) IScore() *big.Int {
+	total := new(big.Int)
+	for _, r := range d.Rewards {
+		total.Add(total, r.IScore)
+	}
+	return total
+}
+
+func (d *TermRewardDetails) ToJSON() map[string]interface{} {
+	rewards := make([]interface{}, 0, len(d.Rewards))
+	for _, r := range d.Rewards {
+		rewards = append(rewards, r.ToJSON())
+	}
+	jso := map[string]interface{}{
+		"startBlockHeight": intconv.FormatInt(d.StartHeight),
+		"endBlockHeight":   intconv.FormatInt(d.EndHeight),
+		"iscore":           intconv.FormatBigInt(d.IScore()),
+		"rewards":          rewards,
+	}
+	if d.Sequence >= 0 {
+		jso["sequence"] = intconv.FormatInt(int64(d.Sequence))
+	}
+	return jso
+}
+
+type rewardDetailKey struct {
+	t    RewardType
+	prep string
+}
+
+// rewardsByPRep has voting rewards keyed by the P-Rep.
+type rewardsByPRep map[string]*big.Int
+
+func (r rewardsByPRep) add(prep module.Address, reward *big.Int) {
+	if r == nil {
+		return
+	}
+	key := icutils.ToKey(prep)
+	if v, ok := r[key]; ok {
+		v.Add(v, reward)
+	} else {
+		r[key] = new(big.Int).Set(reward)
+	}
+}
+
+func (r rewardsByPRep) addAll(o rewardsByPRep, sign int) {
+	for key, reward := range o {
+		v, ok := r[key]
+		if !ok {
+			v = new(big.Int)
+			r[key] = v
+		}
+		if sign < 0 {
+			v.Sub(v, reward)
+		} else {
+			v.Add(v, reward)
+		}
+	}
+}
+
+// rewardDetails accumulates rewards of accounts in a calculation.
+type rewardDetails struct {
+	accounts map[string]map[rewardDetailKey]*big.Int
+}
+
+func newRewardDetails() *rewardDetails {
+	return &rewardDetails{
+		accounts: make(map[string]map[rewardDetailKey]*big.Int),
+	}
+}
+
+func (d *rewardDetails) add(addr module.Address, t RewardType, prep string, reward *big.Int) {
+	if d == nil || reward.Sign() == 0 {
+		return
+	}
+	akey := icutils.ToKey(addr)
+	rewards, ok := d.accounts[akey]
+	if !ok {
+		rewards = make(map[rewardDetailKey]*big.Int)
+		d.accounts[akey] = rewards
+	}
+	key := rewardDetailKey{t, prep}
+	if v, ok := rewards[key]; ok {
+		v.Add(v, reward)
+	} else {
+		rewards[key] = new(big.Int).Set(reward)
+	}
+}
+
+func (d *rewardDetails) addVoting(addr module.Address, reward *big.Int, byPRep rewardsByPRep) {
+	if d == nil {
+		return
+	}
+	sum := new(big.Int)
+	for prep, v := range byPRep {
+		d.add(addr, TypeVoting, prep, v)
+		sum.Add(sum, v)
+	}
+	// reward without the P-Rep. ex) replay of BugDisabledPRep
+	d.add(addr, TypeVoting, "", sum.Sub(reward, sum))
+}
+
+func (d *rewardDetails) rewardsOf(akey string) ([]*RewardDetail, error) {
+	rewards := d.accounts[akey]
+	details := make([]*RewardDetail, 0, len(rewards))
+	for key, v := range rewards {
+		if v.Sign() == 0 {
+			continue
+		}
+		detail := &RewardDetail{Type: key.t, IScore: v}
+		if key.prep != "" {
+			prep, err := common.NewAddress([]byte(key.prep))
+			if err != nil {
+				return nil, err
+			}
+			detail.PRep = prep
+		}
+		details = append(details, detail)
+	}
+	sort.Slice(details, func(i, j int) bool {
+		if details[i].Type != details[j].Type {
+			return details[i].Type < details[j].Type
+		}
+		var pi, pj []byte
+		if details[i].PRep != nil {
+			pi = details[i].PRep.Bytes()
+		}
+		if details[j].PRep != nil {
+			pj = details[j].PRep.Bytes()
+		}
+		return bytes.Compare(pi, pj) < 0
+	})
+	return details, nil
+}
+
+// write stores rewards of the term described by global.
+func (d *rewardDetails) write(dbase db.Database, global icstage.Global) error {
+	if d == nil || dbase == nil || global == nil {
+		return nil
+	}
+	bk := db.BucketOf(dbase, icdb.RewardDetail)
+	startHeight := global.GetStartHeight()
+	for akey := range d.accounts {
+		rewards, err := d.rewardsOf(akey)
+		if err != nil {
+			return err
+		}
+		value, err := codec.BC.MarshalToBytes(rewards)
+		if err != nil {
+			return err
+		}
+		if err = bk.Set(rewardKeyOf([]byte(akey), startHeight), value); err != nil {
+			return err
+		}
+	}
+	endHeight := startHeight + int64(global.GetOffsetLimit())
+	if err := bk.Set(termKeyOf(rewardDetailPrefixTerm, startHeight), codec.BC.MustMarshalToBytes(endHeight)); err != nil {
+		return err
+	}
+	var latest int64
+	if bs, err := bk.Get([]byte{rewardDetailKeyLatest}); err != nil {
+		return err
+	} else if bs != nil {
+		codec.BC.MustUnmarshalFromBytes(bs, &latest)
+	}
+	if startHeight > latest {
+		return bk.Set([]byte{rewardDetailKeyLatest}, codec.BC.MustMarshalToBytes(startHeight))
+	}
+	return nil
+}
+
+func termKeyOf(prefix byte, startHeight int64) []byte {
+	return append([]byte{prefix}, intconv.Int64ToBytes(startHeight)...)
+}
+
+func rewardKeyOf(addr []byte, startHeight int64) []byte {
+	key := make([]byte, 0, 1+len(addr)+8)
+	key = append(key, rewardDetailPrefixReward)
+	key = append(key, addr...)
+	return append(key, intconv.Int64ToBytes(startHeight)...)
+}
+
+// recordTermSequence keeps the sequence of the term starting at startHeight.
+func recordTermSequence(dbase db.Database, startHeight int64, sequence int) error {
+	bk := db.BucketOf(dbase, icdb.RewardDetail)
+	return bk.Set(termKeyOf(rewardDetailPrefixSequence, startHeight), codec.BC.MustMarshalToBytes(sequence))
+}
+
+// GetRewardDetails returns the breakdown of I-Score rewarded to the account
+// in the term starting at startHeight. If startHeight is not positive, it
+// returns the one of the latest calculated term.
+func GetRewardDetails(dbase db.Database, addr module.Address, startHeight int64) (*TermRewardDetails, error) {
+	bk := db.BucketOf(dbase, icdb.RewardDetail)
+	if startHeight <= 0 {
+		bs, err := bk.Get([]byte{rewardDetailKeyLatest})
+		if err != nil {
+			return nil, err
+		}
+		if bs == nil {
+			return nil, errors.NotFoundError.New("NoRewardDetails")
+		}
+		codec.BC.MustUnmarshalFromBytes(bs, &startHeight)
+	}
+	bs, err := bk.Get(termKeyOf(rewardDetailPrefixTerm, startHeight))
+	if err != nil {
+		return nil, err
+	}
+	if bs == nil {
+		return nil, errors.NotFoundError.Errorf("NoRewardDetails(startHeight=%d)", startHeight)
+	}
+	details := &TermRewardDetails{
+		StartHeight: startHeight,
+		Sequence:    -1,
+	}
+	if _, err = codec.BC.UnmarshalFromBytes(bs, &details.EndHeight); err != nil {
+		return nil, err
+	}
+	if bs, err = bk.Get(termKeyOf(rewardDetailPrefixSequence, startHeight)); err != nil {
+		return nil, err
+	} else if bs != nil {
+		if _, err = codec.BC.UnmarshalFromBytes(bs, &details.Sequence); err != nil {
+			return nil, err
+		}
+	}
+	if bs, err = bk.Get(rewardKeyOf(addr.Bytes(), startHeight)); err != nil {
+		return nil, err
+	} else if bs != nil {
+		if _, err = codec.BC.UnmarshalFromBytes(bs, &details.Rewards); err != nil {
+			return nil, err
+		}
+	}
+	return details, nil
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstage"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+)
+
+func TestCalculator_VotingRewardByPRep(t *testing.T) {
+	addr1 := common.MustNewAddressFromString("hx1")
+	addr2 := common.MustNewAddressFromString("hx2")
+	addr3 := common.MustNewAddressFromString("hx3")
+	prepInfo := map[string]*pRepEnable{
+		string(addr1.Bytes()): {0, 0},
+		string(addr2.Bytes()): {10, 0},
+	}
+	delegating := icstate.Delegations{
+		icstate.NewDelegation(addr1, big.NewInt(MinDelegation)),
+		icstate.NewDelegation(addr2, big.NewInt(MinDelegation*2)),
+		icstate.NewDelegation(addr3, big.NewInt(MinDelegation)),
+	}
+
+	calculator := new(Calculator)
+	calculator.log = log.New()
+	calculator.global = &testGlobal{iissVersion: icstate.IISSVersion3}
+
+	byPRep := make(rewardsByPRep)
+	reward := calculator.votingRewardByPRep(big.NewInt(100), big.NewInt(10), 0, 1000,
+		prepInfo, delegating.Iterator(), byPRep)
+
+	want1 := int64(100 * MinDelegation * 1000 / 10)
+	want2 := int64(100 * MinDelegation * 2 * (1000 - 10) / 10)
+	assert.Equal(t, want1+want2, reward.Int64())
+	assert.Equal(t, 2, len(byPRep))
+	assert.Equal(t, want1, byPRep[string(addr1.Bytes())].Int64())
+	assert.Equal(t, want2, byPRep[string(addr2.Bytes())].Int64())
+
+	sub := make(rewardsByPRep)
+	calculator.votingRewardByPRep(big.NewInt(100), big.NewInt(10), 500, 1000,
+		prepInfo, delegating.Iterator(), sub)
+	byPRep.addAll(sub, -1)
+	assert.Equal(t, want1/2, byPRep[string(addr1.Bytes())].Int64())
+}
+
+func TestRewardDetails(t *testing.T) {
+	dbase := db.NewMapDB()
+	user := common.MustNewAddressFromString("hx10")
+	prep1 := common.MustNewAddressFromString("hx1")
+	prep2 := common.MustNewAddressFromString("hx2")
+
+	_, err := GetRewardDetails(dbase, user, 0)
+	assert.True(t, errors.NotFoundError.Equals(err))
+
+	details := newRewardDetails()
+	details.add(prep1, TypeBlockProduce, "", big.NewInt(10))
+	details.add(prep1, TypeBlockProduce, "", big.NewInt(5))
+	details.add(prep1, TypeVoted, "", big.NewInt(20))
+	details.addVoting(user, big.NewInt(35), rewardsByPRep{
+		string(prep2.Bytes()): big.NewInt(10),
+		string(prep1.Bytes()): big.NewInt(20),
+	})
+
+	global := icstage.NewGlobalV2(icstate.IISSVersion3, 100, 43200-1, icmodule.RevisionRewardDetails,
+		big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), 22, 5)
+	assert.NoError(t, details.write(dbase, global))
+	assert.NoError(t, recordTermSequence(dbase, 100, 7))
+
+	d, err := GetRewardDetails(dbase, user, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), d.StartHeight)
+	assert.Equal(t, int64(100+43200-1), d.EndHeight)
+	assert.Equal(t, 7, d.Sequence)
+	assert.Equal(t, int64(35), d.IScore().Int64())
+	if assert.Equal(t, 3, len(d.Rewards)) {
+		assert.Nil(t, d.Rewards[0].PRep)
+		assert.Equal(t, int64(5), d.Rewards[0].IScore.Int64())
+		assert.True(t, prep1.Equal(d.Rewards[1].PRep))
+		assert.Equal(t, int64(20), d.Rewards[1].IScore.Int64())
+		assert.True(t, prep2.Equal(d.Rewards[2].PRep))
+		for _, r := range d.Rewards {
+			assert.Equal(t, TypeVoting, r.Type)
+		}
+	}
+
+	d, err = GetRewardDetails(dbase, prep1, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(35), d.IScore().Int64())
+	jso := d.ToJSON()
+	assert.Equal(t, "0x7", jso["sequence"])
+	rewards := jso["rewards"].([]interface{})
+	assert.Equal(t, "blockProduce", rewards[0].(map[string]interface{})["type"])
+	assert.Equal(t, "0xf", rewards[0].(map[string]interface{})["iscore"])
+	assert.Equal(t, "voted", rewards[1].(map[string]interface{})["type"])
+
+	// known term without rewards for the account
+	d, err = GetRewardDetails(dbase, prep2, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(d.Rewards))
+
+	_, err = GetRewardDetails(dbase, user, 200)
+	assert.True(t, errors.NotFoundError.Equals(err))
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package iiss
+
+import (
+	"math/big"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/icon/iiss/icstate"
+)
+
+// previewCandidateCount is the number of P-Reps not elected, which are
+// shown in the preview in order of the election.
+const previewCandidateCount = 10
+
+func previewEntryInJSON(
+	e icstate.PRepSetEntry, grade icstate.Grade, br int64, revision int,
+) map[string]interface{} {
+	prep := e.PRep()
+	jso := map[string]interface{}{
+		"address":      e.Owner(),
+		"nodeAddress":  prep.NodeAddress(),
+		"grade":        int(grade),
+		"currentGrade": int(e.Grade()),
+		"power":        e.Power(br),
+		"delegated":    e.Delegated(),
+		"bonded":       e.Bonded(),
+	}
+	if info := prep.Info(); info != nil && info.Name != nil {
+		jso["name"] = *info.Name
+	}
+	if revision >= icmodule.RevisionBTP2 {
+		jso["hasPublicKey"] = e.HasPubKey()
+	}
+	return jso
+}
+
+// notElectedReason returns the reason why the P-Rep in the candidate grade
+// isn't elected.
+func notElectedReason(e icstate.PRepSetEntry, br int64, revision int) string {
+	if revision >= icmodule.RevisionBTP2 {
+		if e.Power(br).Sign() == 0 {
+			return "noPower"
+		}
+		if !e.HasPubKey() {
+			return "noPublicKey"
+		}
+	}
+	return "outOfRank"
+}
+
+func minPower(cur, power *big.Int) *big.Int {
+	if cur == nil || power.Cmp(cur) < 0 {
+		return power
+	}
+	return cur
+}
+
+// GetValidatorsPreviewInJSON returns main and sub P-Reps to be elected if
+// the current term ends with the current state. It follows the election
+// of onTermEnd without changing the status of P-Reps.
+func (es *ExtensionStateImpl) GetValidatorsPreviewInJSON(cc icmodule.CallContext) (map[string]interface{}, error) {
+	term := es.State.GetTermSnapshot()
+	if term == nil {
+		return nil, errors.InvalidStateError.New("TermNotFound")
+	}
+	revision := cc.Revision().Value()
+	br := es.State.GetBondRequirement()
+	mainPRepCount, subPRepCount, extraMainPRepCount := es.electionConfig(revision)
+
+	prepSet := es.State.GetPRepSet(cc.GetBTPContext(), revision)
+	prepSet.Sort(mainPRepCount, subPRepCount, extraMainPRepCount, br, revision)
+	isDecentralized := term.IsDecentralized() ||
+		es.State.IsDecentralizationConditionMet(revision, cc.GetTotalSupply(), prepSet)
+
+	mainPReps := make([]interface{}, 0, mainPRepCount+extraMainPRepCount)
+	subPReps := make([]interface{}, 0, subPRepCount)
+	candidates := make([]interface{}, 0, previewCandidateCount)
+	var mainCutoff, electedCutoff *big.Int
+	if isDecentralized {
+		grades := prepSet.ElectGrades(revision, mainPRepCount, subPRepCount, extraMainPRepCount, br)
+		for i, grade := range grades {
+			e := prepSet.GetByIndex(i)
+			power := e.Power(br)
+			switch grade {
+			case icstate.GradeMain:
+				jso := previewEntryInJSON(e, grade, br, revision)
+				jso["extra"] = i >= mainPRepCount
+				mainPReps = append(mainPReps, jso)
+				if i < mainPRepCount {
+					mainCutoff = minPower(mainCutoff, power)
+				}
+				electedCutoff = minPower(electedCutoff, power)
+			case icstate.GradeSub:
+				subPReps = append(subPReps, previewEntryInJSON(e, grade, br, revision))
+				electedCutoff = minPower(electedCutoff, power)
+			default:
+				if len(candidates) < previewCandidateCount {
+					jso := previewEntryInJSON(e, grade, br, revision)
+					jso["reason"] = notElectedReason(e, br, revision)
+					candidates = append(candidates, jso)
+				}
+			}
+		}
+	}
+
+	jso := map[string]interface{}{
+		"blockHeight":         cc.BlockHeight(),
+		"nextTermStartHeight": term.GetEndHeight() + 1,
+		"isDecentralized":     isDecentralized,
+		"mainPRepCount":       mainPRepCount,
+		"subPRepCount":        subPRepCount,
+		"extraMainPRepCount":  extraMainPRepCount,
+		"mainPReps":           mainPReps,
+		"subPReps":            subPReps,
+		"candidates":          candidates,
+	}
+	if mainCutoff != nil {
+		jso["mainPRepCutoff"] = mainCutoff
+	}
+	if electedCutoff != nil {
+		jso["electedPRepCutoff"] = electedCutoff
+	}
+	return jso, nil
+}
@@ -282,6 +282,14 @@ func (sm *ServiceManager) ExecuteTransaction(result []byte, vh []byte, js []byte
 	return nil, errors.ErrInvalidState
 }
 
+func (sm *ServiceManager) GetStateDiff(req *module.StateDiffRequest) (interface{}, error) {
+	return nil, errors.ErrInvalidState
+}
+
+func (sm *ServiceManager) GetStorage(result []byte, addr module.Address, start []byte, limit int, containers []*module.StorageContainer) (interface{}, error) {
+	return nil, errors.ErrInvalidState
+}
+
 func (sm *ServiceManager) AddSyncRequest(id db.BucketID, key []byte) error {
 	return errors.ErrInvalidState
 }
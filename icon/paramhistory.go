@@ -0,0 +1,192 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icon
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/goloop/common/codec"
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/state"
+)
+
+// Names of network parameters recorded in the parameter history.
+// Step costs and max step limits are recorded for each type with the prefix.
+const (
+	ParamRevision           = "revision"
+	ParamStepPrice          = "stepPrice"
+	ParamStepCostPrefix     = "stepCost."
+	ParamMaxStepLimitPrefix = "maxStepLimit."
+	ParamRewardFundIglobal  = "rewardFund.iglobal"
+	ParamRewardFundIprep    = "rewardFund.iprep"
+	ParamRewardFundIcps     = "rewardFund.icps"
+	ParamRewardFundIrelay   = "rewardFund.irelay"
+	ParamRewardFundIvoter   = "rewardFund.ivoter"
+	ParamSlashingRatePrefix = "slashingRate."
+)
+
+// ParamChange is a change of a network parameter made by the governance.
+// Old is nil if the parameter wasn't set before the change.
+type ParamChange struct {
+	Height int64
+	Old    *big.Int
+	New    *big.Int
+	TxHash []byte
+}
+
+func (c *ParamChange) ToJSON() map[string]interface{} {
+	jso := map[string]interface{}{
+		"blockHeight": c.Height,
+		"newValue":    c.New,
+	}
+	if c.Old != nil {
+		jso["oldValue"] = c.Old
+	}
+	if len(c.TxHash) > 0 {
+		jso["txHash"] = c.TxHash
+	}
+	return jso
+}
+
+type paramHistory struct {
+	names          *containerdb.ArrayDB
+	snapshot       *containerdb.DictDB
+	snapshotHeight *containerdb.VarDB
+	store          containerdb.BytesStoreState
+}
+
+func newParamHistory(as state.AccountState) *paramHistory {
+	return &paramHistory{
+		names:          scoredb.NewArrayDB(as, state.VarParamNames),
+		snapshot:       scoredb.NewDictDB(as, state.VarParamSnapshot, 1),
+		snapshotHeight: scoredb.NewVarDB(as, state.VarParamSnapshotHeight),
+		store:          as,
+	}
+}
+
+// Snapshot records the values of the parameters at the height, where the
+// history starts. The values before the height are unknown.
+func (h *paramHistory) Snapshot(height int64, values map[string]*big.Int) error {
+	if err := h.snapshotHeight.Set(height); err != nil {
+		return err
+	}
+	for name, value := range values {
+		if value == nil {
+			continue
+		}
+		if err := h.snapshot.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SnapshotHeight returns the height of the snapshot. It returns false if
+// there is no snapshot.
+func (h *paramHistory) SnapshotHeight() (int64, bool) {
+	if v := h.snapshotHeight.BigInt(); v != nil {
+		return v.Int64(), true
+	}
+	return 0, false
+}
+
+func (h *paramHistory) changesDB(name string) *containerdb.ArrayDB {
+	return scoredb.NewArrayDB(h.store, state.VarParamHistory, name)
+}
+
+func (h *paramHistory) Record(name string, height int64, old, new *big.Int, txHash []byte) error {
+	if old != nil && old.Cmp(new) == 0 {
+		return nil
+	}
+	db := h.changesDB(name)
+	if db.Size() == 0 {
+		if err := h.names.Put(name); err != nil {
+			return err
+		}
+	}
+	bs, err := codec.BC.MarshalToBytes(&ParamChange{
+		Height: height,
+		Old:    old,
+		New:    new,
+		TxHash: txHash,
+	})
+	if err != nil {
+		return err
+	}
+	return db.Put(bs)
+}
+
+func (h *paramHistory) Names() []string {
+	size := h.names.Size()
+	names := make([]string, 0, size)
+	for i := 0; i < size; i++ {
+		names = append(names, h.names.Get(i).String())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (h *paramHistory) Changes(name string) ([]*ParamChange, error) {
+	db := h.changesDB(name)
+	size := db.Size()
+	changes := make([]*ParamChange, 0, size)
+	for i := 0; i < size; i++ {
+		c := new(ParamChange)
+		if _, err := codec.BC.UnmarshalFromBytes(db.Get(i).Bytes(), c); err != nil {
+			return nil, errors.CriticalFormatError.Wrapf(err, "InvalidParamChange(name=%s,idx=%d)", name, i)
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// ValueAt returns the value of the parameter in effect after executing the
+// block at the height. current is the stored value of the parameter, which
+// is used for the parameter without the snapshot and changes.
+// It returns nil if the value isn't known.
+func (h *paramHistory) ValueAt(name string, height int64, current *big.Int) (*big.Int, error) {
+	if sh, ok := h.SnapshotHeight(); !ok || height < sh {
+		return nil, nil
+	}
+	changes, err := h.Changes(name)
+	if err != nil {
+		return nil, err
+	}
+	idx := sort.Search(len(changes), func(i int) bool {
+		return changes[i].Height > height
+	})
+	if idx > 0 {
+		return changes[idx-1].New, nil
+	}
+	if len(changes) > 0 {
+		return changes[0].Old, nil
+	}
+	if v := h.snapshot.Get(name); v != nil {
+		return v.BigInt(), nil
+	}
+	return current, nil
+}
+
+func bigIntOf(v containerdb.Value) *big.Int {
+	if v == nil {
+		return nil
+	}
+	return v.BigInt()
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package icon
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/service/state"
+)
+
+func TestParamHistory(t *testing.T) {
+	ws := state.NewWorldState(db.NewMapDB(), nil, nil, nil, nil)
+	h := newParamHistory(ws.GetAccountState(state.SystemID))
+
+	assert.Zero(t, len(h.Names()))
+	_, ok := h.SnapshotHeight()
+	assert.False(t, ok)
+	// values are unknown without the snapshot
+	v, err := h.ValueAt(ParamStepPrice, 10, big.NewInt(100))
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	assert.NoError(t, h.Snapshot(5, map[string]*big.Int{
+		ParamStepPrice:      big.NewInt(100),
+		ParamRevision:       big.NewInt(24),
+		ParamRewardFundIcps: nil,
+	}))
+	sh, ok := h.SnapshotHeight()
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), sh)
+
+	txHash := []byte{0x01, 0x02}
+	assert.NoError(t, h.Record(ParamStepPrice, 10, big.NewInt(100), big.NewInt(200), txHash))
+	assert.NoError(t, h.Record(ParamStepPrice, 20, big.NewInt(200), big.NewInt(300), nil))
+	// no change is not recorded
+	assert.NoError(t, h.Record(ParamStepPrice, 25, big.NewInt(300), big.NewInt(300), nil))
+	assert.NoError(t, h.Record(ParamStepCostPrefix+"apiCall", 15, nil, big.NewInt(1000), nil))
+
+	assert.Equal(t, []string{ParamStepCostPrefix + "apiCall", ParamStepPrice}, h.Names())
+
+	changes, err := h.Changes(ParamStepPrice)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, int64(10), changes[0].Height)
+	assert.Equal(t, txHash, changes[0].TxHash)
+	assert.Zero(t, changes[1].Old.Cmp(big.NewInt(200)))
+	assert.Zero(t, changes[1].New.Cmp(big.NewInt(300)))
+
+	cases := []struct {
+		name   string
+		height int64
+		exp    *big.Int
+	}{
+		{ParamStepPrice, 4, nil},
+		{ParamStepPrice, 5, big.NewInt(100)},
+		{ParamStepPrice, 9, big.NewInt(100)},
+		{ParamStepPrice, 10, big.NewInt(200)},
+		{ParamStepPrice, 19, big.NewInt(200)},
+		{ParamStepPrice, 20, big.NewInt(300)},
+		{ParamStepPrice, 1000, big.NewInt(300)},
+		{ParamStepCostPrefix + "apiCall", 14, nil},
+		{ParamStepCostPrefix + "apiCall", 15, big.NewInt(1000)},
+		// without changes, the value of the snapshot or the current value
+		{ParamRevision, 4, nil},
+		{ParamRevision, 100, big.NewInt(24)},
+		{ParamRewardFundIcps, 4, nil},
+		{ParamRewardFundIcps, 100, big.NewInt(7)},
+	}
+	for _, c := range cases {
+		v, err := h.ValueAt(c.name, c.height, big.NewInt(7))
+		assert.NoError(t, err)
+		if c.exp == nil {
+			assert.Nil(t, v)
+		} else {
+			assert.Zero(t, c.exp.Cmp(v), "name=%s height=%d", c.name, c.height)
+		}
+	}
+
+	jso := changes[0].ToJSON()
+	assert.Equal(t, int64(10), jso["blockHeight"])
+	assert.Equal(t, txHash, jso["txHash"])
+	changes, err = h.Changes(ParamStepCostPrefix + "apiCall")
+	assert.NoError(t, err)
+	_, ok = changes[0].ToJSON()["oldValue"]
+	assert.False(t, ok)
+}
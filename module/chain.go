@@ -29,6 +29,7 @@ type Chain interface {
 	NetID() int
 	Channel() string
 	ConcurrencyLevel() int
+	OptimisticExecution() bool
 	NormalTxPoolSize() int
 	PatchTxPoolSize() int
 	MaxBlockTxBytes() int
@@ -37,6 +38,9 @@ type Chain interface {
 	TransactionTimeout() time.Duration
 	ChildrenLimit() int
 	NephewsLimit() int
+	BandwidthLimit() string
+	PrivatePeers() string
+	DisableDiscovery() bool
 	ValidateTxOnSend() bool
 	Genesis() []byte
 	GenesisStorage() GenesisStorage
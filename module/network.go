@@ -127,6 +127,8 @@ type NetworkTransport interface {
 	GetSecureSuites(channel string) string
 	SetSecureAeads(channel string, secureAeads string) error
 	GetSecureAeads(channel string) string
+	SetNAT(spec string) error
+	GetNAT() string
 }
 
 type NetworkError interface {
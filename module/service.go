@@ -240,6 +240,32 @@ type APIInfo interface {
 
 type SCOREStatus interface {
 	ToJSON(height int64, version JSONVersion) (interface{}, error)
+	// AvailableDeposit returns the usable deposit and the available virtual
+	// steps of the contract at the height.
+	AvailableDeposit(height int64) (deposit *big.Int, virtualSteps *big.Int)
+}
+
+// StateDiffRequest describes world states compared by GetStateDiff.
+// From and To are results of blocks, or state hashes if ByStateHash is true.
+// For state hashes, the extension is compared only with FromExtension and
+// ToExtension, which are extension data of the states.
+// Addresses are used to resolve keys of changed accounts.
+// It stops after Limit entries if Limit is positive.
+type StateDiffRequest struct {
+	From, To                   []byte
+	ByStateHash                bool
+	FromExtension, ToExtension []byte
+	Addresses                  []Address
+	Limit                      int
+}
+
+// StorageContainer describes a container (VarDB, ArrayDB or DictDB) of the
+// contract for decoding storage keys. Type is one of "var", "array" and
+// "dict". Keys has candidate keys of items in DictDB.
+type StorageContainer struct {
+	Type string
+	Name string
+	Keys [][]interface{}
 }
 
 // Options for finalize
@@ -405,6 +431,15 @@ type ServiceManager interface {
 	// It ignores supplied step limit.
 	ExecuteTransaction(result []byte, vh []byte, js []byte, bi BlockInfo) (Receipt, error)
 
+	// GetStateDiff returns differences between two world states described
+	// by the request.
+	GetStateDiff(req *StateDiffRequest) (interface{}, error)
+
+	// GetStorage returns entries of the storage of the contract in the order
+	// of keys from the start key. It returns at most limit entries, and the
+	// key to continue if there are more. Keys are decoded with containers.
+	GetStorage(result []byte, addr Address, start []byte, limit int, containers []*StorageContainer) (interface{}, error)
+
 	// AddSyncRequest add sync request for specified data.
 	AddSyncRequest(id db.BucketID, key []byte) error
 
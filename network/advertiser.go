@@ -0,0 +1,225 @@
+package network
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	DefaultObservedAddressMin   = 2
+	DefaultObservedAddressLimit = 64
+	DefaultNATRetryPeriod       = 1 * time.Minute
+)
+
+type addressUpdateCbFunc func(na NetAddress)
+
+// addressAdvertiser manages advertised NetAddress of the transport.
+// Advertised address is updated by the port mapping of NAT gateway or
+// by the addresses observed by the peers.
+type addressAdvertiser struct {
+	configured NetAddress
+	advertised NetAddress
+	nat        NAT
+	spec       string
+	detect     bool
+	mapped     bool
+	observed   map[string]NetAddress
+	listen     string
+	stopCh     chan bool
+	onUpdate   addressUpdateCbFunc
+	mtx        sync.RWMutex
+	//log
+	logger log.Logger
+}
+
+func newAddressAdvertiser(na NetAddress, cbFunc addressUpdateCbFunc, l log.Logger) *addressAdvertiser {
+	return &addressAdvertiser{
+		configured: na,
+		advertised: na,
+		observed:   make(map[string]NetAddress),
+		onUpdate:   cbFunc,
+		logger:     l.WithFields(log.Fields{LoggerFieldKeySubModule: "advertiser"}),
+	}
+}
+
+func (a *addressAdvertiser) Address() NetAddress {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	return a.advertised
+}
+
+func (a *addressAdvertiser) NAT() string {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+	if a.nat != nil {
+		return a.nat.String()
+	}
+	return a.spec
+}
+
+func (a *addressAdvertiser) setNAT(spec string) error {
+	nat, detect, err := ParseNAT(spec)
+	if err != nil {
+		return err
+	}
+	a.mtx.Lock()
+	running := a.stopCh != nil
+	listen := a.listen
+	a.mtx.Unlock()
+
+	if running {
+		a.stop()
+	}
+	a.mtx.Lock()
+	a.nat = nat
+	a.spec = spec
+	a.detect = detect
+	a.observed = make(map[string]NetAddress)
+	a.mtx.Unlock()
+	if running {
+		a.start(listen)
+	}
+	return nil
+}
+
+func (a *addressAdvertiser) start(listen string) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.listen = listen
+	if a.nat == nil || a.stopCh != nil {
+		return
+	}
+	_, lp, err := net.SplitHostPort(listen)
+	if err != nil {
+		a.logger.Warnln("start", "invalid listen address", listen, err)
+		return
+	}
+	_, ap, err := net.SplitHostPort(string(a.configured))
+	if err != nil {
+		a.logger.Warnln("start", "invalid advertise address", a.configured, err)
+		return
+	}
+	intPort, _ := strconv.Atoi(lp)
+	extPort, _ := strconv.Atoi(ap)
+	a.stopCh = make(chan bool)
+	go a.mapRoutine(a.nat, intPort, extPort, a.stopCh)
+}
+
+func (a *addressAdvertiser) stop() {
+	a.mtx.Lock()
+	stopCh := a.stopCh
+	a.stopCh = nil
+	a.mtx.Unlock()
+
+	if stopCh != nil {
+		stopCh <- true
+	}
+	a.update(a.configured, false)
+}
+
+// mapPort adds or renews the port mapping, and returns the mapped external
+// port and the delay before the next renewal. If the gateway maps another
+// external port on renewal, the previous mapping is deleted.
+func (a *addressAdvertiser) mapPort(nat NAT, intPort, extPort, mapped int) (int, time.Duration) {
+	port, err := nat.AddPortMapping("tcp", extPort, intPort,
+		DefaultNATMappingDescription, DefaultNATMappingLifetime)
+	if err != nil {
+		a.logger.Infoln("mapRoutine", "fail to add port mapping", nat, err)
+		return mapped, DefaultNATRetryPeriod
+	}
+	if mapped != 0 && mapped != port {
+		a.logger.Infoln("mapRoutine", "mapped port changed", nat, mapped, "->", port)
+		if err := nat.DeletePortMapping("tcp", mapped, intPort); err != nil {
+			a.logger.Infoln("mapRoutine", "fail to delete port mapping", nat, err)
+		}
+	}
+	ip, err := nat.ExternalIP()
+	if err != nil {
+		a.logger.Infoln("mapRoutine", "fail to get external ip", nat, err)
+		return port, DefaultNATRetryPeriod
+	}
+	if mapped == 0 {
+		a.logger.Infoln("mapRoutine", "port mapped", nat, ip, port, "->", intPort)
+	}
+	a.update(NetAddress(net.JoinHostPort(ip.String(), strconv.Itoa(port))), true)
+	return port, DefaultNATMappingLifetime / 2
+}
+
+func (a *addressAdvertiser) mapRoutine(nat NAT, intPort, extPort int, stopCh chan bool) {
+	mapped := 0
+	for {
+		var d time.Duration
+		mapped, d = a.mapPort(nat, intPort, extPort, mapped)
+		select {
+		case <-stopCh:
+			if mapped != 0 {
+				if err := nat.DeletePortMapping("tcp", mapped, intPort); err != nil {
+					a.logger.Infoln("mapRoutine", "fail to delete port mapping", nat, err)
+				}
+			}
+			return
+		case <-time.After(d):
+		}
+	}
+}
+
+func (a *addressAdvertiser) update(na NetAddress, mapped bool) {
+	a.mtx.Lock()
+	a.mapped = mapped
+	if a.advertised == na {
+		a.mtx.Unlock()
+		return
+	}
+	a.logger.Infoln("update", "advertised address", a.advertised, "->", na)
+	a.advertised = na
+	a.mtx.Unlock()
+
+	if a.onUpdate != nil {
+		a.onUpdate(na)
+	}
+}
+
+// observe records the address of the node which is observed by the peer.
+// If enough peers report the same address, then it becomes advertised
+// address unless the port mapping of NAT is available.
+func (a *addressAdvertiser) observe(id module.PeerID, na NetAddress) {
+	if len(na) == 0 || na.Validate() != nil {
+		return
+	}
+	host, _, _ := net.SplitHostPort(string(na))
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() {
+		return
+	}
+
+	a.mtx.Lock()
+	if !a.detect || a.mapped {
+		a.mtx.Unlock()
+		return
+	}
+	k := id.String()
+	if _, ok := a.observed[k]; !ok && len(a.observed) >= DefaultObservedAddressLimit {
+		for ek := range a.observed {
+			delete(a.observed, ek)
+			break
+		}
+	}
+	a.observed[k] = na
+	n := 0
+	for _, v := range a.observed {
+		if v == na {
+			n++
+		}
+	}
+	a.mtx.Unlock()
+
+	if n >= DefaultObservedAddressMin {
+		a.update(na, false)
+	}
+}
@@ -46,6 +46,9 @@ func inspectP2P(mgr *manager, informal bool) map[string]interface{} {
 		m["reject"] = peerSetToMapArray(mgr.p2p.reject, informal)
 	}
 	m["trustSeeds"] = mgr.p2p.trustSeeds.Map()
+	m["privatePeers"] = mgr.p2p.privatePeers.Map()
+	m["discovery"] = mgr.p2p.isDiscovery()
+	m["bandwidthLimit"] = mgr.getBandwidthLimit().String()
 	return m
 }
 
@@ -92,6 +95,12 @@ func peerToMap(p *Peer, informal bool) map[string]interface{} {
 				}
 				m["sendQueue"] = strings.Join(sq, ",")
 			}
+			if p.up != nil && p.down != nil {
+				m["bandwidth"] = map[string]interface{}{
+					"up":   p.up.ToMap(),
+					"down": p.down.ToMap(),
+				}
+			}
 		}
 	}
 	return m
@@ -117,6 +126,10 @@ func protocolHandlerToMap(ph *protocolHandler) map[string]interface{} {
 		m["receiveQueue"] = ph.receiveQueue.Available()
 		m["eventQueue"] = ph.eventQueue.Available()
 		m["sendQueue"] = ph.m.p2p.sendQueue.Available(int(ph.protocol.ID()))
+		m["bandwidth"] = map[string]interface{}{
+			"up":   ph.up.ToMap(),
+			"down": ph.down.ToMap(),
+		}
 	}
 	return m
 }
@@ -0,0 +1,201 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	NATNone   = "none"
+	NATAuto   = "auto"
+	NATAny    = "any"
+	NATUPnP   = "upnp"
+	NATPMP    = "pmp"
+	NATExtIP  = "extip"
+	natSpecSp = ":"
+
+	DefaultNATMappingLifetime    = 20 * time.Minute
+	DefaultNATMappingDescription = "goloop p2p"
+	DefaultNATDiscoveryTimeout   = 3 * time.Second
+)
+
+// NAT is port mapping interface of the gateway between the node and the internet
+type NAT interface {
+	ExternalIP() (net.IP, error)
+	AddPortMapping(protocol string, extPort, intPort int, desc string, lifetime time.Duration) (int, error)
+	DeletePortMapping(protocol string, extPort, intPort int) error
+	String() string
+}
+
+// ParseNAT returns NAT for the spec and whether the observed address detection
+// is enabled. Supported spec forms are
+//
+//	"" or "none" : no NAT traversal
+//	"auto"       : observed address detection only
+//	"any"        : UPnP or NAT-PMP which is discovered first
+//	"upnp"       : UPnP IGD
+//	"pmp"        : NAT-PMP with guessed gateway, "pmp:<gateway ip>" for given gateway
+//	"extip:<ip>" : fixed external ip without port mapping
+func ParseNAT(spec string) (nat NAT, detect bool, err error) {
+	mech, arg := spec, ""
+	if i := strings.Index(spec, natSpecSp); i >= 0 {
+		mech, arg = spec[:i], spec[i+1:]
+	}
+	switch strings.ToLower(mech) {
+	case "", NATNone:
+		return nil, false, nil
+	case NATAuto:
+		return nil, true, nil
+	case NATAny:
+		return &autoNAT{}, true, nil
+	case NATUPnP:
+		return &autoNAT{upnpOnly: true}, true, nil
+	case NATPMP:
+		var gw net.IP
+		if arg != "" {
+			if gw = net.ParseIP(arg); gw == nil {
+				return nil, false, errors.IllegalArgumentError.Errorf("invalid gateway ip %s", arg)
+			}
+		}
+		return &autoNAT{pmpOnly: true, gateway: gw}, true, nil
+	case NATExtIP:
+		ip := net.ParseIP(arg)
+		if ip == nil {
+			return nil, false, errors.IllegalArgumentError.Errorf("invalid external ip %s", arg)
+		}
+		return extIP(ip), false, nil
+	default:
+		return nil, false, errors.IllegalArgumentError.Errorf("unknown NAT mechanism %s", spec)
+	}
+}
+
+type extIP net.IP
+
+func (n extIP) ExternalIP() (net.IP, error) {
+	return net.IP(n), nil
+}
+
+func (n extIP) AddPortMapping(protocol string, extPort, intPort int, desc string, lifetime time.Duration) (int, error) {
+	return extPort, nil
+}
+
+func (n extIP) DeletePortMapping(protocol string, extPort, intPort int) error {
+	return nil
+}
+
+func (n extIP) String() string {
+	return fmt.Sprintf("%s:%s", NATExtIP, net.IP(n))
+}
+
+// autoNAT discovers the gateway lazily, so that configured node is able to
+// start even if the gateway is not reachable at the moment.
+type autoNAT struct {
+	upnpOnly bool
+	pmpOnly  bool
+	gateway  net.IP
+	found    NAT
+}
+
+func (n *autoNAT) discover() (NAT, error) {
+	if n.found != nil {
+		return n.found, nil
+	}
+	if !n.pmpOnly {
+		if u, err := discoverUPnP(DefaultNATDiscoveryTimeout); err == nil {
+			n.found = u
+			return u, nil
+		} else if n.upnpOnly {
+			return nil, err
+		}
+	}
+	gws := []net.IP{n.gateway}
+	if n.gateway == nil {
+		gws = guessGateways()
+	}
+	for _, gw := range gws {
+		pmp := newNATPMP(gw)
+		if _, err := pmp.ExternalIP(); err == nil {
+			n.found = pmp
+			return pmp, nil
+		}
+	}
+	return nil, errors.NotFoundError.New("NAT gateway not found")
+}
+
+func (n *autoNAT) ExternalIP() (net.IP, error) {
+	nat, err := n.discover()
+	if err != nil {
+		return nil, err
+	}
+	return nat.ExternalIP()
+}
+
+func (n *autoNAT) AddPortMapping(protocol string, extPort, intPort int, desc string, lifetime time.Duration) (int, error) {
+	nat, err := n.discover()
+	if err != nil {
+		return 0, err
+	}
+	return nat.AddPortMapping(protocol, extPort, intPort, desc, lifetime)
+}
+
+func (n *autoNAT) DeletePortMapping(protocol string, extPort, intPort int) error {
+	if n.found == nil {
+		return nil
+	}
+	return n.found.DeletePortMapping(protocol, extPort, intPort)
+}
+
+func (n *autoNAT) String() string {
+	if n.found != nil {
+		return n.found.String()
+	}
+	switch {
+	case n.upnpOnly:
+		return NATUPnP
+	case n.pmpOnly:
+		if n.gateway != nil {
+			return NATPMP + natSpecSp + n.gateway.String()
+		}
+		return NATPMP
+	default:
+		return NATAny
+	}
+}
+
+// guessGateways returns the first address of the networks of local
+// interfaces, which is the gateway address of the most home routers.
+func guessGateways() []net.IP {
+	var gws []net.IP
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return gws
+	}
+	for _, addr := range addrs {
+		ipn, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		ip := ipn.IP.To4()
+		if ip == nil || ip.IsLoopback() || !ip.IsPrivate() {
+			continue
+		}
+		gw := ip.Mask(ipn.Mask)
+		gw[3] |= 0x01
+		gws = append(gws, gw)
+	}
+	return gws
+}
+
+// localIPFor returns the local address which is used to reach the addr
+func localIPFor(addr string) (net.IP, error) {
+	conn, err := net.Dial("udp4", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
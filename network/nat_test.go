@@ -0,0 +1,317 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/log"
+)
+
+func Test_nat_ParseNAT(t *testing.T) {
+	tests := []struct {
+		spec   string
+		isNil  bool
+		detect bool
+		str    string
+		err    bool
+	}{
+		{"", true, false, "", false},
+		{"none", true, false, "", false},
+		{"auto", true, true, "", false},
+		{"any", false, true, "any", false},
+		{"upnp", false, true, "upnp", false},
+		{"pmp", false, true, "pmp", false},
+		{"pmp:192.168.0.1", false, true, "pmp:192.168.0.1", false},
+		{"pmp:invalid", true, false, "", true},
+		{"extip:1.2.3.4", false, false, "extip:1.2.3.4", false},
+		{"extip:", true, false, "", true},
+		{"unknown", true, false, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			nat, detect, err := ParseNAT(tt.spec)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.isNil, nat == nil)
+			assert.Equal(t, tt.detect, detect)
+			if nat != nil {
+				assert.Equal(t, tt.str, nat.String())
+			}
+		})
+	}
+}
+
+type testNATPMPGateway struct {
+	conn     net.PacketConn
+	extIP    net.IP
+	mappings map[uint16]uint16
+	lifetime map[uint16]uint32
+	result   uint16
+}
+
+func newTestNATPMPGateway(t *testing.T, extIP net.IP) *testNATPMPGateway {
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+	gw := &testNATPMPGateway{
+		conn:     conn,
+		extIP:    extIP.To4(),
+		mappings: make(map[uint16]uint16),
+		lifetime: make(map[uint16]uint32),
+	}
+	go gw.serve()
+	return gw
+}
+
+func (gw *testNATPMPGateway) serve() {
+	buf := make([]byte, 16)
+	for {
+		n, addr, err := gw.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if n < 2 {
+			continue
+		}
+		op := buf[1]
+		var resp []byte
+		switch op {
+		case natPMPOpExternalAddr:
+			resp = make([]byte, 12)
+			copy(resp[8:], gw.extIP)
+		case natPMPOpMapTCP, natPMPOpMapUDP:
+			intPort := binary.BigEndian.Uint16(buf[4:6])
+			extPort := binary.BigEndian.Uint16(buf[6:8])
+			lifetime := binary.BigEndian.Uint32(buf[8:12])
+			if lifetime == 0 {
+				delete(gw.mappings, intPort)
+			} else {
+				if extPort == 0 {
+					extPort = intPort
+				}
+				gw.mappings[intPort] = extPort
+				gw.lifetime[intPort] = lifetime
+			}
+			resp = make([]byte, 16)
+			binary.BigEndian.PutUint16(resp[8:10], intPort)
+			binary.BigEndian.PutUint16(resp[10:12], extPort)
+			binary.BigEndian.PutUint32(resp[12:16], lifetime)
+		default:
+			continue
+		}
+		resp[1] = natPMPOpResponse + op
+		binary.BigEndian.PutUint16(resp[2:4], gw.result)
+		_, _ = gw.conn.WriteTo(resp, addr)
+	}
+}
+
+func (gw *testNATPMPGateway) Close() {
+	_ = gw.conn.Close()
+}
+
+func Test_nat_NATPMP(t *testing.T) {
+	extIP := net.ParseIP("203.0.113.10")
+	gw := newTestNATPMPGateway(t, extIP)
+	defer gw.Close()
+
+	pmp := &natPMP{gateway: gw.conn.LocalAddr().String()}
+	ip, err := pmp.ExternalIP()
+	assert.NoError(t, err)
+	assert.True(t, extIP.Equal(ip))
+
+	port, err := pmp.AddPortMapping("tcp", 8080, 18080, "test", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, port)
+	assert.Equal(t, uint16(8080), gw.mappings[18080])
+	assert.Equal(t, uint32(3600), gw.lifetime[18080])
+
+	_, err = pmp.AddPortMapping("sctp", 8080, 18080, "test", time.Hour)
+	assert.Error(t, err)
+
+	assert.NoError(t, pmp.DeletePortMapping("tcp", 8080, 18080))
+	_, ok := gw.mappings[18080]
+	assert.False(t, ok)
+
+	gw.result = 2
+	_, err = pmp.ExternalIP()
+	assert.Error(t, err)
+}
+
+const testUPnPDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+<device>
+<deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+<deviceList><device>
+<deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+<deviceList><device>
+<deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+<serviceList><service>
+<serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+<controlURL>/ctl/IPConn</controlURL>
+</service></serviceList>
+</device></deviceList>
+</device></deviceList>
+</device>
+</root>`
+
+func Test_nat_UPnP(t *testing.T) {
+	var actions []string
+	mappings := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/desc.xml":
+			_, _ = io.WriteString(w, testUPnPDescription)
+		case "/ctl/IPConn":
+			action := r.Header.Get("SOAPAction")
+			actions = append(actions, action)
+			b, _ := io.ReadAll(r.Body)
+			body := string(b)
+			switch {
+			case strings.HasSuffix(action, "#GetExternalIPAddress\""):
+				_, _ = fmt.Fprintf(w, `<?xml version="1.0"?>
+<s:Envelope xmlns:s="%s"><s:Body><u:GetExternalIPAddressResponse xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1">
+<NewExternalIPAddress>203.0.113.20</NewExternalIPAddress>
+</u:GetExternalIPAddressResponse></s:Body></s:Envelope>`, upnpSOAPEnvelope)
+			case strings.HasSuffix(action, "#AddPortMapping\""):
+				assert.Contains(t, body, "<NewExternalPort>8080</NewExternalPort>")
+				assert.Contains(t, body, "<NewInternalPort>18080</NewInternalPort>")
+				assert.Contains(t, body, "<NewProtocol>TCP</NewProtocol>")
+				mappings["8080"] = "18080"
+			case strings.HasSuffix(action, "#DeletePortMapping\""):
+				delete(mappings, "8080")
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	_, err := newUPnP(srv.URL + "/invalid.xml")
+	assert.Error(t, err)
+
+	u, err := newUPnP(srv.URL + "/desc.xml")
+	assert.NoError(t, err)
+	assert.Equal(t, srv.URL+"/ctl/IPConn", u.controlURL)
+	assert.Equal(t, "urn:schemas-upnp-org:service:WANIPConnection:1", u.serviceType)
+
+	ip, err := u.ExternalIP()
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.20", ip.String())
+
+	port, err := u.AddPortMapping("tcp", 8080, 18080, "test", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, port)
+	assert.Equal(t, "18080", mappings["8080"])
+
+	assert.NoError(t, u.DeletePortMapping("tcp", 8080, 18080))
+	assert.Len(t, mappings, 0)
+	assert.Len(t, actions, 3)
+}
+
+func Test_nat_addressAdvertiser(t *testing.T) {
+	l := log.New()
+	updated := make(chan NetAddress, 10)
+	a := newAddressAdvertiser("192.168.0.2:8080", func(na NetAddress) {
+		updated <- na
+	}, l)
+
+	id1, id2 := generatePeerID(), generatePeerID()
+	observed := NetAddress("203.0.113.30:8080")
+
+	// detection is disabled by default
+	a.observe(id1, observed)
+	a.observe(id2, observed)
+	assert.Equal(t, NetAddress("192.168.0.2:8080"), a.Address())
+
+	assert.NoError(t, a.setNAT(NATAuto))
+	a.observe(id1, "127.0.0.1:8080")
+	a.observe(id2, "127.0.0.1:8080")
+	assert.Equal(t, NetAddress("192.168.0.2:8080"), a.Address())
+
+	a.observe(id1, observed)
+	a.observe(id1, observed)
+	assert.Equal(t, NetAddress("192.168.0.2:8080"), a.Address())
+	a.observe(id2, observed)
+	assert.Equal(t, observed, a.Address())
+	assert.Equal(t, observed, <-updated)
+
+	// port mapping of NAT takes precedence over observed address
+	gw := newTestNATPMPGateway(t, net.ParseIP("203.0.113.40"))
+	defer gw.Close()
+	_, port, _ := net.SplitHostPort(gw.conn.LocalAddr().String())
+	assert.NoError(t, a.setNAT("pmp:127.0.0.1"))
+	a.nat.(*autoNAT).found = &natPMP{gateway: "127.0.0.1:" + port}
+	a.start("0.0.0.0:18080")
+	select {
+	case na := <-updated:
+		if na != "203.0.113.40:8080" {
+			assert.Equal(t, NetAddress("203.0.113.40:8080"), <-updated)
+		}
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "timeout")
+	}
+	assert.Equal(t, NetAddress("203.0.113.40:8080"), a.Address())
+	a.observe(id1, observed)
+	a.observe(id2, observed)
+	assert.Equal(t, NetAddress("203.0.113.40:8080"), a.Address())
+
+	a.stop()
+	assert.Equal(t, NetAddress("192.168.0.2:8080"), a.Address())
+}
+
+type testRemappingNAT struct {
+	ports   []int
+	deleted []int
+}
+
+func (n *testRemappingNAT) ExternalIP() (net.IP, error) {
+	return net.ParseIP("203.0.113.50"), nil
+}
+
+func (n *testRemappingNAT) AddPortMapping(protocol string, extPort, intPort int, desc string, lifetime time.Duration) (int, error) {
+	port := n.ports[0]
+	n.ports = n.ports[1:]
+	return port, nil
+}
+
+func (n *testRemappingNAT) DeletePortMapping(protocol string, extPort, intPort int) error {
+	n.deleted = append(n.deleted, extPort)
+	return nil
+}
+
+func (n *testRemappingNAT) String() string {
+	return "test"
+}
+
+func Test_nat_addressAdvertiser_remap(t *testing.T) {
+	a := newAddressAdvertiser("192.168.0.2:8080", nil, log.New())
+	nat := &testRemappingNAT{ports: []int{8080, 8080, 18080}}
+
+	mapped, _ := a.mapPort(nat, 8080, 8080, 0)
+	assert.Equal(t, 8080, mapped)
+	assert.Equal(t, NetAddress("203.0.113.50:8080"), a.Address())
+
+	// renewal with the same port keeps the mapping
+	mapped, _ = a.mapPort(nat, 8080, 8080, mapped)
+	assert.Equal(t, 8080, mapped)
+	assert.Empty(t, nat.deleted)
+
+	// renewal with another port deletes the old mapping
+	mapped, _ = a.mapPort(nat, 8080, 8080, mapped)
+	assert.Equal(t, 18080, mapped)
+	assert.Equal(t, []int{8080}, nat.deleted)
+	assert.Equal(t, NetAddress("203.0.113.50:18080"), a.Address())
+}
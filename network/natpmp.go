@@ -0,0 +1,134 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	natPMPPort           = 5351
+	natPMPVersion        = 0
+	natPMPOpExternalAddr = 0
+	natPMPOpMapUDP       = 1
+	natPMPOpMapTCP       = 2
+	natPMPOpResponse     = 128
+	natPMPRetryTimeout   = 250 * time.Millisecond
+	natPMPMaxRetry       = 4
+)
+
+var natPMPResultCodes = map[uint16]string{
+	1: "UnsupportedVersion",
+	2: "NotAuthorized",
+	3: "NetworkFailure",
+	4: "OutOfResources",
+	5: "UnsupportedOpcode",
+}
+
+// natPMP is NAT Port Mapping Protocol client, RFC 6886
+type natPMP struct {
+	gateway string
+	mtx     sync.Mutex
+}
+
+func newNATPMP(gw net.IP) *natPMP {
+	return &natPMP{gateway: net.JoinHostPort(gw.String(), strconv.Itoa(natPMPPort))}
+}
+
+func (n *natPMP) call(req []byte, respLen int) ([]byte, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	conn, err := net.Dial("udp4", n.gateway)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp := make([]byte, 16)
+	timeout := natPMPRetryTimeout
+	for i := 0; i < natPMPMaxRetry; i++ {
+		if _, err = conn.Write(req); err != nil {
+			return nil, err
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(timeout))
+		var rn int
+		rn, err = conn.Read(resp)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				timeout *= 2
+				continue
+			}
+			return nil, err
+		}
+		if rn < respLen || resp[0] != natPMPVersion || resp[1] != natPMPOpResponse+req[1] {
+			continue
+		}
+		if rc := binary.BigEndian.Uint16(resp[2:4]); rc != 0 {
+			msg, ok := natPMPResultCodes[rc]
+			if !ok {
+				msg = fmt.Sprintf("ResultCode(%d)", rc)
+			}
+			return nil, errors.InvalidStateError.Errorf("NAT-PMP %s", msg)
+		}
+		return resp[:respLen], nil
+	}
+	if err == nil {
+		err = errors.InvalidStateError.New("NAT-PMP invalid response")
+	}
+	return nil, errors.TimeoutError.Wrap(err, "NAT-PMP no response")
+}
+
+func (n *natPMP) ExternalIP() (net.IP, error) {
+	resp, err := n.call([]byte{natPMPVersion, natPMPOpExternalAddr}, 12)
+	if err != nil {
+		return nil, err
+	}
+	return net.IPv4(resp[8], resp[9], resp[10], resp[11]), nil
+}
+
+func (n *natPMP) AddPortMapping(protocol string, extPort, intPort int, desc string, lifetime time.Duration) (int, error) {
+	op, err := natPMPOpOf(protocol)
+	if err != nil {
+		return 0, err
+	}
+	req := make([]byte, 12)
+	req[0] = natPMPVersion
+	req[1] = op
+	binary.BigEndian.PutUint16(req[4:6], uint16(intPort))
+	binary.BigEndian.PutUint16(req[6:8], uint16(extPort))
+	binary.BigEndian.PutUint32(req[8:12], uint32(lifetime/time.Second))
+	resp, err := n.call(req, 16)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint16(resp[10:12])), nil
+}
+
+func (n *natPMP) DeletePortMapping(protocol string, extPort, intPort int) error {
+	// mapping with zero lifetime and zero external port is deletion
+	_, err := n.AddPortMapping(protocol, 0, intPort, "", 0)
+	return err
+}
+
+func (n *natPMP) String() string {
+	host, _, _ := net.SplitHostPort(n.gateway)
+	return NATPMP + natSpecSp + host
+}
+
+func natPMPOpOf(protocol string) (byte, error) {
+	switch strings.ToLower(protocol) {
+	case "tcp":
+		return natPMPOpMapTCP, nil
+	case "udp":
+		return natPMPOpMapUDP, nil
+	default:
+		return 0, errors.IllegalArgumentError.Errorf("unsupported protocol %s", protocol)
+	}
+}
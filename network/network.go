@@ -5,6 +5,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
@@ -17,6 +18,7 @@ type transportForManager interface {
 	removeProtocol(channel string, pi module.ProtocolInfo)
 	registerPeerHandler(channel string, ph PeerHandler, mtr *metric.NetworkMetric) bool
 	unregisterPeerHandler(channel string)
+	observeAddress(id module.PeerID, na NetAddress)
 }
 
 type manager struct {
@@ -35,6 +37,8 @@ type manager struct {
 	mtr *metric.NetworkMetric
 
 	streamReactors []*streamReactor
+
+	bwLimit *BandwidthLimit
 }
 
 func NewManager(c module.Chain, nt module.NetworkTransport, trustSeeds string, roles ...module.Role) module.NetworkManager {
@@ -51,12 +55,20 @@ func NewManager(c module.Chain, nt module.NetworkTransport, trustSeeds string, r
 		m.t.GetDialer(m.channel),
 		m.mtr,
 		m.logger)
+	m.p2p.setObserveCbFunc(m.t.observeAddress)
 
 	m.SetInitialRoles(roles...)
 	m.SetTrustSeeds(trustSeeds)
 
 	m.p2p.setConnectionLimit(p2pConnTypeChildren, c.ChildrenLimit())
 	m.p2p.setConnectionLimit(p2pConnTypeNephew, c.NephewsLimit())
+	if err := m.SetBandwidthLimit(c.BandwidthLimit()); err != nil {
+		m.logger.Warnln("NewManager", "invalid bandwidth limit", c.BandwidthLimit(), err)
+	}
+	if err := m.SetPrivatePeers(c.PrivatePeers()); err != nil {
+		m.logger.Warnln("NewManager", "invalid private peers", c.PrivatePeers(), err)
+	}
+	m.SetDiscovery(!c.DisableDiscovery())
 
 	m.logger.Infof("NetworkManager use channel=%s for cid=%#x nid=%#x",
 		m.channel, c.CID(), c.NID())
@@ -157,7 +169,7 @@ func (m *manager) RegisterReactor(
 			m.logger.Debugln("RegisterReactor, p2p started")
 		}
 
-		ph = newProtocolHandler(m, pi, piList, reactor, name, priority, policy, m.logger)
+		ph = newProtocolHandler(m, pi, piList, reactor, name, priority, policy, m.bwLimit.Protocol(name), m.logger)
 		m.p2p.setCbFunc(pi, ph.onPacket, ph.onEvent, p2pEventJoin, p2pEventLeave, p2pEventDuplicate)
 		m.protocolHandlers[k] = ph
 		m.t.addProtocol(m.channel, pi)
@@ -165,6 +177,30 @@ func (m *manager) RegisterReactor(
 	return ph, nil
 }
 
+// SetBandwidthLimit applies the limits of the spec to the peers and
+// the protocols. See ParseBandwidthLimit for the format of the spec.
+func (m *manager) SetBandwidthLimit(spec string) error {
+	bl, err := ParseBandwidthLimit(spec)
+	if err != nil {
+		return err
+	}
+	defer m.mtx.Unlock()
+	m.mtx.Lock()
+
+	m.bwLimit = bl
+	m.p2p.setBandwidthLimit(bl.Peer)
+	for _, ph := range m.protocolHandlers {
+		ph.setBandwidthLimit(bl.Protocol(ph.getName()))
+	}
+	return nil
+}
+
+func (m *manager) getBandwidthLimit() *BandwidthLimit {
+	defer m.mtx.RUnlock()
+	m.mtx.RLock()
+	return m.bwLimit
+}
+
 func (m *manager) UnregisterReactor(reactor module.Reactor) error {
 	defer m.mtx.Unlock()
 	m.mtx.Lock()
@@ -246,6 +282,49 @@ func (m *manager) SetTrustSeeds(seeds string) {
 	m.p2p.setTrustSeeds(ss)
 }
 
+// ParsePrivatePeers parses comma separated private peers. Each entry is
+// "<host>:<port>" or "<peer id>@<host>:<port>". If peer id is given, then
+// the peer is identified by the id instead of the address.
+func ParsePrivatePeers(peers string) (map[NetAddress]string, error) {
+	m := make(map[NetAddress]string)
+	for _, s := range strings.Split(peers, ",") {
+		s = strings.TrimSpace(s)
+		if len(s) == 0 {
+			continue
+		}
+		var id string
+		if i := strings.Index(s, "@"); i >= 0 {
+			addr, err := common.NewAddressFromString(s[:i])
+			if err != nil || addr.IsContract() {
+				return nil, errors.IllegalArgumentError.Errorf("invalid peer id %s", s[:i])
+			}
+			id, s = addr.String(), s[i+1:]
+		}
+		na := NetAddress(s)
+		if err := na.Validate(); err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err, "invalid address %s", s)
+		}
+		m[na] = id
+	}
+	return m, nil
+}
+
+func (m *manager) SetPrivatePeers(peers string) error {
+	pm, err := ParsePrivatePeers(peers)
+	if err != nil {
+		return err
+	}
+	m.p2p.setPrivatePeers(pm)
+	return nil
+}
+
+// SetDiscovery enables or disables discovery of the peers. If it's disabled,
+// then the node only connects to the private peers, and it doesn't
+// propagate any address of the peers.
+func (m *manager) SetDiscovery(enable bool) {
+	m.p2p.setDiscovery(enable)
+}
+
 func (m *manager) SetInitialRoles(roles ...module.Role) {
 	m.p2p.setRole(NewPeerRoleFlag(roles...))
 }
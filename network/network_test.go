@@ -296,6 +296,10 @@ func (c *dummyChain) Logger() log.Logger                    { return c.logger }
 func (c *dummyChain) MetricContext() context.Context        { return c.metricCtx }
 func (c *dummyChain) ChildrenLimit() int                    { return -1 }
 func (c *dummyChain) NephewsLimit() int                     { return -1 }
+func (c *dummyChain) BandwidthLimit() string                 { return "" }
+func (c *dummyChain) PrivatePeers() string                   { return "" }
+func (c *dummyChain) DisableDiscovery() bool                 { return false }
+func (c *dummyChain) OptimisticExecution() bool              { return false }
 func (c *dummyChain) NetworkManager() module.NetworkManager { return c.nm }
 
 type dummyReactor struct{}
@@ -610,6 +614,7 @@ func Test_manager(t *testing.T) {
 		arg.name,
 		arg.priority,
 		arg.policy,
+		RateLimit{},
 		nm.logger)
 
 	expectPanicFunc := func(priority uint8) assert.PanicTestFunc {
@@ -3,6 +3,7 @@ package network
 import (
 	"context"
 	"fmt"
+	"net"
 	"sort"
 	"strings"
 	"sync"
@@ -79,6 +80,7 @@ type PeerToPeer struct {
 	onEventCbFuncs  map[string]map[uint16]eventCbFunc
 	packetPool      *PacketPool
 	dialer          *Dialer
+	onObserve       observeCbFunc
 
 	//Topology with Connected Peers
 	self       *Peer
@@ -92,6 +94,10 @@ type PeerToPeer struct {
 	seeds      *NetAddressSet //map[NetAddress]PeerID
 	roots      *NetAddressSet //map[NetAddress]PeerID //Only for seed and root
 
+	//always connected and never gossiped
+	privatePeers *NetAddressSet //map[NetAddress]PeerID
+	noDiscovery  int32
+
 	//managed PeerId
 	allowedRoots *PeerIDSet
 	allowedSeeds *PeerIDSet
@@ -101,6 +107,10 @@ type PeerToPeer struct {
 	cLimit    map[PeerConnectionType]int
 	cLimitMtx sync.RWMutex
 
+	//bandwidth limit for each peer
+	bwLimit    RateLimit
+	bwLimitMtx sync.RWMutex
+
 	//monitor
 	mtr *metric.NetworkMetric
 
@@ -110,6 +120,7 @@ type PeerToPeer struct {
 }
 
 type eventCbFunc func(evt string, p *Peer)
+type observeCbFunc func(id module.PeerID, na NetAddress)
 
 const (
 	p2pEventJoin       = "join"
@@ -140,6 +151,8 @@ func newPeerToPeer(channel string, self *Peer, d *Dialer, mtr *metric.NetworkMet
 		seeds:      NewNetAddressSet(),
 		roots:      NewNetAddressSet(),
 		//
+		privatePeers: NewNetAddressSet(),
+		//
 		allowedRoots: NewPeerIDSet(),
 		allowedSeeds: NewPeerIDSet(),
 		allowedPeers: NewPeerIDSet(),
@@ -226,6 +239,10 @@ func (p2p *PeerToPeer) supportedProtocols() []module.ProtocolInfo {
 }
 
 func (p2p *PeerToPeer) dial(na NetAddress) error {
+	if !p2p.isDiscovery() && !p2p.privatePeers.Contains(na) {
+		p2p.logger.Debugln("Dial ignore, discovery disabled", na)
+		return ErrNotAvailable
+	}
 	if err := p2p.dialer.Dial(string(na)); err != nil {
 		if err == ErrAlreadyDialing {
 			p2p.logger.Infoln("Dial ignore", na, err)
@@ -257,6 +274,10 @@ func (p2p *PeerToPeer) unsetCbFunc(pi module.ProtocolInfo) {
 	}
 }
 
+func (p2p *PeerToPeer) setObserveCbFunc(cbFunc observeCbFunc) {
+	p2p.onObserve = cbFunc
+}
+
 func (p2p *PeerToPeer) setEventCbFunc(evt string, k uint16, evtFunc eventCbFunc) {
 	m := p2p.onEventCbFuncs[evt]
 	if m == nil {
@@ -282,9 +303,15 @@ func (p2p *PeerToPeer) onPeer(p *Peer) {
 		p.CloseByError(fmt.Errorf("onPeer not allowed connection"))
 		return
 	}
+	if !p2p.isDiscovery() && !p2p.privatePeers.IsEmpty() && !p2p.isPrivatePeer(p) {
+		p2p.onEvent(p2pEventNotAllowed, p)
+		p.CloseByError(fmt.Errorf("onPeer not private peer"))
+		return
+	}
 	if p2p.isTrustSeed(p) {
 		p2p.trustSeeds.SetAndRemoveByData(p.DialNetAddress(), string(p.NetAddress()))
 	}
+	p.setBandwidthLimit(p2p.getBandwidthLimit())
 	if p2p.addPeer(p) && !p.In() {
 		p2p.sendQuery(p)
 	}
@@ -431,6 +458,7 @@ type QueryResultMessage struct {
 	Children []NetAddress
 	Nephews  []NetAddress
 	Message  string
+	Observed NetAddress
 }
 
 type RttMessage struct {
@@ -540,6 +568,33 @@ func (p2p *PeerToPeer) NetAddress() NetAddress {
 	return p2p.self.NetAddress()
 }
 
+func (p2p *PeerToPeer) updateNetAddress(na NetAddress) {
+	old := p2p.self.NetAddress()
+	if old == na {
+		return
+	}
+	p2p.logger.Infoln("updateNetAddress", old, "->", na)
+	p2p.self.setNetAddress(na)
+	p2p.applyPeerRole(p2p.self)
+}
+
+// observedNetAddress returns the address of the peer which is observed by
+// the connection, with the port of the advertised address of the peer.
+func (p2p *PeerToPeer) observedNetAddress(p *Peer) NetAddress {
+	if !p.In() || p.conn == nil {
+		return ""
+	}
+	ra, ok := p.conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return ""
+	}
+	_, port, err := net.SplitHostPort(string(p.NetAddress()))
+	if err != nil {
+		return ""
+	}
+	return NetAddress(net.JoinHostPort(ra.IP.String(), port))
+}
+
 func (p2p *PeerToPeer) startRtt(p *Peer) {
 	p.rtt.StartWithAfterFunc(DefaultRttLogTimeout, func() {
 		p2p.logger.Warnln("RTT Timeout", DefaultRttLogTimeout, p)
@@ -581,6 +636,7 @@ func (p2p *PeerToPeer) handleQuery(pkt *Packet, p *Peer) {
 		Role:     r,
 		Children: p2p.getNetAddresses(p2pConnTypeChildren),
 		Nephews:  p2p.getNetAddresses(p2pConnTypeNephew),
+		Observed: p2p.observedNetAddress(p),
 	}
 	rr := p2p.resolveRole(qm.Role, p.ID(), true)
 	if rr != qm.Role {
@@ -596,7 +652,7 @@ func (p2p *PeerToPeer) handleQuery(pkt *Packet, p *Peer) {
 		m.Roots = p2p.roots.Array()
 		m.Seeds = p2p.seeds.Array()
 	} else {
-		if r.Has(p2pRoleRoot) {
+		if r.Has(p2pRoleRoot) && !p2p.isPrivatePeer(p) {
 			p2p.logger.Infoln("handleQuery", "not allowed connection", p)
 			p.Close("handleQuery not allowed connection")
 			return
@@ -615,6 +671,20 @@ func (p2p *PeerToPeer) handleQuery(pkt *Packet, p *Peer) {
 		m.Seeds = m.Seeds[:0]
 	}
 
+	//hide all addresses if discovery is disabled, otherwise hide private peers
+	if !p2p.isDiscovery() {
+		m.Roots = m.Roots[:0]
+		m.Seeds = m.Seeds[:0]
+		m.Children = m.Children[:0]
+		m.Nephews = m.Nephews[:0]
+	} else if !p2p.privatePeers.IsEmpty() {
+		private := p2p.privateNetAddresses()
+		m.Roots = excludeNetAddresses(m.Roots, private)
+		m.Seeds = excludeNetAddresses(m.Seeds, private)
+		m.Children = excludeNetAddresses(m.Children, private)
+		m.Nephews = excludeNetAddresses(m.Nephews, private)
+	}
+
 	if len(m.Roots) > DefaultQueryElementLength {
 		m.Roots = m.Roots[:DefaultQueryElementLength]
 	}
@@ -661,6 +731,9 @@ func (p2p *PeerToPeer) handleQueryResult(pkt *Packet, p *Peer) {
 
 	p.children.ClearAndAdd(qrm.Children...)
 	p.nephews.ClearAndAdd(qrm.Nephews...)
+	if len(qrm.Observed) > 0 && p2p.onObserve != nil {
+		p2p.onObserve(p.ID(), qrm.Observed)
+	}
 
 	rr := p2p.resolveRole(qrm.Role, p.ID(), true)
 	if rr != qrm.Role {
@@ -673,13 +746,30 @@ func (p2p *PeerToPeer) handleQueryResult(pkt *Packet, p *Peer) {
 		p2p.applyPeerRole(p)
 	}
 	if !rr.Has(p2pRoleSeed) && !rr.Has(p2pRoleRoot) {
-		if !p2p.isTrustSeed(p) {
+		if !p2p.isTrustSeed(p) && !p2p.isPrivatePeer(p) {
 			p2p.logger.Infoln("handleQueryResult", "invalid query, not allowed connection", p)
 			p.CloseByError(fmt.Errorf("handleQueryResult invalid query, resolved role %d", rr))
 			return
 		}
 	}
 
+	if p2p.isDiscovery() {
+		p2p.mergeQueryResult(qrm)
+	}
+
+	last, avg := p.rtt.Value()
+	m := &RttMessage{Last: last, Average: avg}
+	rpkt := newPacket(p2pProtoControl, p2pProtoRttReq, p2p.encode(m), p2p.ID())
+	rpkt.destPeer = p.ID()
+	err = p.sendPacket(rpkt)
+	if err != nil {
+		p2p.logger.Infoln("handleQueryResult", "sendRttRequest", err, p)
+	} else {
+		p2p.logger.Traceln("handleQueryResult", "sendRttRequest", m, p)
+	}
+}
+
+func (p2p *PeerToPeer) mergeQueryResult(qrm *QueryResultMessage) {
 	r := p2p.Role()
 	if r.Has(p2pRoleSeed) || r.Has(p2pRoleRoot) {
 		roots := make([]NetAddress, 0)
@@ -697,17 +787,6 @@ func (p2p *PeerToPeer) handleQueryResult(pkt *Packet, p *Peer) {
 		}
 	}
 	p2p.seeds.Merge(seeds...)
-
-	last, avg := p.rtt.Value()
-	m := &RttMessage{Last: last, Average: avg}
-	rpkt := newPacket(p2pProtoControl, p2pProtoRttReq, p2p.encode(m), p2p.ID())
-	rpkt.destPeer = p.ID()
-	err = p.sendPacket(rpkt)
-	if err != nil {
-		p2p.logger.Infoln("handleQueryResult", "sendRttRequest", err, p)
-	} else {
-		p2p.logger.Traceln("handleQueryResult", "sendRttRequest", m, p)
-	}
 }
 
 func (p2p *PeerToPeer) handleRttRequest(pkt *Packet, p *Peer) {
@@ -1163,6 +1242,7 @@ func (p2p *PeerToPeer) discoverRoutine() {
 		seedTicker.Stop()
 		discoveryTicker.Stop()
 	}()
+	p2p.dialPrivatePeers()
 	for na, _ := range p2p.trustSeeds.Map() {
 		p2p.logger.Debugln("discoverRoutine", "initialize", "dial to trustSeed", na)
 		p2p.dial(na)
@@ -1174,6 +1254,7 @@ Loop:
 			p2p.logger.Debugln("discoverRoutine", "stop")
 			break Loop
 		case <-seedTicker.C:
+			p2p.dialPrivatePeers()
 			r := p2p.Role()
 			if p2p.query(r) {
 				dialed := 0
@@ -1201,7 +1282,7 @@ Loop:
 				}
 			} else {
 				outSeeds := p2p.findPeers(func(p *Peer) bool {
-					return !p.In() && p.HasRole(p2pRoleSeed) && !p.HasRole(p2pRoleRoot)
+					return !p.In() && p.HasRole(p2pRoleSeed) && !p.HasRole(p2pRoleRoot) && !p2p.isPrivatePeer(p)
 				}, p2pConnTypeNone)
 				for _, p := range outSeeds {
 					p2p.logger.Debugln("discoverRoutine", "seedTicker", "no need outgoing p2pRoleSeed connection")
@@ -1342,12 +1423,85 @@ func (p2p *PeerToPeer) setTrustSeeds(seeds []NetAddress) {
 	p2p.trustSeeds.ClearAndAdd(ss...)
 }
 
+// setPrivatePeers sets the peers which are always connected and never
+// gossiped. Data of the entry is the expected PeerID, if it's empty then
+// the peer is identified by NetAddress.
+func (p2p *PeerToPeer) setPrivatePeers(peers map[NetAddress]string) {
+	p2p.privatePeers.Clear()
+	for na, id := range peers {
+		if na != p2p.NetAddress() {
+			p2p.privatePeers.SetAndRemoveByData(na, id)
+		}
+	}
+}
+
+func (p2p *PeerToPeer) isPrivatePeer(p *Peer) bool {
+	if p.ID() == nil {
+		return false
+	}
+	id := p.ID().String()
+	for na, d := range p2p.privatePeers.Map() {
+		if len(d) > 0 {
+			if d == id {
+				return true
+			}
+		} else if na == p.DialNetAddress() || na == p.NetAddress() {
+			return true
+		}
+	}
+	return false
+}
+
+// privateNetAddresses returns configured addresses of private peers and
+// advertised addresses of connected private peers.
+func (p2p *PeerToPeer) privateNetAddresses() map[NetAddress]bool {
+	m := make(map[NetAddress]bool)
+	for na := range p2p.privatePeers.Map() {
+		m[na] = true
+	}
+	for _, p := range p2p.findPeers(p2p.isPrivatePeer) {
+		m[p.NetAddress()] = true
+	}
+	return m
+}
+
+func excludeNetAddresses(arr []NetAddress, m map[NetAddress]bool) []NetAddress {
+	r := make([]NetAddress, 0, len(arr))
+	for _, na := range arr {
+		if !m[na] {
+			r = append(r, na)
+		}
+	}
+	return r
+}
+
+func (p2p *PeerToPeer) dialPrivatePeers() {
+	for na := range p2p.privatePeers.Map() {
+		if !p2p.hasNetAddress(na) {
+			p2p.logger.Debugln("dialPrivatePeers", "dial to privatePeer", na)
+			p2p.dial(na)
+		}
+	}
+}
+
+func (p2p *PeerToPeer) setDiscovery(enable bool) {
+	if enable {
+		atomic.StoreInt32(&p2p.noDiscovery, 0)
+	} else {
+		atomic.StoreInt32(&p2p.noDiscovery, 1)
+	}
+}
+
+func (p2p *PeerToPeer) isDiscovery() bool {
+	return atomic.LoadInt32(&p2p.noDiscovery) == 0
+}
+
 func (p2p *PeerToPeer) discoverParents(pr PeerRoleFlag) (complete bool) {
 	ps := p2p.findPeers(func(p *Peer) bool {
 		return !p.HasRole(pr)
 	}, p2pConnTypeParent)
 	for _, p := range ps {
-		if !(pr == p2pRoleSeed && p2p.isTrustSeed(p)) {
+		if !(pr == p2pRoleSeed && p2p.isTrustSeed(p)) && !p2p.isPrivatePeer(p) {
 			p2p.logger.Debugln("discoverParents", "not allowed connection", p.id)
 			p.Close("discoverParents not allowed connection")
 		}
@@ -1488,6 +1642,22 @@ func (p2p *PeerToPeer) getConnectionLimit(connType PeerConnectionType) int {
 	return v
 }
 
+func (p2p *PeerToPeer) setBandwidthLimit(r RateLimit) {
+	p2p.bwLimitMtx.Lock()
+	p2p.bwLimit = r
+	p2p.bwLimitMtx.Unlock()
+
+	for _, p := range p2p.getPeers() {
+		p.setBandwidthLimit(r)
+	}
+}
+
+func (p2p *PeerToPeer) getBandwidthLimit() RateLimit {
+	p2p.bwLimitMtx.RLock()
+	defer p2p.bwLimitMtx.RUnlock()
+	return p2p.bwLimit
+}
+
 func (p2p *PeerToPeer) addPeer(p *Peer) bool {
 	p2p.connMtx.Lock()
 	defer p2p.connMtx.Unlock()
@@ -1649,7 +1819,7 @@ func (p2p *PeerToPeer) updatePeerConnectionType(p *Peer, connType PeerConnection
 
 	t := p2p.m[connType]
 	l := p2p.getConnectionLimit(connType)
-	if l < 0 || l > t.Len() {
+	if l < 0 || l > t.Len() || p2p.isPrivatePeer(p) {
 		p2p._removePeer(p)
 		if updated = t.Add(p); !updated {
 			//unexpected failure
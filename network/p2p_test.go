@@ -166,3 +166,51 @@ func Test_PeerToPeer_resolveConnection(t *testing.T) {
 		assert.Equal(t, arg.expected.invalidResp, invalidResp)
 	}
 }
+
+func Test_PeerToPeer_privatePeers(t *testing.T) {
+	p2p := &PeerToPeer{
+		peerHandler:  newPeerHandler(generatePeerID(), testLogger()),
+		self:         &Peer{id: generatePeerID(), netAddress: "127.0.0.1:8080"},
+		m:            make(map[PeerConnectionType]*PeerSet),
+		privatePeers: NewNetAddressSet(),
+	}
+	for connType := p2pConnTypeNone; connType < p2pConnTypeReserved; connType++ {
+		p2p.m[connType] = NewPeerSet()
+	}
+
+	idPeer := &Peer{id: generatePeerID(), netAddress: "10.0.0.1:7100", in: true}
+	addrPeer := &Peer{id: generatePeerID(), dial: "10.0.0.2:7100", netAddress: "192.168.0.2:7100"}
+	other := &Peer{id: generatePeerID(), netAddress: "10.0.0.3:7100", in: true}
+
+	_, err := ParsePrivatePeers("invalid@10.0.0.1:7100")
+	assert.Error(t, err)
+	_, err = ParsePrivatePeers("10.0.0.1")
+	assert.Error(t, err)
+	pm, err := ParsePrivatePeers(idPeer.ID().String() + "@10.0.0.100:7100, 10.0.0.2:7100,127.0.0.1:8080")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(pm))
+	assert.Equal(t, idPeer.ID().String(), pm["10.0.0.100:7100"])
+	assert.Equal(t, "", pm["10.0.0.2:7100"])
+
+	p2p.setPrivatePeers(pm)
+	assert.Equal(t, 2, p2p.privatePeers.Len(), "self address should be ignored")
+	assert.True(t, p2p.isPrivatePeer(idPeer), "identified by id")
+	assert.True(t, p2p.isPrivatePeer(addrPeer), "identified by dial address")
+	assert.False(t, p2p.isPrivatePeer(other))
+
+	p2p.m[p2pConnTypeChildren].Add(addrPeer)
+	p2p.m[p2pConnTypeChildren].Add(other)
+	private := p2p.privateNetAddresses()
+	assert.True(t, private["10.0.0.100:7100"])
+	assert.True(t, private["10.0.0.2:7100"])
+	assert.True(t, private["192.168.0.2:7100"])
+	assert.Equal(t, []NetAddress{"10.0.0.3:7100"},
+		excludeNetAddresses([]NetAddress{"192.168.0.2:7100", "10.0.0.3:7100", "10.0.0.2:7100"}, private))
+
+	assert.True(t, p2p.isDiscovery())
+	p2p.setDiscovery(false)
+	assert.False(t, p2p.isDiscovery())
+	assert.Error(t, p2p.dial("10.0.0.3:7100"), "only private peers are allowed to dial")
+	p2p.setDiscovery(true)
+	assert.True(t, p2p.isDiscovery())
+}
@@ -62,6 +62,10 @@ type Peer struct {
 	secureKey *secureKey
 	rtt       PeerRTT
 
+	//bandwidth
+	up   *tokenBucket
+	down *tokenBucket
+
 	//log
 	logger log.Logger
 
@@ -89,6 +93,8 @@ func newPeer(conn net.Conn, in bool, dial NetAddress, l log.Logger) *Peer {
 		nephews:     NewNetAddressSet(),
 		attr:        make(map[string]interface{}),
 		dial:        dial,
+		up:          newTokenBucket(0),
+		down:        newTokenBucket(0),
 		logger:      l,
 	}
 }
@@ -396,7 +402,28 @@ func (p *Peer) receiveRoutine() {
 		} else {
 			p.logger.Infof("Peer[%s].onPacket in nil, Drop %s", p.ConnString(), pkt.String())
 		}
+		// the reader never waits for the download limit, which is applied
+		// by the receiving routine of each protocol.
+		p.down.consume(int(pkt.lengthOfPayload))
+	}
+}
+
+// waitBandwidth blocks until the bucket has available tokens,
+// it returns false if the peer is closed while waiting.
+func (p *Peer) waitBandwidth(b *tokenBucket) bool {
+	for d := b.delay(); d > 0; d = b.delay() {
+		select {
+		case <-p.close:
+			return false
+		case <-time.After(d):
+		}
 	}
+	return true
+}
+
+func (p *Peer) setBandwidthLimit(r RateLimit) {
+	p.up.setRate(r.Up)
+	p.down.setRate(r.Down)
 }
 
 func (p *Peer) sendDirect(pkt *Packet) error {
@@ -421,6 +448,9 @@ Loop:
 			break Loop
 		case <-p.q.Wait():
 			for {
+				if !p.waitBandwidth(p.up) {
+					break Loop
+				}
 				ctx := p.q.Pop()
 				if ctx == nil {
 					break
@@ -434,6 +464,7 @@ Loop:
 					return
 				}
 				p.pool.Put(pkt.hashOfPacket)
+				p.up.consume(int(pkt.lengthOfPayload))
 				p.getMetric().OnSend(pkt.dest, pkt.ttl, pkt.extendInfo.hint(), pkt.protocol.Uint16(), pkt.lengthOfPayload)
 			}
 		case <-secondTick.C:
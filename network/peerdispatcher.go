@@ -74,6 +74,21 @@ func (pd *PeerDispatcher) getByChannel(channel string) (*channelPeerHandler, boo
 	return v, ok
 }
 
+type netAddressUpdater interface {
+	updateNetAddress(na NetAddress)
+}
+
+func (pd *PeerDispatcher) updateNetAddress(na NetAddress) {
+	pd.peerHandlerMapMtx.RLock()
+	defer pd.peerHandlerMapMtx.RUnlock()
+
+	for _, v := range pd.peerHandlerMap {
+		if u, ok := v.ph.(netAddressUpdater); ok {
+			u.updateNetAddress(na)
+		}
+	}
+}
+
 func (pd *PeerDispatcher) registerPeerHandler(ph PeerHandler, pushBack bool) {
 	pd.peerHandlersMtx.Lock()
 	defer pd.peerHandlersMtx.Unlock()
@@ -18,6 +18,8 @@ type protocolHandler struct {
 	policy       module.NotRegisteredProtocolPolicy
 	receiveQueue Queue
 	eventQueue   Queue
+	up           *tokenBucket
+	down         *tokenBucket
 	//log
 	logger log.Logger
 
@@ -33,6 +35,7 @@ func newProtocolHandler(
 	name string,
 	priority uint8,
 	policy module.NotRegisteredProtocolPolicy,
+	limit RateLimit,
 	l log.Logger) *protocolHandler {
 	phLogger := l.WithFields(log.Fields{LoggerFieldKeySubModule: name})
 	ph := &protocolHandler{
@@ -45,6 +48,8 @@ func newProtocolHandler(
 		policy:       policy,
 		receiveQueue: NewQueue(DefaultReceiveQueueSize),
 		eventQueue:   NewQueue(DefaultEventQueueSize),
+		up:           newTokenBucket(limit.Up),
+		down:         newTokenBucket(limit.Down),
 		logger:       phLogger,
 	}
 	for _, sp := range spiList {
@@ -107,6 +112,33 @@ func (ph *protocolHandler) getName() string {
 	return ph.name
 }
 
+func (ph *protocolHandler) setBandwidthLimit(r RateLimit) {
+	ph.up.setRate(r.Up)
+	ph.down.setRate(r.Down)
+}
+
+// isPeerLimitExempt returns whether the protocol doesn't wait for the
+// download limit of the peer, so that the node keeps up with the consensus
+// while other protocols of the peer are throttled.
+func isPeerLimitExempt(pi module.ProtocolInfo) bool {
+	return pi.ID() == module.ProtoConsensus.ID() || pi.ID() == module.ProtoConsensusSync.ID()
+}
+
+// waitBandwidth blocks until the download limits of the protocol and the
+// peer allow the packet. It's called by receiveRoutine of the protocol, so
+// it doesn't block the reader of the peer and other protocols.
+// It returns false if the handler is terminated while waiting.
+func (ph *protocolHandler) waitBandwidth(pkt *Packet, p *Peer) bool {
+	if !ph.down.wait(ph.run) {
+		return false
+	}
+	ph.down.consume(len(pkt.payload))
+	if !isPeerLimitExempt(ph.protocol) {
+		return p.down.wait(ph.run)
+	}
+	return true
+}
+
 func (ph *protocolHandler) getSubProtocol(spi module.ProtocolInfo) (module.ProtocolInfo, bool) {
 	p, ok := ph.subProtocols[spi.Uint16()]
 	return p, ok
@@ -136,6 +168,9 @@ Loop:
 				}
 				pkt := ctx.Value(p2pContextKeyPacket).(*Packet)
 				p := ctx.Value(p2pContextKeyPeer).(*Peer)
+				if !ph.waitBandwidth(pkt, p) {
+					break Loop
+				}
 				r := ph.getReactor()
 				isRelay, _ := r.OnReceive(pkt.subProtocol, pkt.payload, p.ID())
 				if isRelay && pkt.ttl == byte(module.BroadcastAll) && pkt.dest != p2pDestPeer {
@@ -235,6 +270,10 @@ func (ph *protocolHandler) send(
 	if DefaultPacketPayloadMax < len(b) {
 		return ErrIllegalArgument
 	}
+	if !ph.up.wait(ph.run) {
+		return ErrAlreadyClosed
+	}
+	ph.up.consume(len(b))
 	pkt := NewPacket(ph.protocol, spi, b)
 	pkt.priority = ph.getPriority()
 	pkt.dest = dest
@@ -0,0 +1,81 @@
+package network
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/test/clock"
+)
+
+type countingReactor struct {
+	mtx   sync.Mutex
+	count int
+}
+
+func (r *countingReactor) OnReceive(pi module.ProtocolInfo, b []byte, id module.PeerID) (bool, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.count += 1
+	return false, nil
+}
+
+func (r *countingReactor) OnJoin(id module.PeerID)  {}
+func (r *countingReactor) OnLeave(id module.PeerID) {}
+
+func (r *countingReactor) Count() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.count
+}
+
+func newTestProtocolHandler(pi module.ProtocolInfo, name string, r module.Reactor) *protocolHandler {
+	return newProtocolHandler(nil, pi, nil, r, name, testProtoPriority,
+		module.NotRegisteredProtocolPolicyNone, RateLimit{}, testLogger())
+}
+
+func TestProtocolHandler_DownloadLimit(t *testing.T) {
+	const size = 1000
+	cl := &clock.Clock{}
+	p, _ := newPeerWithFakeConn(true)
+	p.down = newTokenBucketWithClock(4*size, cl)
+
+	sr := &countingReactor{}
+	ss := newTestProtocolHandler(module.ProtoStateSync, "statesync", sr)
+	ss.down = newTokenBucketWithClock(size, cl)
+	defer ss.Term()
+	cr := &countingReactor{}
+	cs := newTestProtocolHandler(module.ProtoConsensus, "consensus", cr)
+	defer cs.Term()
+
+	// receive emulates the reader of the peer, which never waits
+	receive := func(ph *protocolHandler, n int) {
+		for i := 0; i < n; i++ {
+			ph.onPacket(NewPacket(ph.protocol, ph.protocol, make([]byte, size)), p)
+			p.down.consume(size)
+		}
+	}
+
+	// state sync uses up the limits of the protocol and the peer
+	receive(ss, 3)
+	assert.Eventually(t, func() bool {
+		return ss.down.ToMap()["throttled"] != "0s"
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, 1, sr.Count())
+
+	// consensus packets of the peer are delivered without passing time
+	receive(cs, 5)
+	assert.Eventually(t, func() bool {
+		return cr.Count() == 5
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, 1, sr.Count())
+
+	// throttled packets are delivered as time passes, without dropping
+	assert.Eventually(t, func() bool {
+		cl.PassTime(time.Second)
+		return sr.Count() == 3
+	}, 5*time.Second, time.Millisecond)
+}
@@ -0,0 +1,256 @@
+package network
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	BandwidthLimitPeer     = "peer"
+	bandwidthLimitSp       = ","
+	bandwidthLimitKeySp    = "="
+	bandwidthLimitRateSp   = "/"
+	DefaultTokenBucketWait = 100 * time.Millisecond
+)
+
+// RateLimit is the bandwidth limit in bytes per second for each direction.
+// Zero means unlimited.
+type RateLimit struct {
+	Up   int64
+	Down int64
+}
+
+func (r RateLimit) IsUnlimited() bool {
+	return r.Up <= 0 && r.Down <= 0
+}
+
+func (r RateLimit) String() string {
+	return formatRate(r.Up) + bandwidthLimitRateSp + formatRate(r.Down)
+}
+
+// BandwidthLimit is the set of limits for the peers and the protocols.
+// Limits of the protocols are indexed by the name of the reactor.
+// Received packets wait for the download limits in the receiving routine of
+// the protocol, and packets of the consensus don't wait for the limit of
+// the peer.
+type BandwidthLimit struct {
+	Peer      RateLimit
+	Protocols map[string]RateLimit
+}
+
+// ParseBandwidthLimit parses the spec like "peer=1m/2m,statesync=512k/1m".
+// Each entry has the name of the reactor (or "peer" for each peer
+// connection) and upload/download limit in bytes per second.
+// Size suffix k, m and g are allowed. If only one rate is given, it's used
+// for both direction.
+func ParseBandwidthLimit(spec string) (*BandwidthLimit, error) {
+	bl := &BandwidthLimit{Protocols: make(map[string]RateLimit)}
+	for _, e := range strings.Split(spec, bandwidthLimitSp) {
+		e = strings.TrimSpace(e)
+		if len(e) == 0 {
+			continue
+		}
+		kv := strings.SplitN(e, bandwidthLimitKeySp, 2)
+		if len(kv) != 2 || len(kv[0]) == 0 {
+			return nil, errors.IllegalArgumentError.Errorf("invalid bandwidth limit entry %s", e)
+		}
+		var r RateLimit
+		var err error
+		rates := strings.SplitN(kv[1], bandwidthLimitRateSp, 2)
+		if r.Up, err = parseRate(rates[0]); err != nil {
+			return nil, err
+		}
+		r.Down = r.Up
+		if len(rates) > 1 {
+			if r.Down, err = parseRate(rates[1]); err != nil {
+				return nil, err
+			}
+		}
+		if kv[0] == BandwidthLimitPeer {
+			bl.Peer = r
+		} else {
+			bl.Protocols[kv[0]] = r
+		}
+	}
+	return bl, nil
+}
+
+func (bl *BandwidthLimit) Protocol(name string) RateLimit {
+	if bl == nil {
+		return RateLimit{}
+	}
+	return bl.Protocols[name]
+}
+
+func (bl *BandwidthLimit) String() string {
+	if bl == nil {
+		return ""
+	}
+	var l []string
+	if !bl.Peer.IsUnlimited() {
+		l = append(l, BandwidthLimitPeer+bandwidthLimitKeySp+bl.Peer.String())
+	}
+	names := make([]string, 0, len(bl.Protocols))
+	for k := range bl.Protocols {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		l = append(l, k+bandwidthLimitKeySp+bl.Protocols[k].String())
+	}
+	return strings.Join(l, bandwidthLimitSp)
+}
+
+var rateUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"g", 1024 * 1024 * 1024},
+	{"m", 1024 * 1024},
+	{"k", 1024},
+}
+
+func parseRate(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range rateUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSuffix(s, u.suffix), u.size
+			break
+		}
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 0 {
+		return 0, errors.IllegalArgumentError.Errorf("invalid rate %s", s)
+	}
+	return v * unit, nil
+}
+
+func formatRate(v int64) string {
+	if v <= 0 {
+		return "0"
+	}
+	for _, u := range rateUnits {
+		if v%u.size == 0 {
+			return strconv.FormatInt(v/u.size, 10) + u.suffix
+		}
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+// tokenBucket limits the rate of bytes. Consuming is always allowed even
+// though it exceeds available tokens, then the user should wait for the
+// delay before the next consuming. Burst size is same as the rate.
+type tokenBucket struct {
+	mtx       sync.Mutex
+	clock     common.Clock
+	rate      int64
+	tokens    float64
+	last      time.Time
+	bytes     int64
+	throttled time.Duration
+}
+
+func newTokenBucket(rate int64) *tokenBucket {
+	return newTokenBucketWithClock(rate, &common.GoTimeClock{})
+}
+
+func newTokenBucketWithClock(rate int64, clock common.Clock) *tokenBucket {
+	b := &tokenBucket{clock: clock}
+	b.setRate(rate)
+	return b
+}
+
+func (b *tokenBucket) setRate(rate int64) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if rate < 0 {
+		rate = 0
+	}
+	b.rate = rate
+	b.tokens = float64(rate)
+	b.last = b.clock.Now()
+}
+
+func (b *tokenBucket) Rate() int64 {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.rate
+}
+
+func (b *tokenBucket) _refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += float64(b.rate) * elapsed.Seconds()
+		if limit := float64(b.rate); b.tokens > limit {
+			b.tokens = limit
+		}
+		b.last = now
+	}
+}
+
+func (b *tokenBucket) consume(n int) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.bytes += int64(n)
+	if b.rate <= 0 {
+		return
+	}
+	b._refill(b.clock.Now())
+	b.tokens -= float64(n)
+}
+
+// delay returns the duration to wait until the tokens become available.
+func (b *tokenBucket) delay() time.Duration {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.rate <= 0 {
+		return 0
+	}
+	b._refill(b.clock.Now())
+	if b.tokens > 0 {
+		return 0
+	}
+	d := time.Duration((1 - b.tokens) / float64(b.rate) * float64(time.Second))
+	if d > DefaultTokenBucketWait {
+		d = DefaultTokenBucketWait
+	}
+	b.throttled += d
+	return d
+}
+
+// wait blocks until the bucket has available tokens. It returns false if
+// stop is closed while waiting.
+func (b *tokenBucket) wait(stop <-chan bool) bool {
+	for d := b.delay(); d > 0; d = b.delay() {
+		ch := make(chan bool, 1)
+		t := b.clock.AfterFunc(d, func() {
+			ch <- true
+		})
+		select {
+		case <-stop:
+			t.Stop()
+			return false
+		case <-ch:
+		}
+	}
+	return true
+}
+
+func (b *tokenBucket) ToMap() map[string]interface{} {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return map[string]interface{}{
+		"limit":     formatRate(b.rate),
+		"bytes":     b.bytes,
+		"throttled": b.throttled.String(),
+	}
+}
@@ -0,0 +1,69 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBandwidthLimit(t *testing.T) {
+	bl, err := ParseBandwidthLimit("peer=1m/2m, statesync=512k,fastsync=100/0")
+	assert.NoError(t, err)
+	assert.Equal(t, RateLimit{Up: 1024 * 1024, Down: 2 * 1024 * 1024}, bl.Peer)
+	assert.Equal(t, RateLimit{Up: 512 * 1024, Down: 512 * 1024}, bl.Protocol("statesync"))
+	assert.Equal(t, RateLimit{Up: 100}, bl.Protocol("fastsync"))
+	assert.Equal(t, RateLimit{}, bl.Protocol("consensus"))
+	assert.Equal(t, "peer=1m/2m,fastsync=100/0,statesync=512k/512k", bl.String())
+
+	bl, err = ParseBandwidthLimit("")
+	assert.NoError(t, err)
+	assert.True(t, bl.Peer.IsUnlimited())
+	assert.Equal(t, "", bl.String())
+
+	var nilBL *BandwidthLimit
+	assert.Equal(t, RateLimit{}, nilBL.Protocol("statesync"))
+
+	for _, spec := range []string{"peer", "=1m", "peer=1x", "peer=-1", "peer=1m/a"} {
+		_, err = ParseBandwidthLimit(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(0)
+	b.consume(1024 * 1024)
+	assert.Equal(t, time.Duration(0), b.delay())
+
+	b.setRate(1000)
+	assert.Equal(t, int64(1000), b.Rate())
+	b.consume(500)
+	assert.Equal(t, time.Duration(0), b.delay())
+	b.consume(550)
+	d := b.delay()
+	assert.True(t, d > 0 && d <= DefaultTokenBucketWait, d)
+
+	time.Sleep(d + 10*time.Millisecond)
+	assert.Equal(t, time.Duration(0), b.delay())
+
+	m := b.ToMap()
+	assert.Equal(t, int64(1024*1024+1050), m["bytes"])
+	assert.Equal(t, "1000", m["limit"])
+}
+
+func TestTokenBucket_Throughput(t *testing.T) {
+	b := newTokenBucket(10 * 1024)
+	start := time.Now()
+	sent := 0
+	for sent < 30*1024 {
+		for d := b.delay(); d > 0; d = b.delay() {
+			time.Sleep(d)
+		}
+		b.consume(1024)
+		sent += 1024
+	}
+	// burst of 10k is allowed, then remaining 20k takes about 2 seconds
+	elapsed := time.Since(start)
+	assert.True(t, elapsed > 1500*time.Millisecond, elapsed)
+	assert.True(t, elapsed < 3*time.Second, elapsed)
+}
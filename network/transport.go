@@ -19,6 +19,7 @@ type transport struct {
 	cn      *ChannelNegotiator
 	pd      *PeerDispatcher
 	dMap    map[string]*Dialer
+	aa      *addressAdvertiser
 	logger  log.Logger
 }
 
@@ -43,15 +44,24 @@ func NewTransport(address string, w module.Wallet, l log.Logger) module.NetworkT
 		dMap:    make(map[string]*Dialer),
 		logger:  transportLogger,
 	}
+	t.aa = newAddressAdvertiser(na, t.onAddressUpdate, transportLogger)
 	return t
 }
 
 func (t *transport) Listen() error {
-	return t.l.Listen()
+	if err := t.l.Listen(); err != nil {
+		return err
+	}
+	t.aa.start(t.l.Address())
+	return nil
 }
 
 func (t *transport) Close() error {
-	return t.l.Close()
+	if err := t.l.Close(); err != nil {
+		return err
+	}
+	t.aa.stop()
+	return nil
 }
 
 func (t *transport) Dial(address string, channel string) error {
@@ -64,7 +74,7 @@ func (t *transport) PeerID() module.PeerID {
 }
 
 func (t *transport) Address() string {
-	return string(t.address)
+	return string(t.aa.Address())
 }
 
 func (t *transport) SetListenAddress(address string) error {
@@ -75,6 +85,23 @@ func (t *transport) GetListenAddress() string {
 	return t.l.Address()
 }
 
+func (t *transport) SetNAT(spec string) error {
+	return t.aa.setNAT(spec)
+}
+
+func (t *transport) GetNAT() string {
+	return t.aa.NAT()
+}
+
+func (t *transport) onAddressUpdate(na NetAddress) {
+	t.cn.setNetAddress(na)
+	t.pd.updateNetAddress(na)
+}
+
+func (t *transport) observeAddress(id module.PeerID, na NetAddress) {
+	t.aa.observe(id, na)
+}
+
 func (t *transport) GetDialer(channel string) *Dialer {
 	d, ok := t.dMap[channel]
 	if !ok {
@@ -0,0 +1,240 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/icon-project/goloop/common/errors"
+)
+
+const (
+	upnpSSDPAddress   = "239.255.255.250:1900"
+	upnpSearchTarget  = "urn:schemas-upnp-org:device:InternetGatewayDevice:1"
+	upnpHTTPTimeout   = 5 * time.Second
+	upnpSOAPEnvelope  = "http://schemas.xmlsoap.org/soap/envelope/"
+	upnpSOAPEncoding  = "http://schemas.xmlsoap.org/soap/encoding/"
+	upnpMaxDescLength = 1024 * 1024
+)
+
+var upnpServiceTypes = []string{
+	"urn:schemas-upnp-org:service:WANIPConnection:2",
+	"urn:schemas-upnp-org:service:WANIPConnection:1",
+	"urn:schemas-upnp-org:service:WANPPPConnection:1",
+}
+
+// upnp is UPnP Internet Gateway Device client
+type upnp struct {
+	serviceType string
+	controlURL  string
+	client      *http.Client
+}
+
+func discoverUPnP(timeout time.Duration) (*upnp, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	dst, err := net.ResolveUDPAddr("udp4", upnpSSDPAddress)
+	if err != nil {
+		return nil, err
+	}
+	req := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + upnpSSDPAddress + "\r\n" +
+		"ST: " + upnpSearchTarget + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 2\r\n\r\n"
+	if _, err = conn.WriteTo([]byte(req), dst); err != nil {
+		return nil, err
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return nil, errors.NotFoundError.Wrap(err, "UPnP gateway not found")
+		}
+		resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(buf[:n])), nil)
+		if err != nil {
+			continue
+		}
+		location := resp.Header.Get("Location")
+		if location == "" {
+			continue
+		}
+		if u, err := newUPnP(location); err == nil {
+			return u, nil
+		}
+	}
+}
+
+type upnpService struct {
+	ServiceType string `xml:"serviceType"`
+	ControlURL  string `xml:"controlURL"`
+}
+
+type upnpDevice struct {
+	DeviceType string        `xml:"deviceType"`
+	Services   []upnpService `xml:"serviceList>service"`
+	Devices    []upnpDevice  `xml:"deviceList>device"`
+}
+
+func (d *upnpDevice) findService(st string) *upnpService {
+	for i := range d.Services {
+		if d.Services[i].ServiceType == st {
+			return &d.Services[i]
+		}
+	}
+	for i := range d.Devices {
+		if s := d.Devices[i].findService(st); s != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+type upnpRoot struct {
+	URLBase string     `xml:"URLBase"`
+	Device  upnpDevice `xml:"device"`
+}
+
+// newUPnP creates client with the device description at the location
+func newUPnP(location string) (*upnp, error) {
+	client := &http.Client{Timeout: upnpHTTPTimeout}
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.InvalidStateError.Errorf("UPnP description status %s", resp.Status)
+	}
+	root := &upnpRoot{}
+	if err = xml.NewDecoder(io.LimitReader(resp.Body, upnpMaxDescLength)).Decode(root); err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if root.URLBase != "" {
+		if base, err = url.Parse(root.URLBase); err != nil {
+			return nil, err
+		}
+	}
+	for _, st := range upnpServiceTypes {
+		if s := root.Device.findService(st); s != nil {
+			ctl, err := base.Parse(s.ControlURL)
+			if err != nil {
+				return nil, err
+			}
+			return &upnp{serviceType: st, controlURL: ctl.String(), client: client}, nil
+		}
+	}
+	return nil, errors.NotFoundError.Errorf("UPnP WAN connection service not found in %s", location)
+}
+
+func (u *upnp) soap(action string, args [][2]string, result interface{}) error {
+	b := &strings.Builder{}
+	b.WriteString(`<?xml version="1.0"?>`)
+	fmt.Fprintf(b, `<s:Envelope xmlns:s="%s" s:encodingStyle="%s"><s:Body>`, upnpSOAPEnvelope, upnpSOAPEncoding)
+	fmt.Fprintf(b, `<u:%s xmlns:u="%s">`, action, u.serviceType)
+	for _, arg := range args {
+		fmt.Fprintf(b, "<%s>", arg[0])
+		_ = xml.EscapeText(b, []byte(arg[1]))
+		fmt.Fprintf(b, "</%s>", arg[0])
+	}
+	fmt.Fprintf(b, `</u:%s></s:Body></s:Envelope>`, action)
+
+	req, err := http.NewRequest(http.MethodPost, u.controlURL, strings.NewReader(b.String()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", fmt.Sprintf(`"%s#%s"`, u.serviceType, action))
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.InvalidStateError.Errorf("UPnP %s status %s", action, resp.Status)
+	}
+	if result == nil {
+		return nil
+	}
+	env := &struct {
+		Body struct {
+			Inner []byte `xml:",innerxml"`
+		}
+	}{}
+	if err = xml.NewDecoder(io.LimitReader(resp.Body, upnpMaxDescLength)).Decode(env); err != nil {
+		return err
+	}
+	return xml.Unmarshal(env.Body.Inner, result)
+}
+
+func (u *upnp) ExternalIP() (net.IP, error) {
+	r := &struct {
+		IP string `xml:"NewExternalIPAddress"`
+	}{}
+	if err := u.soap("GetExternalIPAddress", nil, r); err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(strings.TrimSpace(r.IP))
+	if ip == nil {
+		return nil, errors.InvalidStateError.Errorf("UPnP invalid external ip %s", r.IP)
+	}
+	return ip, nil
+}
+
+func (u *upnp) AddPortMapping(protocol string, extPort, intPort int, desc string, lifetime time.Duration) (int, error) {
+	cu, err := url.Parse(u.controlURL)
+	if err != nil {
+		return 0, err
+	}
+	port := cu.Port()
+	if port == "" {
+		port = "80"
+	}
+	local, err := localIPFor(net.JoinHostPort(cu.Hostname(), port))
+	if err != nil {
+		return 0, err
+	}
+	args := [][2]string{
+		{"NewRemoteHost", ""},
+		{"NewExternalPort", strconv.Itoa(extPort)},
+		{"NewProtocol", strings.ToUpper(protocol)},
+		{"NewInternalPort", strconv.Itoa(intPort)},
+		{"NewInternalClient", local.String()},
+		{"NewEnabled", "1"},
+		{"NewPortMappingDescription", desc},
+		{"NewLeaseDuration", strconv.Itoa(int(lifetime / time.Second))},
+	}
+	if err = u.soap("AddPortMapping", args, nil); err != nil {
+		return 0, err
+	}
+	return extPort, nil
+}
+
+func (u *upnp) DeletePortMapping(protocol string, extPort, intPort int) error {
+	args := [][2]string{
+		{"NewRemoteHost", ""},
+		{"NewExternalPort", strconv.Itoa(extPort)},
+		{"NewProtocol", strings.ToUpper(protocol)},
+	}
+	return u.soap("DeletePortMapping", args, nil)
+}
+
+func (u *upnp) String() string {
+	return NATUPnP
+}
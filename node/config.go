@@ -20,14 +20,18 @@ const (
 
 type StaticConfig struct {
 	// static
-	CliSocket     string `json:"node_sock"` // relative path
-	P2PAddr       string `json:"p2p"`
-	P2PListenAddr string `json:"p2p_listen"`
-	RPCAddr       string `json:"rpc_addr"`
-	RPCDump       bool   `json:"rpc_dump"`
-	EESocket      string `json:"ee_socket"`
-	Engines       string `json:"engines"`
-	BackupDir     string `json:"backup_dir"`
+	CliSocket      string `json:"node_sock"` // relative path
+	P2PAddr        string `json:"p2p"`
+	P2PListenAddr  string `json:"p2p_listen"`
+	P2PNAT         string `json:"p2p_nat,omitempty"`
+	RPCAddr        string `json:"rpc_addr"`
+	RPCDump        bool   `json:"rpc_dump"`
+	GRPCAddr       string `json:"grpc_addr,omitempty"`
+	GRPCMaxConns   int    `json:"grpc_max_conns,omitempty"`
+	GRPCMaxStreams int    `json:"grpc_max_streams,omitempty"`
+	EESocket       string `json:"ee_socket"`
+	Engines        string `json:"engines"`
+	BackupDir      string `json:"backup_dir"`
 
 	AuthSkipIfEmptyUsers bool `json:"auth_skip_if_empty_users,omitempty"`
 	NIDForP2P            bool `json:"nid_for_p2p,omitempty"`
@@ -113,6 +117,9 @@ type RuntimeConfig struct {
 	RPCRosetta        bool   `json:"rpcRosetta"`
 	RPCBatchLimit     int    `json:"rpcBatchLimit"`
 	WSMaxSession      int    `json:"wsMaxSession"`
+	RPCCacheSize      int    `json:"rpcCacheSize"`
+
+	RPCRateLimit *server.RateLimitConfig `json:"rpcRateLimit,omitempty"`
 
 	FilePath string `json:"-"` // absolute path
 }
@@ -150,6 +157,7 @@ func loadRuntimeConfig(baseDir string) (*RuntimeConfig, error) {
 		RPCBatchLimit: jsonrpc.DefaultBatchLimit,
 		FilePath:      path.Join(baseDir, "rconfig.json"),
 		WSMaxSession:  server.DefaultWSMaxSession,
+		RPCCacheSize:  server.DefaultJSONRPCCacheSize,
 	}
 	if err := cfg.load(); err != nil {
 		if os.IsNotExist(err) {
@@ -401,6 +401,7 @@ func (n *Node) JoinChain(
 		Role:             p.Role,
 		GenesisStorage:   genesisStorage,
 		ConcurrencyLevel: p.ConcurrencyLevel,
+		OptimisticExec:   p.OptimisticExec,
 		NormalTxPoolSize: p.NormalTxPoolSize,
 		PatchTxPoolSize:  p.PatchTxPoolSize,
 		MaxBlockTxBytes:  p.MaxBlockTxBytes,
@@ -414,6 +415,9 @@ func (n *Node) JoinChain(
 		ChildrenLimit:    p.ChildrenLimit,
 		NephewsLimit:     p.NephewsLimit,
 		ValidateTxOnSend: p.ValidateTxOnSend,
+		BandwidthLimit:   p.BandwidthLimit,
+		PrivatePeers:     p.PrivatePeers,
+		DisableDiscovery: p.DisableDiscovery,
 	}
 
 	if err := cfg.Save(); err != nil {
@@ -676,6 +680,12 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 			} else {
 				c.cfg.ConcurrencyLevel = intVal
 			}
+		case "optimisticExecution":
+			if bc, err := strconv.ParseBool(value); err != nil {
+				return errors.Wrapf(err, "InvalidValueType(exp=bool,val=%s)", value)
+			} else {
+				c.cfg.OptimisticExec = bc
+			}
 		case "normalTxPool":
 			if intVal, err := strconv.Atoi(value); err != nil {
 				return errors.Wrapf(err, "invalid value type")
@@ -767,6 +777,22 @@ func (n *Node) ConfigureChain(cid int, key string, value string) error {
 			} else {
 				c.cfg.ValidateTxOnSend = bc
 			}
+		case "bandwidthLimit":
+			if _, err := network.ParseBandwidthLimit(value); err != nil {
+				return err
+			}
+			c.cfg.BandwidthLimit = value
+		case "privatePeers":
+			if _, err := network.ParsePrivatePeers(value); err != nil {
+				return err
+			}
+			c.cfg.PrivatePeers = value
+		case "disableDiscovery":
+			if bc, err := strconv.ParseBool(value); err != nil {
+				return errors.Wrapf(err, "InvalidValueType(exp=bool,val=%s)", value)
+			} else {
+				c.cfg.DisableDiscovery = bc
+			}
 		default:
 			return errors.Errorf("not found key %s", key)
 		}
@@ -893,6 +919,22 @@ func (n *Node) Configure(key string, value string) error {
 			n.rcfg.WSMaxSession = intVal
 		}
 		n.srv.SetWSMaxSession(n.rcfg.WSMaxSession)
+	case "rpcCacheSize":
+		if intVal, err := strconv.Atoi(value); err != nil {
+			return errors.Wrapf(err, "invalid value type")
+		} else {
+			n.rcfg.RPCCacheSize = intVal
+		}
+		n.srv.SetCacheSize(n.rcfg.RPCCacheSize)
+	case "rpcRateLimit":
+		if cfg, err := server.ParseRateLimitConfig(value); err != nil {
+			return err
+		} else {
+			n.rcfg.RPCRateLimit = cfg
+		}
+		if err := n.srv.SetRateLimit(n.rcfg.RPCRateLimit); err != nil {
+			return err
+		}
 	default:
 		return errors.Errorf("not found key")
 	}
@@ -924,14 +966,22 @@ func NewNode(
 	if cfg.P2PListenAddr != "" {
 		_ = nt.SetListenAddress(cfg.P2PListenAddr)
 	}
+	if err := nt.SetNAT(cfg.P2PNAT); err != nil {
+		log.Panicf("invalid P2P NAT err=%+v", err)
+	}
 	config := &server.Config{
 		ServerAddress:         cfg.RPCAddr,
+		GRPCAddress:           cfg.GRPCAddr,
+		GRPCMaxConnections:    cfg.GRPCMaxConns,
+		GRPCMaxStreams:        cfg.GRPCMaxStreams,
 		JSONRPCDump:           cfg.RPCDump,
 		JSONRPCIncludeDebug:   rcfg.RPCIncludeDebug,
 		JSONRPCRosetta:        rcfg.RPCRosetta,
 		JSONRPCDefaultChannel: rcfg.RPCDefaultChannel,
 		JSONRPCBatchLimit:     rcfg.RPCBatchLimit,
 		WSMaxSession:          rcfg.WSMaxSession,
+		JSONRPCRateLimit:      rcfg.RPCRateLimit,
+		JSONRPCCacheSize:      rcfg.RPCCacheSize,
 	}
 	srv := server.NewManager(config, w, l)
 
@@ -55,8 +55,10 @@ type SystemView struct {
 		Address       string `json:"address"`
 		P2PAddr       string `json:"p2p"`
 		P2PListenAddr string `json:"p2pListen"`
+		P2PNAT        string `json:"p2pNAT,omitempty"`
 		RPCAddr       string `json:"rpcAddr"`
 		RPCDump       bool   `json:"rpcDump"`
+		GRPCAddr      string `json:"grpcAddr,omitempty"`
 	} `json:"setting"`
 	Config interface{} `json:"config"`
 }
@@ -89,6 +91,7 @@ type ChainConfig struct {
 	SeedAddr         string `json:"seedAddress"`
 	Role             uint   `json:"role"`
 	ConcurrencyLevel int    `json:"concurrencyLevel,omitempty"`
+	OptimisticExec   bool   `json:"optimisticExecution,omitempty"`
 	NormalTxPoolSize int    `json:"normalTxPool,omitempty"`
 	PatchTxPoolSize  int    `json:"patchTxPool,omitempty"`
 	MaxBlockTxBytes  int    `json:"maxBlockTxBytes,omitempty"`
@@ -103,6 +106,9 @@ type ChainConfig struct {
 	ChildrenLimit    *int   `json:"childrenLimit,omitempty"`
 	NephewsLimit     *int   `json:"nephewsLimit,omitempty"`
 	ValidateTxOnSend bool   `json:"validateTxOnSend,omitempty"`
+	BandwidthLimit   string `json:"bandwidthLimit,omitempty"`
+	PrivatePeers     string `json:"privatePeers,omitempty"`
+	DisableDiscovery bool   `json:"disableDiscovery,omitempty"`
 }
 
 type ChainResetParam struct {
@@ -171,6 +177,7 @@ func NewChainConfig(cfg *chain.Config) *ChainConfig {
 		SeedAddr:         cfg.SeedAddr,
 		Role:             cfg.Role,
 		ConcurrencyLevel: cfg.ConcurrencyLevel,
+		OptimisticExec:   cfg.OptimisticExec,
 		NormalTxPoolSize: cfg.NormalTxPoolSize,
 		PatchTxPoolSize:  cfg.PatchTxPoolSize,
 		MaxBlockTxBytes:  cfg.MaxBlockTxBytes,
@@ -185,6 +192,9 @@ func NewChainConfig(cfg *chain.Config) *ChainConfig {
 		ChildrenLimit:    cfg.ChildrenLimit,
 		NephewsLimit:     cfg.NephewsLimit,
 		ValidateTxOnSend: cfg.ValidateTxOnSend,
+		BandwidthLimit:   cfg.BandwidthLimit,
+		PrivatePeers:     cfg.PrivatePeers,
+		DisableDiscovery: cfg.DisableDiscovery,
 	}
 	return v
 }
@@ -484,8 +494,10 @@ func (r *Rest) GetSystem(ctx echo.Context) error {
 	v.Setting.Address = r.n.w.Address().String()
 	v.Setting.P2PAddr = r.n.nt.Address()
 	v.Setting.P2PListenAddr = r.n.nt.GetListenAddress()
+	v.Setting.P2PNAT = r.n.nt.GetNAT()
 	v.Setting.RPCAddr = r.n.cfg.RPCAddr
 	v.Setting.RPCDump = r.n.cfg.RPCDump
+	v.Setting.GRPCAddr = r.n.cfg.GRPCAddr
 	v.Config = r.n.rcfg
 
 	format := ctx.QueryParam("format")
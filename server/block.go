@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+)
+
+// errStopped is returned when the chain stops while waiting for a block.
+var errStopped = errors.NewBase(errors.InvalidStateError, "Stopped")
+
+// waitBlock returns the block at the height. It waits until the block is
+// finalized or ctx is done.
+func waitBlock(ctx context.Context, bm module.BlockManager, height int64) (module.Block, error) {
+	bch, err := bm.WaitForBlock(height)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case blk, ok := <-bch:
+		if !ok {
+			return nil, errStopped
+		}
+		return blk, nil
+	}
+}
@@ -0,0 +1,439 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+
+	"golang.org/x/net/netutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/stream"
+)
+
+type grpcServer struct {
+	stream.UnimplementedStreamServer
+	srv    *Manager
+	logger log.Logger
+}
+
+func grpcErrorOf(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case err == context.Canceled:
+		return status.Error(codes.Canceled, err.Error())
+	case err == errStopped:
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.NotFoundError.Equals(err):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.IllegalArgumentError.Equals(err):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func (s *grpcServer) chainOf(channel string, height int64) (module.Chain, error) {
+	chain := s.srv.Chain(channel)
+	if chain == nil {
+		return nil, status.Errorf(codes.NotFound, "chain not found (channel=%s)", channel)
+	}
+	if chain.BlockManager() == nil || chain.ServiceManager() == nil {
+		return nil, status.Error(codes.Unavailable, "Stopped")
+	}
+	if gh := chain.GenesisStorage().Height(); gh > height {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"given height(%d) is lower than genesis height(%d)", height, gh)
+	}
+	return chain, nil
+}
+
+func addressBytesOf(addr module.Address) []byte {
+	if addr == nil {
+		return nil
+	}
+	return addr.Bytes()
+}
+
+func eventLogOf(el module.EventLog) *stream.EventLog {
+	return &stream.EventLog{
+		Address: addressBytesOf(el.Address()),
+		Indexed: el.Indexed(),
+		Data:    el.Data(),
+	}
+}
+
+func transactionsOf(txl module.TransactionList, patch bool) ([]*stream.Transaction, error) {
+	var txs []*stream.Transaction
+	for it := txl.Iterator(); it.Has(); it.Next() {
+		tx, _, err := it.Get()
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, &stream.Transaction{
+			Hash:    tx.ID(),
+			Version: int32(tx.Version()),
+			From:    addressBytesOf(tx.From()),
+			Patch:   patch,
+			Raw:     tx.Bytes(),
+		})
+	}
+	return txs, nil
+}
+
+func receiptsOf(sm module.ServiceManager, result []byte, group module.TransactionGroup) ([]*stream.Receipt, error) {
+	rl, err := sm.ReceiptListFromResult(result, group)
+	if err != nil {
+		return nil, err
+	}
+	var receipts []*stream.Receipt
+	index := int32(0)
+	for it := rl.Iterator(); it.Has(); it.Next() {
+		r, err := it.Get()
+		if err != nil {
+			return nil, err
+		}
+		rct := &stream.Receipt{
+			Index:              index,
+			Patch:              group == module.TransactionGroupPatch,
+			Status:             int32(r.Status()),
+			To:                 addressBytesOf(r.To()),
+			ScoreAddress:       addressBytesOf(r.SCOREAddress()),
+			CumulativeStepUsed: intconv.BigIntToBytes(r.CumulativeStepUsed()),
+			StepUsed:           intconv.BigIntToBytes(r.StepUsed()),
+			StepPrice:          intconv.BigIntToBytes(r.StepPrice()),
+			LogsBloom:          r.LogsBloom().Bytes(),
+		}
+		for eit := r.EventLogIterator(); eit.Has(); eit.Next() {
+			el, err := eit.Get()
+			if err != nil {
+				return nil, err
+			}
+			rct.Logs = append(rct.Logs, eventLogOf(el))
+		}
+		receipts = append(receipts, rct)
+		index++
+	}
+	return receipts, nil
+}
+
+func (s *grpcServer) blockOf(ctx context.Context, chain module.Chain, blk module.Block, req *stream.BlockRequest) (*stream.Block, error) {
+	header := bytes.NewBuffer(nil)
+	if err := blk.MarshalHeader(header); err != nil {
+		return nil, err
+	}
+	b := &stream.Block{
+		Height:    blk.Height(),
+		Hash:      blk.ID(),
+		PrevHash:  blk.PrevID(),
+		Timestamp: blk.Timestamp(),
+		Proposer:  addressBytesOf(blk.Proposer()),
+		Header:    header.Bytes(),
+	}
+	if req.Transactions {
+		txs, err := transactionsOf(blk.NormalTransactions(), false)
+		if err != nil {
+			return nil, err
+		}
+		ptxs, err := transactionsOf(blk.PatchTransactions(), true)
+		if err != nil {
+			return nil, err
+		}
+		b.Transactions = append(txs, ptxs...)
+	}
+	if req.Receipts {
+		// receipts of the transactions are confirmed by the next block
+		next, err := waitBlock(ctx, chain.BlockManager(), blk.Height()+1)
+		if err != nil {
+			return nil, err
+		}
+		sm := chain.ServiceManager()
+		receipts, err := receiptsOf(sm, next.Result(), module.TransactionGroupNormal)
+		if err != nil {
+			return nil, err
+		}
+		preceipts, err := receiptsOf(sm, next.Result(), module.TransactionGroupPatch)
+		if err != nil {
+			return nil, err
+		}
+		b.Receipts = append(receipts, preceipts...)
+	}
+	return b, nil
+}
+
+func (s *grpcServer) Blocks(req *stream.BlockRequest, ss stream.Stream_BlocksServer) error {
+	chain, err := s.chainOf(req.Channel, req.Height)
+	if err != nil {
+		return err
+	}
+	ctx := ss.Context()
+	for h := req.Height; ; h++ {
+		blk, err := waitBlock(ctx, chain.BlockManager(), h)
+		if err != nil {
+			return grpcErrorOf(err)
+		}
+		b, err := s.blockOf(ctx, chain, blk, req)
+		if err != nil {
+			return grpcErrorOf(err)
+		}
+		if err := ss.Send(b); err != nil {
+			s.logger.Infof("fail to send block (height=%d) err=%+v", h, err)
+			return err
+		}
+	}
+}
+
+func eventFilterOf(f *stream.EventFilter) (*EventFilter, error) {
+	ef := &EventFilter{Signature: f.Event}
+	for _, s := range f.Addrs {
+		addr, err := common.NewAddressFromString(s)
+		if err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidAddress(addr=%s)", s)
+		}
+		ef.Addrs = append(ef.Addrs, addr)
+	}
+	for _, vs := range f.Indexed {
+		ef.IndexedSets = append(ef.IndexedSets, vs.GetValues())
+	}
+	for _, vs := range f.Data {
+		ef.DataSets = append(ef.DataSets, vs.GetValues())
+	}
+	parse := func(s string) (*common.HexInt, error) {
+		if len(s) == 0 {
+			return nil, nil
+		}
+		v := new(common.HexInt)
+		if err := intconv.ParseBigInt(&v.Int, s); err != nil {
+			return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidRangeValue(value=%s)", s)
+		}
+		return v, nil
+	}
+	for _, vr := range f.IndexedRanges {
+		if vr == nil {
+			ef.IndexedRanges = append(ef.IndexedRanges, nil)
+			continue
+		}
+		r := new(EventValueRange)
+		var err error
+		if r.GT, err = parse(vr.Gt); err != nil {
+			return nil, err
+		}
+		if r.GTE, err = parse(vr.Gte); err != nil {
+			return nil, err
+		}
+		if r.LT, err = parse(vr.Lt); err != nil {
+			return nil, err
+		}
+		if r.LTE, err = parse(vr.Lte); err != nil {
+			return nil, err
+		}
+		if r.GT == nil && r.GTE == nil && r.LT == nil && r.LTE == nil {
+			r = nil
+		}
+		ef.IndexedRanges = append(ef.IndexedRanges, r)
+	}
+	return ef, nil
+}
+
+func eventFiltersOf(fs []*stream.EventFilter) (EventFilters, error) {
+	er := new(EventRequest)
+	for _, f := range fs {
+		ef, err := eventFilterOf(f)
+		if err != nil {
+			return nil, err
+		}
+		er.Filters = append(er.Filters, ef)
+	}
+	return er.Compile()
+}
+
+func (s *grpcServer) Events(req *stream.EventRequest, ss stream.Stream_EventsServer) error {
+	filters, err := eventFiltersOf(req.Filters)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	chain, err := s.chainOf(req.Channel, req.Height)
+	if err != nil {
+		return err
+	}
+	sm := chain.ServiceManager()
+	ctx := ss.Context()
+	last := int64(0)
+	for h := req.Height; ; h++ {
+		blk, err := waitBlock(ctx, chain.BlockManager(), h)
+		if err != nil {
+			return grpcErrorOf(err)
+		}
+		msgSent := 0
+		if filters2, contained := filters.FilteredByLogBloom(blk.LogsBloom()); contained {
+			rl, err := sm.ReceiptListFromResult(blk.Result(), module.TransactionGroupNormal)
+			if err != nil {
+				return grpcErrorOf(err)
+			}
+			index := int32(0)
+			for it := rl.Iterator(); it.Has(); it.Next() {
+				r, err := it.Get()
+				if err != nil {
+					return grpcErrorOf(err)
+				}
+				var en *stream.EventNotification
+				if err := filters2.filterEvents(r, func(fi, idx int, el module.EventLog) {
+					if en == nil {
+						en = &stream.EventNotification{
+							Height: h,
+							Hash:   blk.ID(),
+							Index:  index,
+						}
+					}
+					en.Events = append(en.Events, int32(idx))
+					if req.Logs {
+						en.Logs = append(en.Logs, eventLogOf(el))
+					}
+				}); err != nil {
+					return grpcErrorOf(err)
+				}
+				if en != nil {
+					msg := &stream.EventMessage{
+						Message: &stream.EventMessage_Event{Event: en},
+					}
+					if err := ss.Send(msg); err != nil {
+						s.logger.Infof("fail to send events (height=%d) err=%+v", h, err)
+						return err
+					}
+					msgSent++
+				}
+				index++
+			}
+		}
+		if pi := req.ProgressInterval; pi > 0 {
+			if last == 0 || (h-last) >= pi || msgSent > 0 {
+				last = h
+				msg := &stream.EventMessage{
+					Message: &stream.EventMessage_Progress{Progress: h},
+				}
+				if err := ss.Send(msg); err != nil {
+					s.logger.Infof("fail to send progress (height=%d) err=%+v", h, err)
+					return err
+				}
+			}
+		}
+	}
+}
+
+func (s *grpcServer) BTPMessages(req *stream.BTPRequest, ss stream.Stream_BTPMessagesServer) error {
+	chain, err := s.chainOf(req.Channel, req.Height)
+	if err != nil {
+		return err
+	}
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	cs := chain.Consensus()
+	if cs == nil {
+		return status.Error(codes.Unavailable, "Stopped")
+	}
+	lastBlk, err := bm.GetLastBlock()
+	if err != nil {
+		return grpcErrorOf(err)
+	}
+	nw, err := sm.BTPNetworkFromResult(lastBlk.Result(), req.NetworkId)
+	if err != nil {
+		return status.Errorf(codes.NotFound, "network not found (networkID=%d)", req.NetworkId)
+	}
+	ctx := ss.Context()
+	last := int64(0)
+	for h := req.Height; ; h++ {
+		blk, err := waitBlock(ctx, bm, h)
+		if err != nil {
+			return grpcErrorOf(err)
+		}
+		msgSent := 0
+		if nw.StartHeight()+1 <= h {
+			nw, err := sm.BTPNetworkFromResult(blk.Result(), req.NetworkId)
+			if err != nil {
+				return grpcErrorOf(err)
+			}
+			if !nw.Open() {
+				return status.Errorf(codes.FailedPrecondition,
+					"network is closed (height=%d,networkID=%d)", h, req.NetworkId)
+			}
+			flag := uint(module.FlagBTPBlockHeader)
+			if req.Proof && h != nw.StartHeight()+1 {
+				flag |= module.FlagBTPBlockProof
+			}
+			btpBlock, proof, err := cs.GetBTPBlockHeaderAndProof(blk, req.NetworkId, flag)
+			if err == nil {
+				bn := &stream.BTPNotification{
+					Height: h,
+					Header: btpBlock.HeaderBytes(),
+				}
+				if flag&module.FlagBTPBlockProof != 0 {
+					bn.Proof = proof
+				}
+				msg := &stream.BTPMessage{
+					Message: &stream.BTPMessage_Btp{Btp: bn},
+				}
+				if err := ss.Send(msg); err != nil {
+					s.logger.Infof("fail to send BTP message (height=%d) err=%+v", h, err)
+					return err
+				}
+				msgSent++
+			}
+		}
+		if pi := req.ProgressInterval; pi > 0 {
+			if last == 0 || (h-last) >= pi || msgSent > 0 {
+				last = h
+				msg := &stream.BTPMessage{
+					Message: &stream.BTPMessage_Progress{Progress: h},
+				}
+				if err := ss.Send(msg); err != nil {
+					s.logger.Infof("fail to send progress (height=%d) err=%+v", h, err)
+					return err
+				}
+			}
+		}
+	}
+}
+
+const (
+	DefaultGRPCMaxConnections = 10
+	DefaultGRPCMaxStreams     = 10
+)
+
+func (srv *Manager) newGRPCServer() *grpc.Server {
+	var opts []grpc.ServerOption
+	if srv.grpcMaxStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(uint32(srv.grpcMaxStreams)))
+	}
+	gs := grpc.NewServer(opts...)
+	stream.RegisterStreamServer(gs, &grpcServer{srv: srv, logger: srv.logger})
+	return gs
+}
+
+func (srv *Manager) startGRPC() error {
+	ln, err := net.Listen("tcp", srv.grpcAddr)
+	if err != nil {
+		return err
+	}
+	// connections over the limit wait until others are closed
+	if srv.grpcMaxConns > 0 {
+		ln = netutil.LimitListener(ln, srv.grpcMaxConns)
+	}
+	gs := srv.newGRPCServer()
+	srv.grpc = gs
+	srv.logger.Infof("starting the gRPC server on %s", ln.Addr())
+	go func() {
+		if err := gs.Serve(ln); err != nil {
+			srv.logger.Warnf("gRPC server stopped err=%+v", err)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/stream"
+)
+
+type testStreamBlock struct {
+	testBlock
+}
+
+func (b *testStreamBlock) Height() int64 {
+	return b.height
+}
+
+func (b *testStreamBlock) PrevID() []byte {
+	return testHeightToBlockID(b.height - 1)
+}
+
+func (b *testStreamBlock) Timestamp() int64 {
+	return b.height * 1000
+}
+
+func (b *testStreamBlock) Proposer() module.Address {
+	return nil
+}
+
+func (b *testStreamBlock) MarshalHeader(w io.Writer) error {
+	_, err := w.Write(b.ID())
+	return err
+}
+
+func newTestStreamClient(t *testing.T, chain module.Chain) stream.StreamClient {
+	return newTestStreamClientWithLimit(t, chain, 0)
+}
+
+func newTestStreamClientWithLimit(t *testing.T, chain module.Chain, maxStreams int) stream.StreamClient {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+	srv := &Manager{
+		chains:         make(map[string]module.Chain),
+		logger:         logger,
+		grpcMaxStreams: maxStreams,
+	}
+	srv.SetChain("test", chain)
+
+	ln := bufconn.Listen(1024 * 1024)
+	gs := srv.newGRPCServer()
+	go gs.Serve(ln)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			return ln.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return stream.NewStreamClient(conn)
+}
+
+func TestGRPCServer_Blocks(t *testing.T) {
+	chain := newTestChain(1, func(h int64) (getBlockFunc, error) {
+		return func() module.Block {
+			return &testStreamBlock{testBlock{height: h, result: "empty"}}
+		}, nil
+	}, blockReceipts{"empty": testReceiptList{}})
+	client := newTestStreamClient(t, chain)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bs, err := client.Blocks(ctx, &stream.BlockRequest{Channel: "test", Height: 3, Receipts: true})
+	assert.NoError(t, err)
+	for h := int64(3); h < 6; h++ {
+		b, err := bs.Recv()
+		assert.NoError(t, err)
+		assert.Equal(t, h, b.Height)
+		assert.Equal(t, testHeightToBlockID(h), b.Hash)
+		assert.Equal(t, testHeightToBlockID(h-1), b.PrevHash)
+		assert.Equal(t, testHeightToBlockID(h), b.Header)
+		assert.Empty(t, b.Receipts)
+	}
+
+	bs, err = client.Blocks(ctx, &stream.BlockRequest{Channel: "test", Height: 0})
+	assert.NoError(t, err)
+	_, err = bs.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	bs, err = client.Blocks(ctx, &stream.BlockRequest{Channel: "unknown", Height: 1})
+	assert.NoError(t, err)
+	_, err = bs.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCServer_Events(t *testing.T) {
+	receipts := blockReceipts{
+		"empty": testReceiptList{},
+		"2": testReceiptList{
+			newTestReceipt([]*testEventLog{
+				newTestEventLog("cx01", "EventLog1()", nil, nil),
+			}),
+			newTestReceipt([]*testEventLog{
+				newTestEventLog("cx02", "EventLog1()", nil, nil),
+				newTestEventLog("cx04", "EventLog2()", nil, nil),
+				newTestEventLog("cx03", "EventLog1()", nil, nil),
+			}),
+		},
+	}
+	chain := newTestChain(0, func(h int64) (getBlockFunc, error) {
+		return func() module.Block {
+			if h%2 == 0 {
+				return &testBlock{height: h, result: "2", lb: receipts["2"].LogsBloom()}
+			}
+			return &testBlock{height: h, result: "empty"}
+		}, nil
+	}, receipts)
+	client := newTestStreamClient(t, chain)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	es, err := client.Events(ctx, &stream.EventRequest{
+		Channel: "test",
+		Height:  1,
+		Filters: []*stream.EventFilter{
+			{Addrs: []string{"cx02", "cx03"}, Event: "EventLog1()"},
+			{Event: "EventLog2*"},
+		},
+		Logs:             true,
+		ProgressInterval: 10,
+	})
+	assert.NoError(t, err)
+
+	msg, err := es.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), msg.GetProgress())
+
+	msg, err = es.Recv()
+	assert.NoError(t, err)
+	en := msg.GetEvent()
+	if assert.NotNil(t, en) {
+		assert.Equal(t, int64(2), en.Height)
+		assert.Equal(t, testHeightToBlockID(2), en.Hash)
+		assert.Equal(t, int32(1), en.Index)
+		assert.Equal(t, []int32{0, 1, 2}, en.Events)
+		assert.Len(t, en.Logs, 3)
+	}
+	msg, err = es.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), msg.GetProgress())
+
+	es, err = client.Events(ctx, &stream.EventRequest{
+		Channel: "test",
+		Height:  1,
+		Filters: []*stream.EventFilter{{Event: "EventLog1("}},
+	})
+	assert.NoError(t, err)
+	_, err = es.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCServer_MaxStreams(t *testing.T) {
+	chain := newTestChain(1, func(h int64) (getBlockFunc, error) {
+		return func() module.Block {
+			return &testStreamBlock{testBlock{height: h, result: "empty"}}
+		}, nil
+	}, blockReceipts{"empty": testReceiptList{}})
+	client := newTestStreamClientWithLimit(t, chain, 1)
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	defer cancel1()
+	bs, err := client.Blocks(ctx1, &stream.BlockRequest{Channel: "test", Height: 1})
+	assert.NoError(t, err)
+	_, err = bs.Recv()
+	assert.NoError(t, err)
+
+	// other streams wait until the active one ends
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel2()
+	_, err = client.Blocks(ctx2, &stream.BlockRequest{Channel: "test", Height: 1})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+	cancel1()
+	ctx3, cancel3 := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel3()
+	bs, err = client.Blocks(ctx3, &stream.BlockRequest{Channel: "test", Height: 1})
+	assert.NoError(t, err)
+	_, err = bs.Recv()
+	assert.NoError(t, err)
+}
@@ -142,6 +142,19 @@ func (ctx *Context) BatchLimit() int {
 	return batchLimit
 }
 
+// Limiter limits requests of clients.
+type Limiter interface {
+	// Acquire reserves the resources to handle the method for the client of
+	// the context. It returns an error if the client exceeds its limits.
+	// Otherwise, release should be called after handling the request.
+	Acquire(ctx *Context, method string) (release func(), err error)
+}
+
+func (ctx *Context) Limiter() Limiter {
+	limiter, _ := ctx.Get("limiter").(Limiter)
+	return limiter
+}
+
 func (ctx *Context) GetTimeout(t time.Duration) time.Duration {
 	if v, err := ctx.opts.GetInt(IconOptionsTimeout); err != nil {
 		return t
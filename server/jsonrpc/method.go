@@ -108,6 +108,18 @@ func (mr *MethodRepository) handle(ctx *Context, raw json.RawMessage) *Response
 		return nil
 	}
 
+	if limiter := ctx.Limiter(); limiter != nil {
+		release, err := limiter.Acquire(ctx, *req.Method)
+		if err != nil {
+			resp.Error = ErrorLackOfResource.Wrap(err, debug)
+			if req.ID == nil {
+				return nil
+			}
+			return resp
+		}
+		defer release()
+	}
+
 	p := &Params{
 		rawMessage: req.Params,
 		validator:  mr.v,
@@ -190,6 +202,9 @@ func (mr *MethodRepository) Handle(c echo.Context) error {
 		resp := mr.handle(ctx, raw)
 		if resp != nil {
 			if resp.Error != nil {
+				if resp.Error.Code == ErrorLackOfResource {
+					return c.JSON(http.StatusTooManyRequests, resp)
+				}
 				return c.JSON(http.StatusBadRequest, resp)
 			} else {
 				return c.JSON(http.StatusOK, resp)
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/server/metric"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -227,3 +228,43 @@ func noArgs(ctx *Context, params *Params) (interface{}, error) {
 	}
 	return "noArgs", nil
 }
+
+type testLimiter struct {
+	limited map[string]bool
+	active  int
+}
+
+func (l *testLimiter) Acquire(ctx *Context, method string) (func(), error) {
+	if l.limited[method] {
+		return nil, errors.New("limited")
+	}
+	l.active++
+	return func() { l.active-- }, nil
+}
+
+func TestMethodRepository_Limiter(t *testing.T) {
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := NewMethodRepository(mtr)
+	mr.RegisterMethod("noArgs", noArgs)
+	mr.RegisterMethod("limited", noArgs)
+	limiter := &testLimiter{limited: map[string]bool{"limited": true}}
+
+	invoke := func(req, resp string, status int) {
+		c, rec, err := prepare(req)
+		assert.NoError(t, err)
+		c.Set("limiter", limiter)
+		assert.NoError(t, mr.Handle(c))
+		assert.Equal(t, status, rec.Code)
+		assert.Equal(t, resp+"\n", rec.Body.String())
+	}
+	invoke(`{"jsonrpc":"2.0","method":"noArgs","id":"1001"}`,
+		`{"jsonrpc":"2.0","result":"noArgs","id":"1001"}`, http.StatusOK)
+	assert.Equal(t, 0, limiter.active)
+	invoke(`{"jsonrpc":"2.0","method":"limited","id":"1001"}`,
+		`{"jsonrpc":"2.0","error":{"code":-31005,"message":"LackOfResource: limited"},"id":"1001"}`,
+		http.StatusTooManyRequests)
+	invoke(`[{"jsonrpc":"2.0","method":"noArgs","id":"1"},{"jsonrpc":"2.0","method":"limited","id":"2"}]`,
+		`[{"jsonrpc":"2.0","result":"noArgs","id":"1"},{"jsonrpc":"2.0","error":{"code":-31005,"message":"LackOfResource: limited"},"id":"2"}]`,
+		http.StatusOK)
+	assert.Equal(t, 0, limiter.active)
+}
@@ -12,6 +12,8 @@ import (
 
 var (
 	mkMethod  = NewMetricKey("method")
+	mkReason  = NewMetricKey("reason")
+	msReject  = stats.Int64("jsonrpc_reject", "jsonrpc rejected requests by rate limits", stats.UnitDimensionless)
 	msFailure = &measure{
 		ms:    stats.Int64("jsonrpc_failure", "jsonrpc failures", "ns"),
 		msAvg: stats.Int64("jsonrpc_failure_avg", "moving average of jsonrpc failures", "ns"),
@@ -65,6 +67,11 @@ var (
 		"btp_getHeader":              msRetrieve,
 		"btp_getProof":               msRetrieve,
 		"btp_getSourceInformation":   msRetrieve,
+		"icx_getFeeSharingUsage": {
+			stats.Int64("jsonrpc_get_fee_sharing_usage", "jsonrpc icx_getFeeSharingUsage method", "ns"),
+			stats.Int64("jsonrpc_get_fee_sharing_usage_avg", "moving average of jsonrpc icx_getFeeSharingUsage method", "ns"),
+			emptyMks,
+		},
 		"debug_getTrace": {
 			stats.Int64("jsonrpc_get_trace", "jsonrpc debug_getTrace method", "ns"),
 			stats.Int64("jsonrpc_get_trace_avg", "moving average of jsonrpc debug_getTrace method", "ns"),
@@ -75,6 +82,16 @@ var (
 			stats.Int64("jsonrpc_estimate_step_avg", "moving average of jsonrpc debug_estimateStep method", "ns"),
 			emptyMks,
 		},
+		"debug_getStateDiff": {
+			stats.Int64("jsonrpc_get_state_diff", "jsonrpc debug_getStateDiff method", "ns"),
+			stats.Int64("jsonrpc_get_state_diff_avg", "moving average of jsonrpc debug_getStateDiff method", "ns"),
+			emptyMks,
+		},
+		"debug_getScoreStorage": {
+			stats.Int64("jsonrpc_get_score_storage", "jsonrpc debug_getScoreStorage method", "ns"),
+			stats.Int64("jsonrpc_get_score_storage_avg", "moving average of jsonrpc debug_getScoreStorage method", "ns"),
+			emptyMks,
+		},
 		"rosetta_getTrace": {
 			stats.Int64("jsonrpc_rosetta_trace_", "jsonrpc rosetta_getTrace method", "ns"),
 			stats.Int64("jsonrpc_rosetta_trace_avg", "moving average of jsonrpc rosetta_getTTrace method", "ns"),
@@ -101,6 +118,7 @@ func RegisterJsonrpc() {
 	RegisterMetricView(msFailure.msAvg, view.LastValue(), emptyMks)
 	RegisterMetricView(msRetrieve.ms, view.Count(), msRetrieve.mks)
 	RegisterMetricView(msRetrieve.msAvg, view.LastValue(), emptyMks)
+	RegisterMetricView(msReject, view.Count(), []tag.Key{mkMethod, mkReason})
 	for _, v := range msMap {
 		if v != msRetrieve {
 			RegisterMetricView(v.ms, view.Count(), v.mks)
@@ -198,6 +216,13 @@ func (m *JsonrpcMetric) OnHandle(ctx context.Context, method string, ts time.Tim
 	jm.RemoveAndRecord(ctx, ts, m.expire)
 }
 
+// OnReject records the request rejected by rate limits for the reason.
+func (m *JsonrpcMetric) OnReject(ctx context.Context, method string, reason string) {
+	ctx = GetMetricContext(ctx, &mkMethod, method)
+	ctx = GetMetricContext(ctx, &mkReason, reason)
+	stats.Record(ctx, msReject.M(1))
+}
+
 func NewJsonrpcMetric(expire time.Duration, durationsSize int, useDefault bool) *JsonrpcMetric {
 	jmsMtx.Lock()
 	defer jmsMtx.Unlock()
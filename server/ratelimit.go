@@ -0,0 +1,258 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/metric"
+)
+
+const (
+	HeaderKeyAPIKey = "X-Api-Key"
+
+	rateLimitPruneInterval = time.Minute
+)
+
+const (
+	rejectReasonKey         = "key"
+	rejectReasonRate        = "rate"
+	rejectReasonConcurrency = "concurrency"
+)
+
+// ClientLimit is the limit for a client.
+// Rate is the cost which can be spent for a second, and Burst is the maximum
+// cost which can be spent at once (Rate if it's zero). Concurrency is the
+// maximum number of requests handled at the same time.
+// Zero value means no limit.
+type ClientLimit struct {
+	Rate        int `json:"rate,omitempty"`
+	Burst       int `json:"burst,omitempty"`
+	Concurrency int `json:"concurrency,omitempty"`
+}
+
+func (l *ClientLimit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Rate)
+}
+
+func (l *ClientLimit) Verify() error {
+	if l.Rate < 0 || l.Burst < 0 || l.Concurrency < 0 {
+		return errors.IllegalArgumentError.Errorf("NegativeLimit(%+v)", *l)
+	}
+	return nil
+}
+
+// RateLimitConfig is the configuration of rate limits of JSON-RPC requests.
+// Clients are identified by API keys in X-Api-Key header, or by their IP
+// addresses without keys. The default limit is applied to each IP address.
+// If APIKeys is not empty, requests with unknown keys are rejected, and
+// requests without keys are limited by their IP addresses. Keys are ignored
+// if APIKeys is empty.
+// Costs has costs of methods, and the cost of other methods is one.
+type RateLimitConfig struct {
+	ClientLimit
+	Costs          map[string]int          `json:"costs,omitempty"`
+	APIKeys        map[string]*ClientLimit `json:"apiKeys,omitempty"`
+	TrustForwarded bool                    `json:"trustForwarded,omitempty"`
+}
+
+func (c *RateLimitConfig) Verify() error {
+	if err := c.ClientLimit.Verify(); err != nil {
+		return err
+	}
+	for method, cost := range c.Costs {
+		if cost < 0 {
+			return errors.IllegalArgumentError.Errorf("NegativeCost(method=%s,cost=%d)", method, cost)
+		}
+	}
+	for key, limit := range c.APIKeys {
+		if limit == nil {
+			return errors.IllegalArgumentError.Errorf("NoLimitForKey(key=%s)", key)
+		}
+		if err := limit.Verify(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *RateLimitConfig) costOf(method string) int {
+	if cost, ok := c.Costs[method]; ok {
+		return cost
+	}
+	return 1
+}
+
+func ParseRateLimitConfig(s string) (*RateLimitConfig, error) {
+	if s == "" || s == "null" {
+		return nil, nil
+	}
+	c := new(RateLimitConfig)
+	if err := json.Unmarshal([]byte(s), c); err != nil {
+		return nil, errors.IllegalArgumentError.Wrapf(err, "InvalidRateLimitConfig(%s)", s)
+	}
+	if err := c.Verify(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type clientState struct {
+	tokens float64
+	last   time.Time
+	active int
+}
+
+type rateLimiter struct {
+	mtx       sync.Mutex
+	config    *RateLimitConfig
+	clients   map[string]*clientState
+	lastPrune time.Time
+	mtr       *metric.JsonrpcMetric
+	now       func() time.Time
+}
+
+func newRateLimiter(config *RateLimitConfig, mtr *metric.JsonrpcMetric) *rateLimiter {
+	return &rateLimiter{
+		config:  config,
+		clients: make(map[string]*clientState),
+		mtr:     mtr,
+		now:     time.Now,
+	}
+}
+
+func (l *rateLimiter) SetConfig(config *RateLimitConfig) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.config = config
+	l.clients = make(map[string]*clientState)
+}
+
+func (l *rateLimiter) Config() *RateLimitConfig {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	return l.config
+}
+
+func clientIPOf(ctx *jsonrpc.Context, trustForwarded bool) string {
+	if trustForwarded {
+		return ctx.RealIP()
+	}
+	addr := ctx.Request().RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+func (l *rateLimiter) reject(ctx *jsonrpc.Context, method, reason string) {
+	if l.mtr != nil {
+		l.mtr.OnReject(ctx.MetricContext(), method, reason)
+	}
+}
+
+// pruneInLock removes clients which have been idle for the prune interval.
+func (l *rateLimiter) pruneInLock(now time.Time) {
+	if now.Sub(l.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+	l.lastPrune = now
+	for id, cs := range l.clients {
+		if cs.active == 0 && now.Sub(cs.last) >= rateLimitPruneInterval {
+			delete(l.clients, id)
+		}
+	}
+}
+
+func (l *rateLimiter) Acquire(ctx *jsonrpc.Context, method string) (func(), error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	config := l.config
+	if config == nil {
+		return func() {}, nil
+	}
+	var id string
+	limit := &config.ClientLimit
+	if key := ctx.Request().Header.Get(HeaderKeyAPIKey); key != "" && len(config.APIKeys) > 0 {
+		kl, ok := config.APIKeys[key]
+		if !ok {
+			l.reject(ctx, method, rejectReasonKey)
+			return nil, errors.New("unknown api key")
+		}
+		id, limit = "key:"+key, kl
+	} else {
+		id = "ip:" + clientIPOf(ctx, config.TrustForwarded)
+	}
+
+	now := l.now()
+	l.pruneInLock(now)
+	cs, ok := l.clients[id]
+	if !ok {
+		cs = &clientState{tokens: limit.burst(), last: now}
+		l.clients[id] = cs
+	}
+	if limit.Concurrency > 0 && cs.active >= limit.Concurrency {
+		l.reject(ctx, method, rejectReasonConcurrency)
+		return nil, errors.Errorf("too many concurrent requests (limit=%d)", limit.Concurrency)
+	}
+	if limit.Rate > 0 {
+		burst := limit.burst()
+		cs.tokens += now.Sub(cs.last).Seconds() * float64(limit.Rate)
+		if cs.tokens > burst {
+			cs.tokens = burst
+		}
+		cost := float64(config.costOf(method))
+		if cs.tokens < cost {
+			cs.last = now
+			l.reject(ctx, method, rejectReasonRate)
+			return nil, errors.Errorf("rate limit exceeded (rate=%d)", limit.Rate)
+		}
+		cs.tokens -= cost
+	}
+	cs.last = now
+	cs.active += 1
+
+	released := false
+	return func() {
+		l.mtx.Lock()
+		defer l.mtx.Unlock()
+		if !released {
+			released = true
+			cs.active -= 1
+		}
+	}, nil
+}
+
+// WSRateLimit applies the limits to websocket sessions. The session is
+// treated as a request of the method, which is active until it ends.
+func WSRateLimit(srv *Manager, method string) echo.MiddlewareFunc {
+	return wsRateLimit(srv.limiter, method)
+}
+
+func wsRateLimit(l *rateLimiter, method string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if l == nil {
+				return next(ctx)
+			}
+			release, err := l.Acquire(jsonrpc.NewContext(ctx), method)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+			}
+			defer release()
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+func newTestRateLimitContext(remote, key string) *jsonrpc.Context {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.RemoteAddr = remote
+	if key != "" {
+		req.Header.Set(HeaderKeyAPIKey, key)
+	}
+	return jsonrpc.NewContext(echo.New().NewContext(req, httptest.NewRecorder()))
+}
+
+func TestRateLimiter_Rate(t *testing.T) {
+	config, err := ParseRateLimitConfig(`{"rate":2,"burst":4,"costs":{"icx_call":3,"icx_getLastBlock":0}}`)
+	assert.NoError(t, err)
+	l := newRateLimiter(config, nil)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+
+	c1 := newTestRateLimitContext("10.0.0.1:1000", "")
+	c2 := newTestRateLimitContext("10.0.0.2:1000", "")
+
+	release, err := l.Acquire(c1, "icx_call")
+	assert.NoError(t, err)
+	release()
+	_, err = l.Acquire(c1, "icx_call")
+	assert.Error(t, err)
+	_, err = l.Acquire(c1, "icx_getBalance")
+	assert.NoError(t, err)
+	_, err = l.Acquire(c1, "icx_getBalance")
+	assert.Error(t, err)
+	_, err = l.Acquire(c1, "icx_getLastBlock")
+	assert.NoError(t, err)
+
+	// other clients have their own limits
+	_, err = l.Acquire(c2, "icx_call")
+	assert.NoError(t, err)
+
+	now = now.Add(time.Second)
+	_, err = l.Acquire(c1, "icx_call")
+	assert.Error(t, err)
+	_, err = l.Acquire(c1, "icx_getBalance")
+	assert.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	_, err = l.Acquire(c1, "icx_call")
+	assert.NoError(t, err)
+
+	l.SetConfig(nil)
+	for i := 0; i < 10; i++ {
+		_, err = l.Acquire(c1, "icx_call")
+		assert.NoError(t, err)
+	}
+}
+
+func TestRateLimiter_Concurrency(t *testing.T) {
+	config, err := ParseRateLimitConfig(`{"concurrency":2}`)
+	assert.NoError(t, err)
+	l := newRateLimiter(config, nil)
+	c := newTestRateLimitContext("10.0.0.1:1000", "")
+
+	r1, err := l.Acquire(c, "icx_call")
+	assert.NoError(t, err)
+	r2, err := l.Acquire(c, "icx_call")
+	assert.NoError(t, err)
+	_, err = l.Acquire(c, "icx_call")
+	assert.Error(t, err)
+
+	r1()
+	r1()
+	_, err = l.Acquire(c, "icx_call")
+	assert.NoError(t, err)
+	_, err = l.Acquire(c, "icx_call")
+	assert.Error(t, err)
+	r2()
+}
+
+func TestRateLimiter_APIKeys(t *testing.T) {
+	config, err := ParseRateLimitConfig(`{"rate":1,"apiKeys":{"key1":{"rate":10},"key2":{}}}`)
+	assert.NoError(t, err)
+	l := newRateLimiter(config, nil)
+
+	// anonymous clients are limited by IP addresses
+	_, err = l.Acquire(newTestRateLimitContext("10.0.0.1:1000", ""), "icx_call")
+	assert.NoError(t, err)
+	_, err = l.Acquire(newTestRateLimitContext("10.0.0.1:1000", ""), "icx_call")
+	assert.Error(t, err)
+	_, err = l.Acquire(newTestRateLimitContext("10.0.0.2:1000", ""), "icx_call")
+	assert.NoError(t, err)
+	_, err = l.Acquire(newTestRateLimitContext("10.0.0.3:1000", "unknown"), "icx_call")
+	assert.Error(t, err)
+
+	for i := 0; i < 10; i++ {
+		_, err = l.Acquire(newTestRateLimitContext("10.0.0.1:1000", "key1"), "icx_call")
+		assert.NoError(t, err)
+	}
+	_, err = l.Acquire(newTestRateLimitContext("10.0.0.2:1000", "key1"), "icx_call")
+	assert.Error(t, err)
+
+	for i := 0; i < 100; i++ {
+		_, err = l.Acquire(newTestRateLimitContext("10.0.0.1:1000", "key2"), "icx_call")
+		assert.NoError(t, err)
+	}
+}
+
+func TestRateLimiter_NoAPIKeys(t *testing.T) {
+	config, err := ParseRateLimitConfig(`{"rate":1}`)
+	assert.NoError(t, err)
+	l := newRateLimiter(config, nil)
+
+	// keys are ignored, and clients are limited by IP addresses
+	_, err = l.Acquire(newTestRateLimitContext("10.0.0.1:1000", "key1"), "icx_call")
+	assert.NoError(t, err)
+	_, err = l.Acquire(newTestRateLimitContext("10.0.0.1:1000", "key2"), "icx_call")
+	assert.Error(t, err)
+	_, err = l.Acquire(newTestRateLimitContext("10.0.0.2:1000", "key1"), "icx_call")
+	assert.NoError(t, err)
+}
+
+func TestWSRateLimit(t *testing.T) {
+	config, err := ParseRateLimitConfig(`{"concurrency":1}`)
+	assert.NoError(t, err)
+	l := newRateLimiter(config, nil)
+	e := echo.New()
+
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1000"
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+	session := func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	}
+
+	// requests are refused while the session is active
+	var inner error
+	h := wsRateLimit(l, "ws_block")(func(ctx echo.Context) error {
+		c, _ := newContext()
+		inner = wsRateLimit(l, "ws_event")(session)(c)
+		return session(ctx)
+	})
+	c, _ := newContext()
+	assert.NoError(t, h(c))
+	if he, ok := inner.(*echo.HTTPError); assert.True(t, ok) {
+		assert.Equal(t, http.StatusTooManyRequests, he.Code)
+	}
+
+	// the session is released on its end
+	c, rec := newContext()
+	assert.NoError(t, wsRateLimit(l, "ws_event")(session)(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestParseRateLimitConfig(t *testing.T) {
+	config, err := ParseRateLimitConfig("")
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	for _, s := range []string{
+		`{"rate":-1}`,
+		`{"costs":{"icx_call":-1}}`,
+		`{"apiKeys":{"key1":null}}`,
+		`{"apiKeys":{"key1":{"concurrency":-1}}}`,
+		`{"rate":"1"}`,
+	} {
+		_, err = ParseRateLimitConfig(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"google.golang.org/grpc"
 
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
@@ -20,21 +21,32 @@ const (
 	flagENABLE  int32 = 1
 	flagDISABLE int32 = 0
 	UrlAdmin          = "/admin"
+
+	DefaultJSONRPCCacheSize = v3.DefaultResponseCacheSize
 )
 
 type Config struct {
 	ServerAddress         string
+	GRPCAddress           string
+	GRPCMaxConnections    int
+	GRPCMaxStreams        int
 	JSONRPCDump           bool
 	JSONRPCIncludeDebug   bool
 	JSONRPCRosetta        bool
 	JSONRPCDefaultChannel string
 	JSONRPCBatchLimit     int
 	WSMaxSession          int
+	JSONRPCRateLimit      *RateLimitConfig
+	JSONRPCCacheSize      int
 }
 
 type Manager struct {
 	e                     *echo.Echo
 	addr                  string
+	grpcAddr              string
+	grpcMaxConns          int
+	grpcMaxStreams        int
+	grpc                  *grpc.Server
 	wallet                module.Wallet
 	chains                map[string]module.Chain // chain manager
 	wssm                  *wsSessionManager
@@ -47,6 +59,8 @@ type Manager struct {
 	logger                log.Logger
 	metricsHandler        echo.HandlerFunc
 	mtr                   *metric.JsonrpcMetric
+	limiter               *rateLimiter
+	cache                 *v3.ResponseCache
 }
 
 func NewManager(
@@ -66,6 +80,9 @@ func NewManager(
 	m := &Manager{
 		e:                     e,
 		addr:                  config.ServerAddress,
+		grpcAddr:              config.GRPCAddress,
+		grpcMaxConns:          config.GRPCMaxConnections,
+		grpcMaxStreams:        config.GRPCMaxStreams,
 		wallet:                wallet,
 		chains:                make(map[string]module.Chain),
 		wssm:                  newWSSessionManager(logger, config.WSMaxSession),
@@ -75,6 +92,8 @@ func NewManager(
 		logger:                logger,
 		metricsHandler:        echo.WrapHandler(metric.PrometheusExporter()),
 		mtr:                   mtr,
+		limiter:               newRateLimiter(config.JSONRPCRateLimit, mtr),
+		cache:                 v3.NewResponseCache(config.JSONRPCCacheSize, v3.DefaultLatestCacheTTL),
 	}
 	m.SetMessageDump(config.JSONRPCDump)
 	m.SetIncludeDebug(config.JSONRPCIncludeDebug)
@@ -175,6 +194,24 @@ func (srv *Manager) BatchLimit() int {
 	return int(atomic.LoadInt32(&srv.jsonrpcBatchLimit))
 }
 
+func (srv *Manager) SetRateLimit(config *RateLimitConfig) error {
+	if config != nil {
+		if err := config.Verify(); err != nil {
+			return err
+		}
+	}
+	srv.limiter.SetConfig(config)
+	return nil
+}
+
+func (srv *Manager) RateLimit() *RateLimitConfig {
+	return srv.limiter.Config()
+}
+
+func (srv *Manager) SetCacheSize(size int) {
+	srv.cache.SetLimit(size)
+}
+
 func (srv *Manager) SetWSMaxSession(limit int) {
 	srv.wssm.SetMaxSession(limit)
 }
@@ -192,6 +229,13 @@ func (srv *Manager) Start() error {
 	// metric
 	srv.RegisterMetricsHandler(srv.e.Group("/metrics"))
 
+	// gRPC streams
+	if srv.grpcAddr != "" {
+		if err := srv.startGRPC(); err != nil {
+			return err
+		}
+	}
+
 	return srv.e.Start(srv.addr)
 }
 
@@ -211,12 +255,15 @@ func (srv *Manager) RegisterAPIHandler(g *echo.Group) {
 			ctx.Set("includeDebug", srv.IncludeDebug())
 			ctx.Set("batchLimit", srv.BatchLimit())
 			ctx.Set("rosetta", srv.Rosetta())
+			if srv.limiter != nil {
+				ctx.Set("limiter", srv.limiter)
+			}
 			return next(ctx)
 		}
 	})
 
 	// v3 APIs
-	mr := v3.MethodRepository(srv.mtr)
+	mr := v3.MethodRepository(srv.mtr, srv.cache)
 	v3api := rpc.Group("/v3")
 	v3api.Use(JsonRpc(), Chunk())
 	v3api.POST("", mr.Handle, ChainInjector(srv))
@@ -240,9 +287,15 @@ func (srv *Manager) RegisterAPIHandler(g *echo.Group) {
 
 	// group for websocket
 	ws := g.Group("")
-	ws.GET("/v3/:channel/block", srv.wssm.RunBlockSession, ChainInjector(srv))
-	ws.GET("/v3/:channel/event", srv.wssm.RunEventSession, ChainInjector(srv))
-	ws.GET("/v3/:channel/btp", srv.wssm.RunBtpSession, ChainInjector(srv))
+	ws.GET("/v3/:channel/block", srv.wssm.RunBlockSession, ChainInjector(srv), WSRateLimit(srv, "ws_block"), func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			ctx.Set("includeDebug", srv.IncludeDebug())
+			ctx.Set("rosetta", srv.Rosetta())
+			return next(ctx)
+		}
+	})
+	ws.GET("/v3/:channel/event", srv.wssm.RunEventSession, ChainInjector(srv), WSRateLimit(srv, "ws_event"))
+	ws.GET("/v3/:channel/btp", srv.wssm.RunBtpSession, ChainInjector(srv), WSRateLimit(srv, "ws_btp"))
 }
 
 func (srv *Manager) RegisterMetricsHandler(g *echo.Group) {
@@ -282,6 +335,9 @@ func (srv *Manager) Stop() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
 	srv.wssm.StopAllSessions()
+	if srv.grpc != nil {
+		srv.grpc.Stop()
+	}
 	return srv.e.Shutdown(ctx)
 }
 
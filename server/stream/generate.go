@@ -0,0 +1,5 @@
+// Package stream has protobuf messages and gRPC service definitions for
+// streaming blocks, events and BTP messages.
+package stream
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative stream.proto
@@ -0,0 +1,258 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.9
+// source: stream.proto
+
+package stream
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// StreamClient is the client API for Stream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StreamClient interface {
+	Blocks(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (Stream_BlocksClient, error)
+	Events(ctx context.Context, in *EventRequest, opts ...grpc.CallOption) (Stream_EventsClient, error)
+	BTPMessages(ctx context.Context, in *BTPRequest, opts ...grpc.CallOption) (Stream_BTPMessagesClient, error)
+}
+
+type streamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStreamClient(cc grpc.ClientConnInterface) StreamClient {
+	return &streamClient{cc}
+}
+
+func (c *streamClient) Blocks(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (Stream_BlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Stream_ServiceDesc.Streams[0], "/goloop.stream.Stream/Blocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &streamBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Stream_BlocksClient interface {
+	Recv() (*Block, error)
+	grpc.ClientStream
+}
+
+type streamBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *streamBlocksClient) Recv() (*Block, error) {
+	m := new(Block)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *streamClient) Events(ctx context.Context, in *EventRequest, opts ...grpc.CallOption) (Stream_EventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Stream_ServiceDesc.Streams[1], "/goloop.stream.Stream/Events", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &streamEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Stream_EventsClient interface {
+	Recv() (*EventMessage, error)
+	grpc.ClientStream
+}
+
+type streamEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *streamEventsClient) Recv() (*EventMessage, error) {
+	m := new(EventMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *streamClient) BTPMessages(ctx context.Context, in *BTPRequest, opts ...grpc.CallOption) (Stream_BTPMessagesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Stream_ServiceDesc.Streams[2], "/goloop.stream.Stream/BTPMessages", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &streamBTPMessagesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Stream_BTPMessagesClient interface {
+	Recv() (*BTPMessage, error)
+	grpc.ClientStream
+}
+
+type streamBTPMessagesClient struct {
+	grpc.ClientStream
+}
+
+func (x *streamBTPMessagesClient) Recv() (*BTPMessage, error) {
+	m := new(BTPMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// StreamServer is the server API for Stream service.
+// All implementations must embed UnimplementedStreamServer
+// for forward compatibility
+type StreamServer interface {
+	Blocks(*BlockRequest, Stream_BlocksServer) error
+	Events(*EventRequest, Stream_EventsServer) error
+	BTPMessages(*BTPRequest, Stream_BTPMessagesServer) error
+	mustEmbedUnimplementedStreamServer()
+}
+
+// UnimplementedStreamServer must be embedded to have forward compatible implementations.
+type UnimplementedStreamServer struct {
+}
+
+func (UnimplementedStreamServer) Blocks(*BlockRequest, Stream_BlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method Blocks not implemented")
+}
+func (UnimplementedStreamServer) Events(*EventRequest, Stream_EventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method Events not implemented")
+}
+func (UnimplementedStreamServer) BTPMessages(*BTPRequest, Stream_BTPMessagesServer) error {
+	return status.Errorf(codes.Unimplemented, "method BTPMessages not implemented")
+}
+func (UnimplementedStreamServer) mustEmbedUnimplementedStreamServer() {}
+
+// UnsafeStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StreamServer will
+// result in compilation errors.
+type UnsafeStreamServer interface {
+	mustEmbedUnimplementedStreamServer()
+}
+
+func RegisterStreamServer(s grpc.ServiceRegistrar, srv StreamServer) {
+	s.RegisterService(&Stream_ServiceDesc, srv)
+}
+
+func _Stream_Blocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BlockRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StreamServer).Blocks(m, &streamBlocksServer{stream})
+}
+
+type Stream_BlocksServer interface {
+	Send(*Block) error
+	grpc.ServerStream
+}
+
+type streamBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *streamBlocksServer) Send(m *Block) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Stream_Events_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EventRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StreamServer).Events(m, &streamEventsServer{stream})
+}
+
+type Stream_EventsServer interface {
+	Send(*EventMessage) error
+	grpc.ServerStream
+}
+
+type streamEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *streamEventsServer) Send(m *EventMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Stream_BTPMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BTPRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StreamServer).BTPMessages(m, &streamBTPMessagesServer{stream})
+}
+
+type Stream_BTPMessagesServer interface {
+	Send(*BTPMessage) error
+	grpc.ServerStream
+}
+
+type streamBTPMessagesServer struct {
+	grpc.ServerStream
+}
+
+func (x *streamBTPMessagesServer) Send(m *BTPMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Stream_ServiceDesc is the grpc.ServiceDesc for Stream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Stream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "goloop.stream.Stream",
+	HandlerType: (*StreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Blocks",
+			Handler:       _Stream_Blocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Events",
+			Handler:       _Stream_Events_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BTPMessages",
+			Handler:       _Stream_BTPMessages_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "stream.proto",
+}
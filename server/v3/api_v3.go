@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 	"time"
@@ -29,35 +30,36 @@ const (
 	ConfigShowPatchTransaction = false
 )
 
-func MethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository {
+func MethodRepository(mtr *metric.JsonrpcMetric, cache *ResponseCache) *jsonrpc.MethodRepository {
 	mr := jsonrpc.NewMethodRepository(mtr)
 	RegisterValidationRule(mr.Validator())
 
-	mr.RegisterMethod("icx_getLastBlock", getLastBlock)
-	mr.RegisterMethod("icx_getBlockByHeight", getBlockByHeight)
-	mr.RegisterMethod("icx_getBlockByHash", getBlockByHash)
-	mr.RegisterMethod("icx_call", call)
-	mr.RegisterMethod("icx_getBalance", getBalance)
-	mr.RegisterMethod("icx_getScoreApi", getScoreApi)
-	mr.RegisterMethod("icx_getTotalSupply", getTotalSupply)
-	mr.RegisterMethod("icx_getTransactionResult", getTransactionResult)
+	mr.RegisterMethod("icx_getLastBlock", cache.Wrap("icx_getLastBlock", getLastBlock, latestState))
+	mr.RegisterMethod("icx_getBlockByHeight", cache.Wrap("icx_getBlockByHeight", getBlockByHeight, latestWithoutHeight))
+	mr.RegisterMethod("icx_getBlockByHash", cache.Wrap("icx_getBlockByHash", getBlockByHash, immutable))
+	mr.RegisterMethod("icx_call", cache.Wrap("icx_call", call, latestState))
+	mr.RegisterMethod("icx_getBalance", cache.Wrap("icx_getBalance", getBalance, latestState))
+	mr.RegisterMethod("icx_getScoreApi", cache.Wrap("icx_getScoreApi", getScoreApi, latestState))
+	mr.RegisterMethod("icx_getTotalSupply", cache.Wrap("icx_getTotalSupply", getTotalSupply, latestState))
+	mr.RegisterMethod("icx_getTransactionResult", cache.Wrap("icx_getTransactionResult", getTransactionResult, immutable))
 	mr.RegisterMethod("icx_getTransactionByHash", getTransactionByHash)
 	mr.RegisterMethod("icx_sendTransaction", sendTransaction)
 	mr.RegisterMethod("icx_sendTransactionAndWait", sendTransactionAndWait)
 	mr.RegisterMethod("icx_waitTransactionResult", waitTransactionResult)
 
-	mr.RegisterMethod("icx_getDataByHash", getDataByHash)
-	mr.RegisterMethod("icx_getBlockHeaderByHeight", getBlockHeaderByHeight)
+	mr.RegisterMethod("icx_getDataByHash", cache.Wrap("icx_getDataByHash", getDataByHash, immutable))
+	mr.RegisterMethod("icx_getBlockHeaderByHeight", cache.Wrap("icx_getBlockHeaderByHeight", getBlockHeaderByHeight, latestWithoutHeight))
 	mr.RegisterMethod("icx_getVotesByHeight", getVotesByHeight)
 	mr.RegisterMethod("icx_getProofForResult", getProofForResult)
 	mr.RegisterMethod("icx_getProofForEvents", getProofForEvents)
-	mr.RegisterMethod("icx_getScoreStatus", getScoreStatus)
+	mr.RegisterMethod("icx_getScoreStatus", cache.Wrap("icx_getScoreStatus", getScoreStatus, latestState))
 	mr.RegisterMethod("icx_getNetworkInfo", getNetworkInfo)
+	mr.RegisterMethod("icx_getFeeSharingUsage", cache.Wrap("icx_getFeeSharingUsage", getFeeSharingUsage, latestWithoutTo))
 
 	mr.RegisterMethod("btp_getNetworkInfo", getBTPNetworkInfo)
 	mr.RegisterMethod("btp_getNetworkTypeInfo", getBTPNetworkTypeInfo)
 	mr.RegisterMethod("btp_getMessages", getBTPMessages)
-	mr.RegisterMethod("btp_getHeader", getBTPHeader)
+	mr.RegisterMethod("btp_getHeader", cache.Wrap("btp_getHeader", getBTPHeader, immutable))
 	mr.RegisterMethod("btp_getProof", getBTPProof)
 	mr.RegisterMethod("btp_getSourceInformation", getBTPSourceInformation)
 
@@ -87,6 +89,59 @@ func fillTransactions(blockJson interface{}, b module.Block, v module.JSONVersio
 	return nil
 }
 
+// BlockToJSON returns JSON of the block with its transactions as
+// icx_getBlockByHeight does.
+func BlockToJSON(blk module.Block, v module.JSONVersion) (interface{}, error) {
+	blockJson, err := blk.ToJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	if err = fillTransactions(blockJson, blk, v); err != nil {
+		return nil, err
+	}
+	return blockJson, nil
+}
+
+func receiptToJSON(r module.Receipt, blk module.Block, index int, txHash []byte, v module.JSONVersion) (interface{}, error) {
+	res, err := r.ToJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	result := res.(map[string]interface{})
+	result["blockHash"] = "0x" + hex.EncodeToString(blk.ID())
+	result["blockHeight"] = "0x" + strconv.FormatInt(blk.Height(), 16)
+	result["txIndex"] = "0x" + strconv.FormatInt(int64(index), 16)
+	result["txHash"] = "0x" + hex.EncodeToString(txHash)
+	return result, nil
+}
+
+// ReceiptsToJSON returns JSON of results of normal transactions in the block
+// as icx_getTransactionResult does. Results are in the result of the next
+// block.
+func ReceiptsToJSON(sm module.ServiceManager, blk, next module.Block, v module.JSONVersion) ([]interface{}, error) {
+	rl, err := sm.ReceiptListFromResult(next.Result(), module.TransactionGroupNormal)
+	if err != nil {
+		return nil, err
+	}
+	receipts := []interface{}{}
+	for idx, it := 0, blk.NormalTransactions().Iterator(); it.Has(); _, idx = it.Next(), idx+1 {
+		tx, _, err := it.Get()
+		if err != nil {
+			return nil, err
+		}
+		r, err := rl.Get(idx)
+		if err != nil {
+			return nil, err
+		}
+		result, err := receiptToJSON(r, blk, idx, tx.ID(), v)
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, result)
+	}
+	return receipts, nil
+}
+
 type contextWithChain struct {
 	*jsonrpc.Context
 	debug bool
@@ -256,14 +311,10 @@ func getBlockByHeight(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}
 		return nil, err
 	}
 
-	blockJson, err := blk.ToJSON(module.JSONVersion3)
+	blockJson, err := BlockToJSON(blk, module.JSONVersion3)
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
 	}
-
-	if err = fillTransactions(blockJson, blk, module.JSONVersion3); err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
-	}
 	return blockJson, nil
 }
 
@@ -439,17 +490,10 @@ func getTransactionResult(ctx *jsonrpc.Context, params *jsonrpc.Params) (interfa
 	} else if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
 	}
-	res, err := receipt.ToJSON(module.JSONVersion3)
+	result, err := receiptToJSON(receipt, blk, txInfo.Index(), param.Hash.Bytes(), module.JSONVersion3)
 	if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
 	}
-
-	result := res.(map[string]interface{})
-	result["blockHash"] = "0x" + hex.EncodeToString(blk.ID())
-	result["blockHeight"] = "0x" + strconv.FormatInt(blk.Height(), 16)
-	result["txIndex"] = "0x" + strconv.FormatInt(int64(txInfo.Index()), 16)
-	result["txHash"] = "0x" + hex.EncodeToString(param.Hash.Bytes())
-
 	return result, nil
 }
 
@@ -729,6 +773,122 @@ func getScoreStatus(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{},
 	return jso, nil
 }
 
+func getFeeSharingUsage(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithSM
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+	var param FeeSharingUsageParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+
+	last, err := c.bm.GetLastBlock()
+	if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	// results of transactions in a block are in the next block
+	end := last.Height() - 1
+	if param.To != "" {
+		if end, err = param.To.Int64(); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+		if end >= last.Height() {
+			return nil, jsonrpc.ErrorCodeNotFound.Errorf(
+				"NoResultYet(to=%d,last=%d)", end, last.Height())
+		}
+	}
+	var start int64
+	if param.From != "" {
+		if start, err = param.From.Int64(); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+	} else {
+		start = end - defaultFeeUsageRange + 1
+		if base := c.chain.GenesisStorage().Height(); start < base {
+			start = base
+		}
+	}
+	if start > end || end-start >= maxFeeUsageRange {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf(
+			"InvalidRange(from=%d,to=%d,max=%d)", start, end, maxFeeUsageRange)
+	}
+	if err = c.CheckBaseHeight(start); err != nil {
+		return nil, err
+	}
+	limit := int64(defaultFeeUsageLimit)
+	if param.Limit != "" {
+		if limit, err = param.Limit.Int64(); err != nil || limit < 0 || limit > maxFeeUsageCallers {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Errorf("InvalidLimit(%s)", param.Limit)
+		}
+	}
+
+	addr := param.Address.Address()
+	after, err := c.bm.GetBlockByHeight(end + 1)
+	if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	status, err := c.sm.GetSCOREStatus(after.Result(), addr)
+	if err != nil {
+		if errors.IllegalArgumentError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+		return nil, c.AsRPCError(err)
+	}
+	deposit, virtualSteps := status.AvailableDeposit(after.Height())
+	price, err := c.sm.GetStepPrice(after.Result())
+	if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+
+	usage := newFeeUsage(addr)
+	blk, err := c.bm.GetBlockByHeight(start)
+	if err != nil {
+		return nil, c.AsRPCError(err)
+	}
+	for height := start; height <= end; height++ {
+		next := after
+		if height < end {
+			if next, err = c.bm.GetBlockByHeight(height + 1); err != nil {
+				return nil, c.AsRPCError(err)
+			}
+		}
+		it := blk.NormalTransactions().Iterator()
+		if it.Has() {
+			rl, err := c.sm.ReceiptListFromResult(next.Result(), module.TransactionGroupNormal)
+			if err != nil {
+				return nil, c.AsRPCError(err)
+			}
+			// transactions of the block are executed on the result of it
+			vs := new(big.Int)
+			if bs, err := c.sm.GetSCOREStatus(blk.Result(), addr); err == nil {
+				_, vs = bs.AvailableDeposit(height)
+			} else if !errors.NotFoundError.Equals(err) {
+				return nil, c.AsRPCError(err)
+			}
+			usage.SetVirtualSteps(vs)
+			for idx := 0; it.Has(); _, idx = it.Next(), idx+1 {
+				tx, _, err := it.Get()
+				if err != nil {
+					return nil, c.AsRPCError(err)
+				}
+				r, err := rl.Get(idx)
+				if err != nil {
+					return nil, c.AsRPCError(err)
+				}
+				if err = usage.Add(height, tx.From(), r); err != nil {
+					return nil, c.AsRPCError(err)
+				}
+			}
+		}
+		blk = next
+	}
+
+	jso := usage.ToJSON(start, end, int(limit))
+	jso["projection"] = usage.Projection(start, end, deposit, virtualSteps, price)
+	return jso, nil
+}
+
 type NetworkInfo struct {
 	Platform  string         `json:"platform"`
 	NID       jsonrpc.HexInt `json:"nid"`
@@ -1149,6 +1309,8 @@ func DebugMethodRepository(mtr *metric.JsonrpcMetric) *jsonrpc.MethodRepository
 
 	mr.RegisterMethod("debug_getTrace", getTrace)
 	mr.RegisterMethod("debug_estimateStep", estimateStep)
+	mr.RegisterMethod("debug_getStateDiff", getStateDiff)
+	mr.RegisterMethod("debug_getScoreStorage", getScoreStorage)
 
 	return mr
 }
@@ -1235,6 +1397,242 @@ func getTrace(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error)
 	}
 }
 
+func getStateDiff(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithSM
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	var param StateDiffParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+
+	var limit int64
+	if param.Limit != "" {
+		var err error
+		if limit, err = param.Limit.Int64(); err != nil || limit < 0 {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Errorf("InvalidLimit(%s)", param.Limit)
+		}
+	}
+
+	req := &module.StateDiffRequest{
+		ByStateHash: param.FromState != "" || param.ToState != "",
+		Limit:       int(limit),
+	}
+	for _, addr := range param.Addresses {
+		req.Addresses = append(req.Addresses, addr.Address())
+	}
+	if req.ByStateHash {
+		if param.FromState == "" || param.ToState == "" || param.From != "" || param.To != "" {
+			return nil, jsonrpc.ErrorCodeInvalidParams.New("Both fromState and toState are required")
+		}
+		if (param.FromExtension == "") != (param.ToExtension == "") {
+			return nil, jsonrpc.ErrorCodeInvalidParams.New("Both fromExtension and toExtension are required")
+		}
+		req.From, req.To = param.FromState.Bytes(), param.ToState.Bytes()
+		if param.FromExtension != "" {
+			var ok1, ok2 bool
+			req.FromExtension, ok1 = extensionDataOf(param.FromExtension)
+			req.ToExtension, ok2 = extensionDataOf(param.ToExtension)
+			if !ok1 || !ok2 {
+				return nil, jsonrpc.ErrorCodeInvalidParams.New("InvalidExtension")
+			}
+		}
+	} else {
+		if param.From == "" {
+			return nil, jsonrpc.ErrorCodeInvalidParams.New("Either from or fromState is required")
+		}
+		if param.FromExtension != "" || param.ToExtension != "" {
+			return nil, jsonrpc.ErrorCodeInvalidParams.New("Extensions are only for fromState and toState")
+		}
+		blk1, err := c.GetBlockByHeight(param.From)
+		if err != nil {
+			return nil, err
+		}
+		blk2, err := c.GetBlockByHeight(param.To)
+		if err != nil {
+			return nil, err
+		}
+		req.From, req.To = blk1.Result(), blk2.Result()
+		addrs, err := c.addressesOfBlocks(blk1, blk2)
+		if err != nil {
+			return nil, err
+		}
+		req.Addresses = append(req.Addresses, addrs...)
+	}
+
+	res, err := c.sm.GetStateDiff(req)
+	if err != nil {
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+	}
+	return res, nil
+}
+
+// maxStateDiffResolveRange is the maximum number of blocks whose receipts
+// are examined to resolve addresses of changed accounts for
+// debug_getStateDiff.
+const maxStateDiffResolveRange = 100
+
+func extensionDataOf(hs jsonrpc.HexBytes) ([]byte, bool) {
+	if !strings.HasPrefix(string(hs), "0x") {
+		return nil, false
+	}
+	bs, err := hex.DecodeString(string(hs[2:]))
+	return bs, err == nil
+}
+
+// addressesOfBlocks returns senders, receivers and deployed contracts of
+// transactions changing the state from the result of blk1 to the result of
+// blk2. The result of a block is made by transactions of its previous block,
+// and receipts of them are in the result of the block. It returns nothing
+// for more than maxStateDiffResolveRange blocks.
+func (c *contextWithSM) addressesOfBlocks(blk1, blk2 module.Block) ([]module.Address, error) {
+	if blk2.Height() <= blk1.Height() || blk2.Height()-blk1.Height() > maxStateDiffResolveRange {
+		return nil, nil
+	}
+	var addrs []module.Address
+	blk := blk1
+	for height := blk1.Height(); height < blk2.Height(); height++ {
+		next := blk2
+		if height+1 < blk2.Height() {
+			var err error
+			if next, err = c.bm.GetBlockByHeight(height + 1); err != nil {
+				return nil, c.AsRPCError(err)
+			}
+		}
+		it := blk.NormalTransactions().Iterator()
+		if it.Has() {
+			rl, err := c.sm.ReceiptListFromResult(next.Result(), module.TransactionGroupNormal)
+			if err != nil {
+				return nil, c.AsRPCError(err)
+			}
+			for idx := 0; it.Has(); _, idx = it.Next(), idx+1 {
+				tx, _, err := it.Get()
+				if err != nil {
+					return nil, c.AsRPCError(err)
+				}
+				addrs = append(addrs, tx.From())
+				r, err := rl.Get(idx)
+				if err != nil {
+					return nil, c.AsRPCError(err)
+				}
+				addrs = append(addrs, r.To(), r.SCOREAddress())
+			}
+		}
+		blk = next
+	}
+	return addrs, nil
+}
+
+func storageKeyOf(keyType string, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, errors.IllegalArgumentError.Errorf("InvalidKey(key=%v)", v)
+	}
+	switch keyType {
+	case "", "str":
+		return s, nil
+	case "address":
+		return common.NewAddressFromString(s)
+	case "int":
+		value := new(big.Int)
+		if err := intconv.ParseBigInt(value, s); err != nil {
+			return nil, err
+		}
+		return value, nil
+	case "bytes":
+		if !strings.HasPrefix(s, "0x") {
+			return nil, errors.IllegalArgumentError.Errorf("InvalidBytes(key=%s)", s)
+		}
+		return hex.DecodeString(s[2:])
+	default:
+		return nil, errors.IllegalArgumentError.Errorf("InvalidKeyType(type=%s)", keyType)
+	}
+}
+
+func storageContainerOf(param *StorageContainerParam) (*module.StorageContainer, error) {
+	if param == nil || param.Name == "" {
+		return nil, errors.IllegalArgumentError.New("NoContainerName")
+	}
+	c := &module.StorageContainer{
+		Type: param.Type,
+		Name: param.Name,
+	}
+	for _, k := range param.Keys {
+		ks, ok := k.([]interface{})
+		if !ok {
+			ks = []interface{}{k}
+		}
+		keys := make([]interface{}, len(ks))
+		for i, kv := range ks {
+			var keyType string
+			if n := len(param.KeyTypes); n > 0 {
+				if i < n {
+					keyType = param.KeyTypes[i]
+				} else {
+					keyType = param.KeyTypes[n-1]
+				}
+			}
+			var err error
+			if keys[i], err = storageKeyOf(keyType, kv); err != nil {
+				return nil, err
+			}
+		}
+		c.Keys = append(c.Keys, keys)
+	}
+	return c, nil
+}
+
+func getScoreStorage(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+	var c contextWithSM
+	if err := c.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	var param ScoreStorageParam
+	if err := params.Convert(&param); err != nil {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+	}
+
+	var start []byte
+	if param.Start != "" {
+		var err error
+		if !strings.HasPrefix(string(param.Start), "0x") {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Errorf("InvalidStart(%s)", param.Start)
+		}
+		if start, err = hex.DecodeString(string(param.Start[2:])); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+	}
+	limit, err := param.Limit.Int64()
+	if err != nil || limit < 0 {
+		return nil, jsonrpc.ErrorCodeInvalidParams.Errorf("InvalidLimit(%s)", param.Limit)
+	}
+	containers := make([]*module.StorageContainer, len(param.Containers))
+	for i, cp := range param.Containers {
+		if containers[i], err = storageContainerOf(cp); err != nil {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+	}
+
+	blk, err := c.GetBlockByHeight(param.Height)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.sm.GetStorage(blk.Result(), param.Address.Address(), start, int(limit), containers)
+	if err != nil {
+		if errors.IllegalArgumentError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeInvalidParams.Wrap(err, c.debug)
+		}
+		if errors.NotFoundError.Equals(err) {
+			return nil, jsonrpc.ErrorCodeNotFound.Wrap(err, c.debug)
+		}
+		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+	}
+	return res, nil
+}
+
 func estimateStep(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
 	var c contextWithSM
 	if err := c.Init(ctx); err != nil {
@@ -1312,31 +1710,67 @@ func getTraceForRosetta(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface
 		return nil, err
 	}
 
-	csi, err := c.bm.NewConsensusInfo(blk)
-	if err != nil {
+	res, err := traceBalanceChanges(c.chain, blk, func(ti *module.TraceInfo) {
+		if txInfo != nil {
+			ti.Range = module.TraceRangeTransaction
+			ti.Group = module.TransactionGroupNormal
+			ti.Index = txInfo.Index()
+		} else {
+			if len(param.Tx) > 0 {
+				ti.Range = module.TraceRangeBlockTransaction
+			} else {
+				ti.Range = module.TraceRangeBlock
+			}
+		}
+	}, time.Second*60)
+	if errors.TimeoutError.Equals(err) {
+		return nil, jsonrpc.ErrorCodeSystemTimeout.Errorf(
+			"Not enough time to get result of %+v", param)
+	} else if err != nil {
 		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
 	}
-	nblk, err := c.bm.GetBlockByHeight(blk.Height() + 1)
+	return res, nil
+}
+
+// TraceBalanceChanges executes transactions of the block again and returns
+// balance changes of the block as the trace for rosetta does.
+func TraceBalanceChanges(chain module.Chain, blk module.Block, timeout time.Duration) (interface{}, error) {
+	return traceBalanceChanges(chain, blk, func(ti *module.TraceInfo) {
+		ti.Range = module.TraceRangeBlock
+	}, timeout)
+}
+
+func traceBalanceChanges(chain module.Chain, blk module.Block, setRange func(ti *module.TraceInfo), timeout time.Duration) (interface{}, error) {
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	if bm == nil || sm == nil {
+		return nil, errors.InvalidStateError.New("Stopped")
+	}
+	csi, err := bm.NewConsensusInfo(blk)
 	if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+		return nil, err
 	}
-	tr1, err := c.sm.CreateInitialTransition(blk.Result(), blk.NextValidators())
+	nblk, err := bm.GetBlockByHeight(blk.Height() + 1)
 	if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+		return nil, err
 	}
-	tr2, err := c.sm.CreateTransition(tr1, blk.NormalTransactions(), blk, csi, true)
+	tr1, err := sm.CreateInitialTransition(blk.Result(), blk.NextValidators())
 	if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+		return nil, err
 	}
-	tr2 = c.sm.PatchTransition(tr2, nblk.PatchTransactions(), nblk)
+	tr2, err := sm.CreateTransition(tr1, blk.NormalTransactions(), blk, csi, true)
+	if err != nil {
+		return nil, err
+	}
+	tr2 = sm.PatchTransition(tr2, nblk.PatchTransactions(), nblk)
 
-	rl, err := c.sm.ReceiptListFromResult(nblk.Result(), module.TransactionGroupNormal)
+	rl, err := sm.ReceiptListFromResult(nblk.Result(), module.TransactionGroupNormal)
 	if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+		return nil, err
 	}
 
 	var replacer trace.TxHashReplacer
-	if mt := findMissingTransactionInfoOf(c.chain.CID()); mt != nil {
+	if mt := findMissingTransactionInfoOf(chain.CID()); mt != nil {
 		replacer = mt.ReplaceID
 	}
 	cb := &traceCallback{
@@ -1348,29 +1782,18 @@ func getTraceForRosetta(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface
 		TraceBlock: trace.NewTraceBlock(blk.ID(), rl),
 		Callback:   cb,
 	}
-	if txInfo != nil {
-		ti.Range = module.TraceRangeTransaction
-		ti.Group = module.TransactionGroupNormal
-		ti.Index = txInfo.Index()
-	} else {
-		if len(param.Tx) > 0 {
-			ti.Range = module.TraceRangeBlockTransaction
-		} else {
-			ti.Range = module.TraceRangeBlock
-		}
-	}
+	setRange(&ti)
 	canceller, err := tr2.ExecuteForTrace(ti)
 	if err != nil {
-		return nil, jsonrpc.ErrorCodeSystem.Wrap(err, c.debug)
+		return nil, err
 	}
 
-	timer := time.After(time.Second * 60)
+	timer := time.After(timeout)
 	for {
 		select {
 		case <-timer:
 			canceller()
-			return nil, jsonrpc.ErrorCodeSystemTimeout.Errorf(
-				"Not enough time to get result of %+v", param)
+			return nil, errors.ErrTimeout
 		case <-cb.channel:
 			return cb.balanceChangeToJSON(blk), nil
 		}
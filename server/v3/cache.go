@@ -0,0 +1,257 @@
+package v3
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/icon-project/goloop/server/jsonrpc"
+)
+
+const (
+	DefaultResponseCacheSize = 64 * 1024 * 1024
+	DefaultLatestCacheTTL    = 2 * time.Second
+
+	// cacheEntryOverhead is the approximate memory used by an entry
+	// except its key and value.
+	cacheEntryOverhead = 128
+	// cacheEntryMaxRatio limits the size of an entry to the fraction of
+	// the limit, so a few large responses can't flush the cache.
+	cacheEntryMaxRatio = 16
+)
+
+type cacheEntry struct {
+	key     string
+	channel string
+	value   json.RawMessage
+	// height of the last block for the latest state, or -1 for
+	// immutable data.
+	height int64
+	expire time.Time
+}
+
+func (e *cacheEntry) size() int {
+	return len(e.key) + len(e.value) + cacheEntryOverhead
+}
+
+// ResponseCache caches results of queries in JSON.
+// Results of immutable data, like finalized blocks, are kept until they are
+// evicted by LRU. Results of the latest state are kept for the TTL, and
+// they are invalidated on a new block.
+type ResponseCache struct {
+	mtx     sync.Mutex
+	limit   int
+	size    int
+	ttl     time.Duration
+	lru     *list.List
+	items   map[string]*list.Element
+	latest  map[string]map[*list.Element]struct{}
+	heights map[string]int64
+	now     func() time.Time
+}
+
+func NewResponseCache(limit int, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		limit:   limit,
+		ttl:     ttl,
+		lru:     list.New(),
+		items:   make(map[string]*list.Element),
+		latest:  make(map[string]map[*list.Element]struct{}),
+		heights: make(map[string]int64),
+		now:     time.Now,
+	}
+}
+
+// SetLimit sets the maximum memory used by the cache. Zero disables it.
+func (c *ResponseCache) SetLimit(limit int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.limit = limit
+	c.evictInLock()
+}
+
+func (c *ResponseCache) Limit() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.limit
+}
+
+func (c *ResponseCache) Size() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.size
+}
+
+func (c *ResponseCache) removeInLock(e *list.Element) {
+	entry := e.Value.(*cacheEntry)
+	c.lru.Remove(e)
+	delete(c.items, entry.key)
+	if entry.height >= 0 {
+		delete(c.latest[entry.channel], e)
+	}
+	c.size -= entry.size()
+}
+
+func (c *ResponseCache) evictInLock() {
+	for c.size > c.limit {
+		c.removeInLock(c.lru.Back())
+	}
+}
+
+// updateHeightInLock invalidates results of the latest state of the channel
+// if the height is changed.
+func (c *ResponseCache) updateHeightInLock(channel string, height int64) {
+	if c.heights[channel] == height {
+		return
+	}
+	c.heights[channel] = height
+	for e := range c.latest[channel] {
+		c.removeInLock(e)
+	}
+}
+
+func (c *ResponseCache) get(key, channel string, height int64) (json.RawMessage, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if height >= 0 {
+		c.updateHeightInLock(channel, height)
+	}
+	e, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if entry.height != height || (height >= 0 && !c.now().Before(entry.expire)) {
+		c.removeInLock(e)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return entry.value, true
+}
+
+func (c *ResponseCache) put(key, channel string, height int64, value json.RawMessage) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	entry := &cacheEntry{
+		key:     key,
+		channel: channel,
+		value:   value,
+		height:  height,
+	}
+	if entry.size()*cacheEntryMaxRatio > c.limit {
+		return
+	}
+	if height >= 0 {
+		if height != c.heights[channel] {
+			return
+		}
+		entry.expire = c.now().Add(c.ttl)
+	}
+	if e, ok := c.items[key]; ok {
+		c.removeInLock(e)
+	}
+	e := c.lru.PushFront(entry)
+	c.items[key] = e
+	if height >= 0 {
+		latest, ok := c.latest[channel]
+		if !ok {
+			latest = make(map[*list.Element]struct{})
+			c.latest[channel] = latest
+		}
+		latest[e] = struct{}{}
+	}
+	c.size += entry.size()
+	c.evictInLock()
+}
+
+func cacheKeyOf(channel, method string, params []byte) string {
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString(channel)
+	buf.WriteByte(0)
+	buf.WriteString(method)
+	buf.WriteByte(0)
+	if err := json.Compact(buf, params); err != nil {
+		buf.Write(params)
+	}
+	return buf.String()
+}
+
+// cachePolicy returns whether the result of the request depends on the
+// latest state.
+type cachePolicy func(params *jsonrpc.Params) bool
+
+func immutable(params *jsonrpc.Params) bool {
+	return false
+}
+
+func latestState(params *jsonrpc.Params) bool {
+	return true
+}
+
+// latestWithout returns the policy for the request, whose result depends on
+// the latest state unless the parameter of the name is given.
+func latestWithout(name string) cachePolicy {
+	return func(params *jsonrpc.Params) bool {
+		var p map[string]json.RawMessage
+		if err := json.Unmarshal(params.RawMessage(), &p); err != nil {
+			return true
+		}
+		v := p[name]
+		return len(v) == 0 || string(v) == "null"
+	}
+}
+
+var (
+	latestWithoutHeight = latestWithout("height")
+	latestWithoutTo     = latestWithout("to")
+)
+
+// Wrap returns the handler caching successful results of the method.
+func (c *ResponseCache) Wrap(method string, h jsonrpc.Handler, policy cachePolicy) jsonrpc.Handler {
+	if c == nil {
+		return h
+	}
+	return func(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+		if c.Limit() <= 0 {
+			return h(ctx, params)
+		}
+		chain, err := ctx.Chain()
+		if err != nil {
+			return h(ctx, params)
+		}
+		height := int64(-1)
+		if policy(params) {
+			bm := chain.BlockManager()
+			if bm == nil {
+				return h(ctx, params)
+			}
+			blk, err := bm.GetLastBlock()
+			if err != nil {
+				return h(ctx, params)
+			}
+			height = blk.Height()
+		}
+		channel := chain.Channel()
+		key := cacheKeyOf(channel, method, params.RawMessage())
+		if value, ok := c.get(key, channel, height); ok {
+			return value, nil
+		}
+		result, err := h(ctx, params)
+		if err != nil {
+			return result, err
+		}
+		value, err := json.Marshal(result)
+		if err != nil {
+			return result, nil
+		}
+		c.put(key, channel, height, value)
+		return json.RawMessage(value), nil
+	}
+}
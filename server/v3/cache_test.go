@@ -0,0 +1,201 @@
+package v3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/metric"
+)
+
+type testCacheBlock struct {
+	module.Block
+	height int64
+}
+
+func (b *testCacheBlock) Height() int64 {
+	return b.height
+}
+
+type testCacheBlockManager struct {
+	module.BlockManager
+	height int64
+}
+
+func (bm *testCacheBlockManager) GetLastBlock() (module.Block, error) {
+	return &testCacheBlock{height: bm.height}, nil
+}
+
+type testCacheChain struct {
+	module.Chain
+	bm *testCacheBlockManager
+}
+
+func (c *testCacheChain) Channel() string {
+	return "test"
+}
+
+func (c *testCacheChain) MetricContext() context.Context {
+	return metric.DefaultMetricContext()
+}
+
+func (c *testCacheChain) BlockManager() module.BlockManager {
+	return c.bm
+}
+
+func TestResponseCache_Wrap(t *testing.T) {
+	cache := NewResponseCache(DefaultResponseCacheSize, time.Second)
+	now := time.Unix(1000, 0)
+	cache.now = func() time.Time { return now }
+	chain := &testCacheChain{bm: &testCacheBlockManager{height: 10}}
+
+	calls := make(map[string]int)
+	handler := func(method string) jsonrpc.Handler {
+		return func(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+			calls[method] += 1
+			var p struct {
+				Value string `json:"value"`
+			}
+			if err := params.Convert(&p); err != nil {
+				return nil, err
+			}
+			if p.Value == "error" {
+				return nil, jsonrpc.ErrorCodeNotFound.New("error")
+			}
+			return map[string]interface{}{
+				"value":  p.Value,
+				"height": chain.bm.height,
+			}, nil
+		}
+	}
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := jsonrpc.NewMethodRepository(mtr)
+	mr.RegisterMethod("immutable", cache.Wrap("immutable", handler("immutable"), immutable))
+	mr.RegisterMethod("latest", cache.Wrap("latest", handler("latest"), latestState))
+
+	invoke := func(method, value string) string {
+		req := fmt.Sprintf(`{"jsonrpc":"2.0","method":%q,"params":{"value":%q},"id":1}`, method, value)
+		e := echo.New()
+		e.Validator = jsonrpc.NewValidator()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(req)), httptest.NewRecorder())
+		c.Set("includeDebug", false)
+		c.Set("raw", json.RawMessage(req))
+		c.Set("chain", chain)
+		assert.NoError(t, mr.Handle(c))
+		return c.Response().Writer.(*httptest.ResponseRecorder).Body.String()
+	}
+
+	r1 := invoke("immutable", "a")
+	assert.Contains(t, r1, `"height":10`)
+	assert.Equal(t, r1, invoke("immutable", "a"))
+	assert.Equal(t, 1, calls["immutable"])
+	invoke("immutable", "b")
+	assert.Equal(t, 2, calls["immutable"])
+
+	// errors are not cached
+	invoke("immutable", "error")
+	invoke("immutable", "error")
+	assert.Equal(t, 4, calls["immutable"])
+
+	invoke("latest", "a")
+	invoke("latest", "a")
+	assert.Equal(t, 1, calls["latest"])
+
+	// expired by TTL
+	now = now.Add(time.Second)
+	invoke("latest", "a")
+	assert.Equal(t, 2, calls["latest"])
+
+	// invalidated by a new block
+	chain.bm.height = 11
+	assert.Contains(t, invoke("latest", "a"), `"height":11`)
+	assert.Equal(t, 3, calls["latest"])
+	invoke("latest", "a")
+	assert.Equal(t, 3, calls["latest"])
+
+	// immutable results are kept
+	assert.Equal(t, r1, invoke("immutable", "a"))
+	assert.Equal(t, 4, calls["immutable"])
+
+	cache.SetLimit(0)
+	assert.Equal(t, 0, cache.Size())
+	invoke("immutable", "a")
+	assert.Equal(t, 5, calls["immutable"])
+}
+
+func TestResponseCache_Evict(t *testing.T) {
+	value := json.RawMessage(strings.Repeat("0", 100))
+	entry := &cacheEntry{key: "0000", value: value}
+	cache := NewResponseCache(entry.size()*cacheEntryMaxRatio, time.Second)
+
+	for i := 0; i <= cacheEntryMaxRatio; i++ {
+		cache.put(fmt.Sprintf("%04d", i), "test", -1, value)
+		assert.True(t, cache.Size() <= cache.Limit())
+	}
+	_, ok := cache.get("0000", "test", -1)
+	assert.False(t, ok)
+	_, ok = cache.get(fmt.Sprintf("%04d", cacheEntryMaxRatio), "test", -1)
+	assert.True(t, ok)
+
+	// too large value
+	cache.put("large", "test", -1, json.RawMessage(strings.Repeat("0", cache.Limit())))
+	_, ok = cache.get("large", "test", -1)
+	assert.False(t, ok)
+}
+
+func TestCacheKeyOf(t *testing.T) {
+	assert.Equal(t,
+		cacheKeyOf("test", "method", []byte(`{"height": "0x1"}`)),
+		cacheKeyOf("test", "method", []byte(`{"height":"0x1"}`)),
+	)
+	assert.NotEqual(t,
+		cacheKeyOf("test", "method", []byte(`{"height":"0x1"}`)),
+		cacheKeyOf("test", "method", []byte(`{"height":"0x2"}`)),
+	)
+}
+
+func TestResponseCache_WrapLatestWithoutTo(t *testing.T) {
+	cache := NewResponseCache(DefaultResponseCacheSize, time.Second)
+	chain := &testCacheChain{bm: &testCacheBlockManager{height: 10}}
+
+	calls := 0
+	handler := func(ctx *jsonrpc.Context, params *jsonrpc.Params) (interface{}, error) {
+		calls += 1
+		return chain.bm.height, nil
+	}
+	mtr := metric.NewJsonrpcMetric(metric.DefaultJsonrpcDurationsExpire, metric.DefaultJsonrpcDurationsSize, true)
+	mr := jsonrpc.NewMethodRepository(mtr)
+	mr.RegisterMethod("usage", cache.Wrap("usage", handler, latestWithoutTo))
+
+	invoke := func(params string) {
+		req := fmt.Sprintf(`{"jsonrpc":"2.0","method":"usage","params":%s,"id":1}`, params)
+		e := echo.New()
+		e.Validator = jsonrpc.NewValidator()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(req)), httptest.NewRecorder())
+		c.Set("includeDebug", false)
+		c.Set("raw", json.RawMessage(req))
+		c.Set("chain", chain)
+		assert.NoError(t, mr.Handle(c))
+	}
+
+	// results with "to" are kept on a new block
+	invoke(`{"from":"0x1","to":"0x5"}`)
+	invoke(`{"to":null}`)
+	chain.bm.height = 11
+	invoke(`{"from":"0x1","to":"0x5"}`)
+	assert.Equal(t, 2, calls)
+
+	// results without "to" are invalidated
+	invoke(`{"to":null}`)
+	assert.Equal(t, 3, calls)
+}
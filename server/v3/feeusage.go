@@ -0,0 +1,233 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v3
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	// maxFeeUsageRange is the maximum number of blocks examined by
+	// icx_getFeeSharingUsage, as it loads transactions and receipts of
+	// every block in the range. It's too short for daily usage, so usage
+	// is reported only for blocks and the whole range.
+	maxFeeUsageRange     = 1800
+	defaultFeeUsageRange = 300
+	maxFeeUsageCallers   = 100
+	defaultFeeUsageLimit = 10
+)
+
+// feeAmount is the amount of steps paid by the contract. Steps are paid
+// with virtual steps first, and the rest is paid with the deposit, which is
+// the fee.
+type feeAmount struct {
+	steps        *big.Int
+	virtualSteps *big.Int
+	fee          *big.Int
+	txs          int
+}
+
+func newFeeAmount() feeAmount {
+	return feeAmount{
+		steps:        new(big.Int),
+		virtualSteps: new(big.Int),
+		fee:          new(big.Int),
+	}
+}
+
+func (a *feeAmount) add(steps, virtualSteps, fee *big.Int) {
+	a.steps.Add(a.steps, steps)
+	a.virtualSteps.Add(a.virtualSteps, virtualSteps)
+	a.fee.Add(a.fee, fee)
+	a.txs += 1
+}
+
+func (a *feeAmount) ToJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"steps":        intconv.FormatBigInt(a.steps),
+		"virtualSteps": intconv.FormatBigInt(a.virtualSteps),
+		"fee":          intconv.FormatBigInt(a.fee),
+		"txCount":      intconv.FormatInt(int64(a.txs)),
+	}
+}
+
+type blockFeeUsage struct {
+	feeAmount
+	height int64
+}
+
+func (u *blockFeeUsage) ToJSON() map[string]interface{} {
+	jso := u.feeAmount.ToJSON()
+	jso["blockHeight"] = intconv.FormatInt(u.height)
+	return jso
+}
+
+type callerFeeUsage struct {
+	feeAmount
+	address module.Address
+}
+
+func (u *callerFeeUsage) ToJSON() map[string]interface{} {
+	jso := u.feeAmount.ToJSON()
+	jso["address"] = u.address
+	return jso
+}
+
+// feeUsage accumulates steps paid by the contract for transactions of
+// others. Receipts should be added in order of execution.
+type feeUsage struct {
+	feeAmount
+	contract    module.Address
+	availableVS *big.Int
+	blocks      []*blockFeeUsage
+	callers     map[string]*callerFeeUsage
+}
+
+func newFeeUsage(contract module.Address) *feeUsage {
+	return &feeUsage{
+		feeAmount:   newFeeAmount(),
+		contract:    contract,
+		availableVS: new(big.Int),
+		callers:     make(map[string]*callerFeeUsage),
+	}
+}
+
+// SetVirtualSteps sets the virtual steps of the contract available for
+// following receipts. It should be set before receipts of each block,
+// as virtual steps may be issued by deposits.
+func (u *feeUsage) SetVirtualSteps(vs *big.Int) {
+	u.availableVS = new(big.Int).Set(vs)
+}
+
+// stepsPaidBy returns the steps paid by the payer in the receipt.
+func stepsPaidBy(payer module.Address, r module.Receipt) (*big.Int, error) {
+	for itr := r.FeePaymentIterator(); itr.Has(); itr.Next() {
+		p, err := itr.Get()
+		if err != nil {
+			return nil, err
+		}
+		if payer.Equal(p.Payer()) {
+			return p.Amount(), nil
+		}
+	}
+	return nil, nil
+}
+
+// Add adds the receipt of the transaction sent by from in the block at the
+// height.
+func (u *feeUsage) Add(height int64, from module.Address, r module.Receipt) error {
+	steps, err := stepsPaidBy(u.contract, r)
+	if err != nil || steps == nil || steps.Sign() == 0 {
+		return err
+	}
+	vs := steps
+	if u.availableVS.Cmp(steps) < 0 {
+		vs = u.availableVS
+	}
+	u.availableVS = new(big.Int).Sub(u.availableVS, vs)
+	fee := new(big.Int).Sub(steps, vs)
+	fee.Mul(fee, r.StepPrice())
+	u.add(steps, vs, fee)
+
+	var bu *blockFeeUsage
+	if n := len(u.blocks); n > 0 && u.blocks[n-1].height == height {
+		bu = u.blocks[n-1]
+	} else {
+		bu = &blockFeeUsage{feeAmount: newFeeAmount(), height: height}
+		u.blocks = append(u.blocks, bu)
+	}
+	bu.add(steps, vs, fee)
+
+	if from != nil {
+		key := string(from.Bytes())
+		cu, ok := u.callers[key]
+		if !ok {
+			cu = &callerFeeUsage{feeAmount: newFeeAmount(), address: from}
+			u.callers[key] = cu
+		}
+		cu.add(steps, vs, fee)
+	}
+	return nil
+}
+
+// TopCallers returns up to limit callers ordered by paid steps.
+func (u *feeUsage) TopCallers(limit int) []*callerFeeUsage {
+	callers := make([]*callerFeeUsage, 0, len(u.callers))
+	for _, cu := range u.callers {
+		callers = append(callers, cu)
+	}
+	sort.Slice(callers, func(i, j int) bool {
+		if c := callers[i].steps.Cmp(callers[j].steps); c != 0 {
+			return c > 0
+		}
+		return bytes.Compare(callers[i].address.Bytes(), callers[j].address.Bytes()) < 0
+	})
+	if len(callers) > limit {
+		callers = callers[:limit]
+	}
+	return callers
+}
+
+// Projection estimates how long the remaining virtual steps and deposit
+// last if the contract keeps paying steps at the average rate of the
+// blocks from start to end.
+func (u *feeUsage) Projection(
+	start, end int64, deposit, virtualSteps, stepPrice *big.Int,
+) map[string]interface{} {
+	remaining := new(big.Int).Set(virtualSteps)
+	if stepPrice.Sign() > 0 {
+		remaining.Add(remaining, new(big.Int).Div(deposit, stepPrice))
+	}
+	average := new(big.Int).Div(u.steps, big.NewInt(end-start+1))
+	jso := map[string]interface{}{
+		"availableDeposit":     intconv.FormatBigInt(deposit),
+		"availableVirtualStep": intconv.FormatBigInt(virtualSteps),
+		"stepPrice":            intconv.FormatBigInt(stepPrice),
+		"remainingSteps":       intconv.FormatBigInt(remaining),
+		"averageStepsPerBlock": intconv.FormatBigInt(average),
+	}
+	if average.Sign() > 0 {
+		blocks := new(big.Int).Div(remaining, average)
+		jso["remainingBlocks"] = intconv.FormatBigInt(blocks)
+		jso["exhaustionBlockHeight"] = intconv.FormatBigInt(blocks.Add(blocks, big.NewInt(end)))
+	}
+	return jso
+}
+
+func (u *feeUsage) ToJSON(start, end int64, limit int) map[string]interface{} {
+	blocks := make([]interface{}, 0, len(u.blocks))
+	for _, bu := range u.blocks {
+		blocks = append(blocks, bu.ToJSON())
+	}
+	callers := u.TopCallers(limit)
+	topCallers := make([]interface{}, 0, len(callers))
+	for _, cu := range callers {
+		topCallers = append(topCallers, cu.ToJSON())
+	}
+	jso := u.feeAmount.ToJSON()
+	jso["address"] = u.contract
+	jso["startBlockHeight"] = intconv.FormatInt(start)
+	jso["endBlockHeight"] = intconv.FormatInt(end)
+	jso["blocks"] = blocks
+	jso["topCallers"] = topCallers
+	return jso
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v3
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+func newFeeReceipt(contract, user module.Address, contractSteps, userSteps int64) module.Receipt {
+	r := txresult.NewReceipt(db.NewMapDB(), module.LatestRevision, contract)
+	r.AddPayment(contract, big.NewInt(contractSteps), nil)
+	r.AddPayment(user, big.NewInt(userSteps), nil)
+	r.SetResult(module.StatusSuccess, big.NewInt(contractSteps+userSteps), big.NewInt(10), nil)
+	return r
+}
+
+func TestFeeUsage(t *testing.T) {
+	contract := common.MustNewAddressFromString("cx0000000000000000000000000000000000000001")
+	user1 := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	user2 := common.MustNewAddressFromString("hx0000000000000000000000000000000000000002")
+
+	u := newFeeUsage(contract)
+	// virtual steps are used first, and the rest is paid with the deposit
+	u.SetVirtualSteps(big.NewInt(150))
+	assert.NoError(t, u.Add(10, user1, newFeeReceipt(contract, user1, 100, 10)))
+	assert.NoError(t, u.Add(10, user2, newFeeReceipt(contract, user2, 300, 0)))
+	u.SetVirtualSteps(big.NewInt(0))
+	assert.NoError(t, u.Add(11, user1, newFeeReceipt(contract, user1, 0, 50)))
+	u.SetVirtualSteps(big.NewInt(0))
+	assert.NoError(t, u.Add(12, user1, newFeeReceipt(contract, user1, 400, 0)))
+
+	assert.Equal(t, int64(800), u.steps.Int64())
+	assert.Equal(t, int64(150), u.virtualSteps.Int64())
+	assert.Equal(t, int64(6500), u.fee.Int64())
+	assert.Equal(t, 3, u.txs)
+
+	// blocks without payment of the contract are not included
+	assert.Len(t, u.blocks, 2)
+	assert.Equal(t, int64(10), u.blocks[0].height)
+	assert.Equal(t, int64(400), u.blocks[0].steps.Int64())
+	assert.Equal(t, int64(150), u.blocks[0].virtualSteps.Int64())
+	assert.Equal(t, int64(2500), u.blocks[0].fee.Int64())
+	assert.Equal(t, 2, u.blocks[0].txs)
+	assert.Equal(t, int64(12), u.blocks[1].height)
+	assert.Equal(t, int64(4000), u.blocks[1].fee.Int64())
+
+	callers := u.TopCallers(10)
+	assert.Len(t, callers, 2)
+	assert.True(t, user1.Equal(callers[0].address))
+	assert.Equal(t, int64(500), callers[0].steps.Int64())
+	assert.Equal(t, int64(100), callers[0].virtualSteps.Int64())
+	assert.Equal(t, int64(4000), callers[0].fee.Int64())
+	assert.Equal(t, 2, callers[0].txs)
+	assert.True(t, user2.Equal(callers[1].address))
+	assert.Len(t, u.TopCallers(1), 1)
+
+	jso := u.ToJSON(10, 13, 1)
+	assert.Equal(t, "0x320", jso["steps"])
+	assert.Equal(t, "0x3", jso["txCount"])
+	assert.Equal(t, "0x96", jso["virtualSteps"])
+	assert.NotContains(t, jso, "days")
+	assert.Len(t, jso["topCallers"], 1)
+
+	// 800 steps for 4 blocks, and 1000 steps remain with 2000 loop of deposit
+	p := u.Projection(10, 13, big.NewInt(2000), big.NewInt(800), big.NewInt(10))
+	assert.Equal(t, "0xc8", p["averageStepsPerBlock"])
+	assert.Equal(t, "0x3e8", p["remainingSteps"])
+	assert.Equal(t, "0x5", p["remainingBlocks"])
+	assert.Equal(t, "0x12", p["exhaustionBlockHeight"])
+
+	p = newFeeUsage(contract).Projection(10, 13, big.NewInt(2000), big.NewInt(0), big.NewInt(10))
+	assert.Equal(t, "0x0", p["averageStepsPerBlock"])
+	assert.NotContains(t, p, "remainingBlocks")
+}
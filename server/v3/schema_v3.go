@@ -43,10 +43,47 @@ type ScoreAddressParam struct {
 	Height  jsonrpc.HexInt  `json:"height,omitempty" validate:"optional,t_int"`
 }
 
+type FeeSharingUsageParam struct {
+	Address jsonrpc.Address `json:"address" validate:"required,t_addr_score"`
+	From    jsonrpc.HexInt  `json:"from,omitempty" validate:"optional,t_int"`
+	To      jsonrpc.HexInt  `json:"to,omitempty" validate:"optional,t_int"`
+	Limit   jsonrpc.HexInt  `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
 type TransactionHashParam struct {
 	Hash jsonrpc.HexBytes `json:"txHash" validate:"required,t_hash"`
 }
 
+type StateDiffParam struct {
+	From          jsonrpc.HexInt    `json:"from,omitempty" validate:"optional,t_int"`
+	To            jsonrpc.HexInt    `json:"to,omitempty" validate:"optional,t_int"`
+	FromState     jsonrpc.HexBytes  `json:"fromState,omitempty" validate:"optional,t_hash"`
+	ToState       jsonrpc.HexBytes  `json:"toState,omitempty" validate:"optional,t_hash"`
+	FromExtension jsonrpc.HexBytes  `json:"fromExtension,omitempty"`
+	ToExtension   jsonrpc.HexBytes  `json:"toExtension,omitempty"`
+	Addresses     []jsonrpc.Address `json:"addresses,omitempty" validate:"optional,max=100,dive,t_addr"`
+	Limit         jsonrpc.HexInt    `json:"limit,omitempty" validate:"optional,t_int"`
+}
+
+// StorageContainerParam describes a container of the contract. Keys are
+// candidate keys of DictDB. Each of them is a key, or an array of keys for
+// nested DictDB. KeyTypes are types of keys for each depth, and the last
+// one is used for deeper ones.
+type StorageContainerParam struct {
+	Type     string        `json:"type"`
+	Name     string        `json:"name"`
+	KeyTypes []string      `json:"keyTypes,omitempty"`
+	Keys     []interface{} `json:"keys,omitempty"`
+}
+
+type ScoreStorageParam struct {
+	Address    jsonrpc.Address          `json:"address" validate:"required,t_addr_score"`
+	Height     jsonrpc.HexInt           `json:"height,omitempty" validate:"optional,t_int"`
+	Start      jsonrpc.HexBytes         `json:"start,omitempty"`
+	Limit      jsonrpc.HexInt           `json:"limit,omitempty" validate:"optional,t_int"`
+	Containers []*StorageContainerParam `json:"containers,omitempty"`
+}
+
 type TransactionParamForEstimate struct {
 	Version     jsonrpc.HexInt  `json:"version" validate:"required,t_int"`
 	FromAddress jsonrpc.Address `json:"from" validate:"required,t_addr_eoa"`
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
@@ -24,6 +25,10 @@ type WebSocketConn interface {
 	NextReader() (messageType int, r io.Reader, err error)
 }
 
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
 type WebSocketUpgrader interface {
 	Upgrade(ctx echo.Context) (WebSocketConn, error)
 }
@@ -199,6 +204,24 @@ func (wss *wsSession) WriteJSON(v interface{}) error {
 	}
 }
 
+// WriteJSONWithTimeout writes the value, and it fails if the client doesn't
+// read it in the time.
+func (wss *wsSession) WriteJSONWithTimeout(v interface{}, timeout time.Duration) error {
+	wss.lock.Lock()
+	defer wss.lock.Unlock()
+
+	if wss.c == nil {
+		return io.ErrUnexpectedEOF
+	}
+	if d, ok := wss.c.(writeDeadliner); ok {
+		if err := d.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+			return err
+		}
+		defer d.SetWriteDeadline(time.Time{})
+	}
+	return wss.c.WriteJSON(v)
+}
+
 func (wss *wsSession) Close() error {
 	wss.lock.Lock()
 	defer wss.lock.Unlock()
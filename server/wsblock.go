@@ -1,28 +1,122 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server/jsonrpc"
+	"github.com/icon-project/goloop/server/v3"
 )
 
 type BlockRequest struct {
-	Height       common.HexInt64 `json:"height"`
-	EventFilters []*EventFilter  `json:"eventFilters,omitempty"`
-	Logs         common.HexBool  `json:"logs,omitempty"`
-	bn           BlockNotification
+	Height         common.HexInt64 `json:"height"`
+	EventFilters   []*EventFilter  `json:"eventFilters,omitempty"`
+	Logs           common.HexBool  `json:"logs,omitempty"`
+	Full           common.HexBool  `json:"full,omitempty"`
+	BalanceChanges common.HexBool  `json:"balanceChanges,omitempty"`
 }
 
 type BlockNotification struct {
-	Hash    common.HexBytes       `json:"hash"`
-	Height  common.HexInt64       `json:"height"`
-	Indexes [][]common.HexInt32   `json:"indexes,omitempty"`
-	Events  [][][]common.HexInt32 `json:"events,omitempty"`
-	Logs    [][][]module.EventLog `json:"logs,omitempty"`
+	Hash           common.HexBytes       `json:"hash"`
+	Height         common.HexInt64       `json:"height"`
+	Indexes        [][]common.HexInt32   `json:"indexes,omitempty"`
+	Events         [][][]common.HexInt32 `json:"events,omitempty"`
+	Logs           [][][]module.EventLog `json:"logs,omitempty"`
+	Block          interface{}           `json:"block,omitempty"`
+	Receipts       []interface{}         `json:"receipts,omitempty"`
+	BalanceChanges interface{}           `json:"balanceChanges,omitempty"`
+}
+
+const (
+	// DefaultWSBlockQueueSize is the number of block notifications prepared
+	// ahead of the client. Preparing notifications is paused while the queue
+	// is full, so a client falling behind slows down the session instead of
+	// piling up notifications.
+	DefaultWSBlockQueueSize = 4
+	// DefaultWSWriteTimeout is the time allowed to write a block notification.
+	// The session is closed if the client doesn't read it in time.
+	DefaultWSWriteTimeout = 30 * time.Second
+	DefaultWSTraceTimeout = 60 * time.Second
+)
+
+func (r *BlockRequest) notificationOf(ctx context.Context, chain module.Chain, h int64) (*BlockNotification, error) {
+	bm := chain.BlockManager()
+	sm := chain.ServiceManager()
+	blk, err := waitBlock(ctx, bm, h)
+	if err != nil {
+		return nil, err
+	}
+	bn := &BlockNotification{
+		Hash:   blk.ID(),
+		Height: common.HexInt64{Value: h},
+	}
+	var rl module.ReceiptList
+	lb := blk.LogsBloom()
+	for i, f := range r.EventFilters {
+		if !f.ContainedIn(lb) {
+			continue
+		}
+		if rl == nil {
+			rl, err = sm.ReceiptListFromResult(blk.Result(), module.TransactionGroupNormal)
+			if err != nil {
+				return nil, err
+			}
+		}
+		index := int32(0)
+		for rit := rl.Iterator(); rit.Has(); rit.Next() {
+			rct, err := rit.Get()
+			if err != nil {
+				return nil, err
+			}
+			if es, logs, err := f.MatchEvents(rct, r.Logs.Value); err == nil && len(es) > 0 {
+				if len(bn.Indexes) < 1 {
+					bn.Indexes = make([][]common.HexInt32, len(r.EventFilters))
+					bn.Events = make([][][]common.HexInt32, len(r.EventFilters))
+					for j := range bn.Indexes {
+						bn.Indexes[j] = []common.HexInt32{}
+						bn.Events[j] = [][]common.HexInt32{}
+					}
+					if r.Logs.Value {
+						bn.Logs = make([][][]module.EventLog, len(r.EventFilters))
+						for j := range bn.Logs {
+							bn.Logs[j] = [][]module.EventLog{}
+						}
+					}
+				}
+				bn.Indexes[i] = append(bn.Indexes[i], common.HexInt32{Value: index})
+				bn.Events[i] = append(bn.Events[i], es)
+				if r.Logs.Value {
+					bn.Logs[i] = append(bn.Logs[i], logs)
+				}
+			}
+			index++
+		}
+	}
+	if r.Full.Value {
+		if bn.Block, err = v3.BlockToJSON(blk, module.JSONVersion3); err != nil {
+			return nil, err
+		}
+		// results of transactions in the block are in the next block
+		next, err := waitBlock(ctx, bm, h+1)
+		if err != nil {
+			return nil, err
+		}
+		if bn.Receipts, err = v3.ReceiptsToJSON(sm, blk, next, module.JSONVersion3); err != nil {
+			return nil, err
+		}
+		if r.BalanceChanges.Value {
+			bn.BalanceChanges, err = v3.TraceBalanceChanges(chain, blk, DefaultWSTraceTimeout)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return bn, nil
 }
 
 func (wm *wsSessionManager) RunBlockSession(ctx echo.Context) error {
@@ -37,6 +131,11 @@ func (wm *wsSessionManager) RunBlockSession(ctx echo.Context) error {
 		_ = wss.response(int(jsonrpc.ErrorCodeInvalidParams), err.Error())
 		return nil
 	}
+	// balanceChanges re-executes every block as rosetta_getTrace does.
+	if br.BalanceChanges.Value && !isEnabledIn(ctx, "rosetta") && !isEnabledIn(ctx, "includeDebug") {
+		_ = wss.response(int(jsonrpc.ErrorCodeInvalidRequest), "balanceChanges requires rpc_rosetta or rpc_debug")
+		return nil
+	}
 
 	bm := wss.chain.BlockManager()
 	sm := wss.chain.ServiceManager()
@@ -57,95 +156,55 @@ func (wm *wsSessionManager) RunBlockSession(ctx echo.Context) error {
 	ech := make(chan error, 1)
 	wss.RunLoop(ech)
 
-	var bch <-chan module.Block
-	indexes := make([][]common.HexInt32, len(br.EventFilters))
-	events := make([][][]common.HexInt32, len(br.EventFilters))
-	eventLogs := make([][][]module.EventLog, len(br.EventFilters))
-	for i := 0; i < len(indexes); i++ {
-		indexes[i] = make([]common.HexInt32, 0)
-		events[i] = make([][]common.HexInt32, 0)
-		eventLogs[i] = make([][]module.EventLog, 0)
-	}
-	var rl module.ReceiptList
+	// notifications are prepared ahead while the previous ones are written.
+	nctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	nch := make(chan *BlockNotification, DefaultWSBlockQueueSize)
+	var nerr error
+	go func() {
+		defer close(nch)
+		for ; ; h++ {
+			bn, err := br.notificationOf(nctx, wss.chain, h)
+			if err != nil {
+				nerr = err
+				return
+			}
+			select {
+			case nch <- bn:
+			case <-nctx.Done():
+				return
+			}
+		}
+	}()
 loop:
 	for {
-		bch, err = bm.WaitForBlock(h)
-		if err != nil {
-			break loop
-		}
 		select {
 		case err = <-ech:
 			break loop
-		case blk, ok := <-bch:
+		case bn, ok := <-nch:
 			if !ok {
+				err = nerr
 				break loop
 			}
-			br.bn.Height = common.HexInt64{Value: h}
-			br.bn.Hash = blk.ID()
-			if rl != nil {
-				rl = nil
-			}
-			if len(br.bn.Indexes) > 0 {
-				br.bn.Indexes = indexes[:0]
-				br.bn.Events = events[:0]
-				for i := range indexes {
-					indexes[i] = indexes[i][:0]
-				}
-				for i := range events {
-					events[i] = events[i][:0]
-				}
-				if br.Logs.Value {
-					br.bn.Logs = eventLogs[:0]
-					for i := range eventLogs {
-						eventLogs[i] = eventLogs[i][:0]
-					}
-				}
-			}
-			lb := blk.LogsBloom()
-			for i, f := range br.EventFilters {
-				if lb.Contain(f.lb) {
-					if rl == nil {
-						rl, err = sm.ReceiptListFromResult(blk.Result(), module.TransactionGroupNormal)
-						if err != nil {
-							break loop
-						}
-					}
-					index := int32(0)
-					for rit := rl.Iterator(); rit.Has(); rit.Next() {
-						r, err := rit.Get()
-						if err != nil {
-							break loop
-						}
-						if es, logs, err := f.MatchEvents(r, br.Logs.Value); err == nil && len(es) > 0 {
-							if len(br.bn.Indexes) < 1 {
-								br.bn.Indexes = indexes[:]
-								br.bn.Events = events[:]
-								if br.Logs.Value {
-									br.bn.Logs = eventLogs[:]
-								}
-							}
-							br.bn.Indexes[i] = append(br.bn.Indexes[i], common.HexInt32{Value: index})
-							br.bn.Events[i] = append(br.bn.Events[i], es)
-							if br.Logs.Value {
-								br.bn.Logs[i] = append(br.bn.Logs[i], logs)
-							}
-						}
-						index++
-					}
-				}
-			}
-			if err = wss.WriteJSON(&br.bn); err != nil {
+			if err = wss.WriteJSONWithTimeout(bn, DefaultWSWriteTimeout); err != nil {
 				wm.logger.Infof("fail to write json BlockNotification err:%+v\n", err)
 				break loop
 			}
 		}
-		h++
 	}
 	wm.logger.Warnf("%+v\n", err)
 	return nil
 }
 
+func isEnabledIn(ctx echo.Context, key string) bool {
+	enabled, _ := ctx.Get(key).(bool)
+	return enabled
+}
+
 func (r *BlockRequest) Compile() error {
+	if r.BalanceChanges.Value && !r.Full.Value {
+		return fmt.Errorf("balanceChanges requires full")
+	}
 	for i, f := range r.EventFilters {
 		if f == nil {
 			return fmt.Errorf("null filter idx:%d", i)
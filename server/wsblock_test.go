@@ -27,8 +27,10 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/errors"
 	"github.com/icon-project/goloop/common/log"
 	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/server/jsonrpc"
 )
 
 func TestBlockRequest_Compile(t *testing.T) {
@@ -295,3 +297,195 @@ func TestWsSessionManager_RunBlockSession(t *testing.T) {
 
 	wm.StopAllSessions()
 }
+
+type testTransaction struct {
+	module.Transaction
+	id []byte
+}
+
+func (tx *testTransaction) ID() []byte {
+	return tx.id
+}
+
+func (tx *testTransaction) ToJSON(version module.JSONVersion) (interface{}, error) {
+	return map[string]interface{}{
+		"txHash": common.HexBytes(tx.id),
+	}, nil
+}
+
+type testTransactionList struct {
+	module.TransactionList
+	txs []module.Transaction
+}
+
+type testTransactionIterator struct {
+	txs   []module.Transaction
+	index int
+}
+
+func (it *testTransactionIterator) Has() bool {
+	return it.index < len(it.txs)
+}
+
+func (it *testTransactionIterator) Next() error {
+	it.index++
+	return nil
+}
+
+func (it *testTransactionIterator) Get() (module.Transaction, int, error) {
+	return it.txs[it.index], it.index, nil
+}
+
+func (l *testTransactionList) Iterator() module.TransactionIterator {
+	return &testTransactionIterator{txs: l.txs}
+}
+
+type testFullBlock struct {
+	testBlock
+	txs *testTransactionList
+}
+
+func (b *testFullBlock) Height() int64 {
+	return b.height
+}
+
+func (b *testFullBlock) ToJSON(version module.JSONVersion) (interface{}, error) {
+	return map[string]interface{}{
+		"height": b.height,
+	}, nil
+}
+
+func (b *testFullBlock) NormalTransactions() module.TransactionList {
+	return b.txs
+}
+
+func (b *testFullBlock) PatchTransactions() module.TransactionList {
+	return &testTransactionList{}
+}
+
+type testJSONReceipt struct {
+	module.Receipt
+}
+
+func (r *testJSONReceipt) ToJSON(version module.JSONVersion) (interface{}, error) {
+	return map[string]interface{}{
+		"status": "0x1",
+	}, nil
+}
+
+func TestWsSessionManager_RunBlockSessionFull(t *testing.T) {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+
+	conns := make(chan *testWebSocketConn, 1)
+	upgrader := newTestWebsocketUpgrader(func(ctx echo.Context, conn *testWebSocketConn) {
+		assert.NoError(t, conn.clientWriteJSON(map[string]interface{}{
+			"height": "0x1",
+			"full":   "0x1",
+		}))
+		conns <- conn
+	})
+	wm := newWSSessionManagerWithUpgrader(logger, 10, upgrader)
+
+	chain := newTestChain(0, func(h int64) (getBlockFunc, error) {
+		return func() module.Block {
+			return &testFullBlock{
+				testBlock: testBlock{height: h, result: "receipts"},
+				txs: &testTransactionList{txs: []module.Transaction{
+					&testTransaction{id: testHeightToBlockID(h)},
+				}},
+			}
+		}, nil
+	}, blockReceipts{
+		"receipts": testReceiptList{&testJSONReceipt{}},
+	})
+	done := make(chan struct{})
+	go func() {
+		_ = wm.RunBlockSession(newTestContext(chain))
+		close(done)
+	}()
+
+	conn := <-conns
+	bs, err := conn.clientRead()
+	assert.NoError(t, err)
+	var res WSResponse
+	assert.NoError(t, json.Unmarshal(bs, &res))
+	assert.Equal(t, 0, res.Code)
+
+	for h := int64(1); h <= 2; h++ {
+		bs, err = conn.clientRead()
+		assert.NoError(t, err)
+		var bn struct {
+			Height   common.HexInt64          `json:"height"`
+			Block    map[string]interface{}   `json:"block"`
+			Receipts []map[string]interface{} `json:"receipts"`
+		}
+		assert.NoError(t, json.Unmarshal(bs, &bn))
+		assert.Equal(t, h, bn.Height.Value)
+		assert.EqualValues(t, h, bn.Block["height"])
+		assert.Len(t, bn.Block["confirmed_transaction_list"], 1)
+		if assert.Len(t, bn.Receipts, 1) {
+			assert.Equal(t, "0x1", bn.Receipts[0]["status"])
+			assert.Equal(t, fmt.Sprintf("%#x", h), bn.Receipts[0]["blockHeight"])
+			assert.Equal(t, "0x0", bn.Receipts[0]["txIndex"])
+			assert.Equal(t, common.HexBytes(testHeightToBlockID(h)).String(), bn.Receipts[0]["txHash"])
+		}
+	}
+
+	// drain queued notifications while the session stops
+	go func() {
+		for {
+			if _, err := conn.clientRead(); err != nil {
+				return
+			}
+		}
+	}()
+	assert.NoError(t, conn.clientWrite(nil))
+	_ = conn.Close()
+	<-done
+}
+
+func TestBlockRequest_CompileBalanceChanges(t *testing.T) {
+	r := &BlockRequest{BalanceChanges: common.HexBool{Value: true}}
+	assert.Error(t, r.Compile())
+	r.Full.Value = true
+	assert.NoError(t, r.Compile())
+}
+
+func TestWsSessionManager_RunBlockSessionBalanceChangesDisabled(t *testing.T) {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+
+	conns := make(chan *testWebSocketConn, 1)
+	upgrader := newTestWebsocketUpgrader(func(ctx echo.Context, conn *testWebSocketConn) {
+		assert.NoError(t, conn.clientWriteJSON(map[string]interface{}{
+			"height":         "0x1",
+			"full":           "0x1",
+			"balanceChanges": "0x1",
+		}))
+		conns <- conn
+	})
+	wm := newWSSessionManagerWithUpgrader(logger, 10, upgrader)
+	chain := newTestChain(0, func(h int64) (getBlockFunc, error) {
+		t.Errorf("GetBlock(%d) is called", h)
+		return nil, errors.NotFoundError.New("NoBlock")
+	}, blockReceipts{})
+
+	// neither rpc_rosetta nor rpc_debug is enabled
+	ctx := newTestContext(chain)
+	ctx.config["rosetta"] = false
+	done := make(chan struct{})
+	go func() {
+		assert.NoError(t, wm.RunBlockSession(ctx))
+		close(done)
+	}()
+
+	conn := <-conns
+	bs, err := conn.clientRead()
+	assert.NoError(t, err)
+	var res WSResponse
+	assert.NoError(t, json.Unmarshal(bs, &res))
+	assert.Equal(t, int(jsonrpc.ErrorCodeInvalidRequest), res.Code)
+	<-done
+	_ = conn.Close()
+}
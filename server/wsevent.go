@@ -3,11 +3,15 @@ package server
 import (
 	"bytes"
 	"fmt"
+	"math/big"
+	"strings"
+	"sync"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/icon-project/goloop/common"
 	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
 	"github.com/icon-project/goloop/module"
 	"github.com/icon-project/goloop/server/jsonrpc"
 	"github.com/icon-project/goloop/service/scoreapi"
@@ -25,16 +29,70 @@ type EventRequest struct {
 
 type EventFilters []*EventFilter
 
+const (
+	maxEventFilterAddrs  = 100
+	maxEventFilterValues = 100
+	maxEventArgsCache    = 1024
+)
+
 type EventFilter struct {
-	Addr       *common.Address `json:"addr,omitempty"`
-	Signature  string          `json:"event"`
-	Indexed    []*string       `json:"indexed,omitempty"`
-	Data       []*string       `json:"data,omitempty"`
-	indexedBSs [][]byte
-	dataBSs    [][]byte
-	numOfArgs  int
-	lb         module.LogsBloom
-	indexes    []int
+	Addr          *common.Address    `json:"addr,omitempty"`
+	Addrs         []*common.Address  `json:"addrs,omitempty"`
+	Signature     string             `json:"event"`
+	Indexed       []*string          `json:"indexed,omitempty"`
+	Data          []*string          `json:"data,omitempty"`
+	IndexedSets   [][]string         `json:"indexedSets,omitempty"`
+	DataSets      [][]string         `json:"dataSets,omitempty"`
+	IndexedRanges []*EventValueRange `json:"indexedRanges,omitempty"`
+
+	addrs    []module.Address
+	prefix   string
+	args     *eventArgs
+	argsMap  *eventArgsMap
+	lb       module.LogsBloom
+	lbGroups [][]module.LogsBloom
+}
+
+// EventValueRange is the range of an integer value. Bounds which are not
+// specified are not checked.
+type EventValueRange struct {
+	GT  *common.HexInt `json:"gt,omitempty"`
+	GTE *common.HexInt `json:"gte,omitempty"`
+	LT  *common.HexInt `json:"lt,omitempty"`
+	LTE *common.HexInt `json:"lte,omitempty"`
+}
+
+func (r *EventValueRange) Contains(v *big.Int) bool {
+	if r.GT != nil && v.Cmp(&r.GT.Int) <= 0 {
+		return false
+	}
+	if r.GTE != nil && v.Cmp(&r.GTE.Int) < 0 {
+		return false
+	}
+	if r.LT != nil && v.Cmp(&r.LT.Int) >= 0 {
+		return false
+	}
+	if r.LTE != nil && v.Cmp(&r.LTE.Int) > 0 {
+		return false
+	}
+	return true
+}
+
+// eventArgs is compiled arguments of the filter for an event signature.
+// Each position of indexed and data has alternatives of the value, and
+// nil matches any value.
+type eventArgs struct {
+	indexed [][][]byte
+	data    [][][]byte
+	ranges  []*EventValueRange
+}
+
+// eventArgsMap caches compiled arguments by signature for the filter with
+// the wildcard signature. It keeps at most maxEventArgsCache signatures, and
+// arguments for others are compiled on each use.
+type eventArgsMap struct {
+	lock sync.Mutex
+	args map[string]*eventArgs
 }
 
 type EventNotification struct {
@@ -54,7 +112,7 @@ func (fs EventFilters) FilteredByLogBloom(lb module.LogsBloom) (EventFilters, bo
 		if filter == nil {
 			continue
 		}
-		if lb.Contain(filter.lb) {
+		if filter.ContainedIn(lb) {
 			filters[idx] = filter
 			contained = true
 		}
@@ -196,51 +254,197 @@ loop:
 	return nil
 }
 
-func (f *EventFilter) Compile() error {
-	lb := txresult.NewLogsBloom(nil)
-	if f.Addr != nil {
-		lb.AddAddressOfLog(f.Addr)
+// valuesAt returns alternatives of the value at the position.
+func valuesAt(values []*string, sets [][]string, idx int) ([]string, error) {
+	var value *string
+	var set []string
+	if idx < len(values) {
+		value = values[idx]
+	}
+	if idx < len(sets) {
+		set = sets[idx]
+	}
+	if value != nil && len(set) > 0 {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"BothValueAndSetAreUsed(idx=%d)", idx)
 	}
-	f.numOfArgs = len(f.Indexed) + len(f.Data)
-	name, pts := txresult.DecomposeEventSignature(f.Signature)
-	if len(name) == 0 || pts == nil || len(pts) < f.numOfArgs {
-		return errors.NewBase(errors.IllegalArgumentError, "bad event signature")
+	if value != nil {
+		return []string{*value}, nil
+	}
+	return set, nil
+}
+
+func maxLen(lens ...int) int {
+	m := 0
+	for _, l := range lens {
+		if l > m {
+			m = l
+		}
+	}
+	return m
+}
+
+func (f *EventFilter) compileArgs(sig string) (*eventArgs, error) {
+	name, pts := txresult.DecomposeEventSignature(sig)
+	numOfIndexed := maxLen(len(f.Indexed), len(f.IndexedSets), len(f.IndexedRanges))
+	numOfData := maxLen(len(f.Data), len(f.DataSets))
+	if len(name) == 0 || pts == nil || len(pts) < numOfIndexed+numOfData {
+		return nil, errors.NewBase(errors.IllegalArgumentError, "bad event signature")
 	}
 	for idx, pt := range pts {
 		dt := scoreapi.DataTypeOf(pt)
 		if !dt.UsableForEvent() {
-			return errors.IllegalArgumentError.Errorf("InvalidParameterType(idx=%d,type=%s)", idx, pt)
+			return nil, errors.IllegalArgumentError.Errorf("InvalidParameterType(idx=%d,type=%s)", idx, pt)
 		}
 	}
-	lb.AddIndexedOfLog(0, []byte(f.Signature))
-	idx := 0
-	f.indexedBSs = make([][]byte, len(f.Indexed))
-	for i, arg := range f.Indexed {
-		if arg != nil {
-			bs, err := txresult.EventDataStringToBytesByType(pts[idx], string(*arg))
+	toBytes := func(pt string, values []string) ([][]byte, error) {
+		if len(values) == 0 {
+			return nil, nil
+		}
+		bss := make([][]byte, len(values))
+		for i, v := range values {
+			bs, err := txresult.EventDataStringToBytesByType(pt, v)
 			if err != nil {
-				return errors.NewBase(errors.IllegalArgumentError, "bad event data")
+				return nil, errors.NewBase(errors.IllegalArgumentError, "bad event data")
 			}
-			lb.AddIndexedOfLog(i+1, bs)
-			f.indexedBSs[i] = bs
+			bss[i] = bs
 		}
-		idx++
+		return bss, nil
 	}
-	f.dataBSs = make([][]byte, len(f.Data))
-	for i, arg := range f.Data {
-		if arg != nil {
-			bs, err := txresult.EventDataStringToBytesByType(pts[idx], string(*arg))
-			if err != nil {
-				return errors.NewBase(errors.IllegalArgumentError, "bad event data")
+	args := &eventArgs{
+		indexed: make([][][]byte, numOfIndexed),
+		data:    make([][][]byte, numOfData),
+	}
+	for i := 0; i < numOfIndexed; i++ {
+		values, err := valuesAt(f.Indexed, f.IndexedSets, i)
+		if err != nil {
+			return nil, err
+		}
+		if args.indexed[i], err = toBytes(pts[i], values); err != nil {
+			return nil, err
+		}
+		if i < len(f.IndexedRanges) && f.IndexedRanges[i] != nil {
+			if scoreapi.DataTypeOf(pts[i]) != scoreapi.Integer {
+				return nil, errors.IllegalArgumentError.Errorf(
+					"RangeForNonInteger(idx=%d,type=%s)", i, pts[i])
+			}
+			if args.ranges == nil {
+				args.ranges = make([]*EventValueRange, numOfIndexed)
+			}
+			args.ranges[i] = f.IndexedRanges[i]
+		}
+	}
+	for i := 0; i < numOfData; i++ {
+		values, err := valuesAt(f.Data, f.DataSets, i)
+		if err != nil {
+			return nil, err
+		}
+		if args.data[i], err = toBytes(pts[numOfIndexed+i], values); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+func logsBloomsOf(add func(lb *txresult.LogsBloom, idx int), n int) []module.LogsBloom {
+	lbs := make([]module.LogsBloom, n)
+	for i := range lbs {
+		lb := txresult.NewLogsBloom(nil)
+		add(lb, i)
+		lbs[i] = lb
+	}
+	return lbs
+}
+
+// Compile checks and compiles the filter. If the signature ends with "*",
+// then the filter matches events whose names start with the rest of it, and
+// arguments are compiled for each signature of matching events.
+func (f *EventFilter) Compile() error {
+	if len(f.Addrs) > maxEventFilterAddrs {
+		return errors.IllegalArgumentError.Errorf(
+			"TooManyAddresses(addrs=%d,max=%d)", len(f.Addrs), maxEventFilterAddrs)
+	}
+	for _, sets := range [][][]string{f.IndexedSets, f.DataSets} {
+		for i, set := range sets {
+			if len(set) > maxEventFilterValues {
+				return errors.IllegalArgumentError.Errorf(
+					"TooManyValues(idx=%d,values=%d,max=%d)", i, len(set), maxEventFilterValues)
+			}
+		}
+	}
+	f.addrs = nil
+	if f.Addr != nil {
+		f.addrs = append(f.addrs, f.Addr)
+	}
+	for _, addr := range f.Addrs {
+		if addr == nil {
+			return errors.IllegalArgumentError.New("InvalidAddress(addr=null)")
+		}
+		f.addrs = append(f.addrs, addr)
+	}
+
+	lb := txresult.NewLogsBloom(nil)
+	var groups [][]module.LogsBloom
+	if len(f.addrs) == 1 {
+		lb.AddAddressOfLog(f.addrs[0])
+	} else if len(f.addrs) > 1 {
+		groups = append(groups, logsBloomsOf(func(lb *txresult.LogsBloom, i int) {
+			lb.AddAddressOfLog(f.addrs[i])
+		}, len(f.addrs)))
+	}
+
+	f.prefix = ""
+	f.args = nil
+	f.argsMap = nil
+	if strings.HasSuffix(f.Signature, "*") {
+		f.prefix = strings.TrimSuffix(f.Signature, "*")
+		if len(f.prefix) == 0 || strings.ContainsAny(f.prefix, "(),*") {
+			return errors.NewBase(errors.IllegalArgumentError, "bad event signature")
+		}
+		f.argsMap = &eventArgsMap{args: make(map[string]*eventArgs)}
+	} else {
+		args, err := f.compileArgs(f.Signature)
+		if err != nil {
+			return err
+		}
+		lb.AddIndexedOfLog(0, []byte(f.Signature))
+		for i, values := range args.indexed {
+			if len(values) == 1 {
+				lb.AddIndexedOfLog(i+1, values[0])
+			} else if len(values) > 1 {
+				groups = append(groups, logsBloomsOf(func(lb *txresult.LogsBloom, j int) {
+					lb.AddIndexedOfLog(i+1, values[j])
+				}, len(values)))
 			}
-			f.dataBSs[i] = bs
 		}
-		idx++
+		f.args = args
 	}
 	f.lb = lb
+	f.lbGroups = groups
 	return nil
 }
 
+// ContainedIn returns whether the logs bloom may have events matching
+// the filter.
+func (f *EventFilter) ContainedIn(lb module.LogsBloom) bool {
+	if !lb.Contain(f.lb) {
+		return false
+	}
+	for _, group := range f.lbGroups {
+		contained := false
+		for _, item := range group {
+			if lb.Contain(item) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			return false
+		}
+	}
+	return true
+}
+
 // bytesEqual check equality of byte slice.
 // But it doesn't assume nil as empty bytes.
 func bytesEqual(b1 []byte, b2 []byte) bool {
@@ -267,38 +471,100 @@ func (f *EventFilter) MatchEvents(r module.Receipt, includeLogs bool) ([]common.
 	return indexes, logs, nil
 }
 
+// argsFor returns compiled arguments for the signature. It returns nil
+// if the signature doesn't match the filter.
+func (f *EventFilter) argsFor(sig []byte) *eventArgs {
+	if len(f.prefix) == 0 {
+		if f.args != nil && bytes.Equal([]byte(f.Signature), sig) {
+			return f.args
+		}
+		return nil
+	}
+	if !bytes.HasPrefix(sig, []byte(f.prefix)) {
+		return nil
+	}
+	f.argsMap.lock.Lock()
+	defer f.argsMap.lock.Unlock()
+	args, ok := f.argsMap.args[string(sig)]
+	if !ok {
+		if name, _ := txresult.DecomposeEventSignature(string(sig)); strings.HasPrefix(name, f.prefix) {
+			// signatures not compatible with arguments never match
+			args, _ = f.compileArgs(string(sig))
+		}
+		if len(f.argsMap.args) < maxEventArgsCache {
+			f.argsMap.args[string(sig)] = args
+		}
+	}
+	return args
+}
+
+func matchValue(values [][]byte, v []byte) bool {
+	if values == nil {
+		return true
+	}
+	for _, value := range values {
+		if bytesEqual(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
 func (f *EventFilter) MatchLog(el module.EventLog) bool {
-	if bytes.Equal([]byte(f.Signature), el.Indexed()[0]) {
-		if f.Addr != nil && !el.Address().Equal(f.Addr) {
+	indexed := el.Indexed()
+	if len(indexed) == 0 {
+		return false
+	}
+	args := f.argsFor(indexed[0])
+	if args == nil {
+		return false
+	}
+	if len(f.addrs) > 0 {
+		matched := false
+		for _, addr := range f.addrs {
+			if el.Address().Equal(addr) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(args.indexed)+len(args.data) > 0 {
+		if len(indexed) <= len(args.indexed) {
+			return false
+		}
+		if len(el.Data()) < len(args.data) {
 			return false
 		}
-		if f.numOfArgs > 0 {
-			if len(el.Indexed()) <= len(f.indexedBSs) {
+		for i, values := range args.indexed {
+			if !matchValue(values, indexed[i+1]) {
 				return false
 			}
-			if len(el.Data()) < len(f.dataBSs) {
+		}
+		for i, values := range args.data {
+			if !matchValue(values, el.Data()[i]) {
 				return false
 			}
-
-			for i, arg := range f.indexedBSs {
-				if arg != nil && !bytesEqual(arg, el.Indexed()[i+1]) {
-					return false
-				}
+		}
+		for i, r := range args.ranges {
+			if r == nil {
+				continue
 			}
-			for i, arg := range f.dataBSs {
-				if arg != nil && !bytesEqual(arg, el.Data()[i]) {
-					return false
-				}
+			if indexed[i+1] == nil {
+				return false
+			}
+			if !r.Contains(intconv.BigIntSetBytes(new(big.Int), indexed[i+1])) {
+				return false
 			}
 		}
-		return true
-	} else {
-		return false
 	}
+	return true
 }
 
 func (f *EventFilter) filterEvents(r module.Receipt, v func(idx int, log module.EventLog)) error {
-	if r.LogsBloom().Contain(f.lb) {
+	if f.ContainedIn(r.LogsBloom()) {
 		for it, idx := r.EventLogIterator(), 0; it.Has(); _, idx = it.Next(), idx+1 {
 			el, err := it.Get()
 			if err != nil {
@@ -707,3 +707,225 @@ func TestEventRequest_Compile(t *testing.T) {
 		})
 	}
 }
+
+func TestEventFilter_CompileExtended(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  EventFilter
+		wantErr bool
+	}{
+		{"Addrs", EventFilter{
+			Addr:      common.MustNewAddressFromString("cx01"),
+			Addrs:     []*common.Address{common.MustNewAddressFromString("cx02")},
+			Signature: "Transfer(Address,Address,int,bytes)",
+		}, false},
+		{"NullAddr", EventFilter{
+			Addrs:     []*common.Address{nil},
+			Signature: "Transfer(Address,Address,int,bytes)",
+		}, true},
+		{"IndexedSets", EventFilter{
+			Signature:   "Transfer(Address,Address,int,bytes)",
+			IndexedSets: [][]string{nil, {"hx0000000000000000000000000000000000000001", "hx0000000000000000000000000000000000000002"}},
+		}, false},
+		{"InvalidSetValue", EventFilter{
+			Signature:   "Transfer(Address,Address,int,bytes)",
+			IndexedSets: [][]string{{"hx00"}},
+		}, true},
+		{"ValueAndSet", EventFilter{
+			Signature:   "Transfer(Address,Address,int,bytes)",
+			Indexed:     []*string{stringPtr("hx0000000000000000000000000000000000000001")},
+			IndexedSets: [][]string{{"hx0000000000000000000000000000000000000002"}},
+		}, true},
+		{"TooManyDataSets", EventFilter{
+			Signature: "Transfer(Address,Address,int,bytes)",
+			DataSets:  [][]string{nil, nil, nil, nil, {"0x1"}},
+		}, true},
+		{"Range", EventFilter{
+			Signature:     "Transfer(Address,Address,int,bytes)",
+			IndexedRanges: []*EventValueRange{nil, nil, {GTE: common.NewHexInt(10)}},
+		}, false},
+		{"RangeForNonInteger", EventFilter{
+			Signature:     "Transfer(Address,Address,int,bytes)",
+			IndexedRanges: []*EventValueRange{{GTE: common.NewHexInt(10)}},
+		}, true},
+		{"TooManyAddrs", EventFilter{
+			Addrs:     make([]*common.Address, maxEventFilterAddrs+1),
+			Signature: "Transfer(Address,Address,int,bytes)",
+		}, true},
+		{"TooManyValues", EventFilter{
+			Signature: "Transfer(Address,Address,int,bytes)",
+			DataSets:  [][]string{make([]string, maxEventFilterValues+1)},
+		}, true},
+		{"WildcardTooManyValues", EventFilter{
+			Signature:   "Transfer*",
+			IndexedSets: [][]string{nil, make([]string, maxEventFilterValues+1)},
+		}, true},
+		{"Wildcard", EventFilter{Signature: "Transfer*"}, false},
+		{"InvalidWildcard1", EventFilter{Signature: "*"}, true},
+		{"InvalidWildcard2", EventFilter{Signature: "Transfer(*"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.filter
+			err := f.Compile()
+			assert.Equal(t, tt.wantErr, err != nil, "err=%v", err)
+		})
+	}
+}
+
+func TestEventFilter_MatchLogExtended(t *testing.T) {
+	const sig = "Transfer(Address,Address,int,bytes)"
+	const from = "hx0000000000000000000000000000000000000001"
+	const to1 = "hx0000000000000000000000000000000000000002"
+	const to2 = "hx0000000000000000000000000000000000000003"
+	transfer := func(addr, to, value string) *testEventLog {
+		return newTestEventLog(addr, sig,
+			[][]string{{"Address", from}, {"Address", to}, {"int", value}},
+			[][]string{{"bytes", "0x01"}})
+	}
+	tests := []struct {
+		name   string
+		filter EventFilter
+		log    *testEventLog
+		want   bool
+	}{
+		{"AddrsMatch", EventFilter{
+			Addrs:     []*common.Address{common.MustNewAddressFromString("cx01"), common.MustNewAddressFromString("cx02")},
+			Signature: sig,
+		}, transfer("cx02", to1, "0x1"), true},
+		{"AddrsMismatch", EventFilter{
+			Addrs:     []*common.Address{common.MustNewAddressFromString("cx01"), common.MustNewAddressFromString("cx02")},
+			Signature: sig,
+		}, transfer("cx03", to1, "0x1"), false},
+		{"AddrAndAddrs", EventFilter{
+			Addr:      common.MustNewAddressFromString("cx01"),
+			Addrs:     []*common.Address{common.MustNewAddressFromString("cx02")},
+			Signature: sig,
+		}, transfer("cx01", to1, "0x1"), true},
+		{"SetMatch", EventFilter{
+			Signature:   sig,
+			IndexedSets: [][]string{nil, {to1, to2}},
+		}, transfer("cx01", to2, "0x1"), true},
+		{"SetMismatch", EventFilter{
+			Signature:   sig,
+			IndexedSets: [][]string{nil, {to1}},
+		}, transfer("cx01", to2, "0x1"), false},
+		{"SetWithIndexed", EventFilter{
+			Signature:   sig,
+			Indexed:     []*string{stringPtr(from)},
+			IndexedSets: [][]string{nil, {to1, to2}},
+		}, transfer("cx01", to1, "0x1"), true},
+		{"DataSetMatch", EventFilter{
+			Signature: sig,
+			Indexed:   []*string{nil, nil, nil},
+			DataSets:  [][]string{{"0x02", "0x01"}},
+		}, transfer("cx01", to1, "0x1"), true},
+		{"DataSetMismatch", EventFilter{
+			Signature: sig,
+			Indexed:   []*string{nil, nil, nil},
+			DataSets:  [][]string{{"0x02", "0x03"}},
+		}, transfer("cx01", to1, "0x1"), false},
+		{"RangeMatch", EventFilter{
+			Signature:     sig,
+			IndexedRanges: []*EventValueRange{nil, nil, {GT: common.NewHexInt(10), LTE: common.NewHexInt(100)}},
+		}, transfer("cx01", to1, "0x64"), true},
+		{"RangeLower", EventFilter{
+			Signature:     sig,
+			IndexedRanges: []*EventValueRange{nil, nil, {GT: common.NewHexInt(10), LTE: common.NewHexInt(100)}},
+		}, transfer("cx01", to1, "0xa"), false},
+		{"RangeUpper", EventFilter{
+			Signature:     sig,
+			IndexedRanges: []*EventValueRange{nil, nil, {GT: common.NewHexInt(10), LT: common.NewHexInt(100)}},
+		}, transfer("cx01", to1, "0x64"), false},
+		{"RangeNegative", EventFilter{
+			Signature:     sig,
+			IndexedRanges: []*EventValueRange{nil, nil, {GTE: common.NewHexInt(-10)}},
+		}, transfer("cx01", to1, "-0x5"), true},
+		{"WildcardMatch", EventFilter{Signature: "Trans*"}, transfer("cx01", to1, "0x1"), true},
+		{"WildcardMismatch", EventFilter{Signature: "Approval*"}, transfer("cx01", to1, "0x1"), false},
+		{"WildcardWithSet", EventFilter{
+			Signature:   "Transfer*",
+			IndexedSets: [][]string{nil, {to1, to2}},
+		}, transfer("cx01", to1, "0x1"), true},
+		{"WildcardIncompatible", EventFilter{
+			Signature:     "Transfer*",
+			IndexedRanges: []*EventValueRange{{GTE: common.NewHexInt(10)}},
+		}, transfer("cx01", to1, "0x1"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.filter
+			assert.NoError(t, f.Compile())
+			assert.Equal(t, tt.want, f.MatchLog(tt.log))
+			// the result should be the same for the cached arguments.
+			assert.Equal(t, tt.want, f.MatchLog(tt.log))
+			if tt.want {
+				r := newTestReceipt([]*testEventLog{tt.log})
+				assert.True(t, f.ContainedIn(r.LogsBloom()))
+			}
+		})
+	}
+}
+
+func TestEventFilter_ArgsCache(t *testing.T) {
+	f := EventFilter{Signature: "Transfer*"}
+	assert.NoError(t, f.Compile())
+	for i := 0; i < maxEventArgsCache+10; i++ {
+		log := newTestEventLog("cx01", fmt.Sprintf("Transfer%d(int)", i),
+			[][]string{{"int", "0x1"}}, nil)
+		assert.True(t, f.MatchLog(log))
+	}
+	assert.Len(t, f.argsMap.args, maxEventArgsCache)
+}
+
+func TestEventFilter_ContainedIn(t *testing.T) {
+	const sig = "Transfer(Address,Address,int,bytes)"
+	const from = "hx0000000000000000000000000000000000000001"
+	el := newTestEventLog("cx01", sig,
+		[][]string{{"Address", from}, {"Address", from}, {"int", "0x1"}}, nil)
+	r := newTestReceipt([]*testEventLog{el})
+
+	f := &EventFilter{
+		Addrs:     []*common.Address{common.MustNewAddressFromString("cx02"), common.MustNewAddressFromString("cx03")},
+		Signature: sig,
+	}
+	assert.NoError(t, f.Compile())
+	assert.False(t, f.ContainedIn(r.LogsBloom()))
+
+	f = &EventFilter{
+		Signature:   sig,
+		IndexedSets: [][]string{{"hx0000000000000000000000000000000000000002", from}},
+	}
+	assert.NoError(t, f.Compile())
+	assert.True(t, f.ContainedIn(r.LogsBloom()))
+
+	f = &EventFilter{
+		Signature:   sig,
+		IndexedSets: [][]string{{"hx0000000000000000000000000000000000000002", "hx0000000000000000000000000000000000000003"}},
+	}
+	assert.NoError(t, f.Compile())
+	assert.False(t, f.ContainedIn(r.LogsBloom()))
+}
+
+func TestEventFilters_MatchEventsWithoutDuplicates(t *testing.T) {
+	const sig = "Transfer(Address,Address,int,bytes)"
+	var events []*testEventLog
+	var addrs []*common.Address
+	for i := 1; i <= 3; i++ {
+		addr := fmt.Sprintf("cx%02d", i)
+		addrs = append(addrs, common.MustNewAddressFromString(addr))
+		events = append(events, newTestEventLog(addr, sig,
+			[][]string{{"Address", "hx0000000000000000000000000000000000000001"}}, nil))
+	}
+	r := newTestReceipt(events)
+	fs := EventFilters{
+		{Addrs: addrs[:2], Signature: sig},
+		{Addr: addrs[1], Signature: "Trans*"},
+	}
+	for _, f := range fs {
+		assert.NoError(t, f.Compile())
+	}
+	indexes, _, err := fs.MatchEvents(r, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []common.HexInt32{{Value: 0}, {Value: 1}}, indexes)
+}
@@ -671,6 +671,10 @@ func (s *scoreStatus) ToJSON(height int64, version module.JSONVersion) (interfac
 	return ret, nil
 }
 
+func (s *scoreStatus) AvailableDeposit(height int64) (*big.Int, *big.Int) {
+	return s.ass.GetAvailableDeposit(height)
+}
+
 func (m *manager) GetSCOREStatus(result []byte, addr module.Address) (module.SCOREStatus, error) {
 	if !addr.IsContract() {
 		return nil, errors.IllegalArgumentError.Errorf("Given Address(%s) isn't contract", addr)
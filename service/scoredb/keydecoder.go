@@ -0,0 +1,152 @@
+/*
+ * Copyright 2023 ICON Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scoredb
+
+import (
+	"github.com/icon-project/goloop/common/containerdb"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/errors"
+)
+
+// MaxHashedKeys is the maximum number of hashed keys registered in a
+// KeyDecoder. It limits the cost of registering containers, especially
+// items of a large ArrayDB.
+const MaxHashedKeys = 10000
+
+// KeyInfo is the structure of a storage key. For ArrayDB, Keys has the
+// index of the item, and it's empty for the size of the array. For DictDB,
+// Keys has keys of the item.
+type KeyInfo struct {
+	Type   byte
+	Name   string
+	Keys   []interface{}
+	Hashed bool
+}
+
+func (i *KeyInfo) TypeName() string {
+	switch i.Type {
+	case ArrayDBPrefix:
+		return "array"
+	case DictDBPrefix:
+		return "dict"
+	case VarDBPrefix:
+		return "var"
+	default:
+		return "unknown"
+	}
+}
+
+// KeyDecoder decodes storage keys built with containerdb conventions.
+// Keys hashed with containerdb.HashBuilder can't be decoded by themselves,
+// so containers and candidate keys need to be registered for them.
+type KeyDecoder struct {
+	hashed map[string]*KeyInfo
+}
+
+func (d *KeyDecoder) reserve(n int) error {
+	if n > MaxHashedKeys-len(d.hashed) {
+		return errors.IllegalArgumentError.Errorf(
+			"TooManyKeys(keys=%d,add=%d,max=%d)", len(d.hashed), n, MaxHashedKeys)
+	}
+	return nil
+}
+
+func (d *KeyDecoder) add(info *KeyInfo) {
+	key := containerdb.ToKey(containerdb.HashBuilder, info.Type, info.Name).
+		Append(info.Keys...).Build()
+	info.Hashed = true
+	d.hashed[string(key)] = info
+}
+
+func (d *KeyDecoder) AddVarDB(name string) error {
+	if err := d.reserve(1); err != nil {
+		return err
+	}
+	d.add(&KeyInfo{Type: VarDBPrefix, Name: name})
+	return nil
+}
+
+// ArrayDBSizeKey returns the key for the size of the ArrayDB.
+func ArrayDBSizeKey(name string) []byte {
+	return containerdb.ToKey(containerdb.HashBuilder, ArrayDBPrefix, name).Build()
+}
+
+// AddArrayDB registers the size and items of the ArrayDB. It returns an error
+// if the size is negative or items exceed MaxHashedKeys.
+func (d *KeyDecoder) AddArrayDB(name string, size int64) error {
+	if size < 0 {
+		return errors.IllegalArgumentError.Errorf(
+			"InvalidArraySize(name=%s,size=%d)", name, size)
+	}
+	if size >= MaxHashedKeys {
+		return errors.IllegalArgumentError.Errorf(
+			"TooLargeArray(name=%s,size=%d,max=%d)", name, size, MaxHashedKeys-1)
+	}
+	if err := d.reserve(int(size) + 1); err != nil {
+		return err
+	}
+	d.add(&KeyInfo{Type: ArrayDBPrefix, Name: name})
+	for i := 0; i < int(size); i++ {
+		d.add(&KeyInfo{Type: ArrayDBPrefix, Name: name, Keys: []interface{}{i}})
+	}
+	return nil
+}
+
+// AddDictDB registers an item of the DictDB with the keys.
+func (d *KeyDecoder) AddDictDB(name string, keys ...interface{}) error {
+	if err := d.reserve(1); err != nil {
+		return err
+	}
+	d.add(&KeyInfo{Type: DictDBPrefix, Name: name, Keys: keys})
+	return nil
+}
+
+// Decode returns the structure of the key. Hashed keys are decoded only if
+// they are registered. Others are decoded if they are built with
+// containerdb.RLPBuilder. It returns nil if it fails to decode.
+func (d *KeyDecoder) Decode(key []byte) *KeyInfo {
+	if info, ok := d.hashed[string(key)]; ok {
+		return info
+	}
+	if len(key) == crypto.HashLen {
+		return nil
+	}
+	parts, err := containerdb.SplitKeys(key)
+	if err != nil || len(parts) < 2 || len(parts[0]) != 1 {
+		return nil
+	}
+	switch t := parts[0][0]; t {
+	case ArrayDBPrefix, DictDBPrefix, VarDBPrefix:
+		keys := make([]interface{}, len(parts)-2)
+		for i, k := range parts[2:] {
+			keys[i] = k
+		}
+		return &KeyInfo{
+			Type: t,
+			Name: string(parts[1]),
+			Keys: keys,
+		}
+	default:
+		return nil
+	}
+}
+
+func NewKeyDecoder() *KeyDecoder {
+	return &KeyDecoder{
+		hashed: make(map[string]*KeyInfo),
+	}
+}
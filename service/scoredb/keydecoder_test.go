@@ -0,0 +1,82 @@
+package scoredb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/containerdb"
+)
+
+type mapStore map[string][]byte
+
+func (s mapStore) GetValue(key []byte) ([]byte, error) {
+	return s[string(key)], nil
+}
+
+func (s mapStore) SetValue(key []byte, value []byte) ([]byte, error) {
+	old := s[string(key)]
+	s[string(key)] = value
+	return old, nil
+}
+
+func (s mapStore) DeleteValue(key []byte) ([]byte, error) {
+	old := s[string(key)]
+	delete(s, string(key))
+	return old, nil
+}
+
+func TestKeyDecoder_Decode(t *testing.T) {
+	store := make(mapStore)
+	holder := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+
+	assert.NoError(t, NewVarDB(store, "owner").Set(holder))
+	arr := NewArrayDB(store, "holders")
+	assert.NoError(t, arr.Put(holder))
+	assert.NoError(t, arr.Put("second"))
+	assert.NoError(t, NewDictDB(store, "balances", 1).Set(holder, 100))
+	assert.NoError(t, NewDictDB(store, "allowance", 2).Set(holder, "spender", 1))
+	rlpKey := containerdb.ToKey(containerdb.RLPBuilder, DictDBPrefix, "raw", 7).Build()
+	_, _ = store.SetValue(rlpKey, []byte{1})
+
+	d := NewKeyDecoder()
+	assert.NoError(t, d.AddVarDB("owner"))
+	assert.NoError(t, d.AddArrayDB("holders", 2))
+	assert.NoError(t, d.AddDictDB("balances", holder))
+	assert.NoError(t, d.AddDictDB("allowance", holder, "spender"))
+
+	found := map[string]*KeyInfo{}
+	for k := range store {
+		info := d.Decode([]byte(k))
+		if assert.NotNil(t, info, "key=%x", k) {
+			found[info.TypeName()+":"+info.Name+":"+string(containerdb.AppendKeys(nil, info.Keys...))] = info
+		}
+	}
+	assert.Len(t, found, 7)
+	assert.Contains(t, found, "var:owner:")
+	assert.Contains(t, found, "array:holders:")
+	assert.Contains(t, found, "array:holders:"+string(containerdb.AppendKeys(nil, 1)))
+	assert.Contains(t, found, "dict:balances:"+string(containerdb.AppendKeys(nil, holder)))
+
+	info := d.Decode(rlpKey)
+	assert.False(t, info.Hashed)
+	assert.Equal(t, "raw", info.Name)
+	assert.Equal(t, []interface{}{[]byte{7}}, info.Keys)
+
+	assert.Nil(t, d.Decode(make([]byte, 32)))
+	assert.Nil(t, d.Decode([]byte{0x05, 0x81}))
+}
+
+func TestKeyDecoder_AddArrayDB(t *testing.T) {
+	d := NewKeyDecoder()
+	assert.Error(t, d.AddArrayDB("negative", -1))
+	assert.Error(t, d.AddArrayDB("large", MaxHashedKeys))
+	assert.Nil(t, d.Decode(ArrayDBSizeKey("large")))
+
+	assert.NoError(t, d.AddArrayDB("holders", MaxHashedKeys-2))
+	assert.NotNil(t, d.Decode(ArrayDBSizeKey("holders")))
+	assert.NoError(t, d.AddVarDB("owner"))
+	assert.Error(t, d.AddVarDB("name"))
+	assert.Error(t, d.AddArrayDB("empty", 0))
+}
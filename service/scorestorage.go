@@ -0,0 +1,148 @@
+package service
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/state"
+)
+
+const (
+	defaultStorageLimit = 100
+	maxStorageLimit     = 1000
+)
+
+func storageKeyToJSON(k interface{}) interface{} {
+	switch v := k.(type) {
+	case module.Address:
+		return v.String()
+	case string:
+		return v
+	case int:
+		return intconv.FormatInt(int64(v))
+	case *big.Int:
+		return intconv.FormatBigInt(v)
+	case []byte:
+		return hexOf(v)
+	default:
+		return v
+	}
+}
+
+func keyInfoToJSON(info *scoredb.KeyInfo) interface{} {
+	jso := map[string]interface{}{
+		"type":   info.TypeName(),
+		"name":   info.Name,
+		"hashed": info.Hashed,
+	}
+	if info.Type == scoredb.ArrayDBPrefix {
+		if len(info.Keys) > 0 {
+			jso["index"] = storageKeyToJSON(info.Keys[0])
+		} else {
+			jso["size"] = true
+		}
+		return jso
+	}
+	if len(info.Keys) > 0 {
+		keys := make([]interface{}, len(info.Keys))
+		for i, k := range info.Keys {
+			keys[i] = storageKeyToJSON(k)
+		}
+		jso["keys"] = keys
+	}
+	return jso
+}
+
+func newStorageKeyDecoder(ass state.AccountSnapshot, containers []*module.StorageContainer) (*scoredb.KeyDecoder, error) {
+	d := scoredb.NewKeyDecoder()
+	for _, c := range containers {
+		switch c.Type {
+		case "var":
+			if err := d.AddVarDB(c.Name); err != nil {
+				return nil, err
+			}
+		case "array":
+			bs, err := ass.GetValue(scoredb.ArrayDBSizeKey(c.Name))
+			if err != nil {
+				return nil, err
+			}
+			if err := d.AddArrayDB(c.Name, intconv.BytesToInt64(bs)); err != nil {
+				return nil, err
+			}
+		case "dict":
+			for _, keys := range c.Keys {
+				if err := d.AddDictDB(c.Name, keys...); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, errors.IllegalArgumentError.Errorf(
+				"InvalidContainerType(type=%s)", c.Type)
+		}
+	}
+	return d, nil
+}
+
+func (m *manager) GetStorage(result []byte, addr module.Address, start []byte, limit int, containers []*module.StorageContainer) (interface{}, error) {
+	if limit <= 0 {
+		limit = defaultStorageLimit
+	} else if limit > maxStorageLimit {
+		return nil, errors.IllegalArgumentError.Errorf(
+			"InvalidLimit(limit=%d,max=%d)", limit, maxStorageLimit)
+	}
+	wss, err := m.trc.GetWorldSnapshot(result, nil)
+	if err != nil {
+		return nil, err
+	}
+	ass := wss.GetAccountSnapshot(addr.ID())
+	if ass == nil || !ass.IsContract() {
+		return nil, errors.NotFoundError.Errorf("ContractNotFound(addr=%s)", addr)
+	}
+	d, err := newStorageKeyDecoder(ass, containers)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []interface{}{}
+	var next []byte
+	if itr := state.StorageIteratorOf(ass, start); itr != nil {
+		for ; itr.Has(); err = itr.Next() {
+			if err != nil {
+				return nil, err
+			}
+			value, key, err := itr.Get()
+			if err != nil {
+				return nil, err
+			}
+			if bytes.Compare(key, start) < 0 {
+				continue
+			}
+			if len(entries) >= limit {
+				next = key
+				break
+			}
+			entry := map[string]interface{}{
+				"key":   hexOf(key),
+				"value": hexOf(value),
+			}
+			if info := d.Decode(key); info != nil {
+				entry["container"] = keyInfoToJSON(info)
+			}
+			entries = append(entries, entry)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	res := map[string]interface{}{
+		"entries": entries,
+	}
+	if next != nil {
+		res["next"] = hexOf(next)
+	}
+	return res, nil
+}
@@ -53,6 +53,7 @@ type AccountData interface {
 	CheckDeposit(pc PayContext) bool
 	GetObjGraph(hash []byte, flags bool) (int, []byte, []byte, error)
 	GetDepositInfo(dc DepositContext, v module.JSONVersion) (map[string]interface{}, error)
+	GetAvailableDeposit(height int64) (*big.Int, *big.Int)
 }
 
 // AccountSnapshot represents immutable account state
@@ -210,6 +211,12 @@ func (s *accountData) GetDepositInfo(dc DepositContext, v module.JSONVersion) (
 	return s.deposits.ToJSON(dc, v)
 }
 
+// GetAvailableDeposit returns the usable deposit and the available virtual
+// steps at the height.
+func (s *accountData) GetAvailableDeposit(height int64) (*big.Int, *big.Int) {
+	return s.deposits.getAvailable(height)
+}
+
 type accountSnapshotImpl struct {
 	accountData
 	objGraph *objectGraph
@@ -493,6 +500,24 @@ func (s *accountSnapshotImpl) Store() trie.Immutable {
 	return store
 }
 
+type storageSeeker interface {
+	Seek(start []byte) trie.Iterator
+}
+
+// StorageIteratorOf returns the iterator for the storage of the account
+// in the order of keys starting from the start key. It may return smaller
+// keys if the storage can't seek, so the user should skip them. It returns
+// nil if the account has no storage.
+func StorageIteratorOf(ass AccountSnapshot, start []byte) trie.Iterator {
+	if store := accountSnapshotOf(ass).Store(); store != nil {
+		if s, ok := store.(storageSeeker); ok && len(start) > 0 {
+			return s.Seek(start)
+		}
+		return store.Iterator()
+	}
+	return nil
+}
+
 func newAccountSnapshot(dbase db.Database) *accountSnapshotImpl {
 	return &accountSnapshotImpl{
 		accountData: accountData{
@@ -0,0 +1,70 @@
+package state
+
+import (
+	"bytes"
+
+	"github.com/icon-project/goloop/common/trie"
+)
+
+const (
+	AccountDiffBalance      = "balance"
+	AccountDiffState        = "state"
+	AccountDiffOwner        = "owner"
+	AccountDiffContract     = "contract"
+	AccountDiffNextContract = "nextContract"
+	AccountDiffAPIInfo      = "apiInfo"
+	AccountDiffObjGraph     = "objGraph"
+	AccountDiffDeposits     = "deposits"
+	AccountDiffStorage      = "storage"
+)
+
+func accountSnapshotOf(ass AccountSnapshot) *accountSnapshotImpl {
+	if s, ok := ass.(*accountSnapshotImpl); ok && s != nil {
+		return s
+	}
+	return newAccountSnapshot(nil)
+}
+
+// DiffAccountSnapshots returns names of the parts different between two
+// account snapshots. nil is regarded as an empty account.
+func DiffAccountSnapshots(ass1, ass2 AccountSnapshot) []string {
+	s1 := accountSnapshotOf(ass1)
+	s2 := accountSnapshotOf(ass2)
+
+	var diff []string
+	if s1.balance.Cmp(s2.balance) != 0 {
+		diff = append(diff, AccountDiffBalance)
+	}
+	if s1.isContract != s2.isContract || s1.state != s2.state {
+		diff = append(diff, AccountDiffState)
+	}
+	if !s1.contractOwner.Equal(s2.contractOwner) {
+		diff = append(diff, AccountDiffOwner)
+	}
+	if !s1.curContract.Equal(s2.curContract) {
+		diff = append(diff, AccountDiffContract)
+	}
+	if !s1.nextContract.Equal(s2.nextContract) {
+		diff = append(diff, AccountDiffNextContract)
+	}
+	if !s1.apiInfo.Equal(&s2.apiInfo) {
+		diff = append(diff, AccountDiffAPIInfo)
+	}
+	if !s1.objGraph.Equal(s2.objGraph) {
+		diff = append(diff, AccountDiffObjGraph)
+	}
+	if !s1.deposits.Equal(s2.deposits) {
+		diff = append(diff, AccountDiffDeposits)
+	}
+	if !bytes.Equal(storageHashOf(s1), storageHashOf(s2)) {
+		diff = append(diff, AccountDiffStorage)
+	}
+	return diff
+}
+
+func storageHashOf(s *accountSnapshotImpl) []byte {
+	if store, ok := s.store.(trie.Immutable); ok && store != nil {
+		return store.Hash()
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+)
+
+func TestDiffAccountSnapshots(t *testing.T) {
+	ws := NewWorldState(db.NewMapDB(), nil, nil, nil, nil)
+	id := []byte("test")
+
+	ass0 := ws.GetAccountSnapshot(id)
+	assert.Empty(t, DiffAccountSnapshots(nil, ass0))
+
+	as := ws.GetAccountState(id)
+	as.SetBalance(big.NewInt(100))
+	ass1 := ws.GetSnapshot().GetAccountSnapshot(id)
+	assert.Equal(t, []string{AccountDiffBalance}, DiffAccountSnapshots(ass0, ass1))
+	assert.Equal(t, []string{AccountDiffBalance}, DiffAccountSnapshots(ass1, nil))
+
+	_, err := as.SetValue([]byte("key"), []byte("value"))
+	assert.NoError(t, err)
+	ass2 := ws.GetSnapshot().GetAccountSnapshot(id)
+	assert.Equal(t, []string{AccountDiffStorage}, DiffAccountSnapshots(ass1, ass2))
+
+	assert.True(t, as.InitContractAccount(common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")))
+	ass3 := ws.GetSnapshot().GetAccountSnapshot(id)
+	assert.Equal(t,
+		[]string{AccountDiffState, AccountDiffOwner},
+		DiffAccountSnapshots(ass2, ass3))
+	assert.Empty(t, DiffAccountSnapshots(ass3, ws.GetAccountSnapshot(id)))
+}
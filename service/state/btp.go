@@ -576,6 +576,24 @@ func (bs *BTPStateImpl) HandleMessage(bc BTPContext, from module.Address, nid in
 	return sn, nil
 }
 
+// GetPublicKeyOwner returns the address having the public key of the DSA.
+// It returns nil if no one has the public key.
+func GetPublicKeyOwner(bc BTPContext, name string, pubKey []byte) (module.Address, error) {
+	dsa := ntm.DSAModuleForName(name)
+	if dsa == nil {
+		return nil, scoreresult.InvalidParameterError.Errorf("Invalid name %s", name)
+	}
+	publicKey, err := dsa.Canonicalize(pubKey)
+	if err != nil {
+		return nil, scoreresult.InvalidParameterError.Errorf("Invalid pubKey %+v", err)
+	}
+	ownerDB := scoredb.NewDictDB(bc.Store(), PubKeyOwner, 1)
+	if value := ownerDB.Get(crypto.SHA3Sum256(publicKey)); value != nil {
+		return value.Address(), nil
+	}
+	return nil, nil
+}
+
 func (bs *BTPStateImpl) SetPublicKey(bc BTPContext, from module.Address, name string, pubKey []byte) error {
 	dsa := ntm.DSAModuleForName(name)
 	if dsa == nil {
@@ -189,6 +189,18 @@ func (dl *depositList) PaySteps(pc PayContext, steps *big.Int) (*big.Int, *big.I
 	return paidSteps, stepsByDeposit
 }
 
+// getAvailable returns the usable deposit and the available virtual steps
+// at the height, which are shown in ToJSON.
+func (dl depositList) getAvailable(bh int64) (*big.Int, *big.Int) {
+	deposit := new(big.Int)
+	steps := new(big.Int)
+	for _, dp := range dl {
+		deposit.Add(deposit, dp.GetUsableDeposit(bh))
+		steps.Add(steps, dp.GetAvailableSteps(bh))
+	}
+	return deposit, steps
+}
+
 func (dl depositList) ToJSON(dc DepositContext, v module.JSONVersion) (map[string]interface{}, error) {
 	if len(dl) == 0 {
 		return nil, nil
@@ -21,6 +21,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
 )
 
 const (
@@ -522,3 +525,25 @@ func TestDepositList_WithdrawDepositV1ToV2(t *testing.T) {
 		assert.False(t, dl.CanPay(dc))
 	})
 }
+
+func TestDepositList_GetAvailable(t *testing.T) {
+	dc := &depositContext{
+		rate:   depositIssueRate,
+		price:  big.NewInt(100),
+		height: 10,
+		period: 0,
+		tid:    []byte{0x00},
+	}
+	dl := newDepositList()
+	deposit, steps := dl.getAvailable(dc.height)
+	assert.Equal(t, 0, deposit.Sign())
+	assert.Equal(t, 0, steps.Sign())
+
+	assert.NoError(t, dl.AddDeposit(dc, big.NewInt(50000)))
+	deposit, steps = dl.getAvailable(dc.height)
+	jso, err := dl.ToJSON(dc, module.JSONVersion3)
+	assert.NoError(t, err)
+	assert.Equal(t, intconv.FormatBigInt(deposit), jso["availableDeposit"])
+	assert.Equal(t, intconv.FormatBigInt(steps), jso["availableVirtualStep"])
+	assert.Equal(t, int64(50000), deposit.Int64())
+}
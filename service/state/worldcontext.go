@@ -371,6 +371,7 @@ func (c *worldContext) WorldStateChanged(ws WorldState) WorldContext {
 		blockInfo:    c.blockInfo,
 		csInfo:       c.csInfo,
 		platform:     c.platform,
+		dsDecoder:    c.dsDecoder,
 	}
 	return wc
 }
@@ -512,7 +513,7 @@ func NewWorldContext(ws WorldState, bi module.BlockInfo, csi module.ConsensusInf
 		blockInfo:    bi,
 		csInfo:       csi,
 		platform:     plt,
-		dsDecoder: 	  getDoubleSignDataDecoder(plt),
+		dsDecoder:    getDoubleSignDataDecoder(plt),
 	}
 	ws.EnableAccountNodeCache(SystemID)
 	wc.UpdateSystemInfo()
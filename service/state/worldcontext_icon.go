@@ -19,7 +19,11 @@ package state
 import "github.com/icon-project/goloop/common"
 
 const (
-	VarBlockedScores  = "blocked_scores"
+	VarBlockedScores       = "blocked_scores"
+	VarParamNames          = "param_names"
+	VarParamHistory        = "param_history"
+	VarParamSnapshot       = "param_snapshot"
+	VarParamSnapshotHeight = "param_snapshot_height"
 )
 
 const (
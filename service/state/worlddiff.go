@@ -0,0 +1,142 @@
+package state
+
+import (
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/trie"
+	"github.com/icon-project/goloop/common/trie/trie_manager"
+	"github.com/icon-project/goloop/module"
+)
+
+const (
+	DiffRemoved = -1
+	DiffChanged = 0
+	DiffAdded   = 1
+)
+
+// StorageDiff is a difference of a storage key of the account.
+type StorageDiff struct {
+	Op       int
+	Key      []byte
+	Old, New []byte
+}
+
+// AccountKeyOf returns the key of the account in the world state for the
+// address. It's used to find the address of AccountDiff.
+func AccountKeyOf(addr module.Address) []byte {
+	return addressIDToKey(addr.ID())
+}
+
+// AccountDiff is a difference of an account between two world snapshots.
+// Key is the key of the account in the world state (SHA3 of the address
+// ID), so the address can be found only with AccountKeyOf.
+// Old or New is nil if the account doesn't exist in the snapshot.
+type AccountDiff struct {
+	Op       int
+	Key      []byte
+	Parts    []string
+	Old, New AccountSnapshot
+	Storage  []*StorageDiff
+}
+
+// ExtensionDiff is a difference of a key in a part of the extension state.
+type ExtensionDiff struct {
+	Part     string
+	Op       int
+	Key      []byte
+	Old, New []byte
+}
+
+type ExtensionDiffHandler func(d *ExtensionDiff) error
+
+// ExtensionSnapshotDiffer is implemented by ExtensionSnapshot supporting
+// comparison with another snapshot of the same type.
+type ExtensionSnapshotDiffer interface {
+	DiffWith(es ExtensionSnapshot, handler ExtensionDiffHandler) error
+}
+
+type WorldDiffHandler interface {
+	OnAccount(d *AccountDiff) error
+	OnExtension(d *ExtensionDiff) error
+}
+
+func diffStorage(ass1, ass2 *accountSnapshotImpl, database trie.Immutable) ([]*StorageDiff, error) {
+	s1, s2 := ass1.Store(), ass2.Store()
+	if s1 == nil && s2 == nil {
+		return nil, nil
+	}
+	if s1 == nil {
+		s1 = database
+	}
+	if s2 == nil {
+		s2 = database
+	}
+	var diffs []*StorageDiff
+	err := trie_manager.DiffImmutable(s1, s2, func(op int, key []byte, v1, v2 []byte) error {
+		diffs = append(diffs, &StorageDiff{
+			Op:  op,
+			Key: key,
+			Old: v1,
+			New: v2,
+		})
+		return nil
+	})
+	return diffs, err
+}
+
+func accountSnapshotFromObject(o trie.Object) (*accountSnapshotImpl, error) {
+	if o == nil {
+		return nil, nil
+	}
+	if ass, ok := o.(*accountSnapshotImpl); ok {
+		return ass, nil
+	}
+	return nil, errors.InvalidStateError.Errorf("InvalidAccountObject(type=%T)", o)
+}
+
+// DiffWorldSnapshots reports changed accounts and extension state between
+// two world snapshots. Sub-tries with same hash are skipped, so it takes
+// time proportional to the amount of changes.
+func DiffWorldSnapshots(wss1, wss2 WorldSnapshot, handler WorldDiffHandler) error {
+	ws1, ok1 := wss1.(*worldSnapshotImpl)
+	ws2, ok2 := wss2.(*worldSnapshotImpl)
+	if !ok1 || !ok2 {
+		return errors.IllegalArgumentError.Errorf(
+			"UnsupportedWorldSnapshot(ws1=%T,ws2=%T)", wss1, wss2)
+	}
+	empty := trie_manager.NewImmutable(ws1.database, nil)
+	err := trie_manager.DiffImmutableForObject(ws1.accounts, ws2.accounts,
+		func(op int, key []byte, o1, o2 trie.Object) error {
+			ass1, err := accountSnapshotFromObject(o1)
+			if err != nil {
+				return err
+			}
+			ass2, err := accountSnapshotFromObject(o2)
+			if err != nil {
+				return err
+			}
+			d := &AccountDiff{
+				Op:    op,
+				Key:   key,
+				Parts: DiffAccountSnapshots(ass1, ass2),
+			}
+			if ass1 != nil {
+				d.Old = ass1
+			}
+			if ass2 != nil {
+				d.New = ass2
+			}
+			if d.Storage, err = diffStorage(accountSnapshotOf(d.Old),
+				accountSnapshotOf(d.New), empty); err != nil {
+				return err
+			}
+			return handler.OnAccount(d)
+		})
+	if err != nil {
+		return err
+	}
+
+	if differ, ok := ws1.extension.(ExtensionSnapshotDiffer); ok && ws2.extension != nil {
+		return differ.DiffWith(ws2.extension, handler.OnExtension)
+	}
+	return nil
+}
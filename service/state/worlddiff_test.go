@@ -0,0 +1,97 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+)
+
+type testWorldDiffHandler struct {
+	accounts  []*AccountDiff
+	extension []*ExtensionDiff
+}
+
+func (h *testWorldDiffHandler) OnAccount(d *AccountDiff) error {
+	h.accounts = append(h.accounts, d)
+	return nil
+}
+
+func (h *testWorldDiffHandler) OnExtension(d *ExtensionDiff) error {
+	h.extension = append(h.extension, d)
+	return nil
+}
+
+func TestDiffWorldSnapshots(t *testing.T) {
+	database := db.NewMapDB()
+	ws := NewWorldState(database, nil, nil, nil, nil)
+	for i := 0; i < 100; i++ {
+		as := ws.GetAccountState([]byte{byte(i)})
+		as.SetBalance(big.NewInt(int64(i + 1)))
+	}
+	as := ws.GetAccountState([]byte("contract"))
+	_, err := as.SetValue([]byte("k1"), []byte("v1"))
+	assert.NoError(t, err)
+	_, err = as.SetValue([]byte("k2"), []byte("v2"))
+	assert.NoError(t, err)
+	wss1 := ws.GetSnapshot()
+	assert.NoError(t, wss1.Flush())
+
+	h := new(testWorldDiffHandler)
+	assert.NoError(t, DiffWorldSnapshots(wss1, ws.GetSnapshot(), h))
+	assert.Empty(t, h.accounts)
+
+	ws.GetAccountState([]byte{7}).SetBalance(big.NewInt(100))
+	ws.GetAccountState([]byte("new")).SetBalance(big.NewInt(1))
+	_, err = as.SetValue([]byte("k1"), []byte("v3"))
+	assert.NoError(t, err)
+	_, err = as.DeleteValue([]byte("k2"))
+	assert.NoError(t, err)
+	_, err = as.SetValue([]byte("k4"), []byte("v4"))
+	assert.NoError(t, err)
+	wss2 := ws.GetSnapshot()
+	assert.NoError(t, wss2.Flush())
+
+	h = new(testWorldDiffHandler)
+	assert.NoError(t, DiffWorldSnapshots(wss1, wss2, h))
+	assert.Len(t, h.accounts, 3)
+	byKey := make(map[string]*AccountDiff)
+	for _, d := range h.accounts {
+		byKey[string(d.Key)] = d
+	}
+
+	d := byKey[string(addressIDToKey([]byte{7}))]
+	if assert.NotNil(t, d) {
+		assert.Equal(t, DiffChanged, d.Op)
+		assert.Equal(t, []string{AccountDiffBalance}, d.Parts)
+		assert.Equal(t, int64(8), d.Old.GetBalance().Int64())
+		assert.Equal(t, int64(100), d.New.GetBalance().Int64())
+		assert.Empty(t, d.Storage)
+	}
+
+	d = byKey[string(addressIDToKey([]byte("new")))]
+	if assert.NotNil(t, d) {
+		assert.Equal(t, DiffAdded, d.Op)
+		assert.Nil(t, d.Old)
+	}
+
+	d = byKey[string(addressIDToKey([]byte("contract")))]
+	if assert.NotNil(t, d) {
+		assert.Equal(t, DiffChanged, d.Op)
+		assert.Equal(t, []string{AccountDiffStorage}, d.Parts)
+		assert.Len(t, d.Storage, 3)
+		ops := make(map[string]*StorageDiff)
+		for _, sd := range d.Storage {
+			ops[string(sd.Key)] = sd
+		}
+		assert.Equal(t, &StorageDiff{DiffChanged, []byte("k1"), []byte("v1"), []byte("v3")}, ops["k1"])
+		assert.Equal(t, &StorageDiff{DiffRemoved, []byte("k2"), []byte("v2"), nil}, ops["k2"])
+		assert.Equal(t, &StorageDiff{DiffAdded, []byte("k4"), nil, []byte("v4")}, ops["k4"])
+	}
+
+	h = new(testWorldDiffHandler)
+	assert.NoError(t, DiffWorldSnapshots(wss2, wss1, h))
+	assert.Len(t, h.accounts, 3)
+}
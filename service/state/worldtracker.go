@@ -0,0 +1,218 @@
+package state
+
+import (
+	"bytes"
+)
+
+// WorldTracker is a WorldState recording accounts and world level states
+// (validators, extension and BTP) accessed through it.
+type WorldTracker interface {
+	WorldState
+	Changes() *WorldChanges
+}
+
+// WorldChanges is the read set and the write set recorded by WorldTracker.
+type WorldChanges struct {
+	reads    map[string]struct{}
+	accounts map[string]AccountSnapshot
+
+	worldRead  bool
+	validators ValidatorSnapshot
+	extension  ExtensionSnapshot
+	btp        BTPSnapshot
+}
+
+func (c *WorldChanges) hasWorldWrite() bool {
+	return c.validators != nil || c.extension != nil || c.btp != nil
+}
+
+// Conflicts returns whether it read any state written by the other.
+func (c *WorldChanges) Conflicts(o *WorldChanges) bool {
+	if c.worldRead && o.hasWorldWrite() {
+		return true
+	}
+	if len(c.reads) > len(o.accounts) {
+		for id := range o.accounts {
+			if _, ok := c.reads[id]; ok {
+				return true
+			}
+		}
+	} else {
+		for id := range c.reads {
+			if _, ok := o.accounts[id]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteCount returns the number of written accounts.
+func (c *WorldChanges) WriteCount() int {
+	return len(c.accounts)
+}
+
+// Apply applies written states to the world state.
+func (c *WorldChanges) Apply(ws WorldState) error {
+	for id, ass := range c.accounts {
+		if err := ws.GetAccountState([]byte(id)).Reset(ass); err != nil {
+			return err
+		}
+	}
+	if c.validators != nil {
+		ws.GetValidatorState().Reset(c.validators)
+	}
+	if c.extension != nil {
+		if es := ws.GetExtensionState(); es != nil {
+			es.Reset(c.extension)
+		}
+	}
+	if c.btp != nil {
+		ws.GetBTPState().Reset(c.btp)
+	}
+	return nil
+}
+
+type worldTracker struct {
+	WorldState
+
+	// snapshots on the first access
+	accounts   map[string]AccountSnapshot
+	worldRead  bool
+	validators ValidatorSnapshot
+	extension  ExtensionSnapshot
+	btp        BTPSnapshot
+}
+
+func (wt *worldTracker) touchAccount(id []byte) {
+	if _, ok := wt.accounts[string(id)]; !ok {
+		wt.accounts[string(id)] = wt.WorldState.GetAccountSnapshot(id)
+	}
+}
+
+func (wt *worldTracker) touchWorld() {
+	if wt.worldRead {
+		return
+	}
+	wt.worldRead = true
+	wt.validators = wt.WorldState.GetValidatorState().GetSnapshot()
+	if es := wt.WorldState.GetExtensionState(); es != nil {
+		wt.extension = es.GetSnapshot()
+	}
+	wt.btp = wt.WorldState.GetBTPState().GetSnapshot()
+}
+
+func (wt *worldTracker) GetAccountState(id []byte) AccountState {
+	wt.touchAccount(id)
+	return wt.WorldState.GetAccountState(id)
+}
+
+func (wt *worldTracker) GetAccountSnapshot(id []byte) AccountSnapshot {
+	wt.touchAccount(id)
+	return wt.WorldState.GetAccountSnapshot(id)
+}
+
+func (wt *worldTracker) GetValidatorState() ValidatorState {
+	wt.touchWorld()
+	return wt.WorldState.GetValidatorState()
+}
+
+func (wt *worldTracker) GetExtensionState() ExtensionState {
+	wt.touchWorld()
+	return wt.WorldState.GetExtensionState()
+}
+
+func (wt *worldTracker) GetBTPState() BTPState {
+	wt.touchWorld()
+	return wt.WorldState.GetBTPState()
+}
+
+func (wt *worldTracker) GetSnapshot() WorldSnapshot {
+	return &trackedWorldSnapshot{
+		WorldSnapshot: wt.WorldState.GetSnapshot(),
+		tracker:       wt,
+	}
+}
+
+func (wt *worldTracker) Reset(snapshot WorldSnapshot) error {
+	if tss, ok := snapshot.(*trackedWorldSnapshot); ok {
+		snapshot = tss.WorldSnapshot
+	}
+	return wt.WorldState.Reset(snapshot)
+}
+
+func accountSnapshotEqual(a, b AccountSnapshot) bool {
+	if a == nil || b == nil {
+		return (a == nil || a.IsEmpty()) && (b == nil || b.IsEmpty())
+	}
+	return a.Equal(b)
+}
+
+func extensionSnapshotEqual(a, b ExtensionSnapshot) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return bytes.Equal(a.Bytes(), b.Bytes())
+}
+
+func (wt *worldTracker) Changes() *WorldChanges {
+	c := &WorldChanges{
+		reads:     make(map[string]struct{}, len(wt.accounts)),
+		accounts:  make(map[string]AccountSnapshot),
+		worldRead: wt.worldRead,
+	}
+	for id, ass := range wt.accounts {
+		c.reads[id] = struct{}{}
+		if cur := wt.WorldState.GetAccountSnapshot([]byte(id)); !accountSnapshotEqual(ass, cur) {
+			c.accounts[id] = cur
+		}
+	}
+	if wt.worldRead {
+		if vss := wt.WorldState.GetValidatorState().GetSnapshot(); !bytes.Equal(vss.Hash(), wt.validators.Hash()) {
+			c.validators = vss
+		}
+		if es := wt.WorldState.GetExtensionState(); es != nil {
+			if ess := es.GetSnapshot(); !extensionSnapshotEqual(ess, wt.extension) {
+				c.extension = ess
+			}
+		}
+		if bss := wt.WorldState.GetBTPState().GetSnapshot(); !bytes.Equal(bss.Bytes(), wt.btp.Bytes()) {
+			c.btp = bss
+		}
+	}
+	return c
+}
+
+// NewWorldTracker returns a WorldTracker recording accesses on the world
+// state.
+func NewWorldTracker(ws WorldState) WorldTracker {
+	return &worldTracker{
+		WorldState: ws,
+		accounts:   make(map[string]AccountSnapshot),
+	}
+}
+
+type trackedWorldSnapshot struct {
+	WorldSnapshot
+	tracker *worldTracker
+}
+
+func (s *trackedWorldSnapshot) GetAccountSnapshot(id []byte) AccountSnapshot {
+	s.tracker.touchAccount(id)
+	return s.WorldSnapshot.GetAccountSnapshot(id)
+}
+
+func (s *trackedWorldSnapshot) GetValidatorSnapshot() ValidatorSnapshot {
+	s.tracker.touchWorld()
+	return s.WorldSnapshot.GetValidatorSnapshot()
+}
+
+func (s *trackedWorldSnapshot) GetExtensionSnapshot() ExtensionSnapshot {
+	s.tracker.touchWorld()
+	return s.WorldSnapshot.GetExtensionSnapshot()
+}
+
+func (s *trackedWorldSnapshot) GetBTPSnapshot() BTPSnapshot {
+	s.tracker.touchWorld()
+	return s.WorldSnapshot.GetBTPSnapshot()
+}
@@ -0,0 +1,73 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common/db"
+)
+
+func TestWorldTracker_Changes(t *testing.T) {
+	ws := NewWorldState(db.NewMapDB(), nil, nil, nil, nil)
+	ws.GetAccountState([]byte("a")).SetBalance(big.NewInt(100))
+	base := ws.GetSnapshot()
+
+	ws1, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	wt1 := NewWorldTracker(ws1)
+	bal := wt1.GetAccountSnapshot([]byte("a")).GetBalance()
+	wt1.GetAccountState([]byte("b")).SetBalance(bal)
+	c1 := wt1.Changes()
+	assert.Equal(t, 1, c1.WriteCount())
+
+	ws2, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	wt2 := NewWorldTracker(ws2)
+	wt2.GetAccountState([]byte("a")).SetBalance(big.NewInt(50))
+	c2 := wt2.Changes()
+	assert.Equal(t, 1, c2.WriteCount())
+
+	// reads through the snapshot are recorded
+	ws3, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	wt3 := NewWorldTracker(ws3)
+	wss := wt3.GetSnapshot()
+	wss.GetAccountSnapshot([]byte("b"))
+	assert.NoError(t, wt3.Reset(wss))
+	c3 := wt3.Changes()
+	assert.Equal(t, 0, c3.WriteCount())
+
+	assert.True(t, c1.Conflicts(c2))
+	assert.False(t, c2.Conflicts(c1))
+	assert.True(t, c3.Conflicts(c1))
+	assert.False(t, c3.Conflicts(c2))
+
+	// applying changes results in the same state as sequential execution
+	ws4, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	assert.NoError(t, c1.Apply(ws4))
+	assert.NoError(t, c2.Apply(ws4))
+
+	ws5, err := WorldStateFromSnapshot(base)
+	assert.NoError(t, err)
+	ws5.GetAccountState([]byte("b")).SetBalance(big.NewInt(100))
+	ws5.GetAccountState([]byte("a")).SetBalance(big.NewInt(50))
+	assert.Equal(t, ws5.GetSnapshot().StateHash(), ws4.GetSnapshot().StateHash())
+}
+
+func TestWorldTracker_World(t *testing.T) {
+	ws := NewWorldState(db.NewMapDB(), nil, nil, nil, nil)
+	wt1 := NewWorldTracker(ws)
+	wt1.GetValidatorState()
+	c1 := wt1.Changes()
+	assert.Equal(t, 0, c1.WriteCount())
+	assert.False(t, c1.hasWorldWrite())
+
+	wt2 := NewWorldTracker(ws)
+	wt2.GetAccountSnapshot([]byte("a"))
+	c2 := wt2.Changes()
+	assert.False(t, c2.Conflicts(c1))
+	assert.False(t, c1.Conflicts(c2))
+}
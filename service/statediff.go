@@ -0,0 +1,179 @@
+package service
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/common/intconv"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+var errStateDiffLimit = errors.New("StateDiffLimitReached")
+
+type stateDiffCollector struct {
+	limit     int
+	addresses map[string]module.Address
+	accounts  []interface{}
+	extension []interface{}
+}
+
+func newStateDiffCollector(addrs []module.Address, limit int) *stateDiffCollector {
+	c := &stateDiffCollector{
+		limit:     limit,
+		addresses: make(map[string]module.Address),
+		accounts:  []interface{}{},
+		extension: []interface{}{},
+	}
+	c.addresses[string(state.AccountKeyOf(state.SystemAddress))] = state.SystemAddress
+	for _, addr := range addrs {
+		if addr == nil {
+			continue
+		}
+		c.addresses[string(state.AccountKeyOf(addr))] = addr
+	}
+	return c
+}
+
+func diffOpToString(op int) string {
+	switch op {
+	case state.DiffRemoved:
+		return "removed"
+	case state.DiffAdded:
+		return "added"
+	default:
+		return "changed"
+	}
+}
+
+func hexOf(bs []byte) interface{} {
+	if bs == nil {
+		return nil
+	}
+	return "0x" + hex.EncodeToString(bs)
+}
+
+func balanceOf(ass state.AccountSnapshot) *big.Int {
+	if ass == nil {
+		return new(big.Int)
+	}
+	return ass.GetBalance()
+}
+
+func codeHashOf(ass state.AccountSnapshot) interface{} {
+	if ass == nil || ass.Contract() == nil {
+		return nil
+	}
+	return hexOf(ass.Contract().CodeHash())
+}
+
+func (c *stateDiffCollector) checkLimit() error {
+	if c.limit > 0 && len(c.accounts)+len(c.extension) >= c.limit {
+		return errStateDiffLimit
+	}
+	return nil
+}
+
+func (c *stateDiffCollector) OnAccount(d *state.AccountDiff) error {
+	if err := c.checkLimit(); err != nil {
+		return err
+	}
+	jso := map[string]interface{}{
+		"key":   hexOf(d.Key),
+		"op":    diffOpToString(d.Op),
+		"parts": d.Parts,
+	}
+	if addr, ok := c.addresses[string(d.Key)]; ok {
+		jso["address"] = addr
+	}
+	b1, b2 := balanceOf(d.Old), balanceOf(d.New)
+	if b1.Cmp(b2) != 0 {
+		jso["balance"] = map[string]interface{}{
+			"old":   intconv.FormatBigInt(b1),
+			"new":   intconv.FormatBigInt(b2),
+			"delta": intconv.FormatBigInt(new(big.Int).Sub(b2, b1)),
+		}
+	}
+	if h1, h2 := codeHashOf(d.Old), codeHashOf(d.New); h1 != h2 {
+		jso["codeHash"] = map[string]interface{}{
+			"old": h1,
+			"new": h2,
+		}
+	}
+	if len(d.Storage) > 0 {
+		storage := make([]interface{}, 0, len(d.Storage))
+		for _, sd := range d.Storage {
+			storage = append(storage, map[string]interface{}{
+				"key": hexOf(sd.Key),
+				"op":  diffOpToString(sd.Op),
+				"old": hexOf(sd.Old),
+				"new": hexOf(sd.New),
+			})
+		}
+		jso["storage"] = storage
+	}
+	c.accounts = append(c.accounts, jso)
+	return nil
+}
+
+func (c *stateDiffCollector) OnExtension(d *state.ExtensionDiff) error {
+	if err := c.checkLimit(); err != nil {
+		return err
+	}
+	c.extension = append(c.extension, map[string]interface{}{
+		"part": d.Part,
+		"key":  hexOf(d.Key),
+		"op":   diffOpToString(d.Op),
+		"old":  hexOf(d.Old),
+		"new":  hexOf(d.New),
+	})
+	return nil
+}
+
+// worldSnapshotForDiff returns the world snapshot of the result, or of the
+// state hash with the extension data if byStateHash is true. It also returns
+// whether the extension of the snapshot is known.
+func (m *manager) worldSnapshotForDiff(v []byte, byStateHash bool, ext []byte) (state.WorldSnapshot, bool, error) {
+	if byStateHash {
+		var ess state.ExtensionSnapshot
+		if ext != nil {
+			ess = m.plt.NewExtensionSnapshot(m.db, ext)
+		}
+		return state.NewWorldSnapshot(m.db, v, nil, ess, nil), ext != nil, nil
+	}
+	wss, err := m.trc.GetWorldSnapshot(v, nil)
+	return wss, true, err
+}
+
+func (m *manager) GetStateDiff(req *module.StateDiffRequest) (interface{}, error) {
+	if req.ByStateHash && (req.FromExtension == nil) != (req.ToExtension == nil) {
+		return nil, errors.IllegalArgumentError.New("BothExtensionRequired")
+	}
+	wss1, ext, err := m.worldSnapshotForDiff(req.From, req.ByStateHash, req.FromExtension)
+	if err != nil {
+		return nil, err
+	}
+	wss2, _, err := m.worldSnapshotForDiff(req.To, req.ByStateHash, req.ToExtension)
+	if err != nil {
+		return nil, err
+	}
+	c := newStateDiffCollector(req.Addresses, req.Limit)
+	truncated := false
+	if err := state.DiffWorldSnapshots(wss1, wss2, c); err != nil {
+		if err != errStateDiffLimit {
+			return nil, err
+		}
+		truncated = true
+	}
+	jso := map[string]interface{}{
+		"accounts":  c.accounts,
+		"extension": c.extension,
+		"truncated": truncated,
+	}
+	if !ext {
+		// the extension isn't compared without extension data
+		jso["extension"] = nil
+	}
+	return jso, nil
+}
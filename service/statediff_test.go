@@ -0,0 +1,41 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/state"
+)
+
+func TestStateDiffCollector_Address(t *testing.T) {
+	addr := common.MustNewAddressFromString("hx0000000000000000000000000000000000000001")
+	other := common.MustNewAddressFromString("hx0000000000000000000000000000000000000002")
+
+	ws := state.NewWorldState(db.NewMapDB(), nil, nil, nil, nil)
+	wss1 := ws.GetSnapshot()
+	assert.NoError(t, wss1.Flush())
+	ws.GetAccountState(addr.ID()).SetBalance(big.NewInt(1))
+	ws.GetAccountState(other.ID()).SetBalance(big.NewInt(2))
+	ws.GetAccountState(state.SystemID).SetBalance(big.NewInt(3))
+	wss2 := ws.GetSnapshot()
+	assert.NoError(t, wss2.Flush())
+
+	c := newStateDiffCollector([]module.Address{addr, nil}, 0)
+	assert.NoError(t, state.DiffWorldSnapshots(wss1, wss2, c))
+	assert.Len(t, c.accounts, 3)
+	addrs := make(map[string]bool)
+	for _, a := range c.accounts {
+		if v, ok := a.(map[string]interface{})["address"]; ok {
+			addrs[v.(module.Address).String()] = true
+		}
+	}
+	assert.Equal(t, map[string]bool{
+		addr.String():                true,
+		state.SystemAddress.String(): true,
+	}, addrs)
+}
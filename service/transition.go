@@ -895,6 +895,9 @@ func (t *transition) executeTxs(l module.TransactionList, ctx contract.Context,
 		return t.executeTxsSequential(l, ctx, rctBuf)
 	}
 	if cc := t.chain.ConcurrencyLevel(); cc > 1 {
+		if t.chain.OptimisticExecution() && t.ti == nil {
+			return t.executeTxsOptimistic(cc, l, ctx, rctBuf)
+		}
 		return t.executeTxsConcurrent(cc, l, ctx, rctBuf)
 	}
 	return t.executeTxsSequential(l, ctx, rctBuf)
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/icon-project/goloop/common/errors"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/transaction"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+// optimisticBase is the latest world snapshot with the number of
+// transactions applied to it.
+type optimisticBase struct {
+	lock     sync.Mutex
+	snapshot state.WorldSnapshot
+	applied  int
+}
+
+func (b *optimisticBase) Get() (state.WorldSnapshot, int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.snapshot, b.applied
+}
+
+func (b *optimisticBase) Set(wss state.WorldSnapshot, applied int) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.snapshot = wss
+	b.applied = applied
+}
+
+// speculation is the result of the execution of a transaction on the
+// base with the first applied transactions.
+type speculation struct {
+	done    chan struct{}
+	applied int
+	rct     txresult.Receipt
+	changes *state.WorldChanges
+	err     error
+}
+
+func (t *transition) executeTx(ctx contract.Context, txo transaction.Transaction, idx int) (txresult.Receipt, error) {
+	ctx.SetTransactionInfo(&state.TransactionInfo{
+		Group:     txo.Group(),
+		Index:     int32(idx),
+		Timestamp: txo.Timestamp(),
+		Nonce:     txo.Nonce(),
+		Hash:      txo.ID(),
+		From:      txo.From(),
+	})
+	wcs := ctx.GetSnapshot()
+	for retry := 0; ; retry++ {
+		txh, err := txo.GetHandler(t.cm)
+		if err != nil {
+			return nil, err
+		}
+		ctx.UpdateSystemInfo()
+		rct, err := txh.Execute(ctx, wcs, false)
+		txh.Dispose()
+		if err == nil {
+			if err = t.plt.OnTransactionEnd(ctx, t.log, rct); err == nil {
+				return rct, nil
+			}
+		}
+		if !errors.ExecutionFailError.Equals(err) && !errors.CriticalRerunError.Equals(err) {
+			return nil, err
+		}
+		if retry >= RetryCount {
+			t.log.Warnf("Fail to execute transaction retry=%d err=%+v", retry, err)
+			return nil, err
+		}
+		t.log.Warnf("RETRY TX <%#x> for err=%+v", txo.ID(), err)
+		if err := ctx.Reset(wcs); err != nil {
+			return nil, errors.CriticalUnknownError.Wrapf(err, "FailToResetForRetry")
+		}
+	}
+}
+
+func (t *transition) speculateTx(
+	wc state.WorldContext, base *optimisticBase,
+	txo transaction.Transaction, idx int, sp *speculation,
+) {
+	defer close(sp.done)
+	wss, applied := base.Get()
+	ws, err := state.WorldStateFromSnapshot(wss)
+	if err != nil {
+		sp.err = err
+		return
+	}
+	wt := state.NewWorldTracker(ws)
+	ctx := t.newContractContext(wc.WorldStateChanged(wt))
+	sp.applied = applied
+	sp.rct, sp.err = t.executeTx(ctx, txo, idx)
+	sp.changes = wt.Changes()
+}
+
+// executeTxsOptimistic executes transactions speculatively on the latest
+// world snapshot with recording accessed states. Then it applies the
+// results in order if the transaction didn't read any state written by
+// transactions applied after the snapshot. Otherwise, it executes the
+// transaction again on the current world state.
+func (t *transition) executeTxsOptimistic(level int, l module.TransactionList, ctx contract.Context, rctBuf []txresult.Receipt) error {
+	var txs []transaction.Transaction
+	for i := l.Iterator(); i.Has(); i.Next() {
+		txi, _, err := i.Get()
+		if err != nil {
+			t.log.Errorf("Fail to iterate transaction list err=%+v", err)
+			return err
+		}
+		txs = append(txs, txi.(transaction.Transaction))
+	}
+	if len(txs) == 0 {
+		return nil
+	}
+
+	base := &optimisticBase{snapshot: ctx.GetSnapshot()}
+	wc := ctx.WorldStateChanged(ctx)
+	sps := make([]*speculation, len(txs))
+	for i := range sps {
+		sps[i] = &speculation{done: make(chan struct{})}
+	}
+
+	jobs := make(chan int)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < level; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				t.speculateTx(wc, base, txs[idx], idx, sps[idx])
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for idx := range txs {
+			select {
+			case jobs <- idx:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	changes := make([]*state.WorldChanges, len(txs))
+	reruns := 0
+	for idx, txo := range txs {
+		if t.canceled() {
+			return ErrTransitionInterrupted
+		}
+		sp := sps[idx]
+		<-sp.done
+
+		ctx.SetTransactionInfo(&state.TransactionInfo{
+			Group:     txo.Group(),
+			Index:     int32(idx),
+			Timestamp: txo.Timestamp(),
+			Nonce:     txo.Nonce(),
+			Hash:      txo.ID(),
+			From:      txo.From(),
+		})
+		ctx.UpdateSystemInfo()
+
+		conflict := sp.err != nil
+		for i := sp.applied; !conflict && i < idx; i++ {
+			conflict = sp.changes.Conflicts(changes[i])
+		}
+		if !conflict {
+			if err := sp.changes.Apply(ctx); err != nil {
+				return err
+			}
+			rctBuf[idx] = sp.rct
+			changes[idx] = sp.changes
+		} else {
+			t.log.Tracef("RERUN TX <%#x>", txo.ID())
+			wt := state.NewWorldTracker(ctx)
+			rct, err := t.executeTx(t.newContractContext(ctx.WorldStateChanged(wt)), txo, idx)
+			if err != nil {
+				t.log.Warnf("Fail to execute transaction err=%+v", err)
+				return err
+			}
+			rctBuf[idx] = rct
+			changes[idx] = wt.Changes()
+			reruns++
+		}
+		base.Set(ctx.GetSnapshot(), idx+1)
+	}
+	// applied changes may update the system information of the context,
+	// which is updated by the transaction itself in sequential execution.
+	ctx.UpdateSystemInfo()
+	t.log.Debugf("executeTxsOptimistic() txs=%d reruns=%d", len(txs), reruns)
+	return nil
+}
@@ -0,0 +1,323 @@
+package service_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/common/wallet"
+	"github.com/icon-project/goloop/icon"
+	"github.com/icon-project/goloop/icon/icmodule"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service"
+	"github.com/icon-project/goloop/service/eeproxy"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/transaction"
+	"github.com/icon-project/goloop/test"
+)
+
+const oeTestTermPeriod = 10
+
+// oeTestChain overrides the execution mode of the chain.
+type oeTestChain struct {
+	*test.Chain
+	level      int
+	optimistic bool
+}
+
+func (c *oeTestChain) ConcurrencyLevel() int {
+	return c.level
+}
+
+func (c *oeTestChain) OptimisticExecution() bool {
+	return c.optimistic
+}
+
+type oeTestCallback chan error
+
+func (cb oeTestCallback) OnValidate(tr module.Transition, err error) {
+	if err != nil {
+		cb <- err
+	}
+}
+
+func (cb oeTestCallback) OnExecute(tr module.Transition, err error) {
+	cb <- err
+}
+
+// oeTestBlock is the result of a block executed with the ICON platform.
+type oeTestBlock struct {
+	result     []byte
+	validators []byte
+	receipts   [][]byte
+}
+
+// oeTestExecutor executes blocks of transactions with the ICON platform.
+type oeTestExecutor struct {
+	t      *testing.T
+	dbase  db.Database
+	chain  *oeTestChain
+	em     eeproxy.Manager
+	plt    base.Platform
+	parent module.Transition
+	height int64
+}
+
+func newOETestExecutor(t *testing.T, gtx []byte, level int, optimistic bool) *oeTestExecutor {
+	dir := t.TempDir()
+	dbase := db.NewMapDB()
+	logger := log.New()
+	logger.SetLevel(log.WarnLevel)
+	c, err := test.NewChain(t, wallet.New(), dbase, logger, nil, string(gtx))
+	assert.NoError(t, err)
+	chain := &oeTestChain{Chain: c, level: level, optimistic: optimistic}
+
+	plt, err := icon.NewPlatform(dir, chain.CID())
+	assert.NoError(t, err)
+	cm, err := plt.NewContractManager(dbase, path.Join(dir, "contract"), logger)
+	assert.NoError(t, err)
+	ee, err := eeproxy.AllocEngines(logger, "none")
+	assert.NoError(t, err)
+	em, err := eeproxy.NewManager("unix", path.Join(dir, "ee.sock"), logger, ee...)
+	assert.NoError(t, err)
+	go func() {
+		_ = em.Loop()
+	}()
+
+	tr, err := service.NewInitTransition(dbase, nil, nil, cm, em, chain,
+		logger, plt, service.NewTimestampChecker())
+	assert.NoError(t, err)
+	ex := &oeTestExecutor{t: t, dbase: dbase, chain: chain, em: em, plt: plt, parent: tr}
+	tx, err := transaction.NewGenesisTransaction(gtx)
+	assert.NoError(t, err)
+	ex.execute(tx)
+	return ex
+}
+
+func (ex *oeTestExecutor) execute(txs ...module.Transaction) *oeTestBlock {
+	t := ex.t
+	empty := transaction.NewTransactionListFromSlice(ex.dbase, nil)
+	bi := common.NewBlockInfo(ex.height, ex.height*2_000_000)
+	csi := common.NewConsensusInfo(nil, nil, nil)
+
+	// add base transaction as ProposeTransition does after decentralization
+	wss, err := service.NewWorldSnapshot(ex.dbase, ex.plt, ex.parent.Result(), ex.parent.NextValidators())
+	assert.NoError(t, err)
+	ws, err := state.WorldStateFromSnapshot(wss)
+	assert.NoError(t, err)
+	baseTx, err := ex.plt.NewBaseTransaction(state.NewWorldContext(ws, bi, csi, ex.plt))
+	assert.NoError(t, err)
+	if baseTx != nil {
+		txs = append([]module.Transaction{baseTx}, txs...)
+	}
+
+	tr := service.NewTransition(
+		ex.parent,
+		empty,
+		transaction.NewTransactionListFromSlice(ex.dbase, txs),
+		bi,
+		csi,
+		true,
+	)
+	cb := make(oeTestCallback, 2)
+	_, err = tr.Execute(cb)
+	assert.NoError(t, err)
+	assert.NoError(t, <-cb)
+	assert.NoError(t, service.FinalizeTransition(tr,
+		module.FinalizeNormalTransaction|module.FinalizePatchTransaction|module.FinalizeResult,
+		false))
+
+	blk := &oeTestBlock{
+		result:     tr.Result(),
+		validators: tr.NextValidators().Hash(),
+	}
+	for i := tr.NormalReceipts().Iterator(); i.Has(); i.Next() {
+		rct, err := i.Get()
+		assert.NoError(t, err)
+		assert.Equal(t, module.StatusSuccess, rct.Status(), "height=%d idx=%d", ex.height, len(blk.receipts))
+		blk.receipts = append(blk.receipts, rct.Bytes())
+	}
+	ex.parent = tr
+	ex.height++
+	return blk
+}
+
+func (ex *oeTestExecutor) Close() {
+	_ = ex.em.Close()
+	ex.chain.Close()
+}
+
+// oeTestTxBuilder makes signed transactions for blocks.
+type oeTestTxBuilder struct {
+	t     *testing.T
+	nid   int
+	count int
+}
+
+func (b *oeTestTxBuilder) newTx(
+	w module.Wallet, to module.Address, value *big.Int, method string, params interface{},
+) module.Transaction {
+	b.count++
+	tx := map[string]interface{}{
+		"version":   "0x3",
+		"from":      w.Address().String(),
+		"to":        to.String(),
+		"stepLimit": "0x10000000",
+		"timestamp": fmt.Sprintf("%#x", b.count),
+		"nid":       fmt.Sprintf("%#x", b.nid),
+	}
+	if value != nil {
+		tx["value"] = common.NewHexInt(0).SetValue(value).String()
+	}
+	if len(method) > 0 {
+		tx["dataType"] = "call"
+		data := map[string]interface{}{"method": method}
+		if params != nil {
+			data["params"] = params
+		}
+		tx["data"] = data
+	}
+	js, err := json.Marshal(tx)
+	assert.NoError(b.t, err)
+	bs, err := transaction.SerializeJSON(js, nil, nil)
+	assert.NoError(b.t, err)
+	sig, err := w.Sign(crypto.SHA3Sum256(append([]byte("icx_sendTransaction."), bs...)))
+	assert.NoError(b.t, err)
+	tx["signature"] = sig
+	js, err = json.Marshal(tx)
+	assert.NoError(b.t, err)
+	txo, err := transaction.NewTransactionFromJSON(js)
+	assert.NoError(b.t, err)
+	return txo
+}
+
+func icx(v int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(v), icmodule.BigIntICX)
+}
+
+func TestTransition_ExecuteTxsOptimisticWithICON(t *testing.T) {
+	conf := path.Join(t.TempDir(), "icon_config.json")
+	assert.NoError(t, os.WriteFile(conf, []byte(fmt.Sprintf(`{
+		"termPeriod": "%#x",
+		"mainPRepCount": "0x4",
+		"subPRepCount": "0x4",
+		"extraMainPRepCount": "0x0"
+	}`, oeTestTermPeriod)), 0644))
+	t.Setenv("ICON_CONFIG", conf)
+
+	god := wallet.New()
+	wallets := make([]module.Wallet, 8)
+	for i := range wallets {
+		wallets[i] = wallet.New()
+	}
+	const nid = 3
+	gtx := []byte(fmt.Sprintf(`{
+		"accounts": [
+			{ "name": "god", "address": "%s", "balance": "0x2961fff8ca4a62327800000" },
+			{ "name": "treasury", "address": "hx1000000000000000000000000000000000000000", "balance": "0x0" }
+		],
+		"chain": {
+			"revision": "%#x",
+			"validatorList": [ "%s" ],
+			"fee": {
+				"stepPrice": "0x2e90edd00",
+				"stepLimit": { "invoke": "0x78000000", "query": "0x780000" },
+				"stepCosts": { "default": "0x186a0", "contractCall": "0x61a8", "input": "0xc8", "set": "0x140", "eventLog": "0x64" }
+			}
+		},
+		"message": "genesis for optimistic execution",
+		"nid": "%#x"
+	}`, god.Address(), icmodule.LatestRevision, god.Address(), nid))
+
+	b := &oeTestTxBuilder{t: t, nid: nid}
+	var blocks [][]module.Transaction
+	var txs []module.Transaction
+	for _, w := range wallets {
+		txs = append(txs, b.newTx(god, w.Address(), icx(3_000_000), "", nil))
+	}
+	blocks = append(blocks, txs)
+
+	txs = nil
+	for i, w := range wallets {
+		next := wallets[(i+1)%len(wallets)]
+		txs = append(txs,
+			b.newTx(w, state.SystemAddress, icx(2000), "registerPRep", map[string]interface{}{
+				"name":        fmt.Sprintf("prep%d", i),
+				"email":       fmt.Sprintf("prep%d@example.com", i),
+				"website":     "https://example.com",
+				"country":     "KOR",
+				"city":        "Seoul",
+				"details":     "https://example.com/details.json",
+				"p2pEndpoint": fmt.Sprintf("prep%d.example.com:7100", i),
+				"nodeAddress": w.Address().String(),
+			}),
+			b.newTx(w, next.Address(), icx(10), "", nil),
+			b.newTx(w, state.SystemAddress, nil, "setStake", map[string]interface{}{
+				"value": common.NewHexInt(0).SetValue(icx(2_500_000)).String(),
+			}),
+			b.newTx(w, state.SystemAddress, nil, "setPRepNodePublicKey", map[string]interface{}{
+				"pubKey": common.HexBytes(w.PublicKey()).String(),
+			}),
+			b.newTx(w, state.SystemAddress, nil, "setBonderList", map[string]interface{}{
+				"bonderList": []interface{}{w.Address().String()},
+			}),
+			b.newTx(w, state.SystemAddress, nil, "setBond", map[string]interface{}{
+				"bonds": []interface{}{
+					map[string]interface{}{
+						"address": w.Address().String(),
+						"value":   common.NewHexInt(0).SetValue(icx(200_000)).String(),
+					},
+				},
+			}),
+		)
+	}
+	for i, w := range wallets {
+		txs = append(txs,
+			b.newTx(w, state.SystemAddress, nil, "setDelegation", map[string]interface{}{
+				"delegations": []interface{}{
+					map[string]interface{}{
+						"address": wallets[(i+2)%len(wallets)].Address().String(),
+						"value":   common.NewHexInt(0).SetValue(icx(2_000_000 + 10_000*int64(i))).String(),
+					},
+				},
+			}),
+		)
+	}
+	blocks = append(blocks, txs)
+
+	run := func(level int, optimistic bool) []*oeTestBlock {
+		ex := newOETestExecutor(t, gtx, level, optimistic)
+		defer ex.Close()
+		var results []*oeTestBlock
+		for _, txs := range blocks {
+			results = append(results, ex.execute(txs...))
+		}
+		// pass terms to apply P-Reps to validators
+		for i := 0; i < oeTestTermPeriod*2; i++ {
+			results = append(results, ex.execute())
+		}
+		return results
+	}
+	expected := run(1, false)
+	assert.NotEqual(t, expected[0].validators, expected[len(expected)-1].validators)
+	for _, level := range []int{2, 8} {
+		t.Run(fmt.Sprintf("level=%d", level), func(t *testing.T) {
+			results := run(level, level > 1)
+			for i := range expected {
+				assert.Equal(t, expected[i].receipts, results[i].receipts, "receipts height=%d", i+1)
+				assert.Equal(t, expected[i].result, results[i].result, "result height=%d", i+1)
+				assert.Equal(t, expected[i].validators, results[i].validators, "validators height=%d", i+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/icon-project/goloop/chain/base"
+	"github.com/icon-project/goloop/common"
+	"github.com/icon-project/goloop/common/crypto"
+	"github.com/icon-project/goloop/common/db"
+	"github.com/icon-project/goloop/common/log"
+	"github.com/icon-project/goloop/module"
+	"github.com/icon-project/goloop/service/contract"
+	"github.com/icon-project/goloop/service/scoredb"
+	"github.com/icon-project/goloop/service/state"
+	"github.com/icon-project/goloop/service/transaction"
+	"github.com/icon-project/goloop/service/txresult"
+)
+
+type oeTestPlatform struct {
+	base.Platform
+}
+
+func (p *oeTestPlatform) ToRevision(value int) module.Revision {
+	return module.LatestRevision
+}
+
+func (p *oeTestPlatform) OnTransactionEnd(wc state.WorldContext, logger log.Logger, rct txresult.Receipt) error {
+	return nil
+}
+
+// oeTestTx updates the counter of the account with the value depending on
+// the previous one, so the result depends on the order of transactions.
+type oeTestTx struct {
+	transaction.Transaction
+	id      []byte
+	account module.Address
+	value   int64
+}
+
+func newOETestTx(idx int, account int, value int64) *oeTestTx {
+	return &oeTestTx{
+		id:      crypto.SHA3Sum256([]byte(fmt.Sprintf("tx%d", idx))),
+		account: common.MustNewAddressFromString(fmt.Sprintf("hx%040x", account)),
+		value:   value,
+	}
+}
+
+func (tx *oeTestTx) Group() module.TransactionGroup { return module.TransactionGroupNormal }
+func (tx *oeTestTx) ID() []byte                     { return tx.id }
+func (tx *oeTestTx) From() module.Address           { return tx.account }
+func (tx *oeTestTx) To() module.Address             { return tx.account }
+func (tx *oeTestTx) Timestamp() int64               { return 0 }
+func (tx *oeTestTx) Nonce() *big.Int                { return nil }
+func (tx *oeTestTx) IsSkippable() bool              { return false }
+
+func (tx *oeTestTx) GetHandler(cm contract.ContractManager) (transaction.Handler, error) {
+	return tx, nil
+}
+
+func (tx *oeTestTx) Prepare(ctx contract.Context) (state.WorldContext, error) {
+	return ctx, nil
+}
+
+func (tx *oeTestTx) Execute(ctx contract.Context, wcs state.WorldSnapshot, estimate bool) (txresult.Receipt, error) {
+	as := ctx.GetAccountState(tx.account.ID())
+	counter := scoredb.NewVarDB(as, "counter")
+	value := counter.Int64()*3 + tx.value
+	if err := counter.Set(value); err != nil {
+		return nil, err
+	}
+	r := txresult.NewReceipt(ctx.Database(), ctx.Revision(), tx.account)
+	r.SetResult(module.StatusSuccess, big.NewInt(value), big.NewInt(1), nil)
+	return r, nil
+}
+
+func (tx *oeTestTx) Dispose() {}
+
+type oeTestTxList struct {
+	module.TransactionList
+	txs []module.Transaction
+}
+
+func (l *oeTestTxList) Iterator() module.TransactionIterator {
+	return &oeTestTxIterator{txs: l.txs}
+}
+
+type oeTestTxIterator struct {
+	txs []module.Transaction
+	idx int
+}
+
+func (i *oeTestTxIterator) Has() bool { return i.idx < len(i.txs) }
+
+func (i *oeTestTxIterator) Next() error {
+	i.idx++
+	return nil
+}
+
+func (i *oeTestTxIterator) Get() (module.Transaction, int, error) {
+	return i.txs[i.idx], i.idx, nil
+}
+
+// executeOETestTxs executes transactions on the empty world state, and
+// returns the hash of receipts and the state hash.
+func executeOETestTxs(t *testing.T, l *oeTestTxList, level int) ([]byte, []byte) {
+	dbase := db.NewMapDB()
+	tr := &transition{
+		transitionContext: &transitionContext{
+			db:  dbase,
+			log: log.New(),
+			plt: &oeTestPlatform{},
+		},
+	}
+	ws := state.NewWorldState(dbase, nil, nil, nil, nil)
+	wc := state.NewWorldContext(ws, common.NewBlockInfo(1, 0), nil, tr.plt)
+	ctx := tr.newContractContext(wc)
+
+	rcts := make([]txresult.Receipt, len(l.txs))
+	var err error
+	if level > 1 {
+		err = tr.executeTxsOptimistic(level, l, ctx, rcts)
+	} else {
+		err = tr.executeTxsSequential(l, ctx, rcts)
+	}
+	assert.NoError(t, err)
+	rl := txresult.NewReceiptListFromSlice(dbase, rcts)
+	return rl.Hash(), ctx.GetSnapshot().StateHash()
+}
+
+func TestTransition_ExecuteTxsOptimistic(t *testing.T) {
+	l := new(oeTestTxList)
+	// most of transactions update a few accounts, so they conflict with
+	// transactions executed at the same time.
+	for i := 0; i < 64; i++ {
+		l.txs = append(l.txs, newOETestTx(i, i%3+1, int64(i)))
+	}
+	for i := 0; i < 8; i++ {
+		l.txs = append(l.txs, newOETestTx(64+i, 10+i, int64(i)))
+	}
+
+	rh, sh := executeOETestTxs(t, l, 1)
+	for _, level := range []int{2, 4, 16} {
+		t.Run(fmt.Sprintf("level=%d", level), func(t *testing.T) {
+			rh2, sh2 := executeOETestTxs(t, l, level)
+			assert.Equal(t, rh, rh2)
+			assert.Equal(t, sh, sh2)
+		})
+	}
+}
@@ -143,6 +143,14 @@ func NewBTPContext(dbase db.Database, result []byte) (state.BTPContext, error) {
 	return state.NewBTPContext(nil, as), nil
 }
 
+func ExtensionDataFromResult(result []byte) ([]byte, error) {
+	r, err := newTransitionResultFromBytes(result)
+	if err != nil {
+		return nil, err
+	}
+	return r.ExtensionData, nil
+}
+
 func BTPDigestHashFromResult(result []byte) ([]byte, error) {
 	r, err := newTransitionResultFromBytes(result)
 	if err != nil {
@@ -81,6 +81,10 @@ func (c *Chain) ConcurrencyLevel() int {
 	return 1
 }
 
+func (c *Chain) OptimisticExecution() bool {
+	return false
+}
+
 func (c *Chain) NormalTxPoolSize() int {
 	return 5000
 }
@@ -113,6 +117,18 @@ func (c *Chain) NephewsLimit() int {
 	panic("implement me")
 }
 
+func (c *Chain) BandwidthLimit() string {
+	panic("implement me")
+}
+
+func (c *Chain) PrivatePeers() string {
+	panic("implement me")
+}
+
+func (c *Chain) DisableDiscovery() bool {
+	panic("implement me")
+}
+
 func (c *Chain) ValidateTxOnSend() bool {
 	panic("implement me")
 }
@@ -245,6 +261,10 @@ func (c *Chain) WalletFor(dsa string) module.BaseWallet {
 	case "ecdsa/secp256k1":
 		return c.wallet
 	}
+	if bw := ntm.NewDSAWallet(dsa, c.wallet); bw != nil {
+		c.bwMap[dsa] = bw
+		return bw
+	}
 	return nil
 }
 
@@ -269,7 +289,7 @@ func (wp *walletProvider) WalletFor(dsa string) module.BaseWallet {
 	case "ecdsa/secp256k1":
 		return wp.wallet
 	}
-	return nil
+	return ntm.NewDSAWallet(dsa, wp.wallet)
 }
 
 func NewWalletProvider() module.WalletProvider {